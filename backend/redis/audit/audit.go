@@ -0,0 +1,259 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/devtoolbox/redis/glob"
+)
+
+// 默认的轮换参数
+const (
+	DefaultMaxSize  = 10 << 20 // 单个文件的最大字节数
+	DefaultMaxFiles = 5        // 保留的历史文件数量
+)
+
+// 操作结果
+const (
+	ResultOK       = "ok"
+	ResultError    = "error"
+	ResultRejected = "rejected" // 被权限或危险命令规则拒绝
+)
+
+// ErrInvalidPattern 查询条件中的键模式无效
+var ErrInvalidPattern = errors.New("invalid key pattern")
+
+// KeyState 操作前键的状态，键不存在时Type为none
+type KeyState struct {
+	Key  string `json:"key"`
+	Type string `json:"type"`
+	Size int64  `json:"size"` // 字符串为字节数，其他类型为元素数量
+}
+
+// Entry 一条审计记录
+type Entry struct {
+	Time         time.Time  `json:"time"`
+	Origin       string     `json:"origin,omitempty"`     // 请求的Origin或Referer
+	RemoteAddr   string     `json:"remoteAddr,omitempty"` // 请求的来源地址
+	SessionID    string     `json:"sessionId,omitempty"`  // 只记录会话ID，不记录Token本身
+	ConnectionID string     `json:"connectionId,omitempty"`
+	Environment  string     `json:"environment,omitempty"`
+	Command      string     `json:"command"`
+	Keys         []string   `json:"keys,omitempty"`
+	Before       []KeyState `json:"before,omitempty"`
+	Result       string     `json:"result"`
+	Error        string     `json:"error,omitempty"`
+	Affected     int64      `json:"affected"`
+}
+
+// Filter 查询条件，零值的条件不生效
+type Filter struct {
+	ConnectionID string
+	KeyPattern   string // 至少有一个键匹配，语法与KEYS相同
+	Since        time.Time
+	Until        time.Time
+	Limit        int // 最多返回的条数，从最新的记录开始
+}
+
+// Log 只追加的JSONL审计日志，文件超过maxSize后轮换为path.1、path.2……
+type Log struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	size     int64
+	mutex    sync.Mutex
+}
+
+// Open 打开或创建审计日志，maxSize与maxFiles不大于0时使用默认值
+func Open(path string, maxSize int64, maxFiles int) (*Log, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	if maxFiles <= 0 {
+		maxFiles = DefaultMaxFiles
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %v", err)
+	}
+
+	l := &Log{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Path 返回当前日志文件的路径
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Record 追加一条记录，Time为零时使用当前时间
+func (l *Log) Record(entry Entry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %v", err)
+	}
+	line = append(line, '\n')
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.file == nil {
+		return fmt.Errorf("audit log is closed")
+	}
+	if l.size > 0 && l.size+int64(len(line)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write audit entry: %v", err)
+	}
+	return nil
+}
+
+// Query 按条件查询记录，包括已轮换的文件，结果按时间从新到旧排列
+func (l *Log) Query(filter Filter) ([]Entry, error) {
+	if filter.KeyPattern != "" {
+		if !glob.Valid(filter.KeyPattern) {
+			return nil, fmt.Errorf("%w %q", ErrInvalidPattern, filter.KeyPattern)
+		}
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	entries := make([]Entry, 0)
+	for i := l.maxFiles; i >= 0; i-- {
+		if err := readEntries(l.backup(i), func(entry Entry) {
+			if filter.matches(&entry) {
+				entries = append(entries, entry)
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, nil
+}
+
+// Close 关闭日志文件
+func (l *Log) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// open 以追加方式打开当前文件
+func (l *Log) open() error {
+	file, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %v", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open audit log: %v", err)
+	}
+	l.file = file
+	l.size = info.Size()
+	return nil
+}
+
+// rotate 将当前文件依次后移为path.1……，超出maxFiles的文件被删除
+func (l *Log) rotate() error {
+	if err := l.file.Close(); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %v", err)
+	}
+	l.file = nil
+
+	os.Remove(l.backup(l.maxFiles))
+	for i := l.maxFiles - 1; i >= 0; i-- {
+		if err := os.Rename(l.backup(i), l.backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate audit log: %v", err)
+		}
+	}
+	return l.open()
+}
+
+// backup 返回第n个历史文件的路径，0为当前文件
+func (l *Log) backup(n int) string {
+	if n == 0 {
+		return l.path
+	}
+	return fmt.Sprintf("%s.%d", l.path, n)
+}
+
+// matches 检查记录是否满足条件
+func (f *Filter) matches(entry *Entry) bool {
+	if f.ConnectionID != "" && entry.ConnectionID != f.ConnectionID {
+		return false
+	}
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && entry.Time.After(f.Until) {
+		return false
+	}
+	if f.KeyPattern != "" {
+		for _, key := range entry.Keys {
+			if glob.Match(f.KeyPattern, key) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// readEntries 逐行读取文件中的记录，文件不存在时不报错，无法解析的行被跳过
+func readEntries(path string, fn func(Entry)) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read audit log: %v", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		fn(entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read audit log: %v", err)
+	}
+	return nil
+}
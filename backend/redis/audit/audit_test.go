@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLog_RecordAndQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := Open(path, 0, 0)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer log.Close()
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := []Entry{
+		{Time: base, ConnectionID: "c1", Command: "DEL", Keys: []string{"user:1"}, Result: ResultOK, Affected: 1,
+			Before: []KeyState{{Key: "user:1", Type: "hash", Size: 3}}},
+		{Time: base.Add(time.Minute), ConnectionID: "c2", Command: "RENAME", Keys: []string{"a", "b"}, Result: ResultRejected},
+		{Time: base.Add(2 * time.Minute), ConnectionID: "c1", Command: "FLUSHDB", Result: ResultOK, Affected: 10},
+	}
+	for _, entry := range records {
+		if err := log.Record(entry); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("Expected audit log with mode 0600: %v, %v", info, err)
+	}
+
+	tests := []struct {
+		name     string
+		filter   Filter
+		commands []string
+	}{
+		{"all newest first", Filter{}, []string{"FLUSHDB", "RENAME", "DEL"}},
+		{"connection", Filter{ConnectionID: "c1"}, []string{"FLUSHDB", "DEL"}},
+		{"key pattern", Filter{KeyPattern: "user:*"}, []string{"DEL"}},
+		{"since", Filter{Since: base.Add(time.Minute)}, []string{"FLUSHDB", "RENAME"}},
+		{"until", Filter{Until: base.Add(time.Minute)}, []string{"RENAME", "DEL"}},
+		{"limit", Filter{Limit: 1}, []string{"FLUSHDB"}},
+	}
+	for _, tt := range tests {
+		entries, err := log.Query(tt.filter)
+		if err != nil {
+			t.Fatalf("%s: Query failed: %v", tt.name, err)
+		}
+		var commands []string
+		for _, entry := range entries {
+			commands = append(commands, entry.Command)
+		}
+		if len(commands) != len(tt.commands) {
+			t.Errorf("%s: got %v, want %v", tt.name, commands, tt.commands)
+			continue
+		}
+		for i := range commands {
+			if commands[i] != tt.commands[i] {
+				t.Errorf("%s: got %v, want %v", tt.name, commands, tt.commands)
+				break
+			}
+		}
+	}
+
+	entries, _ := log.Query(Filter{KeyPattern: "user:*"})
+	if len(entries) != 1 || len(entries[0].Before) != 1 || entries[0].Before[0].Type != "hash" {
+		t.Errorf("Expected before state to round-trip: %+v", entries)
+	}
+	if _, err := log.Query(Filter{KeyPattern: "["}); !errors.Is(err, ErrInvalidPattern) {
+		t.Error("Expected invalid key pattern to be rejected")
+	}
+
+	// 与KEYS相同，*可以匹配/
+	log.Record(Entry{Command: "DEL", Keys: []string{"session:eu/1"}, Result: ResultOK})
+	if entries, _ := log.Query(Filter{KeyPattern: "session:*"}); len(entries) != 1 {
+		t.Errorf("Expected * to match across /, got %d entries", len(entries))
+	}
+}
+
+func TestLog_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log, err := Open(path, 200, 2)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	for i := 0; i < 20; i++ {
+		if err := log.Record(Entry{ConnectionID: "c1", Command: "DEL", Keys: []string{"key"}, Result: ResultOK}); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("Expected %s to exist: %v", name, err)
+		}
+		if info.Size() > 200 {
+			t.Errorf("Expected %s to stay under the size limit, got %d", name, info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("Expected rotation to keep only two backups")
+	}
+
+	// 重新打开后继续追加，查询包含历史文件
+	log.Close()
+	reopened, err := Open(path, 200, 2)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer reopened.Close()
+	before, _ := reopened.Query(Filter{})
+	reopened.Record(Entry{ConnectionID: "c2", Command: "FLUSHDB", Result: ResultOK})
+	entries, err := reopened.Query(Filter{})
+	if err != nil || len(entries) == 0 || entries[0].ConnectionID != "c2" {
+		t.Fatalf("Expected newest entry first after reopen: %+v, %v", entries, err)
+	}
+	if len(before) < 2 {
+		t.Errorf("Expected entries from backups to be queried, got %d", len(before))
+	}
+}
@@ -0,0 +1,241 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// keyringVersion 密钥环文件格式版本
+const keyringVersion = 1
+
+// signingKey HMAC-SHA256签名密钥
+type signingKey struct {
+	ID        string    `json:"id"`
+	Secret    []byte    `json:"secret"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// keyringFile 密钥环文件，同时保存撤销列表以便重启后仍然生效
+type keyringFile struct {
+	Version int                  `json:"version"`
+	Keys    []signingKey         `json:"keys"`
+	Revoked map[string]time.Time `json:"revoked,omitempty"` // 会话ID -> 撤销记录保留到的时间
+}
+
+// Keyring Token签名密钥环与撤销列表
+// 最新的密钥用于签名，被替换的密钥在其签发的Token全部过期前仍然用于验证；
+// path为空时只保存在内存中，重启后之前签发的Token全部失效
+type Keyring struct {
+	mutex   sync.RWMutex
+	path    string
+	keys    []signingKey // 按创建时间排序，最后一个用于签名
+	revoked map[string]time.Time
+}
+
+// NewKeyring 创建只保存在内存中的密钥环
+func NewKeyring() *Keyring {
+	k := &Keyring{revoked: make(map[string]time.Time)}
+	if _, err := k.Rotate(); err != nil {
+		// 只有系统随机数不可用时才会失败
+		panic(err)
+	}
+	return k
+}
+
+// OpenKeyring 打开密钥环文件，文件不存在时生成第一个密钥并保存
+// 文件包含签名密钥，权限为0600
+func OpenKeyring(path string) (*Keyring, error) {
+	k := &Keyring{path: path, revoked: make(map[string]time.Time)}
+
+	content, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		var file keyringFile
+		if err := json.Unmarshal(content, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse token keyring %s: %v", path, err)
+		}
+		if file.Version != keyringVersion {
+			return nil, fmt.Errorf("unsupported token keyring version %d", file.Version)
+		}
+		if len(file.Keys) == 0 {
+			return nil, fmt.Errorf("token keyring %s has no keys", path)
+		}
+		k.keys = file.Keys
+		for id, until := range file.Revoked {
+			k.revoked[id] = until
+		}
+		return k, nil
+	case os.IsNotExist(err):
+		if _, err := k.Rotate(); err != nil {
+			return nil, err
+		}
+		return k, nil
+	default:
+		return nil, fmt.Errorf("failed to read token keyring %s: %v", path, err)
+	}
+}
+
+// Rotate 生成新的签名密钥并返回其ID，之后签发的Token使用新密钥
+func (k *Keyring) Rotate() (string, error) {
+	id := make([]byte, 4)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate key id: %v", err)
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate signing key: %v", err)
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	key := signingKey{ID: hex.EncodeToString(id), Secret: secret, CreatedAt: time.Now()}
+	k.keys = append(k.keys, key)
+	if err := k.save(); err != nil {
+		k.keys = k.keys[:len(k.keys)-1]
+		return "", err
+	}
+	return key.ID, nil
+}
+
+// CurrentKey 返回当前签名密钥的ID与创建时间
+func (k *Keyring) CurrentKey() (string, time.Time) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	current := k.keys[len(k.keys)-1]
+	return current.ID, current.CreatedAt
+}
+
+// Prune 删除被替换超过retain的密钥以及到期的撤销记录
+// retain应不短于Token的有效期，否则旧密钥签发的Token会提前失效
+func (k *Keyring) Prune(retain time.Duration) error {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	now := time.Now()
+	changed := false
+	// keys[i]在keys[i+1]创建时被替换
+	for len(k.keys) > 1 && now.Sub(k.keys[1].CreatedAt) > retain {
+		k.keys = k.keys[1:]
+		changed = true
+	}
+	for id, until := range k.revoked {
+		if now.After(until) {
+			delete(k.revoked, id)
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return k.save()
+}
+
+// Revoke 将会话加入撤销列表，记录保留到until，此后该会话的Token都已过期
+func (k *Keyring) Revoke(id string, until time.Time) error {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if previous, exists := k.revoked[id]; exists && !until.After(previous) {
+		return nil
+	}
+	k.revoked[id] = until
+	return k.save()
+}
+
+// IsRevoked 检查会话是否已被撤销
+func (k *Keyring) IsRevoked(id string) bool {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	_, revoked := k.revoked[id]
+	return revoked
+}
+
+// Revoked 返回撤销列表的副本
+func (k *Keyring) Revoked() map[string]time.Time {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	revoked := make(map[string]time.Time, len(k.revoked))
+	for id, until := range k.revoked {
+		revoked[id] = until
+	}
+	return revoked
+}
+
+// sign 使用当前密钥签名，返回密钥ID与签名
+func (k *Keyring) sign(data []byte) (string, []byte) {
+	k.mutex.RLock()
+	current := k.keys[len(k.keys)-1]
+	k.mutex.RUnlock()
+
+	return current.ID, mac(current.Secret, current.ID, data)
+}
+
+// verify 使用指定的密钥验证签名，密钥已被删除时验证失败
+func (k *Keyring) verify(keyID string, data, signature []byte) bool {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	for _, key := range k.keys {
+		if key.ID == keyID {
+			return hmac.Equal(mac(key.Secret, key.ID, data), signature)
+		}
+	}
+	return false
+}
+
+// mac 计算HMAC-SHA256，密钥ID也参与签名
+func mac(secret []byte, keyID string, data []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(keyID))
+	h.Write([]byte{'.'})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// save 原子地写入密钥环文件，调用方需持有写锁
+func (k *Keyring) save() error {
+	if k.path == "" {
+		return nil
+	}
+
+	content, err := json.MarshalIndent(keyringFile{
+		Version: keyringVersion,
+		Keys:    k.keys,
+		Revoked: k.revoked,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(k.path), 0700); err != nil {
+		return fmt.Errorf("failed to create keyring directory: %v", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(k.path), ".keyring-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write token keyring: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write token keyring: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), k.path); err != nil {
+		return fmt.Errorf("failed to write token keyring: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/devtoolbox/redis/command"
+)
+
+// 会话的权限，较高的权限包含较低的权限
+const (
+	// ScopeRead 只能执行读取命令
+	ScopeRead = "read"
+	// ScopeWrite 可以写入、修改和删除键
+	ScopeWrite = "write"
+	// ScopeAdmin 可以清空数据库、修改配置等危险操作
+	ScopeAdmin = "admin"
+)
+
+// scopeLevels 权限从低到高的顺序
+var scopeLevels = []string{ScopeRead, ScopeWrite, ScopeAdmin}
+
+// DefaultScopes 连接时未指定权限的会话可以读写，但不能执行危险操作
+var DefaultScopes = []string{ScopeRead, ScopeWrite}
+
+// requiredScopes 执行各类命令需要的权限
+var requiredScopes = map[command.Category]string{
+	command.Read:   ScopeRead,
+	command.Write:  ScopeWrite,
+	command.Delete: ScopeWrite,
+	command.Flush:  ScopeAdmin,
+	command.Config: ScopeAdmin,
+}
+
+// ErrForbidden 会话的权限不足以执行命令
+var ErrForbidden = errors.New("insufficient scope")
+
+// ParseScopes 校验连接时请求的权限并补全其包含的较低权限，为空时返回DefaultScopes
+func ParseScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return append([]string(nil), DefaultScopes...), nil
+	}
+
+	highest := -1
+	for _, scope := range scopes {
+		level := scopeLevel(scope)
+		if level < 0 {
+			return nil, fmt.Errorf("unknown scope %q", scope)
+		}
+		if level > highest {
+			highest = level
+		}
+	}
+	return append([]string(nil), scopeLevels[:highest+1]...), nil
+}
+
+// ClampScopes 去掉高于max的权限，用于限制连接时请求的权限；max未知时只保留ScopeRead
+func ClampScopes(scopes []string, max string) []string {
+	limit := scopeLevel(max)
+	if limit < 0 {
+		limit = 0
+	}
+	clamped := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if level := scopeLevel(scope); level >= 0 && level <= limit {
+			clamped = append(clamped, scope)
+		}
+	}
+	return clamped
+}
+
+// Allows 检查会话是否可以执行所有给定的命令，命令按command.Classify分类
+// 没有权限信息的会话按DefaultScopes处理
+func (t *TokenInfo) Allows(commands ...string) error {
+	scopes := t.Scopes
+	if len(scopes) == 0 {
+		scopes = DefaultScopes
+	}
+	granted := make(map[string]bool, len(scopes))
+	for _, scope := range scopes {
+		granted[scope] = true
+	}
+
+	for _, name := range commands {
+		required := requiredScopes[command.Classify(name)]
+		if !granted[required] {
+			return fmt.Errorf("%w: %s requires the %s scope", ErrForbidden, name, required)
+		}
+	}
+	return nil
+}
+
+// HasScope 检查会话是否拥有给定的权限，没有权限信息的会话按DefaultScopes处理
+func (t *TokenInfo) HasScope(scope string) bool {
+	scopes := t.Scopes
+	if len(scopes) == 0 {
+		scopes = DefaultScopes
+	}
+	for _, granted := range scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// MaxScope 返回会话拥有的最高权限，没有权限信息的会话按DefaultScopes处理
+func (t *TokenInfo) MaxScope() string {
+	scopes := t.Scopes
+	if len(scopes) == 0 {
+		scopes = DefaultScopes
+	}
+	highest := 0
+	for _, scope := range scopes {
+		if level := scopeLevel(scope); level > highest {
+			highest = level
+		}
+	}
+	return scopeLevels[highest]
+}
+
+// scopeLevel 返回权限的级别，未知权限返回-1
+func scopeLevel(scope string) int {
+	for i, name := range scopeLevels {
+		if name == scope {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseScopes(t *testing.T) {
+	tests := []struct {
+		scopes []string
+		want   []string
+	}{
+		{nil, []string{ScopeRead, ScopeWrite}},
+		{[]string{"read"}, []string{ScopeRead}},
+		{[]string{"write"}, []string{ScopeRead, ScopeWrite}},
+		{[]string{"admin"}, []string{ScopeRead, ScopeWrite, ScopeAdmin}},
+		{[]string{"read", "admin"}, []string{ScopeRead, ScopeWrite, ScopeAdmin}},
+	}
+	for _, tt := range tests {
+		got, err := ParseScopes(tt.scopes)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseScopes(%v) = %v, %v, want %v", tt.scopes, got, err, tt.want)
+		}
+	}
+	if _, err := ParseScopes([]string{"root"}); err == nil {
+		t.Error("Expected unknown scope to be rejected")
+	}
+}
+
+func TestClampScopes(t *testing.T) {
+	all := []string{ScopeRead, ScopeWrite, ScopeAdmin}
+	tests := []struct {
+		max  string
+		want []string
+	}{
+		{ScopeAdmin, all},
+		{ScopeWrite, []string{ScopeRead, ScopeWrite}},
+		{ScopeRead, []string{ScopeRead}},
+		{"", []string{ScopeRead}},
+		{"root", []string{ScopeRead}},
+	}
+	for _, tt := range tests {
+		if got := ClampScopes(all, tt.max); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ClampScopes(%q) = %v, want %v", tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestTokenInfo_Allows(t *testing.T) {
+	readOnly := &TokenInfo{Scopes: []string{ScopeRead}}
+	if err := readOnly.Allows("SCAN", "INFO", "CLUSTER SLOTS"); err != nil {
+		t.Errorf("Expected read-only session to read: %v", err)
+	}
+	for _, command := range []string{"SET", "DEL", "FLUSHDB", "CONFIG SET"} {
+		if err := readOnly.Allows(command); !errors.Is(err, ErrForbidden) {
+			t.Errorf("Expected read-only session to be denied %s, got %v", command, err)
+		}
+	}
+
+	// 没有权限信息的会话可以读写，但不能执行危险操作
+	legacy := &TokenInfo{}
+	if err := legacy.Allows("GET", "SET", "DEL"); err != nil {
+		t.Errorf("Expected default session to read and write: %v", err)
+	}
+	if err := legacy.Allows("GET", "FLUSHALL"); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected default session to be denied FLUSHALL, got %v", err)
+	}
+
+	admin := &TokenInfo{Scopes: []string{ScopeRead, ScopeWrite, ScopeAdmin}}
+	if err := admin.Allows("FLUSHDB", "CONFIG SET", "DEL"); err != nil {
+		t.Errorf("Expected admin session to run everything: %v", err)
+	}
+}
+
+func TestTokenInfo_HasScope(t *testing.T) {
+	if (&TokenInfo{}).HasScope(ScopeAdmin) {
+		t.Error("Expected default session not to be admin")
+	}
+	if !(&TokenInfo{}).HasScope(ScopeWrite) {
+		t.Error("Expected default session to write")
+	}
+	admin := &TokenInfo{Scopes: []string{ScopeRead, ScopeWrite, ScopeAdmin}}
+	if !admin.HasScope(ScopeAdmin) {
+		t.Error("Expected admin session to have the admin scope")
+	}
+}
+
+func TestTokenInfo_MaxScope(t *testing.T) {
+	tests := []struct {
+		scopes []string
+		want   string
+	}{
+		{nil, ScopeWrite},
+		{[]string{ScopeRead}, ScopeRead},
+		{[]string{ScopeRead, ScopeWrite, ScopeAdmin}, ScopeAdmin},
+	}
+	for _, tt := range tests {
+		if got := (&TokenInfo{Scopes: tt.scopes}).MaxScope(); got != tt.want {
+			t.Errorf("MaxScope(%v) = %s, want %s", tt.scopes, got, tt.want)
+		}
+	}
+}
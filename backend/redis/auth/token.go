@@ -2,8 +2,11 @@ package auth
 
 import (
 	"crypto/rand"
+	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 )
@@ -11,155 +14,329 @@ import (
 // TokenInfo Token信息
 type TokenInfo struct {
 	Token        string    `json:"token"`
+	SessionID    string    `json:"sessionId"` // 刷新与切换数据库后签发的Token属于同一个会话
 	ConnectionID string    `json:"connectionId"`
+	ProfileID    string    `json:"profileId,omitempty"` // 通过保存的配置连接时，重启后可以据此重新连接
+	DB           int       `json:"db"`
+	Scopes       []string  `json:"scopes,omitempty"`
 	CreatedAt    time.Time `json:"createdAt"`
 	ExpiresAt    time.Time `json:"expiresAt"`
 	LastUsed     time.Time `json:"lastUsed"`
 }
 
+// Session 签发Token时写入的会话信息
+type Session struct {
+	ConnectionID string
+	ProfileID    string
+	DB           int
+	Scopes       []string
+}
+
+// claims Token中签名的内容
+type claims struct {
+	SessionID    string   `json:"sid"`
+	ConnectionID string   `json:"cid"`
+	ProfileID    string   `json:"pid,omitempty"`
+	DB           int      `json:"db,omitempty"`
+	Scopes       []string `json:"scp,omitempty"`
+	IssuedAt     int64    `json:"iat"`
+	ExpiresAt    int64    `json:"exp"`
+}
+
 // TokenManager Token管理器
+// Token格式为 <密钥ID>.<base64url(claims)>.<base64url(HMAC)>，不依赖服务端状态即可验证，
+// 后端重启后仍然有效；内存中只记录本进程内使用过的会话及其当前连接，用于引用计数与按连接撤销
 type TokenManager struct {
-	tokens      map[string]*TokenInfo
+	sessions    map[string]*TokenInfo // 会话ID -> 会话
 	mutex       sync.RWMutex
 	tokenExpiry time.Duration
+	keyring     *Keyring
 }
 
-// NewTokenManager 创建新的Token管理器
+// NewTokenManager 创建新的Token管理器，签名密钥只保存在内存中
 func NewTokenManager(tokenExpiry time.Duration) *TokenManager {
+	return NewTokenManagerWithKeyring(tokenExpiry, NewKeyring())
+}
+
+// NewTokenManagerWithKeyring 使用指定的密钥环创建Token管理器
+func NewTokenManagerWithKeyring(tokenExpiry time.Duration, keyring *Keyring) *TokenManager {
 	return &TokenManager{
-		tokens:      make(map[string]*TokenInfo),
+		sessions:    make(map[string]*TokenInfo),
 		tokenExpiry: tokenExpiry,
+		keyring:     keyring,
 	}
 }
 
-// GenerateToken 生成新的Token
-func (tm *TokenManager) GenerateToken(connectionID string) (*TokenInfo, error) {
-	tm.mutex.Lock()
-	defer tm.mutex.Unlock()
-
-	// 生成随机Token
-	tokenBytes := make([]byte, 32)
-	if _, err := rand.Read(tokenBytes); err != nil {
+// GenerateToken 为新会话签发Token
+func (tm *TokenManager) GenerateToken(session Session) (*TokenInfo, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
 		return nil, fmt.Errorf("failed to generate token: %v", err)
 	}
 
-	token := hex.EncodeToString(tokenBytes)
 	now := time.Now()
-
-	// 创建Token信息
 	tokenInfo := &TokenInfo{
-		Token:        token,
-		ConnectionID: connectionID,
+		SessionID:    hex.EncodeToString(id),
+		ConnectionID: session.ConnectionID,
+		ProfileID:    session.ProfileID,
+		DB:           session.DB,
+		Scopes:       session.Scopes,
 		CreatedAt:    now,
 		ExpiresAt:    now.Add(tm.tokenExpiry),
 		LastUsed:     now,
 	}
+	if err := tm.sign(tokenInfo); err != nil {
+		return nil, err
+	}
 
-	// 存储Token
-	tm.tokens[token] = tokenInfo
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
 
-	return tokenInfo, nil
+	tm.sessions[tokenInfo.SessionID] = tokenInfo
+	info := *tokenInfo
+	return &info, nil
 }
 
-// ValidateToken 验证Token
+// ValidateToken 验证Token的签名、有效期与撤销列表
+// 会话在本进程中使用过时返回其当前连接，否则按Token中的内容返回
 func (tm *TokenManager) ValidateToken(token string) (*TokenInfo, error) {
-	tm.mutex.RLock()
-	defer tm.mutex.RUnlock()
-
-	tokenInfo, exists := tm.tokens[token]
-	if !exists {
-		return nil, fmt.Errorf("invalid token")
+	tokenInfo, err := tm.parse(token)
+	if err != nil {
+		return nil, err
 	}
 
-	// 检查Token是否过期
-	if time.Now().After(tokenInfo.ExpiresAt) {
-		return nil, fmt.Errorf("token expired")
-	}
-
-	// 更新最后使用时间
-	tokenInfo.LastUsed = time.Now()
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
 
+	if session, tracked := tm.sessions[tokenInfo.SessionID]; tracked {
+		session.LastUsed = time.Now()
+		info := *session
+		info.Token = token
+		return &info, nil
+	}
 	return tokenInfo, nil
 }
 
-// RefreshToken 刷新Token
-func (tm *TokenManager) RefreshToken(token string) (*TokenInfo, error) {
+// Attach 将验证过的会话绑定到连接，例如重启后根据保存的配置重新连接
+// 返回会话之前绑定的连接ID，调用方需要释放该连接的引用
+func (tm *TokenManager) Attach(tokenInfo *TokenInfo, connectionID string) (string, error) {
+	if tm.keyring.IsRevoked(tokenInfo.SessionID) {
+		return "", fmt.Errorf("token revoked")
+	}
+
 	tm.mutex.Lock()
 	defer tm.mutex.Unlock()
 
-	tokenInfo, exists := tm.tokens[token]
-	if !exists {
-		return nil, fmt.Errorf("invalid token")
+	var previous string
+	if session, tracked := tm.sessions[tokenInfo.SessionID]; tracked {
+		previous = session.ConnectionID
 	}
+	session := *tokenInfo
+	session.ConnectionID = connectionID
+	session.LastUsed = time.Now()
+	tm.sessions[session.SessionID] = &session
+	return previous, nil
+}
 
-	// 检查Token是否过期
-	if time.Now().After(tokenInfo.ExpiresAt) {
-		return nil, fmt.Errorf("token expired")
+// RefreshToken 刷新Token，签发属于同一会话、有效期重新计算的Token
+// 原Token在其过期前仍然有效
+func (tm *TokenManager) RefreshToken(token string) (*TokenInfo, error) {
+	tokenInfo, err := tm.ValidateToken(token)
+	if err != nil {
+		return nil, err
 	}
 
 	// 延长过期时间
 	now := time.Now()
 	tokenInfo.ExpiresAt = now.Add(tm.tokenExpiry)
 	tokenInfo.LastUsed = now
+	return tm.reissue(tokenInfo)
+}
 
-	return tokenInfo, nil
+// RebindToken 将会话改为指向另一个连接与数据库，例如切换数据库之后
+// 返回写入新连接的Token，有效期不变
+func (tm *TokenManager) RebindToken(token, connectionID string, db int) (*TokenInfo, error) {
+	tokenInfo, err := tm.ValidateToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenInfo.ConnectionID = connectionID
+	tokenInfo.DB = db
+	tokenInfo.LastUsed = time.Now()
+	return tm.reissue(tokenInfo)
 }
 
-// RevokeToken 撤销Token
-func (tm *TokenManager) RevokeToken(token string) error {
+// reissue 重新签名并更新内存中的会话
+func (tm *TokenManager) reissue(tokenInfo *TokenInfo) (*TokenInfo, error) {
+	if err := tm.sign(tokenInfo); err != nil {
+		return nil, err
+	}
+
 	tm.mutex.Lock()
 	defer tm.mutex.Unlock()
 
-	if _, exists := tm.tokens[token]; !exists {
-		return fmt.Errorf("token not found")
+	session := *tokenInfo
+	tm.sessions[session.SessionID] = &session
+	return tokenInfo, nil
+}
+
+// RevokeToken 撤销Token所属的会话，同一会话签发的所有Token都会失效
+// 返回本进程中记录的会话，调用方需要释放其连接的引用；会话未被记录时返回nil
+func (tm *TokenManager) RevokeToken(token string) (*TokenInfo, error) {
+	tokenInfo, err := tm.parse(token)
+	if err != nil {
+		return nil, err
 	}
 
-	delete(tm.tokens, token)
-	return nil
+	tm.mutex.Lock()
+	session := tm.sessions[tokenInfo.SessionID]
+	delete(tm.sessions, tokenInfo.SessionID)
+	tm.mutex.Unlock()
+
+	// 同一会话的Token最晚在现在加上有效期后过期，撤销记录保留到那时
+	if err := tm.keyring.Revoke(tokenInfo.SessionID, time.Now().Add(tm.tokenExpiry)); err != nil {
+		return session, err
+	}
+	return session, nil
 }
 
-// RevokeTokensByConnectionID 根据连接ID撤销所有Token
+// RevokeTokensByConnectionID 根据连接ID撤销所有会话
 func (tm *TokenManager) RevokeTokensByConnectionID(connectionID string) {
 	tm.mutex.Lock()
-	defer tm.mutex.Unlock()
-
-	for token, tokenInfo := range tm.tokens {
-		if tokenInfo.ConnectionID == connectionID {
-			delete(tm.tokens, token)
+	var revoked []string
+	for id, session := range tm.sessions {
+		if session.ConnectionID == connectionID {
+			delete(tm.sessions, id)
+			revoked = append(revoked, id)
 		}
 	}
+	tm.mutex.Unlock()
+
+	until := time.Now().Add(tm.tokenExpiry)
+	for _, id := range revoked {
+		// 保存失败时撤销记录仍然在内存中生效
+		tm.keyring.Revoke(id, until)
+	}
 }
 
-// CleanupExpiredTokens 清理过期Token
-func (tm *TokenManager) CleanupExpiredTokens() {
+// CleanupExpiredTokens 清理过期的会话、到期的撤销记录以及不再需要的旧密钥
+// 返回被清理的会话以便释放其连接
+func (tm *TokenManager) CleanupExpiredTokens() []*TokenInfo {
 	tm.mutex.Lock()
-	defer tm.mutex.Unlock()
-
+	var expired []*TokenInfo
 	now := time.Now()
-	for token, tokenInfo := range tm.tokens {
-		if now.After(tokenInfo.ExpiresAt) {
-			delete(tm.tokens, token)
+	for id, session := range tm.sessions {
+		if now.After(session.ExpiresAt) {
+			delete(tm.sessions, id)
+			expired = append(expired, session)
 		}
 	}
+	tm.mutex.Unlock()
+
+	tm.keyring.Prune(tm.tokenExpiry)
+	return expired
+}
+
+// RotateKey 生成新的签名密钥，旧密钥签发的Token在过期前仍然有效
+func (tm *TokenManager) RotateKey() (string, error) {
+	id, err := tm.keyring.Rotate()
+	if err != nil {
+		return "", err
+	}
+	return id, tm.keyring.Prune(tm.tokenExpiry)
 }
 
-// GetTokenCount 获取Token数量
+// CurrentKey 返回当前签名密钥的ID与创建时间
+func (tm *TokenManager) CurrentKey() (string, time.Time) {
+	return tm.keyring.CurrentKey()
+}
+
+// RevokedSessions 返回撤销列表：会话ID -> 撤销记录保留到的时间
+func (tm *TokenManager) RevokedSessions() map[string]time.Time {
+	return tm.keyring.Revoked()
+}
+
+// GetTokenCount 获取本进程中记录的会话数量
 func (tm *TokenManager) GetTokenCount() int {
 	tm.mutex.RLock()
 	defer tm.mutex.RUnlock()
 
-	return len(tm.tokens)
+	return len(tm.sessions)
 }
 
-// ListTokens 列出所有Token（用于调试）
+// ListTokens 列出本进程中记录的会话（用于调试）
 func (tm *TokenManager) ListTokens() []*TokenInfo {
 	tm.mutex.RLock()
 	defer tm.mutex.RUnlock()
 
-	tokens := make([]*TokenInfo, 0, len(tm.tokens))
-	for _, tokenInfo := range tm.tokens {
-		tokens = append(tokens, tokenInfo)
+	tokens := make([]*TokenInfo, 0, len(tm.sessions))
+	for _, session := range tm.sessions {
+		info := *session
+		tokens = append(tokens, &info)
 	}
 
 	return tokens
-}
\ No newline at end of file
+}
+
+// sign 将会话写入claims并签名，结果保存在tokenInfo.Token
+func (tm *TokenManager) sign(tokenInfo *TokenInfo) error {
+	payload, err := json.Marshal(claims{
+		SessionID:    tokenInfo.SessionID,
+		ConnectionID: tokenInfo.ConnectionID,
+		ProfileID:    tokenInfo.ProfileID,
+		DB:           tokenInfo.DB,
+		Scopes:       tokenInfo.Scopes,
+		IssuedAt:     time.Now().Unix(),
+		ExpiresAt:    tokenInfo.ExpiresAt.Unix(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate token: %v", err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	keyID, signature := tm.keyring.sign([]byte(encoded))
+	tokenInfo.Token = keyID + "." + encoded + "." + base64.RawURLEncoding.EncodeToString(signature)
+	return nil
+}
+
+// parse 验证Token并解析claims，不查询内存中的会话
+func (tm *TokenManager) parse(token string) (*TokenInfo, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("invalid token")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !tm.keyring.verify(parts[0], []byte(parts[1]), signature) {
+		return nil, fmt.Errorf("invalid token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token")
+	}
+	var c claims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	// 检查Token是否过期
+	expiresAt := time.Unix(c.ExpiresAt, 0)
+	if time.Now().After(expiresAt) {
+		return nil, fmt.Errorf("token expired")
+	}
+	if tm.keyring.IsRevoked(c.SessionID) {
+		return nil, fmt.Errorf("token revoked")
+	}
+
+	return &TokenInfo{
+		Token:        token,
+		SessionID:    c.SessionID,
+		ConnectionID: c.ConnectionID,
+		ProfileID:    c.ProfileID,
+		DB:           c.DB,
+		Scopes:       c.Scopes,
+		CreatedAt:    time.Unix(c.IssuedAt, 0),
+		ExpiresAt:    expiresAt,
+		LastUsed:     time.Now(),
+	}, nil
+}
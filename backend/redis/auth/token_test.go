@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTokenManager_SurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	keyring, err := OpenKeyring(path)
+	if err != nil {
+		t.Fatalf("OpenKeyring failed: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("Expected keyring file with mode 0600: %v, %v", info, err)
+	}
+
+	tm := NewTokenManagerWithKeyring(time.Hour, keyring)
+	issued, err := tm.GenerateToken(Session{ConnectionID: "c1", ProfileID: "p1", DB: 2, Scopes: []string{"read"}})
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+	if strings.Count(issued.Token, ".") != 2 || issued.SessionID == "" {
+		t.Fatalf("Unexpected token: %+v", issued)
+	}
+
+	// 重新打开密钥环模拟重启，内存中没有会话也能验证Token
+	reopened, err := OpenKeyring(path)
+	if err != nil {
+		t.Fatalf("OpenKeyring failed: %v", err)
+	}
+	restarted := NewTokenManagerWithKeyring(time.Hour, reopened)
+	info, err := restarted.ValidateToken(issued.Token)
+	if err != nil {
+		t.Fatalf("ValidateToken after restart failed: %v", err)
+	}
+	if info.SessionID != issued.SessionID || info.ConnectionID != "c1" || info.ProfileID != "p1" ||
+		info.DB != 2 || len(info.Scopes) != 1 || info.Scopes[0] != "read" {
+		t.Errorf("Unexpected token info after restart: %+v", info)
+	}
+	if restarted.GetTokenCount() != 0 {
+		t.Error("Expected no tracked sessions before the connection is restored")
+	}
+	if previous, err := restarted.Attach(info, "c2"); err != nil || previous != "" {
+		t.Errorf("Attach = %q, %v", previous, err)
+	}
+	if info, _ := restarted.ValidateToken(issued.Token); info.ConnectionID != "c2" {
+		t.Errorf("Expected attached session to use c2, got %s", info.ConnectionID)
+	}
+
+	// 其他密钥签名或被篡改的Token无效
+	other := NewTokenManager(time.Hour)
+	if _, err := other.ValidateToken(issued.Token); err == nil {
+		t.Error("Expected token signed with another key to be rejected")
+	}
+	parts := strings.Split(issued.Token, ".")
+	tampered := parts[0] + "." + parts[1] + "x." + parts[2]
+	if _, err := tm.ValidateToken(tampered); err == nil {
+		t.Error("Expected tampered token to be rejected")
+	}
+	if _, err := tm.ValidateToken("not-a-token"); err == nil {
+		t.Error("Expected malformed token to be rejected")
+	}
+}
+
+func TestTokenManager_RefreshRevokeAndRebind(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	keyring, err := OpenKeyring(path)
+	if err != nil {
+		t.Fatalf("OpenKeyring failed: %v", err)
+	}
+	tm := NewTokenManagerWithKeyring(time.Hour, keyring)
+
+	issued, _ := tm.GenerateToken(Session{ConnectionID: "c1"})
+	refreshed, err := tm.RefreshToken(issued.Token)
+	if err != nil {
+		t.Fatalf("RefreshToken failed: %v", err)
+	}
+	if refreshed.SessionID != issued.SessionID || refreshed.ExpiresAt.Before(issued.ExpiresAt) {
+		t.Errorf("Unexpected refreshed token: %+v", refreshed)
+	}
+
+	rebound, err := tm.RebindToken(refreshed.Token, "c2", 3)
+	if err != nil {
+		t.Fatalf("RebindToken failed: %v", err)
+	}
+	if info, _ := tm.ValidateToken(issued.Token); info.ConnectionID != "c2" || info.DB != 3 {
+		t.Errorf("Expected the session to follow the rebind, got %+v", info)
+	}
+	if info, _ := NewTokenManagerWithKeyring(time.Hour, keyring).ValidateToken(rebound.Token); info.ConnectionID != "c2" || info.DB != 3 {
+		t.Errorf("Expected the rebound token to carry c2/3, got %+v", info)
+	}
+
+	// 撤销会话后同一会话的所有Token都失效，重启后仍然有效
+	session, err := tm.RevokeToken(rebound.Token)
+	if err != nil || session == nil || session.ConnectionID != "c2" {
+		t.Fatalf("RevokeToken = %+v, %v", session, err)
+	}
+	for _, token := range []string{issued.Token, refreshed.Token, rebound.Token} {
+		if _, err := tm.ValidateToken(token); err == nil {
+			t.Error("Expected token of revoked session to be rejected")
+		}
+	}
+	reopened, _ := OpenKeyring(path)
+	if _, err := NewTokenManagerWithKeyring(time.Hour, reopened).ValidateToken(issued.Token); err == nil {
+		t.Error("Expected revocation to survive a restart")
+	}
+	if revoked := tm.RevokedSessions(); len(revoked) != 1 {
+		t.Errorf("Expected one revoked session, got %v", revoked)
+	}
+
+	// 按连接撤销
+	a, _ := tm.GenerateToken(Session{ConnectionID: "c3"})
+	b, _ := tm.GenerateToken(Session{ConnectionID: "c4"})
+	tm.RevokeTokensByConnectionID("c3")
+	if _, err := tm.ValidateToken(a.Token); err == nil {
+		t.Error("Expected token of c3 to be revoked")
+	}
+	if _, err := tm.ValidateToken(b.Token); err != nil {
+		t.Errorf("Expected token of c4 to stay valid: %v", err)
+	}
+}
+
+func TestTokenManager_KeyRotation(t *testing.T) {
+	tm := NewTokenManager(time.Hour)
+	oldKey, _ := tm.CurrentKey()
+	before, _ := tm.GenerateToken(Session{ConnectionID: "c1"})
+
+	newKey, err := tm.RotateKey()
+	if err != nil || newKey == oldKey {
+		t.Fatalf("RotateKey = %q, %v", newKey, err)
+	}
+	after, _ := tm.GenerateToken(Session{ConnectionID: "c1"})
+	if !strings.HasPrefix(after.Token, newKey+".") {
+		t.Errorf("Expected new tokens to be signed with %s: %s", newKey, after.Token)
+	}
+	if _, err := tm.ValidateToken(before.Token); err != nil {
+		t.Errorf("Expected token signed with the previous key to stay valid: %v", err)
+	}
+
+	// 旧密钥被替换超过保留时间后删除，其签发的Token失效
+	time.Sleep(5 * time.Millisecond)
+	tm.keyring.Prune(time.Millisecond)
+	if _, err := tm.ValidateToken(before.Token); err == nil {
+		t.Error("Expected token signed with a pruned key to be rejected")
+	}
+	if _, err := tm.ValidateToken(after.Token); err != nil {
+		t.Errorf("Expected token signed with the current key to stay valid: %v", err)
+	}
+}
+
+func TestTokenManager_Expiry(t *testing.T) {
+	tm := NewTokenManager(-time.Second)
+	issued, _ := tm.GenerateToken(Session{ConnectionID: "c1"})
+	if _, err := tm.ValidateToken(issued.Token); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("Expected expired token, got %v", err)
+	}
+	if expired := tm.CleanupExpiredTokens(); len(expired) != 1 || expired[0].ConnectionID != "c1" {
+		t.Errorf("Expected expired session to be cleaned up, got %+v", expired)
+	}
+	if count := tm.GetTokenCount(); count != 0 {
+		t.Errorf("Expected no sessions, got %d", count)
+	}
+}
@@ -0,0 +1,68 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSlot(t *testing.T) {
+	if crc := crc16("123456789"); crc != 0x31c3 {
+		t.Errorf("Expected CRC16 check value 0x31c3, got %#x", crc)
+	}
+
+	tests := []struct {
+		key  string
+		slot int
+	}{
+		{"foo", 12182},
+		{"bar", 5061},
+		{"user:1000", 1649},
+		{"{user1000}.following", Slot("user1000")},
+		{"{user1000}.followers", Slot("user1000")},
+		{"foo{}{bar}", Slot("foo{}{bar}")},
+		{"foo{{bar}}zap", Slot("{bar")},
+		{"foo{bar}{zap}", Slot("bar")},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := Slot(tt.key); got != tt.slot {
+			t.Errorf("Slot(%q) = %d, want %d", tt.key, got, tt.slot)
+		}
+	}
+	// 空的花括号不是hashtag，需要对整个键计算
+	if Slot("foo{}{bar}") == Slot("bar") {
+		t.Error("Empty hashtag should not be used")
+	}
+}
+
+func TestParseNodes(t *testing.T) {
+	text := `07c37dfeb235213a872192d90877d0cd55635b91 127.0.0.1:30004@31004,node-4 slave e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 0 1426238317239 4 connected
+67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1 127.0.0.1:30002@31002 master - 0 1426238316232 2 connected 5461-10922
+e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 127.0.0.1:30001@31001 myself,master - 0 0 1 connected 0-5460 [5461->-67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1]
+6ec23923021cf3ffec47632106199cb7f496ce01 127.0.0.1:30005@31005 master - 0 1426238316232 5 connected 10923 10924-16383
+`
+	nodes, err := ParseNodes(text)
+	if err != nil {
+		t.Fatalf("ParseNodes failed: %v", err)
+	}
+	if len(nodes) != 4 {
+		t.Fatalf("Expected 4 nodes, got %d", len(nodes))
+	}
+
+	replica := nodes[0]
+	if replica.Addr != "127.0.0.1:30004" || replica.IsMaster() || replica.MasterID != "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca" {
+		t.Errorf("Unexpected replica: %+v", replica)
+	}
+	if !nodes[2].IsMyself() || !reflect.DeepEqual(nodes[2].Slots, []SlotRange{{0, 5460}}) {
+		t.Errorf("Unexpected myself node: %+v", nodes[2])
+	}
+	if !reflect.DeepEqual(nodes[3].Slots, []SlotRange{{10923, 10923}, {10924, 16383}}) {
+		t.Errorf("Unexpected slots: %+v", nodes[3].Slots)
+	}
+
+	for _, bad := range []string{"id addr master", "id 1.2.3.4:1@2 master - 0 0 1 connected 16384", "id 1.2.3.4:1@2 master - 0 0 1 connected 5-1"} {
+		if _, err := ParseNodes(bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package cluster
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Node CLUSTER NODES输出中的一个节点
+type Node struct {
+	ID        string      `json:"id"`
+	Addr      string      `json:"addr"`
+	Flags     []string    `json:"flags"`
+	MasterID  string      `json:"masterId,omitempty"`
+	LinkState string      `json:"linkState"`
+	Slots     []SlotRange `json:"slots,omitempty"`
+}
+
+// SlotRange 节点负责的槽位区间，Start与End都包含在内
+type SlotRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// IsMaster 是否为主节点
+func (n Node) IsMaster() bool {
+	return n.hasFlag("master")
+}
+
+// IsMyself 是否为执行命令的节点
+func (n Node) IsMyself() bool {
+	return n.hasFlag("myself")
+}
+
+func (n Node) hasFlag(flag string) bool {
+	for _, f := range n.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseNodes 解析CLUSTER NODES的输出
+// 每行格式：<id> <ip:port@cport[,hostname]> <flags> <master> <ping-sent> <pong-recv> <config-epoch> <link-state> <slot>...
+// 正在迁移的槽位（[slot->-id]、[slot-<-id]）会被忽略
+func ParseNodes(text string) ([]Node, error) {
+	var nodes []Node
+	for lineNo, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 8 {
+			return nil, fmt.Errorf("invalid cluster nodes line %d: %q", lineNo+1, line)
+		}
+
+		addr := fields[1]
+		if i := strings.IndexAny(addr, "@,"); i >= 0 {
+			addr = addr[:i]
+		}
+		node := Node{
+			ID:        fields[0],
+			Addr:      addr,
+			Flags:     strings.Split(fields[2], ","),
+			LinkState: fields[7],
+		}
+		if fields[3] != "-" {
+			node.MasterID = fields[3]
+		}
+
+		for _, field := range fields[8:] {
+			if strings.HasPrefix(field, "[") {
+				continue
+			}
+			slots, err := parseSlotRange(field)
+			if err != nil {
+				return nil, fmt.Errorf("invalid cluster nodes line %d: %w", lineNo+1, err)
+			}
+			node.Slots = append(node.Slots, slots)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// parseSlotRange 解析"5461"或"0-5460"形式的槽位
+func parseSlotRange(field string) (SlotRange, error) {
+	startText, endText, isRange := strings.Cut(field, "-")
+	start, err := strconv.Atoi(startText)
+	if err != nil {
+		return SlotRange{}, fmt.Errorf("invalid slot %q", field)
+	}
+	end := start
+	if isRange {
+		if end, err = strconv.Atoi(endText); err != nil {
+			return SlotRange{}, fmt.Errorf("invalid slot %q", field)
+		}
+	}
+	if start < 0 || end >= SlotCount || start > end {
+		return SlotRange{}, fmt.Errorf("slot out of range %q", field)
+	}
+	return SlotRange{Start: start, End: end}, nil
+}
@@ -0,0 +1,34 @@
+// Package cluster 提供Redis集群的槽位计算与节点信息解析
+package cluster
+
+import "strings"
+
+// SlotCount 集群槽位总数
+const SlotCount = 16384
+
+// Slot 计算键所属的槽位，与CLUSTER KEYSLOT一致
+// 键中包含非空的{hashtag}时只对花括号内的部分计算
+func Slot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	return int(crc16(key)) % SlotCount
+}
+
+// crc16 CRC16-CCITT (XMODEM)，多项式0x1021，初始值0
+func crc16(s string) uint16 {
+	var crc uint16
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
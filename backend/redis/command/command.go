@@ -0,0 +1,109 @@
+package command
+
+import "strings"
+
+// Category 命令的类别，决定执行命令需要的权限
+type Category string
+
+const (
+	// Read 只读取数据的命令
+	Read Category = "read"
+	// Write 写入或修改键的命令
+	Write Category = "write"
+	// Delete 删除整个键的命令
+	Delete Category = "delete"
+	// Flush 清空数据库的命令
+	Flush Category = "flush"
+	// Config 修改服务器配置、执行脚本或其他管理命令，未知命令也归入此类
+	Config Category = "config"
+)
+
+// categories 命令分类表，键为大写的命令名，带子命令时为"命令 子命令"
+var categories = map[string]Category{
+	// 连接与服务器信息
+	"PING": Read, "ECHO": Read, "SELECT": Read, "DBSIZE": Read, "INFO": Read, "TIME": Read,
+	"LASTSAVE": Read, "RANDOMKEY": Read,
+
+	// 通用键操作
+	"EXISTS": Read, "TYPE": Read, "TTL": Read, "PTTL": Read, "EXPIRETIME": Read, "PEXPIRETIME": Read,
+	"KEYS": Read, "SCAN": Read, "DUMP": Read, "OBJECT": Read, "MEMORY USAGE": Read, "TOUCH": Read,
+	"EXPIRE": Write, "PEXPIRE": Write, "EXPIREAT": Write, "PEXPIREAT": Write, "PERSIST": Write,
+	"RENAME": Write, "RENAMENX": Write, "RESTORE": Write, "COPY": Write, "MOVE": Write,
+	"DEL": Delete, "UNLINK": Delete,
+
+	// 字符串与位图
+	"GET": Read, "MGET": Read, "STRLEN": Read, "GETRANGE": Read, "GETBIT": Read, "BITCOUNT": Read,
+	"BITPOS": Read, "BITFIELD_RO": Read,
+	"SET": Write, "SETNX": Write, "SETEX": Write, "PSETEX": Write, "MSET": Write, "MSETNX": Write,
+	"APPEND": Write, "SETRANGE": Write, "GETSET": Write, "GETEX": Write, "INCR": Write, "INCRBY": Write,
+	"INCRBYFLOAT": Write, "DECR": Write, "DECRBY": Write, "SETBIT": Write, "BITOP": Write, "BITFIELD": Write,
+	"GETDEL": Delete,
+
+	// 哈希
+	"HGET": Read, "HMGET": Read, "HGETALL": Read, "HKEYS": Read, "HVALS": Read, "HLEN": Read,
+	"HEXISTS": Read, "HSTRLEN": Read, "HSCAN": Read, "HRANDFIELD": Read,
+	"HSET": Write, "HSETNX": Write, "HMSET": Write, "HDEL": Write, "HINCRBY": Write, "HINCRBYFLOAT": Write,
+
+	// 列表
+	"LRANGE": Read, "LLEN": Read, "LINDEX": Read, "LPOS": Read,
+	"LPUSH": Write, "RPUSH": Write, "LPUSHX": Write, "RPUSHX": Write, "LPOP": Write, "RPOP": Write,
+	"LSET": Write, "LINSERT": Write, "LREM": Write, "LTRIM": Write, "LMOVE": Write, "BLMOVE": Write,
+	"RPOPLPUSH": Write, "BRPOPLPUSH": Write, "BLPOP": Write, "BRPOP": Write,
+
+	// 集合
+	"SMEMBERS": Read, "SISMEMBER": Read, "SMISMEMBER": Read, "SCARD": Read, "SSCAN": Read,
+	"SRANDMEMBER": Read, "SINTER": Read, "SUNION": Read, "SDIFF": Read, "SINTERCARD": Read,
+	"SADD": Write, "SREM": Write, "SPOP": Write, "SMOVE": Write, "SINTERSTORE": Write,
+	"SUNIONSTORE": Write, "SDIFFSTORE": Write,
+
+	// 有序集合
+	"ZRANGE": Read, "ZRANGEBYSCORE": Read, "ZRANGEBYLEX": Read, "ZREVRANGE": Read,
+	"ZREVRANGEBYSCORE": Read, "ZSCORE": Read, "ZMSCORE": Read, "ZCARD": Read, "ZCOUNT": Read,
+	"ZLEXCOUNT": Read, "ZRANK": Read, "ZREVRANK": Read, "ZSCAN": Read, "ZRANDMEMBER": Read,
+	"ZADD": Write, "ZINCRBY": Write, "ZREM": Write, "ZPOPMIN": Write, "ZPOPMAX": Write,
+	"BZPOPMIN": Write, "BZPOPMAX": Write, "ZREMRANGEBYSCORE": Write, "ZREMRANGEBYRANK": Write,
+	"ZREMRANGEBYLEX": Write, "ZRANGESTORE": Write, "ZUNIONSTORE": Write, "ZINTERSTORE": Write,
+
+	// 流
+	"XRANGE": Read, "XREVRANGE": Read, "XLEN": Read, "XREAD": Read, "XINFO": Read, "XPENDING": Read,
+	"XADD": Write, "XDEL": Write, "XTRIM": Write, "XACK": Write, "XGROUP": Write, "XREADGROUP": Write,
+	"XCLAIM": Write, "XAUTOCLAIM": Write,
+
+	// 地理位置与HyperLogLog
+	"GEOPOS": Read, "GEODIST": Read, "GEOHASH": Read, "GEOSEARCH": Read, "GEORADIUS_RO": Read,
+	"GEORADIUSBYMEMBER_RO": Read, "PFCOUNT": Read,
+	"GEOADD": Write, "GEOSEARCHSTORE": Write, "GEORADIUS": Write, "GEORADIUSBYMEMBER": Write,
+	"PFADD": Write, "PFMERGE": Write,
+
+	// RedisJSON
+	"JSON.GET": Read, "JSON.MGET": Read, "JSON.TYPE": Read, "JSON.OBJKEYS": Read, "JSON.OBJLEN": Read,
+	"JSON.ARRLEN": Read, "JSON.STRLEN": Read, "JSON.ARRINDEX": Read, "JSON.RESP": Read,
+	"JSON.SET": Write, "JSON.DEL": Write, "JSON.FORGET": Write, "JSON.NUMINCRBY": Write,
+	"JSON.NUMMULTBY": Write, "JSON.ARRAPPEND": Write, "JSON.ARRINSERT": Write, "JSON.ARRPOP": Write,
+	"JSON.ARRTRIM": Write, "JSON.STRAPPEND": Write, "JSON.TOGGLE": Write, "JSON.CLEAR": Write,
+
+	// 集群拓扑查询
+	"CLUSTER SLOTS": Read, "CLUSTER NODES": Read, "CLUSTER INFO": Read, "CLUSTER KEYSLOT": Read,
+	"CLUSTER SHARDS": Read, "CLUSTER COUNTKEYSINSLOT": Read,
+
+	// 清空数据库
+	"FLUSHDB": Flush, "FLUSHALL": Flush, "SWAPDB": Flush,
+}
+
+// Classify 返回命令的类别，command可以带子命令，例如"CLUSTER SLOTS"
+// 子命令没有单独分类时按命令名分类，未知命令视为Config
+func Classify(command string) Category {
+	fields := strings.Fields(strings.ToUpper(command))
+	if len(fields) == 0 {
+		return Config
+	}
+	if len(fields) > 1 {
+		if category, ok := categories[fields[0]+" "+fields[1]]; ok {
+			return category
+		}
+	}
+	if category, ok := categories[fields[0]]; ok {
+		return category
+	}
+	return Config
+}
@@ -0,0 +1,40 @@
+package command
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		command  string
+		category Category
+	}{
+		{"GET", Read},
+		{"get", Read},
+		{"SCAN", Read},
+		{"HGETALL", Read},
+		{"JSON.GET", Read},
+		{"SET", Write},
+		{"hset", Write},
+		{"EXPIRE", Write},
+		{"DEL", Delete},
+		{"UNLINK", Delete},
+		{"GETDEL", Delete},
+		{"FLUSHDB", Flush},
+		{"FLUSHALL", Flush},
+		{"CONFIG SET", Config},
+		{"SHUTDOWN", Config},
+		{"EVAL", Config},
+		{"", Config},
+		// 子命令单独分类，未列出的子命令按命令名分类
+		{"CLUSTER SLOTS", Read},
+		{"cluster nodes", Read},
+		{"CLUSTER FAILOVER", Config},
+		{"MEMORY USAGE", Read},
+		{"MEMORY PURGE", Config},
+		{"OBJECT ENCODING", Read},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.command); got != tt.category {
+			t.Errorf("Classify(%q) = %s, want %s", tt.command, got, tt.category)
+		}
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+
+	"github.com/devtoolbox/redis/policy"
 )
 
 // Config 应用配置结构
@@ -45,6 +47,13 @@ type RedisBackend struct {
 	CORSEnabled    bool     `json:"corsEnabled"`
 	ConfigDir      string   `json:"configDir"`
 	AllowedOrigins []string `json:"allowedOrigins"`
+	TraceDir       string   `json:"traceDir,omitempty"` // 命令轨迹录制目录，为空时不录制
+	ProfileFile    string   `json:"profileFile,omitempty"` // 保存连接配置的文件，为空时使用用户配置目录下的devtoolbox/redis-profiles.json
+	TokenKeyFile   string   `json:"tokenKeyFile,omitempty"` // Token签名密钥与撤销列表，为空时使用用户配置目录下的devtoolbox/redis-token-keys.json
+	AuditFile      string   `json:"auditFile,omitempty"`    // 修改操作的审计日志（JSONL），为空时使用用户配置目录下的devtoolbox/redis-audit.jsonl
+	AuditMaxSize   int      `json:"auditMaxSize,omitempty"` // 审计日志单个文件的最大大小（MB），默认10
+	AuditMaxFiles  int      `json:"auditMaxFiles,omitempty"` // 审计日志保留的历史文件数量，默认5
+	EncryptionKeyFile string `json:"encryptionKeyFile,omitempty"` // 未配置security.encryption.privateKey时自动生成的RSA密钥，为空时使用用户配置目录下的devtoolbox/redis-rsa-keys.json
 }
 
 // Security 安全配置
@@ -52,6 +61,21 @@ type Security struct {
 	Encryption     Encryption `json:"encryption"`
 	TokenExpiry    int        `json:"tokenExpiry"`
 	MaxConnections int        `json:"maxConnections"`
+	MaxScope       string     `json:"maxScope,omitempty"` // 连接时可以请求的最高权限（read、write、admin），默认write；持有admin权限Token的请求不受限制
+	RotationToken  string     `json:"rotationToken,omitempty"` // 通过/api/crypto/rotate轮换RSA密钥时需要在X-Rotation-Token头中提供的凭据，为空时关闭该接口
+
+	// 后台维护的时间间隔（秒），为0时使用默认值，为负数时关闭对应的任务
+	TokenSweepInterval  int `json:"tokenSweepInterval,omitempty"`  // 清理过期Token与空闲连接的间隔，默认60
+	IdleTimeout         int `json:"idleTimeout,omitempty"`         // 没有被Token引用的连接空闲超过该时间后关闭，默认1800
+	HealthCheckInterval int `json:"healthCheckInterval,omitempty"` // 健康检查的间隔，默认30
+	ReconnectMaxBackoff int `json:"reconnectMaxBackoff,omitempty"` // 不健康连接重试的最长间隔，默认60
+	TokenKeyRotation    int `json:"tokenKeyRotation,omitempty"`    // Token签名密钥的轮换周期，默认604800（7天）
+	EncryptionKeyRotation int `json:"encryptionKeyRotation,omitempty"` // 自动生成的RSA密钥的轮换周期，默认2592000（30天）
+	EncryptionKeyGrace    int `json:"encryptionKeyGrace,omitempty"`    // RSA密钥被替换后仍然可以解密的时间，默认86400（1天）
+
+	// 按连接的环境标签（dev、test、staging、prod）配置的危险命令规则，覆盖同名的内置规则
+	// 没有对应规则的标签使用default
+	CommandPolicies map[string]policy.Rules `json:"commandPolicies,omitempty"`
 }
 
 // Encryption 加密配置
@@ -59,7 +83,8 @@ type Encryption struct {
 	Algorithm  string `json:"algorithm"`
 	KeySize    int    `json:"keySize"`
 	PublicKey  string `json:"publicKey"`
-	PrivateKey string `json:"privateKey"`
+	PrivateKey string `json:"privateKey"` // 为空时在第一次启动时生成密钥并保存到backend.redis.encryptionKeyFile
+	AllowLegacyPadding bool `json:"allowLegacyPadding,omitempty"` // 兼容旧版前端，接受不带信封的PKCS#1 v1.5密文（可以被重放）
 }
 
 
@@ -86,7 +111,9 @@ func LoadConfig(configPath string) (*Config, error) {
 	// 检查配置文件是否存在
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		log.Printf("配置文件不存在，使用默认配置: %s", configPath)
-		return getDefaultConfig(), nil
+		config := getDefaultConfig()
+		applyEnvironmentOverrides(config)
+		return config, nil
 	}
 
 	// 读取配置文件
@@ -144,6 +171,15 @@ func getDefaultConfig() *Config {
 				},
 			},
 		},
+		Security: Security{
+			Encryption: Encryption{
+				Algorithm: "RSA",
+				KeySize:   2048,
+			},
+			TokenExpiry:    3600,
+			MaxConnections: 50,
+			MaxScope:       "write",
+		},
 	}
 }
 
@@ -172,6 +208,51 @@ func applyEnvironmentOverrides(config *Config) {
 		log.Printf("环境变量覆盖配置目录: %s", configDir)
 	}
 
+	if traceDir := os.Getenv("REDIS_TRACE_DIR"); traceDir != "" {
+		config.Backend.Redis.TraceDir = traceDir
+		log.Printf("环境变量覆盖命令轨迹目录: %s", traceDir)
+	}
+
+	if profileFile := os.Getenv("REDIS_PROFILE_FILE"); profileFile != "" {
+		config.Backend.Redis.ProfileFile = profileFile
+		log.Printf("环境变量覆盖连接配置文件: %s", profileFile)
+	}
+
+	if tokenKeyFile := os.Getenv("REDIS_TOKEN_KEY_FILE"); tokenKeyFile != "" {
+		config.Backend.Redis.TokenKeyFile = tokenKeyFile
+		log.Printf("环境变量覆盖Token密钥文件: %s", tokenKeyFile)
+	}
+
+	if auditFile := os.Getenv("REDIS_AUDIT_FILE"); auditFile != "" {
+		config.Backend.Redis.AuditFile = auditFile
+		log.Printf("环境变量覆盖审计日志文件: %s", auditFile)
+	}
+
+	if keyFile := os.Getenv("REDIS_ENCRYPTION_KEY_FILE"); keyFile != "" {
+		config.Backend.Redis.EncryptionKeyFile = keyFile
+		log.Printf("环境变量覆盖RSA密钥文件: %s", keyFile)
+	}
+
+	if maxScope := os.Getenv("REDIS_MAX_SCOPE"); maxScope != "" {
+		config.Security.MaxScope = maxScope
+		log.Printf("环境变量覆盖连接最高权限: %s", maxScope)
+	}
+
+	if rotationToken := os.Getenv("REDIS_ROTATION_TOKEN"); rotationToken != "" {
+		config.Security.RotationToken = rotationToken
+		log.Printf("环境变量覆盖密钥轮换凭据")
+	}
+
+	if legacy := os.Getenv("REDIS_ALLOW_LEGACY_RSA"); legacy != "" {
+		allow, err := strconv.ParseBool(legacy)
+		if err != nil {
+			log.Printf("忽略无效的REDIS_ALLOW_LEGACY_RSA: %s", legacy)
+		} else {
+			config.Security.Encryption.AllowLegacyPadding = allow
+			log.Printf("环境变量覆盖PKCS#1 v1.5兼容模式: %t", allow)
+		}
+	}
+
 	// 前端配置环境变量覆盖
 	if apiBaseURL := os.Getenv("REDIS_MANAGER_API_BASE_URL"); apiBaseURL != "" {
 		config.Frontend.RedisManager.APIBaseURL = apiBaseURL
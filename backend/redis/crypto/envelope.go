@@ -0,0 +1,153 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 加密算法
+const (
+	// EnvelopeOAEP 使用RSA-OAEP（SHA-256）直接加密信封内容，适合密码等短数据
+	// 2048位密钥最多加密190字节的EnvelopePayload JSON，更长的内容使用EnvelopeHybrid
+	EnvelopeOAEP = "RSA-OAEP-256"
+	// EnvelopeHybrid 使用RSA-OAEP（SHA-256）加密随机的AES-256密钥，再用AES-GCM加密信封内容，适合私钥等长数据
+	EnvelopeHybrid = "RSA-OAEP-256+A256GCM"
+	// LegacyPKCS1v15 不带信封的PKCS#1 v1.5密文（jsencrypt），需要开启兼容模式
+	LegacyPKCS1v15 = "RSA-PKCS1-v1_5"
+)
+
+// envelopePrefix 信封的前缀，之后是Base64编码的Envelope JSON
+const envelopePrefix = "env1:"
+
+// DefaultMaxEnvelopeAge 信封时间戳与服务器时间允许的最大差值
+const DefaultMaxEnvelopeAge = 5 * time.Minute
+
+var (
+	// ErrLegacyPadding 未开启兼容模式时收到了不带信封的PKCS#1 v1.5密文
+	ErrLegacyPadding = errors.New("PKCS#1 v1.5 ciphertexts are disabled, send an encrypted envelope instead")
+	// ErrStaleEnvelope 信封的时间戳过旧或在未来
+	ErrStaleEnvelope = errors.New("envelope timestamp is too old or in the future")
+	// ErrReplayedEnvelope 信封的nonce未签发、已过期或已使用
+	ErrReplayedEnvelope = errors.New("envelope nonce is unknown, expired or already used")
+)
+
+// Envelope 前端加密的信封，二进制字段使用标准Base64编码
+// 序列化后以"env1:"为前缀，可以用在所有加密密码与私钥的字段中
+type Envelope struct {
+	KeyID     string `json:"kid,omitempty"` // 加密使用的公钥ID，为空时从新到旧依次尝试
+	Algorithm string `json:"alg"`           // EnvelopeOAEP或EnvelopeHybrid
+	Key       string `json:"key,omitempty"` // EnvelopeHybrid：RSA-OAEP加密的AES-256密钥
+	IV        string `json:"iv,omitempty"`  // EnvelopeHybrid：12字节的GCM nonce
+	Data      string `json:"data"`          // 加密后的EnvelopePayload JSON
+}
+
+// EnvelopePayload 信封中加密的内容
+type EnvelopePayload struct {
+	Nonce     string `json:"nonce"` // /api/crypto/nonce签发的nonce，只能使用一次
+	Timestamp int64  `json:"ts"`    // 加密时的Unix毫秒时间戳
+	Value     string `json:"value"`
+}
+
+// isEnvelope 密文是否为信封格式
+func isEnvelope(encrypted string) bool {
+	return strings.HasPrefix(encrypted, envelopePrefix)
+}
+
+// openEnvelope 解密信封，校验时间戳并使用其中的nonce
+func (r *RSADecryptor) openEnvelope(encrypted string) (string, error) {
+	encoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(encrypted, envelopePrefix))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode envelope: %v", err)
+	}
+	var envelope Envelope
+	if err := json.Unmarshal(encoded, &envelope); err != nil {
+		return "", fmt.Errorf("failed to parse envelope: %v", err)
+	}
+	keys, err := r.keys.candidates(envelope.KeyID)
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(envelope.Data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode envelope data: %v", err)
+	}
+
+	var plaintext []byte
+	switch envelope.Algorithm {
+	case EnvelopeOAEP:
+		if plaintext, err = decryptOAEP(keys, data); err != nil {
+			return "", err
+		}
+	case EnvelopeHybrid:
+		wrapped, err := base64.StdEncoding.DecodeString(envelope.Key)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode envelope key: %v", err)
+		}
+		iv, err := base64.StdEncoding.DecodeString(envelope.IV)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode envelope iv: %v", err)
+		}
+		aesKey, err := decryptOAEP(keys, wrapped)
+		if err != nil {
+			return "", err
+		}
+		if len(aesKey) != 32 {
+			return "", fmt.Errorf("invalid envelope key size %d", len(aesKey))
+		}
+		gcm, err := newGCM(aesKey)
+		if err != nil {
+			return "", err
+		}
+		if len(iv) != gcm.NonceSize() {
+			return "", fmt.Errorf("invalid envelope iv size %d", len(iv))
+		}
+		if plaintext, err = gcm.Open(nil, iv, data, nil); err != nil {
+			return "", fmt.Errorf("failed to decrypt: %v", err)
+		}
+	default:
+		return "", fmt.Errorf("unsupported envelope algorithm %q", envelope.Algorithm)
+	}
+
+	var payload EnvelopePayload
+	if err := json.Unmarshal(plaintext, &payload); err != nil {
+		return "", fmt.Errorf("failed to parse envelope payload: %v", err)
+	}
+	if age := time.Since(time.UnixMilli(payload.Timestamp)); age > r.maxAge || age < -r.maxAge {
+		return "", ErrStaleEnvelope
+	}
+	if !r.nonces.Consume(payload.Nonce) {
+		return "", ErrReplayedEnvelope
+	}
+	return payload.Value, nil
+}
+
+// decryptOAEP 从新到旧依次尝试私钥解密RSA-OAEP（SHA-256）密文
+func decryptOAEP(keys []*rsa.PrivateKey, ciphertext []byte) ([]byte, error) {
+	var err error
+	for _, key := range keys {
+		plaintext, decryptErr := rsa.DecryptOAEP(sha256.New(), rand.Reader, key, ciphertext, nil)
+		if decryptErr == nil {
+			return plaintext, nil
+		}
+		err = decryptErr
+	}
+	return nil, fmt.Errorf("failed to decrypt: %v", err)
+}
+
+// newGCM 创建AES-GCM
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
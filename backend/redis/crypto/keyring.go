@@ -0,0 +1,299 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// keyringVersion 密钥文件格式版本
+const keyringVersion = 1
+
+// DefaultKeySize 生成RSA密钥的默认长度
+const DefaultKeySize = 2048
+
+// ErrStaticKey 私钥来自配置文件时不能轮换
+var ErrStaticKey = errors.New("the private key is set in the configuration and cannot be rotated")
+
+// storedKey 文件中的私钥，使用PKCS#8 PEM格式
+type storedKey struct {
+	PrivateKey string    `json:"privateKey"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// keyringFile 密钥文件
+type keyringFile struct {
+	Version int         `json:"version"`
+	Keys    []storedKey `json:"keys"`
+}
+
+// keyPair 解析后的密钥
+type keyPair struct {
+	id         string
+	privateKey *rsa.PrivateKey
+	createdAt  time.Time
+}
+
+// PublicKey 前端加密使用的公钥
+type PublicKey struct {
+	ID         string     `json:"keyId"`
+	PEM        string     `json:"publicKey"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ReplacedAt *time.Time `json:"replacedAt,omitempty"` // 被新密钥替换的时间，当前密钥为空
+}
+
+// Keyring RSA密钥环
+// 最新的密钥是当前密钥，被替换的密钥在宽限期内仍然可以解密，使前端缓存的旧公钥不会立即失效；
+// path为空时密钥来自配置文件，不能轮换
+type Keyring struct {
+	mutex sync.RWMutex
+	path  string
+	bits  int
+	keys  []*keyPair // 按创建时间排序，最后一个为当前密钥
+}
+
+// NewStaticKeyring 使用配置文件中的PKCS#8私钥创建密钥环
+func NewStaticKeyring(privateKeyPEM string) (*Keyring, error) {
+	key, err := parseKeyPair(privateKeyPEM, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	return &Keyring{keys: []*keyPair{key}}, nil
+}
+
+// OpenKeyring 打开密钥文件，文件不存在时生成bits位的密钥并保存
+// 文件包含私钥，权限为0600；bits不大于0时使用DefaultKeySize
+func OpenKeyring(path string, bits int) (*Keyring, error) {
+	if bits <= 0 {
+		bits = DefaultKeySize
+	}
+	k := &Keyring{path: path, bits: bits}
+
+	content, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		var file keyringFile
+		if err := json.Unmarshal(content, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse key file %s: %v", path, err)
+		}
+		if file.Version != keyringVersion {
+			return nil, fmt.Errorf("unsupported key file version %d", file.Version)
+		}
+		if len(file.Keys) == 0 {
+			return nil, fmt.Errorf("key file %s has no keys", path)
+		}
+		for i, stored := range file.Keys {
+			key, err := parseKeyPair(stored.PrivateKey, stored.CreatedAt)
+			if err != nil {
+				return nil, fmt.Errorf("key %d in %s: %v", i, path, err)
+			}
+			k.keys = append(k.keys, key)
+		}
+		return k, nil
+	case os.IsNotExist(err):
+		if _, err := k.Rotate(); err != nil {
+			return nil, err
+		}
+		return k, nil
+	default:
+		return nil, fmt.Errorf("failed to read key file %s: %v", path, err)
+	}
+}
+
+// Rotate 生成新的密钥并返回其ID，之后前端应使用新的公钥
+func (k *Keyring) Rotate() (string, error) {
+	if k.path == "" {
+		return "", ErrStaticKey
+	}
+	privateKey, err := rsa.GenerateKey(rand.Reader, k.bits)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate RSA key: %v", err)
+	}
+	key, err := newKeyPair(privateKey, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	k.keys = append(k.keys, key)
+	if err := k.save(); err != nil {
+		k.keys = k.keys[:len(k.keys)-1]
+		return "", err
+	}
+	return key.id, nil
+}
+
+// Prune 删除被替换超过grace的密钥，之后用这些公钥加密的数据无法解密
+func (k *Keyring) Prune(grace time.Duration) error {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	now := time.Now()
+	pruned := 0
+	// keys[i]在keys[i+1]创建时被替换
+	for pruned < len(k.keys)-1 && now.Sub(k.keys[pruned+1].createdAt) > grace {
+		pruned++
+	}
+	if pruned == 0 {
+		return nil
+	}
+	previous := k.keys
+	k.keys = k.keys[pruned:]
+	if err := k.save(); err != nil {
+		k.keys = previous
+		return err
+	}
+	return nil
+}
+
+// Rotatable 密钥环是否保存在文件中，可以轮换
+func (k *Keyring) Rotatable() bool {
+	return k.path != ""
+}
+
+// Path 返回密钥文件的路径，私钥来自配置文件时为空
+func (k *Keyring) Path() string {
+	return k.path
+}
+
+// Current 返回当前的公钥
+func (k *Keyring) Current() PublicKey {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	return k.keys[len(k.keys)-1].public(nil)
+}
+
+// PublicKeys 返回仍然可以使用的所有公钥，从新到旧排列
+func (k *Keyring) PublicKeys() []PublicKey {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	keys := make([]PublicKey, 0, len(k.keys))
+	for i := len(k.keys) - 1; i >= 0; i-- {
+		var replacedAt *time.Time
+		if i < len(k.keys)-1 {
+			replacedAt = &k.keys[i+1].createdAt
+		}
+		keys = append(keys, k.keys[i].public(replacedAt))
+	}
+	return keys
+}
+
+// candidates 返回解密时依次尝试的私钥：指定了keyID时只返回对应的密钥，否则从新到旧返回全部
+func (k *Keyring) candidates(keyID string) ([]*rsa.PrivateKey, error) {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	var keys []*rsa.PrivateKey
+	for i := len(k.keys) - 1; i >= 0; i-- {
+		if keyID == "" || k.keys[i].id == keyID {
+			keys = append(keys, k.keys[i].privateKey)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("unknown or expired key id %q", keyID)
+	}
+	return keys, nil
+}
+
+// privateKey 返回指定ID的私钥，keyID为空时返回当前私钥
+func (k *Keyring) privateKey(keyID string) (*rsa.PrivateKey, error) {
+	keys, err := k.candidates(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return keys[0], nil
+}
+
+// save 原子地写入密钥文件，调用方需持有写锁
+func (k *Keyring) save() error {
+	file := keyringFile{Version: keyringVersion}
+	for _, key := range k.keys {
+		der, err := x509.MarshalPKCS8PrivateKey(key.privateKey)
+		if err != nil {
+			return fmt.Errorf("failed to marshal private key: %v", err)
+		}
+		file.Keys = append(file.Keys, storedKey{
+			PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+			CreatedAt:  key.createdAt,
+		})
+	}
+	content, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(k.path), 0700); err != nil {
+		return fmt.Errorf("failed to create key directory: %v", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(k.path), ".rsa-keys-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write key file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write key file: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), k.path); err != nil {
+		return fmt.Errorf("failed to write key file: %v", err)
+	}
+	return nil
+}
+
+// parseKeyPair 解析PKCS#8 PEM格式的RSA私钥
+func parseKeyPair(privateKeyPEM string, createdAt time.Time) (*keyPair, error) {
+	block, _ := pem.Decode([]byte(privateKeyPEM))
+	if block == nil {
+		return nil, errors.New("failed to decode PEM block containing private key")
+	}
+
+	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %v", err)
+	}
+
+	rsaPrivateKey, ok := privateKey.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an RSA private key")
+	}
+	return newKeyPair(rsaPrivateKey, createdAt)
+}
+
+// newKeyPair 密钥ID为公钥（PKIX DER）SHA-256摘要的前8字节
+func newKeyPair(privateKey *rsa.PrivateKey, createdAt time.Time) (*keyPair, error) {
+	der, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal public key: %v", err)
+	}
+	sum := sha256.Sum256(der)
+	return &keyPair{id: hex.EncodeToString(sum[:8]), privateKey: privateKey, createdAt: createdAt}, nil
+}
+
+// public 返回公钥信息
+func (p *keyPair) public(replacedAt *time.Time) PublicKey {
+	der, _ := x509.MarshalPKIXPublicKey(&p.privateKey.PublicKey)
+	return PublicKey{
+		ID:         p.id,
+		PEM:        string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+		CreatedAt:  p.createdAt,
+		ReplacedAt: replacedAt,
+	}
+}
@@ -0,0 +1,136 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testKeySize 测试使用较短的密钥，加快生成速度
+const testKeySize = 1024
+
+// encryptWith 使用公钥加密PKCS#1 v1.5密文，模拟旧版前端的jsencrypt
+func encryptWith(t *testing.T, key PublicKey, plaintext string) string {
+	t.Helper()
+	block, _ := pem.Decode([]byte(key.PEM))
+	if block == nil {
+		t.Fatal("failed to decode public key")
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse public key: %v", err)
+	}
+	ciphertext, err := rsa.EncryptPKCS1v15(rand.Reader, publicKey.(*rsa.PublicKey), []byte(plaintext))
+	if err != nil {
+		t.Fatalf("failed to encrypt: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext)
+}
+
+func TestOpenKeyring_GeneratesAndReopens(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "rsa.json")
+	keys, err := OpenKeyring(path, testKeySize)
+	if err != nil {
+		t.Fatalf("OpenKeyring failed: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Expected key file to be created: %v", err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("Expected key file mode 0600, got %v", mode)
+	}
+
+	reopened, err := OpenKeyring(path, testKeySize)
+	if err != nil {
+		t.Fatalf("Reopen failed: %v", err)
+	}
+	if reopened.Current().ID != keys.Current().ID {
+		t.Error("Expected the same key after reopening")
+	}
+}
+
+func TestKeyring_RotateWithGrace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rsa.json")
+	keys, err := OpenKeyring(path, testKeySize)
+	if err != nil {
+		t.Fatalf("OpenKeyring failed: %v", err)
+	}
+	decryptor := NewRSADecryptorWithKeyring(keys)
+	decryptor.SetLegacyPadding(true)
+	old := keys.Current()
+	cached := encryptWith(t, old, "secret")
+
+	id, err := keys.Rotate()
+	if err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	if id == old.ID || decryptor.KeyID() != id {
+		t.Fatalf("Expected new current key, got %s", decryptor.KeyID())
+	}
+	public := keys.PublicKeys()
+	if len(public) != 2 || public[1].ID != old.ID || public[1].ReplacedAt == nil || public[0].ReplacedAt != nil {
+		t.Errorf("Unexpected public keys after rotation: %+v", public)
+	}
+
+	// 宽限期内旧公钥加密的密码仍然可以解密，带不带密钥ID都可以
+	for _, encrypted := range []string{cached, old.ID + ":" + cached} {
+		if plaintext, err := decryptor.DecryptPassword(encrypted); err != nil || plaintext != "secret" {
+			t.Errorf("Expected old key to decrypt during grace, got %q, %v", plaintext, err)
+		}
+	}
+	if _, err := decryptor.DecryptPassword(id + ":" + cached); err == nil {
+		t.Error("Expected decryption with the wrong key id to fail")
+	}
+
+	// 重新打开后保留两个密钥
+	reopened, err := OpenKeyring(path, testKeySize)
+	if err != nil || len(reopened.PublicKeys()) != 2 {
+		t.Fatalf("Expected both keys after reopening, got %v", err)
+	}
+
+	if err := keys.Prune(time.Hour); err != nil || len(keys.PublicKeys()) != 2 {
+		t.Errorf("Expected old key to be kept during grace, got %v", err)
+	}
+	if err := keys.Prune(0); err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if _, err := decryptor.DecryptPassword(old.ID + ":" + cached); err == nil {
+		t.Error("Expected pruned key to be rejected")
+	}
+	if reopened, _ := OpenKeyring(path, testKeySize); len(reopened.PublicKeys()) != 1 {
+		t.Error("Expected pruned key to be removed from the file")
+	}
+}
+
+func TestStaticKeyring(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, testKeySize)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(privateKey)
+	decryptor, err := NewRSADecryptor(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})))
+	if err != nil {
+		t.Fatalf("NewRSADecryptor failed: %v", err)
+	}
+
+	decryptor.SetLegacyPadding(true)
+	keys := decryptor.Keyring()
+	if keys.Rotatable() {
+		t.Error("Expected configured key not to be rotatable")
+	}
+	if _, err := keys.Rotate(); !errors.Is(err, ErrStaticKey) {
+		t.Errorf("Expected ErrStaticKey, got %v", err)
+	}
+	encrypted := encryptWith(t, keys.Current(), "secret")
+	if plaintext, err := decryptor.DecryptChunks(keys.Current().ID + ":" + encrypted + "." + encrypted); err != nil || plaintext != "secretsecret" {
+		t.Errorf("Expected chunks to decrypt, got %q, %v", plaintext, err)
+	}
+}
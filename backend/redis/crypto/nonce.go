@@ -0,0 +1,99 @@
+package crypto
+
+import (
+	"container/list"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 默认的nonce限制
+const (
+	DefaultNonceTTL  = 30 * time.Second // nonce签发后的有效期，前端在加密前获取，不需要更长
+	DefaultMaxNonces = 10000            // 同时有效的nonce数量上限，超过时淘汰最早签发的nonce
+)
+
+// NonceCache 服务端签发的一次性nonce，信封中的nonce使用后即失效，防止密文被重放
+// nonce按签发顺序保存，有效期相同，因此最早签发的也最先过期
+type NonceCache struct {
+	mutex  sync.Mutex
+	ttl    time.Duration
+	max    int
+	order  *list.List               // 按签发顺序排列的nonceEntry
+	nonces map[string]*list.Element // nonce -> order中的元素
+}
+
+// nonceEntry 一个未使用的nonce
+type nonceEntry struct {
+	nonce     string
+	expiresAt time.Time
+}
+
+// NewNonceCache 创建nonce缓存，参数不大于0时使用默认值
+func NewNonceCache(ttl time.Duration, max int) *NonceCache {
+	if ttl <= 0 {
+		ttl = DefaultNonceTTL
+	}
+	if max <= 0 {
+		max = DefaultMaxNonces
+	}
+	return &NonceCache{ttl: ttl, max: max, order: list.New(), nonces: make(map[string]*list.Element)}
+}
+
+// Issue 签发count个nonce，返回它们的过期时间
+// 未使用的nonce达到上限时淘汰最早签发的，新请求不会因为旧的nonce被拒绝
+func (c *NonceCache) Issue(count int) ([]string, time.Time, error) {
+	nonces := make([]string, count)
+	for i := range nonces {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return nil, time.Time{}, fmt.Errorf("failed to generate nonce: %v", err)
+		}
+		nonces[i] = hex.EncodeToString(b)
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	c.prune(now)
+	for c.order.Len() > 0 && c.order.Len()+count > c.max {
+		c.remove(c.order.Front())
+	}
+	expiresAt := now.Add(c.ttl)
+	for _, nonce := range nonces {
+		c.nonces[nonce] = c.order.PushBack(&nonceEntry{nonce: nonce, expiresAt: expiresAt})
+	}
+	return nonces, expiresAt, nil
+}
+
+// Consume 使用一个nonce，nonce未签发、已过期、已被淘汰或已使用时返回false
+func (c *NonceCache) Consume(nonce string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.nonces[nonce]
+	if !ok {
+		return false
+	}
+	c.remove(element)
+	return time.Now().Before(element.Value.(*nonceEntry).expiresAt)
+}
+
+// prune 从最早签发的开始删除过期的nonce，调用方需持有锁
+func (c *NonceCache) prune(now time.Time) {
+	for element := c.order.Front(); element != nil; element = c.order.Front() {
+		if now.Before(element.Value.(*nonceEntry).expiresAt) {
+			return
+		}
+		c.remove(element)
+	}
+}
+
+// remove 删除一个nonce，调用方需持有锁
+func (c *NonceCache) remove(element *list.Element) {
+	delete(c.nonces, element.Value.(*nonceEntry).nonce)
+	c.order.Remove(element)
+}
@@ -3,69 +3,164 @@ package crypto
 import (
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
-	"encoding/pem"
-	"errors"
 	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/hkdf"
 )
 
 // RSADecryptor RSA解密器
+// 密文需使用带nonce与时间戳的信封（见Envelope），开启兼容模式后也接受不带信封的PKCS#1 v1.5密文
 type RSADecryptor struct {
-	privateKey *rsa.PrivateKey
+	keys          *Keyring
+	nonces        *NonceCache
+	maxAge        time.Duration
+	legacyPadding bool
 }
 
 // NewRSADecryptor 创建新的RSA解密器
 func NewRSADecryptor(privateKeyPEM string) (*RSADecryptor, error) {
-	block, _ := pem.Decode([]byte(privateKeyPEM))
-	if block == nil {
-		return nil, errors.New("failed to decode PEM block containing private key")
-	}
-
-	privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	keys, err := NewStaticKeyring(privateKeyPEM)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse private key: %v", err)
+		return nil, err
 	}
+	return NewRSADecryptorWithKeyring(keys), nil
+}
 
-	rsaPrivateKey, ok := privateKey.(*rsa.PrivateKey)
-	if !ok {
-		return nil, errors.New("not an RSA private key")
+// NewRSADecryptorWithKeyring 使用密钥环创建RSA解密器，轮换后的旧密钥在宽限期内仍然可以解密
+func NewRSADecryptorWithKeyring(keys *Keyring) *RSADecryptor {
+	return &RSADecryptor{
+		keys:   keys,
+		nonces: NewNonceCache(DefaultNonceTTL, DefaultMaxNonces),
+		maxAge: DefaultMaxEnvelopeAge,
 	}
+}
 
-	return &RSADecryptor{
-		privateKey: rsaPrivateKey,
-	}, nil
+// SetLegacyPadding 是否接受不带信封的PKCS#1 v1.5密文，这类密文可以被重放，只用于兼容旧版前端
+// 需在解密之前调用
+func (r *RSADecryptor) SetLegacyPadding(allow bool) {
+	r.legacyPadding = allow
 }
 
-// DecryptPassword 解密密码
+// LegacyPadding 是否开启了PKCS#1 v1.5兼容模式
+func (r *RSADecryptor) LegacyPadding() bool {
+	return r.legacyPadding
+}
+
+// Nonces 返回信封使用的nonce缓存
+func (r *RSADecryptor) Nonces() *NonceCache {
+	return r.nonces
+}
+
+// Keyring 返回解密器使用的密钥环
+func (r *RSADecryptor) Keyring() *Keyring {
+	return r.keys
+}
+
+// DecryptPassword 解密密码，信封中的nonce使用后失效
+// 兼容模式下的PKCS#1 v1.5密文可以带有"<密钥ID>:"前缀指定使用的密钥，否则从新到旧依次尝试所有密钥
 func (r *RSADecryptor) DecryptPassword(encryptedPassword string) (string, error) {
+	if isEnvelope(encryptedPassword) {
+		return r.openEnvelope(encryptedPassword)
+	}
+	if !r.legacyPadding {
+		return "", ErrLegacyPadding
+	}
+	keyID, encoded := splitKeyID(encryptedPassword)
+	return r.decrypt(keyID, encoded)
+}
+
+// decrypt 使用指定的密钥解密Base64编码的PKCS#1 v1.5密文，keyID为空时依次尝试所有密钥
+func (r *RSADecryptor) decrypt(keyID, encoded string) (string, error) {
 	// Base64解码
-	ciphertext, err := base64.StdEncoding.DecodeString(encryptedPassword)
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
 	if err != nil {
 		return "", fmt.Errorf("failed to decode base64: %v", err)
 	}
 
-	// RSA解密
-	plaintext, err := rsa.DecryptPKCS1v15(rand.Reader, r.privateKey, ciphertext)
+	keys, err := r.keys.candidates(keyID)
 	if err != nil {
-		return "", fmt.Errorf("failed to decrypt: %v", err)
+		return "", err
 	}
 
-	return string(plaintext), nil
+	// RSA解密
+	for _, key := range keys {
+		plaintext, decryptErr := rsa.DecryptPKCS1v15(rand.Reader, key, ciphertext)
+		if decryptErr == nil {
+			return string(plaintext), nil
+		}
+		err = decryptErr
+	}
+	return "", fmt.Errorf("failed to decrypt: %v", err)
 }
 
-// GetPublicKeyPEM 获取公钥PEM格式字符串
+// GetPublicKeyPEM 获取当前公钥的PEM格式字符串
 func (r *RSADecryptor) GetPublicKeyPEM() (string, error) {
-	publicKey := &r.privateKey.PublicKey
-	publicKeyDER, err := x509.MarshalPKIXPublicKey(publicKey)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal public key: %v", err)
+	return r.keys.Current().PEM, nil
+}
+
+// KeyID 返回当前密钥的ID
+func (r *RSADecryptor) KeyID() string {
+	return r.keys.Current().ID
+}
+
+// keyIDSeparator 密钥ID与密文之间的分隔符，不在Base64字符集中
+const keyIDSeparator = ":"
+
+// splitKeyID 拆分密文中可选的密钥ID前缀
+func splitKeyID(encrypted string) (string, string) {
+	if keyID, rest, ok := strings.Cut(encrypted, keyIDSeparator); ok {
+		return keyID, rest
 	}
+	return "", encrypted
+}
 
-	publicKeyPEM := pem.EncodeToMemory(&pem.Block{
-		Type:  "PUBLIC KEY",
-		Bytes: publicKeyDER,
-	})
+// chunkSeparator 分段密文之间的分隔符，不在Base64字符集中
+const chunkSeparator = "."
 
-	return string(publicKeyPEM), nil
-}
\ No newline at end of file
+// DecryptChunks 解密较长的数据，例如TLS私钥
+// 信封使用EnvelopeHybrid时不需要分段；兼容模式下RSA单次只能加密不超过密钥长度减11字节的数据，
+// 较长的内容由前端分段加密后以"."连接，密钥ID前缀只需要出现在开头
+func (r *RSADecryptor) DecryptChunks(encrypted string) (string, error) {
+	if isEnvelope(encrypted) {
+		return r.openEnvelope(encrypted)
+	}
+	if !r.legacyPadding {
+		return "", ErrLegacyPadding
+	}
+	keyID, encoded := splitKeyID(encrypted)
+	var sb strings.Builder
+	for i, chunk := range strings.Split(encoded, chunkSeparator) {
+		plaintext, err := r.decrypt(keyID, chunk)
+		if err != nil {
+			return "", fmt.Errorf("chunk %d: %w", i, err)
+		}
+		sb.WriteString(plaintext)
+	}
+	return sb.String(), nil
+}
+
+// DeriveKey 从当前的RSA私钥派生对称密钥，salt和info相同则结果相同
+// 用于加密保存在本地的数据，轮换私钥后需要用新的派生密钥重新加密
+func (r *RSADecryptor) DeriveKey(salt []byte, info string, size int) ([]byte, error) {
+	return r.DeriveKeyWith("", salt, info, size)
+}
+
+// DeriveKeyWith 从指定ID的私钥派生对称密钥，keyID为空时使用当前私钥
+func (r *RSADecryptor) DeriveKeyWith(keyID string, salt []byte, info string, size int) ([]byte, error) {
+	privateKey, err := r.keys.privateKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	secret := x509.MarshalPKCS1PrivateKey(privateKey)
+	key := make([]byte, size)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, []byte(info)), key); err != nil {
+		return nil, fmt.Errorf("failed to derive key: %v", err)
+	}
+	return key, nil
+}
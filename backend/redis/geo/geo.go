@@ -0,0 +1,157 @@
+// Package geo 实现与Redis一致的地理位置编码
+// 坐标编码为52位交织geohash并作为有序集合的分数保存，
+// 距离使用Redis相同的地球半径和Haversine公式计算。
+package geo
+
+import (
+	"math"
+	"strings"
+)
+
+const (
+	// Step 每个坐标轴的编码位数
+	Step = 26
+	// MinLongitude 经度下限
+	MinLongitude = -180.0
+	// MaxLongitude 经度上限
+	MaxLongitude = 180.0
+	// MinLatitude 纬度下限，受墨卡托投影限制
+	MinLatitude = -85.05112878
+	// MaxLatitude 纬度上限
+	MaxLatitude = 85.05112878
+	// EarthRadius 地球半径（米），与Redis一致
+	EarthRadius = 6372797.560856
+	// MaxScore 合法geohash分数的上限（不含）
+	MaxScore = 1 << (Step * 2)
+)
+
+const alphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// Valid 判断坐标是否在Redis可存储的范围内
+func Valid(longitude, latitude float64) bool {
+	return longitude >= MinLongitude && longitude <= MaxLongitude &&
+		latitude >= MinLatitude && latitude <= MaxLatitude
+}
+
+// Encode 将坐标编码为52位geohash，调用方需先用Valid检查
+func Encode(longitude, latitude float64) uint64 {
+	return encode(longitude, latitude, MinLatitude, MaxLatitude)
+}
+
+// encode 按指定纬度范围编码，纬度位在偶数位、经度位在奇数位
+func encode(longitude, latitude, minLat, maxLat float64) uint64 {
+	latOffset := (latitude - minLat) / (maxLat - minLat) * (1 << Step)
+	lonOffset := (longitude - MinLongitude) / (MaxLongitude - MinLongitude) * (1 << Step)
+	return interleave(uint32(latOffset), uint32(lonOffset))
+}
+
+// Decode 将geohash解码为所在网格中心的坐标
+func Decode(hash uint64) (longitude, latitude float64) {
+	lat, lon := deinterleave(hash)
+	scale := float64(uint64(1) << Step)
+
+	minLat := MinLatitude + float64(lat)/scale*(MaxLatitude-MinLatitude)
+	maxLat := MinLatitude + float64(lat+1)/scale*(MaxLatitude-MinLatitude)
+	minLon := MinLongitude + float64(lon)/scale*(MaxLongitude-MinLongitude)
+	maxLon := MinLongitude + float64(lon+1)/scale*(MaxLongitude-MinLongitude)
+
+	longitude = math.Max(MinLongitude, math.Min(MaxLongitude, (minLon+maxLon)/2))
+	latitude = math.Max(MinLatitude, math.Min(MaxLatitude, (minLat+maxLat)/2))
+	return longitude, latitude
+}
+
+// Hash 返回11位标准geohash字符串，与GEOHASH命令一致
+// 标准geohash的纬度范围为±90，因此需要按该范围重新编码
+func Hash(longitude, latitude float64) string {
+	bits := encode(longitude, latitude, -90, 90)
+
+	var sb strings.Builder
+	for i := 0; i < 11; i++ {
+		// 52位只能组成10个字符，最后一位按Redis的做法补0
+		idx := 0
+		if i < 10 {
+			idx = int(bits>>(52-uint((i+1)*5))) & 0x1f
+		}
+		sb.WriteByte(alphabet[idx])
+	}
+	return sb.String()
+}
+
+// Distance 计算两点间的球面距离（米）
+func Distance(lon1, lat1, lon2, lat2 float64) float64 {
+	lat1r, lon1r := radians(lat1), radians(lon1)
+	lat2r, lon2r := radians(lat2), radians(lon2)
+	u := math.Sin((lat2r - lat1r) / 2)
+	v := math.Sin((lon2r - lon1r) / 2)
+	return 2 * EarthRadius * math.Asin(math.Sqrt(u*u+math.Cos(lat1r)*math.Cos(lat2r)*v*v))
+}
+
+// InRadius 判断点是否在圆形范围内，返回距离（米）
+func InRadius(centerLon, centerLat, lon, lat, radius float64) (float64, bool) {
+	distance := Distance(centerLon, centerLat, lon, lat)
+	return distance, distance <= radius
+}
+
+// InBox 判断点是否在以中心为原点的矩形范围内，返回距离（米）
+// 与Redis一致，先比较纬度距离，再在点所在纬度上比较经度距离
+func InBox(centerLon, centerLat, lon, lat, width, height float64) (float64, bool) {
+	latDistance := EarthRadius * math.Abs(radians(lat)-radians(centerLat))
+	if latDistance > height/2 {
+		return 0, false
+	}
+	if Distance(lon, lat, centerLon, lat) > width/2 {
+		return 0, false
+	}
+	return Distance(centerLon, centerLat, lon, lat), true
+}
+
+// UnitFactor 返回距离单位对应的米数，支持m、km、ft、mi
+func UnitFactor(unit string) (float64, bool) {
+	switch strings.ToLower(unit) {
+	case "m":
+		return 1, true
+	case "km":
+		return 1000, true
+	case "ft":
+		return 0.3048, true
+	case "mi":
+		return 1609.34, true
+	}
+	return 0, false
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// interleave 交织两个32位整数，x占偶数位，y占奇数位
+func interleave(x, y uint32) uint64 {
+	return spread(x) | spread(y)<<1
+}
+
+// deinterleave interleave的逆运算
+func deinterleave(bits uint64) (x, y uint32) {
+	return squash(bits), squash(bits >> 1)
+}
+
+// spread 将32位整数的每一位间隔一位展开
+func spread(v uint32) uint64 {
+	x := uint64(v)
+	x = (x | x<<16) & 0x0000FFFF0000FFFF
+	x = (x | x<<8) & 0x00FF00FF00FF00FF
+	x = (x | x<<4) & 0x0F0F0F0F0F0F0F0F
+	x = (x | x<<2) & 0x3333333333333333
+	x = (x | x<<1) & 0x5555555555555555
+	return x
+}
+
+// squash spread的逆运算
+func squash(v uint64) uint32 {
+	x := v & 0x5555555555555555
+	x = (x | x>>1) & 0x3333333333333333
+	x = (x | x>>2) & 0x0F0F0F0F0F0F0F0F
+	x = (x | x>>4) & 0x00FF00FF00FF00FF
+	x = (x | x>>8) & 0x0000FFFF0000FFFF
+	x = (x | x>>16) & 0x00000000FFFFFFFF
+	return uint32(x)
+}
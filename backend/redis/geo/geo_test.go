@@ -0,0 +1,60 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+// 测试数据来自Redis文档中的Sicily示例
+const (
+	palermoLon, palermoLat = 13.361389, 38.115556
+	cataniaLon, cataniaLat = 15.087269, 37.502669
+)
+
+func TestEncode_MatchesRedisScore(t *testing.T) {
+	if score := Encode(palermoLon, palermoLat); score != 3479099956230698 {
+		t.Errorf("Expected Palermo score 3479099956230698, got %d", score)
+	}
+	if score := Encode(cataniaLon, cataniaLat); score != 3479447370796909 {
+		t.Errorf("Expected Catania score 3479447370796909, got %d", score)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	lon, lat := Decode(3479099956230698)
+	if math.Abs(lon-13.36138933897018433) > 1e-12 || math.Abs(lat-38.11555639549629859) > 1e-12 {
+		t.Errorf("Unexpected decoded position: %v, %v", lon, lat)
+	}
+}
+
+func TestHash(t *testing.T) {
+	lon, lat := Decode(Encode(palermoLon, palermoLat))
+	if hash := Hash(lon, lat); hash != "sqc8b49rny0" {
+		t.Errorf("Expected sqc8b49rny0, got %s", hash)
+	}
+	lon, lat = Decode(Encode(cataniaLon, cataniaLat))
+	if hash := Hash(lon, lat); hash != "sqdtr74hyu0" {
+		t.Errorf("Expected sqdtr74hyu0, got %s", hash)
+	}
+}
+
+func TestDistance(t *testing.T) {
+	lon1, lat1 := Decode(Encode(palermoLon, palermoLat))
+	lon2, lat2 := Decode(Encode(cataniaLon, cataniaLat))
+	if d := Distance(lon1, lat1, lon2, lat2); math.Abs(d-166274.1516) > 0.0001 {
+		t.Errorf("Expected 166274.1516, got %.4f", d)
+	}
+
+	if d, ok := InRadius(15, 37, lon2, lat2, 200000); !ok || math.Abs(d/1000-56.4413) > 0.0001 {
+		t.Errorf("Expected Catania within 200km at 56.4413km, got %.4f (%v)", d/1000, ok)
+	}
+	if _, ok := InBox(15, 37, lon1, lat1, 400000, 100000); ok {
+		t.Error("Expected Palermo outside the 400x100km box")
+	}
+}
+
+func TestValid(t *testing.T) {
+	if Valid(181, 10) || Valid(10, 86) || !Valid(-180, -85.05112878) {
+		t.Error("Unexpected coordinate validation result")
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/devtoolbox/redis/geo"
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+// geoMember 地理位置键中的成员
+type geoMember struct {
+	name  string
+	score float64
+}
+
+// geoScore 将有序集合分数转换为float64
+func geoScore(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// buildGeoJSON 将有序集合成员转换为GeoJSON，分数不是合法geohash的成员会被跳过
+func buildGeoJSON(zset map[string]interface{}) GeoJSONFeatureCollection {
+	members := make([]geoMember, 0, len(zset))
+	for name, value := range zset {
+		score, ok := geoScore(value)
+		if !ok || score < 0 || score >= geo.MaxScore || score != math.Trunc(score) {
+			continue
+		}
+		members = append(members, geoMember{name: name, score: score})
+	}
+
+	// 与ZRANGE顺序一致
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].score != members[j].score {
+			return members[i].score < members[j].score
+		}
+		return members[i].name < members[j].name
+	})
+
+	collection := GeoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]GeoJSONFeature, 0, len(members)),
+	}
+	for _, member := range members {
+		lon, lat := geo.Decode(uint64(member.score))
+		collection.Features = append(collection.Features, GeoJSONFeature{
+			Type: "Feature",
+			Geometry: GeoJSONGeometry{
+				Type:        "Point",
+				Coordinates: []float64{lon, lat},
+			},
+			Properties: map[string]interface{}{
+				"member":  member.name,
+				"score":   int64(member.score),
+				"geohash": geo.Hash(lon, lat),
+			},
+		})
+	}
+	return collection
+}
+
+// geoJSONHandler 以GeoJSON格式返回地理位置键的成员
+func geoJSONHandler(w http.ResponseWriter, r *http.Request) {
+	// 只允许GET请求
+	if r.Method != "GET" {
+		http.Error(w, "方法不允许", http.StatusMethodNotAllowed)
+		return
+	}
+
+	keyName := strings.TrimPrefix(r.URL.Path, "/api/redis/geo/")
+	if keyName == "" {
+		http.Error(w, "缺少键名参数", http.StatusBadRequest)
+		return
+	}
+
+	log.Printf("查询地理位置键: %s", keyName)
+
+	keyInfo, err := redisManager.GetKeyInfo(keyName, "")
+	if err == nil && keyInfo.Type != "zset" {
+		err = fmt.Errorf("键 '%s' 的类型为 %s，不是地理位置键: %w", keyName, keyInfo.Type, rediserr.WrongType)
+	}
+	if err != nil {
+		log.Printf("获取地理位置键失败: %v", err)
+		response := KeyInfoResponse{
+			Status:  "error",
+			Message: fmt.Sprintf("获取地理位置键失败: %v", err),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(rediserr.HTTPStatus(err))
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	zset, _ := keyInfo.Value.(map[string]interface{})
+
+	w.Header().Set("Content-Type", "application/geo+json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(buildGeoJSON(zset)); err != nil {
+		log.Printf("编码响应失败: %v", err)
+	}
+}
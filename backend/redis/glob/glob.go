@@ -0,0 +1,102 @@
+// Package glob 实现Redis的glob模式匹配，与KEYS、SCAN MATCH的规则相同
+package glob
+
+// Match 按Redis的glob规则逐字节匹配键
+// *匹配任意字节序列（包括/），?匹配单个字节，[abc]、[^abc]、[a-z]匹配字节集合，\转义下一个字符
+func Match(pattern, key string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 1 && pattern[1] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 1 {
+				return true
+			}
+			for i := 0; i <= len(key); i++ {
+				if Match(pattern[1:], key[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(key) == 0 {
+				return false
+			}
+		case '[':
+			if len(key) == 0 {
+				return false
+			}
+			matched, rest, ok := matchClass(pattern[1:], key[0])
+			if !ok || !matched {
+				return false
+			}
+			pattern, key = rest, key[1:]
+			continue
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if len(key) == 0 || pattern[0] != key[0] {
+				return false
+			}
+		}
+		pattern, key = pattern[1:], key[1:]
+	}
+	return len(key) == 0
+}
+
+// matchClass 匹配"["之后的字节集合，返回"]"之后的模式；ok为false表示集合没有闭合
+func matchClass(pattern string, c byte) (matched bool, rest string, ok bool) {
+	negate := len(pattern) > 0 && pattern[0] == '^'
+	if negate {
+		pattern = pattern[1:]
+	}
+	for len(pattern) > 0 {
+		switch {
+		case pattern[0] == ']':
+			return matched != negate, pattern[1:], true
+		case pattern[0] == '\\' && len(pattern) > 1:
+			pattern = pattern[1:]
+			if pattern[0] == c {
+				matched = true
+			}
+		case len(pattern) > 2 && pattern[1] == '-':
+			start, end := pattern[0], pattern[2]
+			if start > end {
+				start, end = end, start
+			}
+			if c >= start && c <= end {
+				matched = true
+			}
+			pattern = pattern[2:]
+		case pattern[0] == c:
+			matched = true
+		}
+		pattern = pattern[1:]
+	}
+	return false, "", false
+}
+
+// Valid 检查模式中的字节集合都已闭合
+func Valid(pattern string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+		case '[':
+			_, rest, ok := matchClass(pattern[1:], 0)
+			if !ok {
+				return false
+			}
+			pattern = rest
+			continue
+		}
+		pattern = pattern[1:]
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package glob
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		{"*", "", true},
+		{"session:*", "session:eu/1", true},
+		{"session:*", "sessions", false},
+		{"*/*", "a/b/c", true},
+		{"user:?", "user:1", true},
+		{"user:?", "user:12", false},
+		{"h?llo", "h/llo", true},
+		{"h[ae]llo", "hello", true},
+		{"h[ae]llo", "hillo", false},
+		{"h[^e]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-b]llo", "hbllo", true},
+		{"h[b-a]llo", "hallo", true},
+		{"h[a-b]llo", "hcllo", false},
+		{`h\*llo`, "h*llo", true},
+		{`h\*llo`, "hello", false},
+		{`[\]]`, "]", true},
+		{"a**b", "axyb", true},
+		{"*:*:*", "a:b", false},
+		{"\xff*", "\xff\x00", true},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.key); got != tt.want {
+			t.Errorf("Match(%q, %q) = %t, want %t", tt.pattern, tt.key, got, tt.want)
+		}
+	}
+
+	for pattern, want := range map[string]bool{"[": false, "a[bc": false, `\[`: true, "[a]": true, "": true} {
+		if got := Valid(pattern); got != want {
+			t.Errorf("Valid(%q) = %t, want %t", pattern, got, want)
+		}
+	}
+}
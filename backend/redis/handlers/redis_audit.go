@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/devtoolbox/redis/audit"
+	"github.com/devtoolbox/redis/auth"
+	"github.com/devtoolbox/redis/config"
+	"github.com/devtoolbox/redis/mock"
+	"github.com/devtoolbox/redis/pool"
+)
+
+// 审计日志查询默认与最多返回的条数
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// AuditResponse 审计日志查询的响应，按时间从新到旧排列
+type AuditResponse struct {
+	Success bool          `json:"success"`
+	Message string        `json:"message"`
+	Count   int           `json:"count"`
+	Entries []audit.Entry `json:"entries"`
+}
+
+// openAuditLog 打开配置的审计日志文件
+func openAuditLog() (*audit.Log, error) {
+	backend := config.GetRedisBackendConfig()
+	path, err := dataFile(backend.AuditFile, "redis-audit.jsonl")
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Using audit log: %s", path)
+	return audit.Open(path, int64(backend.AuditMaxSize)<<20, backend.AuditMaxFiles)
+}
+
+// HandleAudit 查询审计日志
+// 查询参数：connection、key（模式，语法与KEYS相同）、since、until（RFC3339或Unix秒）、limit（默认100，最多1000）
+func (h *RedisConnectHandler) HandleAudit(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "")
+		return
+	}
+
+	query := r.URL.Query()
+	filter := audit.Filter{
+		ConnectionID: query.Get("connection"),
+		KeyPattern:   query.Get("key"),
+		Limit:        defaultAuditLimit,
+	}
+	var err error
+	if filter.Since, err = parseAuditTime(query.Get("since")); err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid since", err.Error())
+		return
+	}
+	if filter.Until, err = parseAuditTime(query.Get("until")); err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid until", err.Error())
+		return
+	}
+	if text := query.Get("limit"); text != "" {
+		if filter.Limit, err = strconv.Atoi(text); err != nil || filter.Limit <= 0 {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid limit", text)
+			return
+		}
+		if filter.Limit > maxAuditLimit {
+			filter.Limit = maxAuditLimit
+		}
+	}
+
+	entries, err := h.auditLog.Query(filter)
+	if errors.Is(err, audit.ErrInvalidPattern) {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid key pattern", err.Error())
+		return
+	}
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to query audit log", err.Error())
+		return
+	}
+
+	response := AuditResponse{
+		Success: true,
+		Message: "Audit entries retrieved successfully",
+		Count:   len(entries),
+		Entries: entries,
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// RecordAudit 记录handlers包之外的接口在会话连接上的操作，会话信息从请求的Token获取
+func (h *RedisConnectHandler) RecordAudit(r *http.Request, conn *pool.RedisConnection, entry audit.Entry) {
+	tokenInfo, err := h.tokenFromRequest(r)
+	if err != nil {
+		tokenInfo = nil
+	}
+	h.recordAudit(r, tokenInfo, conn, entry)
+}
+
+// recordAudit 记录一次修改操作，补全请求来源、会话与连接信息
+// 写入失败时只打印日志，不影响操作的结果
+func (h *RedisConnectHandler) recordAudit(r *http.Request, tokenInfo *auth.TokenInfo, conn *pool.RedisConnection, entry audit.Entry) {
+	entry.Origin, entry.RemoteAddr = requestOrigin(r)
+	if tokenInfo != nil {
+		entry.SessionID = tokenInfo.SessionID
+	}
+	if conn != nil {
+		entry.ConnectionID = conn.ID
+		entry.Environment = conn.Environment
+	}
+	if err := h.auditLog.Record(entry); err != nil {
+		log.Printf("Failed to write audit entry: %v", err)
+	}
+}
+
+// requestOrigin 返回请求的Origin（没有时为Referer）与来源地址
+func requestOrigin(r *http.Request) (string, string) {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		origin = r.Header.Get("Referer")
+	}
+	return origin, r.RemoteAddr
+}
+
+// keyStates 查询键的类型与大小，用于预览与审计
+func keyStates(ctx context.Context, client mock.RedisInterface, keys []string) ([]audit.KeyState, error) {
+	states := make([]audit.KeyState, 0, len(keys))
+	for _, key := range keys {
+		keyType, err := client.Type(ctx, key).Result()
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, audit.KeyState{Key: key, Type: keyType, Size: keySize(ctx, client, key, keyType)})
+	}
+	return states, nil
+}
+
+// keySize 字符串返回字节数，其他类型返回元素数量，查询失败时返回0
+func keySize(ctx context.Context, client mock.RedisInterface, key, keyType string) int64 {
+	switch keyType {
+	case "string":
+		return client.StrLen(ctx, key).Val()
+	case "list":
+		return client.LLen(ctx, key).Val()
+	case "set":
+		return client.SCard(ctx, key).Val()
+	case "zset":
+		return client.ZCard(ctx, key).Val()
+	case "hash":
+		return int64(len(client.HKeys(ctx, key).Val()))
+	case "stream":
+		return client.XLen(ctx, key).Val()
+	}
+	return 0
+}
+
+// auditResult 根据操作的错误返回审计结果
+func auditResult(err error) (string, string) {
+	if err != nil {
+		return audit.ResultError, err.Error()
+	}
+	return audit.ResultOK, ""
+}
+
+// parseAuditTime 解析RFC3339时间或Unix秒，为空时返回零值
+func parseAuditTime(text string) (time.Time, error) {
+	if text == "" {
+		return time.Time{}, nil
+	}
+	if seconds, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+	t, err := time.Parse(time.RFC3339, text)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC3339 time or unix seconds: %s", text)
+	}
+	return t, nil
+}
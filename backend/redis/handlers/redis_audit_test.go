@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/devtoolbox/redis/audit"
+	"github.com/devtoolbox/redis/auth"
+	"github.com/devtoolbox/redis/mock"
+)
+
+func TestHandleAudit_QueryErrors(t *testing.T) {
+	h := newTestHandler(t)
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	auditLog, err := audit.Open(path, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	t.Cleanup(func() { auditLog.Close() })
+	h.auditLog = auditLog
+
+	if resp := serve(h.HandleAudit, http.MethodGet, "/api/audit", ""); resp.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := serve(h.HandleAudit, http.MethodGet, "/api/audit?key=%5B", ""); resp.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid key pattern, got %d", resp.Code)
+	}
+
+	// 历史文件无法读取属于服务端错误
+	if err := os.Mkdir(path+".1", 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if resp := serve(h.HandleAudit, http.MethodGet, "/api/audit", ""); resp.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500 when the audit log cannot be read, got %d", resp.Code)
+	}
+}
+
+func TestRecordAudit_AttributesSession(t *testing.T) {
+	h := newTestHandler(t)
+	token := connectSession(t, h, "legacy", 0, auth.ScopeRead, auth.ScopeWrite)
+	conn, err := h.connectionPool.GetConnection("legacy")
+	if err != nil {
+		t.Fatalf("Failed to get connection: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/redis/key/k", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	h.RecordAudit(req, conn, audit.Entry{Command: "DEL", Keys: []string{"k"}, Result: audit.ResultOK})
+
+	entries, err := h.auditLog.Query(audit.Filter{})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+	if entries[0].SessionID == "" || entries[0].ConnectionID != "legacy" {
+		t.Errorf("Expected entry to be attributed to the session, got %+v", entries[0])
+	}
+}
+
+func TestKeyStates(t *testing.T) {
+	client := mock.NewRedisMock()
+	defer client.Close()
+	ctx := context.Background()
+
+	client.Set(ctx, "str", "hello", 0)
+	client.RPush(ctx, "list", "a", "b")
+
+	states, err := keyStates(ctx, client, []string{"str", "list", "missing"})
+	if err != nil {
+		t.Fatalf("keyStates failed: %v", err)
+	}
+	want := []audit.KeyState{
+		{Key: "str", Type: "string", Size: 5},
+		{Key: "list", Type: "list", Size: 2},
+		{Key: "missing", Type: "none", Size: 0},
+	}
+	for i, state := range states {
+		if state != want[i] {
+			t.Errorf("Expected %+v, got %+v", want[i], state)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/devtoolbox/redis/cluster"
+	"github.com/devtoolbox/redis/pool"
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+// commandTimeout 单次请求中执行Redis命令的超时时间
+const commandTimeout = 10 * time.Second
+
+// ClusterSlotInfo 槽位区间及其负责的节点
+type ClusterSlotInfo struct {
+	Start    int      `json:"start"`
+	End      int      `json:"end"`
+	Master   string   `json:"master"`
+	Replicas []string `json:"replicas,omitempty"`
+}
+
+// ClusterInfoResponse 集群信息响应结构
+type ClusterInfoResponse struct {
+	Success bool              `json:"success"`
+	Message string            `json:"message"`
+	Slots   []ClusterSlotInfo `json:"slots"`
+	Nodes   []cluster.Node    `json:"nodes"`
+}
+
+// ScanResponse SCAN响应结构，游标以字符串返回以免超出JavaScript的整数精度
+type ScanResponse struct {
+	Success bool     `json:"success"`
+	Message string   `json:"message"`
+	Cursor  string   `json:"cursor"`
+	Keys    []string `json:"keys"`
+}
+
+// HandleClusterInfo 返回集群的槽位分布与节点列表
+func (h *RedisConnectHandler) HandleClusterInfo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "")
+		return
+	}
+
+	_, conn, err := h.authorize(r, "CLUSTER SLOTS", "CLUSTER NODES")
+	if err != nil {
+		h.sendAuthError(w, err)
+		return
+	}
+	if conn.Mode != pool.ModeCluster {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Not a cluster connection", "")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), commandTimeout)
+	defer cancel()
+
+	slots, err := conn.Client.ClusterSlots(ctx).Result()
+	if err != nil {
+		h.sendErrorResponse(w, connectErrorStatus(err), "Failed to get cluster slots", err.Error())
+		return
+	}
+	nodesText, err := conn.Client.ClusterNodes(ctx).Result()
+	if err != nil {
+		h.sendErrorResponse(w, connectErrorStatus(err), "Failed to get cluster nodes", err.Error())
+		return
+	}
+	nodes, err := cluster.ParseNodes(nodesText)
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusBadGateway, "Failed to parse cluster nodes", err.Error())
+		return
+	}
+
+	response := ClusterInfoResponse{
+		Success: true,
+		Message: "Cluster info retrieved successfully",
+		Slots:   make([]ClusterSlotInfo, 0, len(slots)),
+		Nodes:   nodes,
+	}
+	for _, slot := range slots {
+		info := ClusterSlotInfo{Start: slot.Start, End: slot.End}
+		for i, node := range slot.Nodes {
+			if i == 0 {
+				info.Master = node.Addr
+			} else {
+				info.Replicas = append(info.Replicas, node.Addr)
+			}
+		}
+		response.Slots = append(response.Slots, info)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleScan 分页遍历键，集群连接会依次遍历所有主节点
+// 查询参数：cursor（默认0）、match、count
+func (h *RedisConnectHandler) HandleScan(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "")
+		return
+	}
+
+	_, conn, err := h.authorize(r, "SCAN")
+	if err != nil {
+		h.sendAuthError(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	var cursor uint64
+	if text := query.Get("cursor"); text != "" {
+		if cursor, err = strconv.ParseUint(text, 10, 64); err != nil {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid cursor", err.Error())
+			return
+		}
+	}
+	var count int64
+	if text := query.Get("count"); text != "" {
+		if count, err = strconv.ParseInt(text, 10, 64); err != nil || count <= 0 {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid count", text)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), commandTimeout)
+	defer cancel()
+
+	keys, next, err := conn.Client.Scan(ctx, cursor, query.Get("match"), count).Result()
+	if err != nil {
+		h.sendErrorResponse(w, rediserr.HTTPStatus(err), "Failed to scan keys", err.Error())
+		return
+	}
+
+	response := ScanResponse{
+		Success: true,
+		Message: "Keys scanned successfully",
+		Cursor:  strconv.FormatUint(next, 10),
+		Keys:    keys,
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -1,16 +1,28 @@
 package handlers
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
 	"time"
 
+	"github.com/devtoolbox/redis/audit"
 	"github.com/devtoolbox/redis/auth"
 	"github.com/devtoolbox/redis/config"
 	"github.com/devtoolbox/redis/crypto"
 	"github.com/devtoolbox/redis/pool"
+	"github.com/devtoolbox/redis/policy"
+	"github.com/devtoolbox/redis/profile"
+	"github.com/devtoolbox/redis/rediserr"
+	"github.com/devtoolbox/redis/undo"
 )
 
 // ConnectRequest 连接请求结构
@@ -19,7 +31,44 @@ type ConnectRequest struct {
 	Port             int    `json:"port"`
 	EncryptedPassword string `json:"encryptedPassword"`
 	Database         int    `json:"database"`
-	Alias            string `json:"alias"`
+	Environment      string `json:"environment,omitempty"` // 环境标签，例如dev、staging、prod
+	Cluster          bool     `json:"cluster,omitempty"` // 为true时以集群模式连接
+	Nodes            []string `json:"nodes,omitempty"`   // 额外的集群种子节点（host:port）
+	Sentinel         *SentinelRequest `json:"sentinel,omitempty"` // 非空时通过哨兵连接，host和port为第一个哨兵的地址
+	Username         string      `json:"username,omitempty"` // Redis 6 ACL用户名
+	TLS              *TLSRequest `json:"tls,omitempty"`      // 非空时使用TLS连接
+	URI              string      `json:"uri,omitempty"`      // redis://或rediss://连接串，密码部分为RSA加密后的值
+	SSH              *SSHRequest `json:"ssh,omitempty"`      // 非空时经由SSH跳板机连接，host和port为跳板机可以访问的地址
+	Scopes           []string    `json:"scopes,omitempty"`   // 会话的权限：read、write或admin，默认为read和write，超过security.maxScope的部分不会授予
+
+	uriOptions pool.ConnectOptions // 从URI解析出的超时与连接池选项
+}
+
+// SSHRequest SSH跳板机参数，密码、私钥和口令都使用RSA加密
+type SSHRequest struct {
+	Host                string `json:"host"`
+	Port                int    `json:"port,omitempty"`
+	User                string `json:"user"`
+	EncryptedPassword   string `json:"encryptedPassword,omitempty"`
+	EncryptedPrivateKey string `json:"encryptedPrivateKey,omitempty"` // 分段加密后以"."连接
+	EncryptedPassphrase string `json:"encryptedPassphrase,omitempty"`
+	KnownHosts          string `json:"knownHosts"` // known_hosts格式，用于校验跳板机的主机密钥
+}
+
+// TLSRequest TLS连接参数，证书为PEM格式
+type TLSRequest struct {
+	ServerName         string `json:"serverName,omitempty"`
+	CACert             string `json:"caCert,omitempty"`
+	ClientCert         string `json:"clientCert,omitempty"`
+	EncryptedClientKey string `json:"encryptedClientKey,omitempty"` // 客户端私钥，与密码一样使用RSA加密，较长时分段加密后以"."连接
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+}
+
+// SentinelRequest 哨兵连接参数
+type SentinelRequest struct {
+	MasterName        string   `json:"masterName"`
+	Addrs             []string `json:"addrs,omitempty"`             // 额外的哨兵地址（host:port）
+	EncryptedPassword string   `json:"encryptedPassword,omitempty"` // 哨兵自身的密码，与数据节点密码一样使用RSA加密
 }
 
 // ConnectResponse 连接响应结构
@@ -27,8 +76,9 @@ type ConnectResponse struct {
 	Success      bool   `json:"success"`
 	Message      string `json:"message"`
 	ConnectionID string `json:"connectionId,omitempty"`
-	Token        string `json:"token,omitempty"`
-	ExpiresAt    int64  `json:"expiresAt,omitempty"`
+	Token        string   `json:"token,omitempty"`
+	ExpiresAt    int64    `json:"expiresAt,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
 }
 
 // ErrorResponse 错误响应结构
@@ -43,6 +93,15 @@ type RedisConnectHandler struct {
 	connectionPool *pool.ConnectionPool
 	tokenManager   *auth.TokenManager
 	rsaDecryptor   *crypto.RSADecryptor
+	profileStore   *profile.Store
+	profileKeyID   string     // 连接配置加密所用RSA密钥的ID，使用主口令时为空
+	maxScope       string     // 连接时可以请求的最高权限，为空时为write
+	rotationToken  string     // 通过接口轮换RSA密钥需要的凭据，为空时不允许
+	nonceLimiter   *clientLimiter // 按客户端地址限制签发nonce的速率
+	keyMutex       sync.Mutex // 串行化RSA密钥的轮换与清理
+	commandPolicy  *policy.Engine
+	auditLog       *audit.Log
+	undoJournal    *undo.Journal
 }
 
 // NewRedisConnectHandler 创建新的Redis连接处理器
@@ -53,22 +112,67 @@ func NewRedisConnectHandler() (*RedisConnectHandler, error) {
 		return nil, fmt.Errorf("security configuration not found")
 	}
 
-	// 创建RSA解密器
-	rsaDecryptor, err := crypto.NewRSADecryptor(securityConfig.Encryption.PrivateKey)
+	// 连接时可以请求的最高权限
+	maxScope := securityConfig.MaxScope
+	if maxScope == "" {
+		maxScope = auth.ScopeWrite
+	}
+	if _, err := auth.ParseScopes([]string{maxScope}); err != nil {
+		return nil, fmt.Errorf("invalid security.maxScope: %v", err)
+	}
+
+	// 创建RSA解密器，未配置私钥时使用自动生成并保存在密钥文件中的密钥
+	rsaDecryptor, err := openRSADecryptor(securityConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create RSA decryptor: %v", err)
 	}
 
 	// 创建连接池
 	connectionPool := pool.NewConnectionPool(securityConfig.MaxConnections)
+	connectionPool.SetTraceDir(config.GetRedisBackendConfig().TraceDir)
+
+	// 创建Token管理器，签名密钥保存在文件中，重启后已签发的Token仍然有效
+	keyringPath, err := dataFile(config.GetRedisBackendConfig().TokenKeyFile, "redis-token-keys.json")
+	if err != nil {
+		return nil, err
+	}
+	keyring, err := auth.OpenKeyring(keyringPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open token keyring: %v", err)
+	}
+	log.Printf("Using token keyring: %s", keyringPath)
+	tokenManager := auth.NewTokenManagerWithKeyring(time.Duration(securityConfig.TokenExpiry)*time.Second, keyring)
+
+	// 打开保存的连接配置
+	profileStore, profileKeyID, err := openProfileStore(rsaDecryptor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open profile store: %v", err)
+	}
+
+	// 按环境标签检查危险命令的规则
+	commandPolicy, err := policy.NewEngine(securityConfig.CommandPolicies)
+	if err != nil {
+		return nil, err
+	}
 
-	// 创建Token管理器
-	tokenManager := auth.NewTokenManager(time.Duration(securityConfig.TokenExpiry) * time.Second)
+	// 打开修改操作的审计日志
+	auditLog, err := openAuditLog()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %v", err)
+	}
 
 	return &RedisConnectHandler{
 		connectionPool: connectionPool,
 		tokenManager:   tokenManager,
 		rsaDecryptor:   rsaDecryptor,
+		profileStore:   profileStore,
+		profileKeyID:   profileKeyID,
+		maxScope:       maxScope,
+		rotationToken:  securityConfig.RotationToken,
+		nonceLimiter:   newClientLimiter(nonceBurst, nonceRefill),
+		commandPolicy:  commandPolicy,
+		auditLog:       auditLog,
+		undoJournal:    undo.NewJournal(undo.DefaultMaxChanges, undo.DefaultMaxAge),
 	}, nil
 }
 
@@ -90,61 +194,115 @@ func (h *RedisConnectHandler) HandleConnect(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	// 解析连接串并填充对应字段
+	if req.URI != "" {
+		if err := applyURI(&req); err != nil {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid connection URI", err.Error())
+			return
+		}
+	}
+
 	// 验证请求参数
 	if err := h.validateConnectRequest(&req); err != nil {
 		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request parameters", err.Error())
 		return
 	}
 
+	scopes, err := h.grantScopes(r, req.Scopes)
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+
 	// 解密密码
 	password, err := h.rsaDecryptor.DecryptPassword(req.EncryptedPassword)
 	if err != nil {
 		log.Printf("Failed to decrypt password: %v", err)
-		h.sendErrorResponse(w, http.StatusBadRequest, "Failed to decrypt password", "")
+		h.sendErrorResponse(w, http.StatusBadRequest, "Failed to decrypt password", decryptErrorDetail(err))
+		return
+	}
+
+	opts, err := h.connectOptions(&req, password)
+	if err != nil {
+		log.Printf("Invalid connection options: %v", err)
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid connection options", err.Error())
 		return
 	}
 
 	// 生成连接ID
 	connectionID := h.generateConnectionID(req.Host, req.Port, req.Database)
 
-	// 创建Redis连接
-	_, err = h.connectionPool.CreateConnection(
-		connectionID,
-		req.Host,
-		req.Port,
-		req.Database,
-		password,
-		req.Alias,
-	)
+	// 创建Redis连接，端点与凭据相同时复用已有连接
+	var conn *pool.RedisConnection
+	if req.Sentinel != nil {
+		var sentinelPassword string
+		if req.Sentinel.EncryptedPassword != "" {
+			sentinelPassword, err = h.rsaDecryptor.DecryptPassword(req.Sentinel.EncryptedPassword)
+			if err != nil {
+				log.Printf("Failed to decrypt sentinel password: %v", err)
+				h.sendErrorResponse(w, http.StatusBadRequest, "Failed to decrypt sentinel password", decryptErrorDetail(err))
+				return
+			}
+		}
+		conn, err = h.connectionPool.CreateSentinelConnection(
+			connectionID,
+			pool.SentinelConfig{
+				MasterName: req.Sentinel.MasterName,
+				Addrs:      sentinelAddrs(&req),
+				Password:   sentinelPassword,
+			},
+			opts,
+		)
+	} else if req.Cluster {
+		conn, err = h.connectionPool.CreateClusterConnection(
+			connectionID,
+			clusterAddrs(&req),
+			opts,
+		)
+	} else {
+		conn, err = h.connectionPool.CreateConnectionWithOptions(
+			connectionID,
+			req.Host,
+			req.Port,
+			opts,
+		)
+	}
 	if err != nil {
 		log.Printf("Failed to create Redis connection: %v", err)
-		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to connect to Redis", err.Error())
+		h.sendErrorResponse(w, connectErrorStatus(err), "Failed to connect to Redis", err.Error())
+		return
+	}
+
+	if !h.sendConnected(w, auth.Session{ConnectionID: conn.ID, DB: conn.DB, Scopes: scopes}) {
 		return
 	}
 
-	// 生成Token
-	tokenInfo, err := h.tokenManager.GenerateToken(connectionID)
+	log.Printf("Redis connection ready: %s (%s:%d/%d)", conn.ID, req.Host, req.Port, req.Database)
+}
+
+// sendConnected 为连接生成Token并发送成功响应，失败时释放连接的引用并返回false
+func (h *RedisConnectHandler) sendConnected(w http.ResponseWriter, session auth.Session) bool {
+	tokenInfo, err := h.tokenManager.GenerateToken(session)
 	if err != nil {
 		log.Printf("Failed to generate token: %v", err)
-		// 清理已创建的连接
-		h.connectionPool.RemoveConnection(connectionID)
+		// 连接可能被其他Token共用，只释放本次的引用
+		h.connectionPool.Release(session.ConnectionID)
 		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to generate token", err.Error())
-		return
+		return false
 	}
 
-	// 发送成功响应
 	response := ConnectResponse{
 		Success:      true,
 		Message:      "Connected to Redis successfully",
-		ConnectionID: connectionID,
+		ConnectionID: session.ConnectionID,
 		Token:        tokenInfo.Token,
 		ExpiresAt:    tokenInfo.ExpiresAt.Unix(),
+		Scopes:       tokenInfo.Scopes,
 	}
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
-
-	log.Printf("Redis connection created successfully: %s (%s:%d/%d)", connectionID, req.Host, req.Port, req.Database)
+	return true
 }
 
 // validateConnectRequest 验证连接请求参数
@@ -165,13 +323,205 @@ func (h *RedisConnectHandler) validateConnectRequest(req *ConnectRequest) error
 		return fmt.Errorf("invalid database number: %d", req.Database)
 	}
 
+	if req.Cluster && req.Database != 0 {
+		return fmt.Errorf("cluster mode only supports database 0")
+	}
+
+	for _, node := range req.Nodes {
+		if _, _, err := net.SplitHostPort(node); err != nil {
+			return fmt.Errorf("invalid cluster node address: %s", node)
+		}
+	}
+
+	if req.SSH != nil {
+		if req.SSH.Host == "" || req.SSH.User == "" {
+			return fmt.Errorf("ssh host and user are required")
+		}
+		if req.SSH.Port < 0 || req.SSH.Port > 65535 {
+			return fmt.Errorf("invalid ssh port number: %d", req.SSH.Port)
+		}
+		if req.SSH.EncryptedPassword == "" && req.SSH.EncryptedPrivateKey == "" {
+			return fmt.Errorf("ssh password or private key is required")
+		}
+		if req.SSH.KnownHosts == "" {
+			return fmt.Errorf("ssh known_hosts is required")
+		}
+	}
+
+	if req.Sentinel != nil {
+		if req.Cluster {
+			return fmt.Errorf("sentinel and cluster modes cannot be combined")
+		}
+		if req.Sentinel.MasterName == "" {
+			return fmt.Errorf("sentinel master name is required")
+		}
+		for _, addr := range req.Sentinel.Addrs {
+			if _, _, err := net.SplitHostPort(addr); err != nil {
+				return fmt.Errorf("invalid sentinel address: %s", addr)
+			}
+		}
+	}
+
+	return nil
+}
+
+// sshOptions 解密跳板机的凭据
+func (h *RedisConnectHandler) sshOptions(req *SSHRequest) (*pool.SSHOptions, error) {
+	opts := &pool.SSHOptions{
+		Host:       req.Host,
+		Port:       req.Port,
+		User:       req.User,
+		KnownHosts: req.KnownHosts,
+	}
+	var err error
+	if req.EncryptedPassword != "" {
+		if opts.Password, err = h.rsaDecryptor.DecryptPassword(req.EncryptedPassword); err != nil {
+			return nil, fmt.Errorf("failed to decrypt ssh password: %w", err)
+		}
+	}
+	if req.EncryptedPrivateKey != "" {
+		if opts.PrivateKey, err = h.rsaDecryptor.DecryptChunks(req.EncryptedPrivateKey); err != nil {
+			return nil, fmt.Errorf("failed to decrypt ssh private key: %w", err)
+		}
+	}
+	if req.EncryptedPassphrase != "" {
+		if opts.Passphrase, err = h.rsaDecryptor.DecryptPassword(req.EncryptedPassphrase); err != nil {
+			return nil, fmt.Errorf("failed to decrypt ssh passphrase: %w", err)
+		}
+	}
+	return opts, nil
+}
+
+// applyURI 解析连接串，将地址、数据库、用户名、加密的密码和TLS写入请求
+// 使用URI时不能再单独指定这些字段，避免两者不一致
+func applyURI(req *ConnectRequest) error {
+	if req.Host != "" || req.Port != 0 || req.Database != 0 || req.Username != "" {
+		return fmt.Errorf("uri cannot be combined with host, port, database or username")
+	}
+
+	config, err := pool.ParseURI(req.URI)
+	if err != nil {
+		return err
+	}
+	req.Host = config.Host
+	req.Port = config.Port
+	req.Database = config.Options.DB
+	req.Username = config.Options.Username
+	req.uriOptions = config.Options
+
+	if config.Options.Password != "" {
+		if req.EncryptedPassword != "" {
+			return fmt.Errorf("password must be given either in the uri or as encryptedPassword")
+		}
+		req.EncryptedPassword = config.Options.Password
+	}
+
+	if config.Options.TLS == nil {
+		if req.TLS != nil {
+			return fmt.Errorf("tls options require the rediss scheme")
+		}
+		return nil
+	}
+	if req.TLS == nil {
+		req.TLS = &TLSRequest{}
+	}
+	req.TLS.InsecureSkipVerify = req.TLS.InsecureSkipVerify || config.Options.TLS.InsecureSkipVerify
 	return nil
 }
 
-// generateConnectionID 生成连接ID
+// connectOptions 解密客户端私钥并组装连接参数，TLS证书有误时提前返回错误
+func (h *RedisConnectHandler) connectOptions(req *ConnectRequest, password string) (pool.ConnectOptions, error) {
+	opts := req.uriOptions
+	opts.DB = req.Database
+	opts.Username = req.Username
+	opts.Password = password
+	opts.Environment = req.Environment
+	opts.TLS = nil
+
+	if req.SSH != nil {
+		ssh, err := h.sshOptions(req.SSH)
+		if err != nil {
+			return opts, err
+		}
+		opts.SSH = ssh
+	}
+
+	if req.TLS == nil {
+		return opts, nil
+	}
+
+	opts.TLS = &pool.TLSOptions{
+		ServerName:         req.TLS.ServerName,
+		CACert:             req.TLS.CACert,
+		ClientCert:         req.TLS.ClientCert,
+		InsecureSkipVerify: req.TLS.InsecureSkipVerify,
+	}
+	if req.TLS.EncryptedClientKey != "" {
+		key, err := h.rsaDecryptor.DecryptChunks(req.TLS.EncryptedClientKey)
+		if err != nil {
+			return opts, fmt.Errorf("failed to decrypt client key: %w", err)
+		}
+		opts.TLS.ClientKey = key
+	}
+	if _, err := opts.TLS.Config(req.Host); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// clusterAddrs 集群种子节点：请求的host:port在前，其余节点去重后追加
+func clusterAddrs(req *ConnectRequest) []string {
+	return joinAddrs(req.Host, req.Port, req.Nodes)
+}
+
+// sentinelAddrs 哨兵地址：请求的host:port在前，其余哨兵去重后追加
+func sentinelAddrs(req *ConnectRequest) []string {
+	return joinAddrs(req.Host, req.Port, req.Sentinel.Addrs)
+}
+
+// joinAddrs 将host:port与其余地址合并并去重
+func joinAddrs(host string, port int, others []string) []string {
+	addrs := []string{net.JoinHostPort(host, strconv.Itoa(port))}
+	seen := map[string]bool{addrs[0]: true}
+	for _, addr := range others {
+		if !seen[addr] {
+			seen[addr] = true
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// dataFile 返回配置的文件路径，未配置时使用用户配置目录下的devtoolbox/<name>
+func dataFile(configured, name string) (string, error) {
+	if configured != "" {
+		return configured, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("no %s configured and no user config directory: %v", name, err)
+	}
+	return filepath.Join(dir, "devtoolbox", name), nil
+}
+
+// generateConnectionID 生成连接ID，随机后缀保证同一秒内的多次连接不会冲突
 func (h *RedisConnectHandler) generateConnectionID(host string, port, database int) string {
-	timestamp := time.Now().Unix()
-	return fmt.Sprintf("%s_%d_%d_%d", host, port, database, timestamp)
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		// 随机数不可用时退回到纳秒时间戳
+		return fmt.Sprintf("%s_%d_%d_%d", host, port, database, time.Now().UnixNano())
+	}
+	return fmt.Sprintf("%s_%d_%d_%s", host, port, database, hex.EncodeToString(suffix))
+}
+
+// connectErrorStatus 将建立连接的错误映射为HTTP状态码
+// 握手时的Nil等错误不表示资源不存在，因此不返回404，未识别的错误（如网络不可达）仍按500处理
+func connectErrorStatus(err error) int {
+	status := rediserr.HTTPStatus(err)
+	if status == http.StatusNotFound {
+		return http.StatusInternalServerError
+	}
+	return status
 }
 
 // sendErrorResponse 发送错误响应
@@ -191,6 +541,16 @@ func (h *RedisConnectHandler) GetConnectionPool() *pool.ConnectionPool {
 	return h.connectionPool
 }
 
+// GetCommandPolicy 获取危险命令规则引擎（用于其他处理器）
+func (h *RedisConnectHandler) GetCommandPolicy() *policy.Engine {
+	return h.commandPolicy
+}
+
+// GetAuditLog 获取审计日志（用于其他处理器）
+func (h *RedisConnectHandler) GetAuditLog() *audit.Log {
+	return h.auditLog
+}
+
 // GetTokenManager 获取Token管理器（用于其他处理器）
 func (h *RedisConnectHandler) GetTokenManager() *auth.TokenManager {
 	return h.tokenManager
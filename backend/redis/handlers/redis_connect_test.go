@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+func TestConnectErrorStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{rediserr.Nil, http.StatusInternalServerError},
+		{fmt.Errorf("failed to ping: %w", rediserr.Nil), http.StatusInternalServerError},
+		{rediserr.WrongPass, http.StatusUnauthorized},
+		{rediserr.Closed, http.StatusServiceUnavailable},
+		{errors.New("dial tcp: connection refused"), http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		if got := connectErrorStatus(tt.err); got != tt.want {
+			t.Errorf("connectErrorStatus(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
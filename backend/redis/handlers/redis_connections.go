@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devtoolbox/redis/auth"
+	"github.com/devtoolbox/redis/pool"
+)
+
+// pingTimeout 列表与单个连接检测时PING的超时时间
+const pingTimeout = 2 * time.Second
+
+// ConnectionInfo 连接信息及最近一次PING的结果
+type ConnectionInfo struct {
+	*pool.RedisConnection
+	Alive     bool    `json:"alive"`
+	LatencyMs float64 `json:"latencyMs"`
+	PingError string  `json:"pingError,omitempty"`
+}
+
+// ConnectionListResponse 连接列表响应结构
+type ConnectionListResponse struct {
+	Success     bool             `json:"success"`
+	Message     string           `json:"message"`
+	Count       int              `json:"count"`
+	Connections []ConnectionInfo `json:"connections"`
+}
+
+// ConnectionResponse 单个连接的响应结构
+type ConnectionResponse struct {
+	Success    bool            `json:"success"`
+	Message    string          `json:"message"`
+	Connection *ConnectionInfo `json:"connection,omitempty"`
+}
+
+// HandleConnections 列出会话自己的连接（admin权限的会话列出所有连接），并发检测每个连接的延迟
+func (h *RedisConnectHandler) HandleConnections(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "")
+		return
+	}
+
+	tokenInfo, err := h.tokenFromRequest(r)
+	if err != nil {
+		h.sendAuthError(w, err)
+		return
+	}
+
+	var connections []*pool.RedisConnection
+	for _, conn := range h.connectionPool.ListConnections() {
+		if ownsConnection(tokenInfo, conn.ID) {
+			connections = append(connections, conn)
+		}
+	}
+	infos := make([]ConnectionInfo, len(connections))
+	var wg sync.WaitGroup
+	for i, conn := range connections {
+		wg.Add(1)
+		go func(i int, conn *pool.RedisConnection) {
+			defer wg.Done()
+			infos[i] = h.pingConnection(r.Context(), conn)
+		}(i, conn)
+	}
+	wg.Wait()
+
+	response := ConnectionListResponse{
+		Success:     true,
+		Message:     "Connections retrieved successfully",
+		Count:       len(infos),
+		Connections: infos,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleConnection 处理 /api/redis/connections/{id}：
+// GET查看连接，POST /api/redis/connections/{id}/ping检测延迟，DELETE断开连接
+// 只能管理会话自己的连接；普通会话DELETE时只撤销自己的会话，admin权限的会话可以关闭任意连接并撤销其所有Token
+func (h *RedisConnectHandler) HandleConnection(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/redis/connections/"), "/")
+	if id == "" || (action != "" && action != "ping") {
+		h.sendErrorResponse(w, http.StatusNotFound, "Not found", "")
+		return
+	}
+
+	// 先鉴权再查找连接，避免通过404探测其他会话的连接ID
+	tokenInfo, err := h.tokenFromRequest(r)
+	if err != nil {
+		h.sendAuthError(w, err)
+		return
+	}
+	if !ownsConnection(tokenInfo, id) {
+		h.sendAuthError(w, fmt.Errorf("%w: connection %s belongs to another session", auth.ErrForbidden, id))
+		return
+	}
+
+	conn, err := h.connectionPool.GetConnectionInfo(id)
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusNotFound, "Connection not found", err.Error())
+		return
+	}
+
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		info := h.pingConnection(r.Context(), conn)
+		h.sendConnection(w, "Connection retrieved successfully", &info)
+	case action == "ping" && r.Method == http.MethodPost:
+		info := h.pingConnection(r.Context(), conn)
+		if !info.Alive {
+			h.sendErrorResponse(w, http.StatusBadGateway, "Connection is not responding", info.PingError)
+			return
+		}
+		h.sendConnection(w, "Connection is alive", &info)
+	case action == "" && r.Method == http.MethodDelete && !tokenInfo.HasScope(auth.ScopeAdmin):
+		// 连接可能被其他会话共用，只撤销当前会话并释放其引用
+		session, err := h.tokenManager.RevokeToken(tokenInfo.Token)
+		if session != nil {
+			h.connectionPool.Release(session.ConnectionID)
+		}
+		if err != nil {
+			h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to revoke token", err.Error())
+			return
+		}
+		log.Printf("Session %s disconnected from Redis connection %s", tokenInfo.SessionID, id)
+		h.sendConnection(w, "Disconnected successfully", nil)
+	case action == "" && r.Method == http.MethodDelete:
+		h.tokenManager.RevokeTokensByConnectionID(id)
+		if err := h.connectionPool.RemoveConnection(id); err != nil {
+			h.sendErrorResponse(w, http.StatusNotFound, "Connection not found", err.Error())
+			return
+		}
+		log.Printf("Redis connection removed by session %s: %s", tokenInfo.SessionID, id)
+		h.sendConnection(w, "Connection closed successfully", nil)
+	default:
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "")
+	}
+}
+
+// ownsConnection 连接是否属于会话，admin权限的会话可以访问所有连接
+func ownsConnection(tokenInfo *auth.TokenInfo, connectionID string) bool {
+	return tokenInfo.ConnectionID == connectionID || tokenInfo.HasScope(auth.ScopeAdmin)
+}
+
+// pingConnection 检测连接并组装连接信息，失败时Alive为false并记录原因
+func (h *RedisConnectHandler) pingConnection(ctx context.Context, conn *pool.RedisConnection) ConnectionInfo {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	info := ConnectionInfo{RedisConnection: conn}
+	latency, err := h.connectionPool.Ping(ctx, conn.ID)
+	if err != nil {
+		info.PingError = err.Error()
+		return info
+	}
+	info.Alive = true
+	info.LatencyMs = float64(latency.Microseconds()) / 1000
+	return info
+}
+
+// sendConnection 发送单个连接的响应，断开后info为nil
+func (h *RedisConnectHandler) sendConnection(w http.ResponseWriter, message string, info *ConnectionInfo) {
+	response := ConnectionResponse{
+		Success:    true,
+		Message:    message,
+		Connection: info,
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/devtoolbox/redis/audit"
+	"github.com/devtoolbox/redis/auth"
+	"github.com/devtoolbox/redis/pool"
+	"github.com/devtoolbox/redis/undo"
+)
+
+// newTestHandler 创建使用Mock连接池的处理器，审计日志写入临时目录
+func newTestHandler(t *testing.T) *RedisConnectHandler {
+	t.Helper()
+	connectionPool := pool.NewConnectionPool(10)
+	connectionPool.SetMockMode(true)
+	t.Cleanup(connectionPool.Close)
+	auditLog, err := audit.Open(filepath.Join(t.TempDir(), "audit.jsonl"), 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	t.Cleanup(func() { auditLog.Close() })
+	return &RedisConnectHandler{
+		connectionPool: connectionPool,
+		tokenManager:   auth.NewTokenManager(time.Hour),
+		nonceLimiter:   newClientLimiter(nonceBurst, nonceRefill),
+		auditLog:       auditLog,
+		undoJournal:    undo.NewJournal(undo.DefaultMaxChanges, undo.DefaultMaxAge),
+	}
+}
+
+// connectSession 创建Mock连接并签发具有给定权限的Token
+func connectSession(t *testing.T, h *RedisConnectHandler, id string, db int, scopes ...string) string {
+	t.Helper()
+	if _, err := h.connectionPool.CreateConnection(id, "localhost", 6379, db, "", ""); err != nil {
+		t.Fatalf("Failed to create connection: %v", err)
+	}
+	tokenInfo, err := h.tokenManager.GenerateToken(auth.Session{ConnectionID: id, DB: db, Scopes: scopes})
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	return tokenInfo.Token
+}
+
+// serve 使用Token调用处理函数并返回响应
+func serve(handler http.HandlerFunc, method, target, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	recorder := httptest.NewRecorder()
+	handler(recorder, req)
+	return recorder
+}
+
+func TestHandleConnection_Ownership(t *testing.T) {
+	h := newTestHandler(t)
+	first := connectSession(t, h, "first", 0, auth.ScopeRead, auth.ScopeWrite)
+	second := connectSession(t, h, "second", 1, auth.ScopeRead, auth.ScopeWrite)
+	admin := connectSession(t, h, "third", 2, auth.ScopeRead, auth.ScopeWrite, auth.ScopeAdmin)
+
+	if resp := serve(h.HandleConnection, http.MethodDelete, "/api/redis/connections/second", ""); resp.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without token, got %d", resp.Code)
+	}
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		if resp := serve(h.HandleConnection, method, "/api/redis/connections/second", first); resp.Code != http.StatusForbidden {
+			t.Errorf("%s: expected 403 for another session's connection, got %d", method, resp.Code)
+		}
+	}
+	if resp := serve(h.HandleConnection, http.MethodPost, "/api/redis/connections/second/ping", first); resp.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 when pinging another session's connection, got %d", resp.Code)
+	}
+	if _, err := h.connectionPool.GetConnectionInfo("second"); err != nil {
+		t.Fatalf("Expected connection to survive a forbidden delete: %v", err)
+	}
+
+	var list ConnectionListResponse
+	resp := serve(h.HandleConnections, http.MethodGet, "/api/redis/connections", first)
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("Failed to decode list: %v", err)
+	}
+	if list.Count != 1 || list.Connections[0].ID != "first" {
+		t.Errorf("Expected only the session's own connection, got %+v", list.Connections)
+	}
+	resp = serve(h.HandleConnections, http.MethodGet, "/api/redis/connections", admin)
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("Failed to decode list: %v", err)
+	}
+	if list.Count != 3 {
+		t.Errorf("Expected admin to see all 3 connections, got %d", list.Count)
+	}
+
+	if resp := serve(h.HandleConnection, http.MethodDelete, "/api/redis/connections/second", second); resp.Code != http.StatusOK {
+		t.Errorf("Expected owner to close the connection, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if resp := serve(h.HandleConnection, http.MethodDelete, "/api/redis/connections/first", admin); resp.Code != http.StatusOK {
+		t.Errorf("Expected admin to close any connection, got %d: %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestHandleConnection_DeleteSharedConnection(t *testing.T) {
+	h := newTestHandler(t)
+	first := connectSession(t, h, "shared", 0, auth.ScopeRead, auth.ScopeWrite)
+	// 相同端点的第二次连接复用已有的连接
+	conn, err := h.connectionPool.CreateConnection("duplicate", "localhost", 6379, 0, "", "")
+	if err != nil || conn.ID != "shared" {
+		t.Fatalf("Expected the connection to be reused, got %v, %v", conn, err)
+	}
+	second, err := h.tokenManager.GenerateToken(auth.Session{ConnectionID: conn.ID, Scopes: []string{auth.ScopeRead, auth.ScopeWrite}})
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	admin := connectSession(t, h, "admin", 1, auth.ScopeRead, auth.ScopeWrite, auth.ScopeAdmin)
+
+	if resp := serve(h.HandleConnection, http.MethodDelete, "/api/redis/connections/shared", first); resp.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if _, err := h.tokenManager.ValidateToken(first); err == nil {
+		t.Error("Expected the caller's session to be revoked")
+	}
+	if _, err := h.tokenManager.ValidateToken(second.Token); err != nil {
+		t.Errorf("Expected the other session to stay valid: %v", err)
+	}
+	info, err := h.connectionPool.GetConnectionInfo("shared")
+	if err != nil {
+		t.Fatalf("Expected the shared connection to stay open: %v", err)
+	}
+	if info.Refs != 1 {
+		t.Errorf("Expected 1 remaining ref, got %d", info.Refs)
+	}
+
+	// admin关闭连接并撤销所有会话
+	if resp := serve(h.HandleConnection, http.MethodDelete, "/api/redis/connections/shared", admin); resp.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", resp.Code, resp.Body.String())
+	}
+	if _, err := h.tokenManager.ValidateToken(second.Token); err == nil {
+		t.Error("Expected admin teardown to revoke the remaining session")
+	}
+	if _, err := h.connectionPool.GetConnectionInfo("shared"); err == nil {
+		t.Error("Expected admin teardown to close the connection")
+	}
+}
@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/devtoolbox/redis/config"
+	"github.com/devtoolbox/redis/crypto"
+)
+
+// rotationTokenHeader 轮换RSA密钥时携带security.rotationToken的请求头
+const rotationTokenHeader = "X-Rotation-Token"
+
+// maxNoncesPerRequest 单次请求最多签发的nonce数量，一次连接请求最多包含6个加密字段
+const maxNoncesPerRequest = 8
+
+// 每个客户端签发nonce的限制：最多连续签发nonceBurst个，之后每nonceRefill恢复一个
+const (
+	nonceBurst  = 4 * maxNoncesPerRequest
+	nonceRefill = 2 * time.Second
+)
+
+// PublicKeyResponse 前端加密密码使用的公钥
+// 密码与私钥放在信封（crypto.Envelope）中加密，信封中的kid指明使用的公钥；旧公钥在被替换后的宽限期内仍然可以使用
+type PublicKeyResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	crypto.PublicKey
+	Algorithms []string           `json:"algorithms"` // 接受的加密算法
+	Rotatable  bool               `json:"rotatable"`  // 私钥来自配置文件时为false
+	Keys       []crypto.PublicKey `json:"keys"`       // 仍然可以解密的公钥，从新到旧排列
+}
+
+// NonceResponse 签发的一次性nonce，每个信封使用一个
+type NonceResponse struct {
+	Success    bool      `json:"success"`
+	Message    string    `json:"message"`
+	Nonces     []string  `json:"nonces"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	ServerTime time.Time `json:"serverTime"` // 信封的时间戳需与服务器时间相差不超过5分钟
+}
+
+// RotateKeyResponse 轮换RSA密钥的响应
+type RotateKeyResponse struct {
+	Success bool   `json:"success"`
+	Message string `json:"message"`
+	KeyID   string `json:"keyId"`
+}
+
+// openRSADecryptor 使用配置文件中的私钥创建RSA解密器，未配置时打开（首次启动时生成）密钥文件
+func openRSADecryptor(security *config.Security) (*crypto.RSADecryptor, error) {
+	var rsaDecryptor *crypto.RSADecryptor
+	if security.Encryption.PrivateKey != "" {
+		var err error
+		if rsaDecryptor, err = crypto.NewRSADecryptor(security.Encryption.PrivateKey); err != nil {
+			return nil, err
+		}
+	} else {
+		path, err := dataFile(config.GetRedisBackendConfig().EncryptionKeyFile, "redis-rsa-keys.json")
+		if err != nil {
+			return nil, err
+		}
+		keys, err := crypto.OpenKeyring(path, security.Encryption.KeySize)
+		if err != nil {
+			return nil, err
+		}
+		log.Printf("Using RSA key file: %s (key %s)", path, keys.Current().ID)
+		rsaDecryptor = crypto.NewRSADecryptorWithKeyring(keys)
+	}
+
+	if security.Encryption.AllowLegacyPadding {
+		log.Printf("Warning: accepting PKCS#1 v1.5 ciphertexts without envelopes, captured passwords can be replayed")
+		rsaDecryptor.SetLegacyPadding(true)
+	}
+	return rsaDecryptor, nil
+}
+
+// decryptErrorDetail 只返回信封与兼容模式的错误，前端据此重新获取nonce或改用信封；
+// 其他解密错误不返回细节
+func decryptErrorDetail(err error) string {
+	for _, known := range []error{crypto.ErrLegacyPadding, crypto.ErrStaleEnvelope, crypto.ErrReplayedEnvelope} {
+		if errors.Is(err, known) {
+			return known.Error()
+		}
+	}
+	return ""
+}
+
+// HandlePublicKey 处理 GET /api/crypto/public-key，返回当前公钥及其ID
+func (h *RedisConnectHandler) HandlePublicKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if r.Method != http.MethodGet {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "")
+		return
+	}
+
+	algorithms := []string{crypto.EnvelopeHybrid, crypto.EnvelopeOAEP}
+	if h.rsaDecryptor.LegacyPadding() {
+		algorithms = append(algorithms, crypto.LegacyPKCS1v15)
+	}
+	keys := h.rsaDecryptor.Keyring()
+	response := PublicKeyResponse{
+		Success:    true,
+		Message:    "Public key retrieved successfully",
+		PublicKey:  keys.Current(),
+		Algorithms: algorithms,
+		Rotatable:  keys.Rotatable(),
+		Keys:       keys.PublicKeys(),
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleNonce 处理 POST /api/crypto/nonce?count=n，签发加密信封使用的一次性nonce
+// 每个加密字段需要一个nonce，count默认为1，最多为8
+// 建立连接前还没有Token，因此按客户端地址限流，超过时返回429与Retry-After
+func (h *RedisConnectHandler) HandleNonce(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+
+	if r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "")
+		return
+	}
+
+	count := 1
+	if value := r.URL.Query().Get("count"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxNoncesPerRequest {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid count", fmt.Sprintf("count must be between 1 and %d", maxNoncesPerRequest))
+			return
+		}
+		count = n
+	}
+
+	if ok, wait := h.nonceLimiter.allow(clientAddress(r), count, time.Now()); !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		h.sendErrorResponse(w, http.StatusTooManyRequests, "Too many nonce requests", fmt.Sprintf("retry after %s", wait.Round(time.Second)))
+		return
+	}
+
+	nonces, expiresAt, err := h.rsaDecryptor.Nonces().Issue(count)
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to issue nonce", err.Error())
+		return
+	}
+
+	response := NonceResponse{
+		Success:    true,
+		Message:    "Nonces issued successfully",
+		Nonces:     nonces,
+		ExpiresAt:  expiresAt,
+		ServerTime: time.Now(),
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleRotateKey 处理 POST /api/crypto/rotate，需要在X-Rotation-Token头中提供security.rotationToken
+// 会话的权限由客户端在连接时请求，不能用来授权轮换；未配置rotationToken时该接口关闭
+// 立即生成新的RSA密钥，旧密钥在宽限期内仍然可以解密
+func (h *RedisConnectHandler) HandleRotateKey(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "")
+		return
+	}
+
+	if h.rotationToken == "" {
+		h.sendErrorResponse(w, http.StatusForbidden, "Key rotation is disabled", "security.rotationToken is not configured")
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get(rotationTokenHeader)), []byte(h.rotationToken)) != 1 {
+		h.sendErrorResponse(w, http.StatusUnauthorized, "Unauthorized", "invalid or missing "+rotationTokenHeader)
+		return
+	}
+
+	id, err := h.RotateEncryptionKey()
+	if errors.Is(err, crypto.ErrStaticKey) {
+		h.sendErrorResponse(w, http.StatusConflict, "Key cannot be rotated", err.Error())
+		return
+	}
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to rotate key", err.Error())
+		return
+	}
+
+	log.Printf("Rotated RSA key on request from %s: %s", r.RemoteAddr, id)
+	response := RotateKeyResponse{
+		Success: true,
+		Message: "Key rotated successfully",
+		KeyID:   id,
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// CurrentEncryptionKey 返回当前RSA密钥的ID与创建时间（用于后台维护）
+func (h *RedisConnectHandler) CurrentEncryptionKey() (string, time.Time) {
+	key := h.rsaDecryptor.Keyring().Current()
+	return key.ID, key.CreatedAt
+}
+
+// RotateEncryptionKey 生成新的RSA密钥，并用新密钥重新加密保存的连接配置（用于后台维护）
+// 私钥来自配置文件时返回crypto.ErrStaticKey
+func (h *RedisConnectHandler) RotateEncryptionKey() (string, error) {
+	h.keyMutex.Lock()
+	defer h.keyMutex.Unlock()
+
+	id, err := h.rsaDecryptor.Keyring().Rotate()
+	if err != nil {
+		return "", err
+	}
+	if err := h.rekeyProfiles(); err != nil {
+		// 旧密钥在宽限期内仍然可以派生配置密钥，清理前会再次尝试
+		log.Printf("Failed to re-encrypt connection profiles: %v", err)
+	}
+	return id, nil
+}
+
+// PruneEncryptionKeys 删除被替换超过grace的RSA密钥（用于后台维护）
+// 连接配置仍然使用旧密钥加密且无法重新加密时不删除，避免配置无法解密
+func (h *RedisConnectHandler) PruneEncryptionKeys(grace time.Duration) error {
+	h.keyMutex.Lock()
+	defer h.keyMutex.Unlock()
+
+	keys := h.rsaDecryptor.Keyring()
+	if !keys.Rotatable() {
+		return nil
+	}
+	if err := h.rekeyProfiles(); err != nil {
+		return fmt.Errorf("connection profiles still use key %s: %v", h.profileKeyID, err)
+	}
+	return keys.Prune(grace)
+}
+
+// rekeyProfiles 连接配置不是使用当前RSA密钥加密时重新加密，调用方需持有keyMutex
+func (h *RedisConnectHandler) rekeyProfiles() error {
+	current := h.rsaDecryptor.KeyID()
+	if h.profileKeyID == "" || h.profileKeyID == current {
+		return nil
+	}
+	if err := h.profileStore.Rekey(profileKey(h.rsaDecryptor, current)); err != nil {
+		return err
+	}
+	log.Printf("Re-encrypted connection profiles with key %s (was %s)", current, h.profileKeyID)
+	h.profileKeyID = current
+	return nil
+}
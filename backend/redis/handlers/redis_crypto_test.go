@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/devtoolbox/redis/auth"
+	"github.com/devtoolbox/redis/crypto"
+)
+
+// withKeyring 为处理器配置保存在临时目录中的RSA密钥
+func withKeyring(t *testing.T, h *RedisConnectHandler) {
+	t.Helper()
+	keys, err := crypto.OpenKeyring(filepath.Join(t.TempDir(), "keys.json"), 1024)
+	if err != nil {
+		t.Fatalf("Failed to open keyring: %v", err)
+	}
+	h.rsaDecryptor = crypto.NewRSADecryptorWithKeyring(keys)
+}
+
+func TestHandleRotateKey_RequiresRotationToken(t *testing.T) {
+	h := newTestHandler(t)
+	withKeyring(t, h)
+	admin := connectSession(t, h, "admin", 0, auth.ScopeRead, auth.ScopeWrite, auth.ScopeAdmin)
+	initial := h.rsaDecryptor.KeyID()
+
+	rotate := func(rotationToken, sessionToken string) int {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/crypto/rotate", nil)
+		if rotationToken != "" {
+			req.Header.Set(rotationTokenHeader, rotationToken)
+		}
+		if sessionToken != "" {
+			req.Header.Set("Authorization", "Bearer "+sessionToken)
+		}
+		recorder := httptest.NewRecorder()
+		h.HandleRotateKey(recorder, req)
+		return recorder.Code
+	}
+
+	// 未配置rotationToken时即使是admin会话也不能轮换
+	if code := rotate("", admin); code != http.StatusForbidden {
+		t.Errorf("Expected 403 when rotation is disabled, got %d", code)
+	}
+
+	h.rotationToken = "operator-secret"
+	if code := rotate("", admin); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for admin session without rotation token, got %d", code)
+	}
+	if code := rotate("wrong", ""); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for wrong rotation token, got %d", code)
+	}
+	if h.rsaDecryptor.KeyID() != initial {
+		t.Fatal("Expected key not to be rotated by unauthorized requests")
+	}
+
+	if code := rotate("operator-secret", ""); code != http.StatusOK {
+		t.Errorf("Expected 200 with rotation token, got %d", code)
+	}
+	if h.rsaDecryptor.KeyID() == initial {
+		t.Error("Expected key to be rotated")
+	}
+}
+
+func TestHandleNonce_RateLimitedPerClient(t *testing.T) {
+	h := newTestHandler(t)
+	withKeyring(t, h)
+
+	issue := func(remoteAddr string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/crypto/nonce?count=8", nil)
+		req.RemoteAddr = remoteAddr
+		recorder := httptest.NewRecorder()
+		h.HandleNonce(recorder, req)
+		return recorder
+	}
+
+	for i := 0; i < nonceBurst/maxNoncesPerRequest; i++ {
+		if resp := issue("192.0.2.1:1000"); resp.Code != http.StatusOK {
+			t.Fatalf("Request %d: expected 200, got %d", i, resp.Code)
+		}
+	}
+	// 同一地址的其他端口属于同一个客户端
+	resp := issue("192.0.2.1:2000")
+	if resp.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429 after the burst, got %d", resp.Code)
+	}
+	if resp.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After header")
+	}
+	if resp := issue("192.0.2.2:1000"); resp.Code != http.StatusOK {
+		t.Errorf("Expected other clients not to be limited, got %d", resp.Code)
+	}
+}
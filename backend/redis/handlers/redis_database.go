@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/devtoolbox/redis/pool"
+)
+
+// DatabaseRequest 切换数据库的请求
+type DatabaseRequest struct {
+	Database int `json:"database"`
+}
+
+// DatabaseResponse 当前数据库及各数据库键数量的响应，没有键的数据库不会出现在keyspace中
+// 切换数据库后返回写入新数据库的Token，原Token仍然指向切换后的连接
+type DatabaseResponse struct {
+	Success      bool              `json:"success"`
+	Message      string            `json:"message"`
+	ConnectionID string            `json:"connectionId"`
+	Database     int               `json:"database"`
+	Keyspace     []pool.KeyspaceDB `json:"keyspace"`
+	Token        string            `json:"token,omitempty"`
+	ExpiresAt    int64             `json:"expiresAt,omitempty"`
+}
+
+// HandleDatabase GET返回当前数据库与INFO keyspace统计，POST切换数据库
+// 切换后会话指向目标数据库的连接，共用原连接的其他会话不受影响
+func (h *RedisConnectHandler) HandleDatabase(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "")
+		return
+	}
+
+	commands := []string{"INFO"}
+	if r.Method == http.MethodPost {
+		commands = append(commands, "SELECT")
+	}
+	tokenInfo, conn, err := h.authorize(r, commands...)
+	if err != nil {
+		h.sendAuthError(w, err)
+		return
+	}
+
+	response := DatabaseResponse{
+		Success: true,
+		Message: "Database info retrieved successfully",
+	}
+	if r.Method == http.MethodPost {
+		var req DatabaseRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", err.Error())
+			return
+		}
+		if req.Database < 0 {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid database", "database must not be negative")
+			return
+		}
+		if conn.Mode == pool.ModeCluster && req.Database != 0 {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid database", "cluster mode only supports database 0")
+			return
+		}
+
+		connectionID := h.generateConnectionID(conn.Host, conn.Port, req.Database)
+		next, err := h.connectionPool.SwitchDB(conn.ID, connectionID, req.Database)
+		if err != nil {
+			log.Printf("Failed to switch %s to database %d: %v", conn.ID, req.Database, err)
+			h.sendErrorResponse(w, connectErrorStatus(err), "Failed to switch database", err.Error())
+			return
+		}
+		if next.ID != conn.ID {
+			if tokenInfo, err = h.tokenManager.RebindToken(tokenInfo.Token, next.ID, next.DB); err != nil {
+				h.connectionPool.Release(next.ID)
+				h.sendErrorResponse(w, http.StatusUnauthorized, "Unauthorized", err.Error())
+				return
+			}
+			log.Printf("Switched to database %d: %s -> %s", req.Database, conn.ID, next.ID)
+			response.Token = tokenInfo.Token
+			response.ExpiresAt = tokenInfo.ExpiresAt.Unix()
+		}
+		conn = next
+		response.Message = "Database switched successfully"
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), commandTimeout)
+	defer cancel()
+
+	keyspace, err := h.connectionPool.Keyspace(ctx, conn.ID)
+	if err != nil {
+		h.sendErrorResponse(w, connectErrorStatus(err), "Failed to get keyspace", err.Error())
+		return
+	}
+
+	response.ConnectionID = conn.ID
+	response.Database = conn.DB
+	response.Keyspace = keyspace
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,531 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/devtoolbox/redis/audit"
+	"github.com/devtoolbox/redis/auth"
+	"github.com/devtoolbox/redis/policy"
+	"github.com/devtoolbox/redis/pool"
+	"github.com/devtoolbox/redis/rediserr"
+	"github.com/devtoolbox/redis/undo"
+)
+
+// PolicyRequest 危险操作请求中的确认信息，规则见policy.Rules
+type PolicyRequest struct {
+	DryRun    bool   `json:"dryRun,omitempty"`    // 只预览影响范围，不执行
+	Confirm   string `json:"confirm,omitempty"`   // 规则要求确认时需要输入的文本
+	PreviewID string `json:"previewId,omitempty"` // 规则要求预览时，预览返回的ID
+}
+
+// DeleteKeysRequest 删除键的请求
+type DeleteKeysRequest struct {
+	PolicyRequest
+	Keys []string `json:"keys"`
+}
+
+// RenameKeyRequest 重命名键的请求
+type RenameKeyRequest struct {
+	PolicyRequest
+	Key    string `json:"key"`
+	NewKey string `json:"newKey"`
+}
+
+// SetKeyRequest 写入字符串值的请求，键已存在时覆盖原有的值
+type SetKeyRequest struct {
+	PolicyRequest
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	TTL   int64  `json:"ttl,omitempty"` // 过期秒数，0表示不过期
+}
+
+// ExpireKeyRequest 修改TTL的请求
+type ExpireKeyRequest struct {
+	PolicyRequest
+	Key string `json:"key"`
+	TTL int64  `json:"ttl"` // 过期秒数，-1表示移除TTL
+}
+
+// KeyPreview 预览中单个键的状态
+type KeyPreview struct {
+	Key    string `json:"key"`
+	Exists bool   `json:"exists"`
+	Type   string `json:"type,omitempty"`
+	Size   int64  `json:"size,omitempty"` // 字符串为字节数，其他类型为元素数量
+}
+
+// OperationPreview 危险操作的影响范围
+type OperationPreview struct {
+	Affected int64        `json:"affected"` // 将被删除或覆盖的键数量
+	Keys     []KeyPreview `json:"keys,omitempty"`
+}
+
+// KeyOperationResponse 键操作的响应，预览时Preview与PreviewID非空
+type KeyOperationResponse struct {
+	Success     bool              `json:"success"`
+	Message     string            `json:"message"`
+	Environment string            `json:"environment,omitempty"`
+	Policy      policy.Decision   `json:"policy"`
+	Preview     *OperationPreview `json:"preview,omitempty"`
+	PreviewID   string            `json:"previewId,omitempty"`
+	Affected    int64             `json:"affected"`
+	UndoID      uint64            `json:"undoId,omitempty"`    // 撤销该操作时使用的ID
+	UndoError   string            `json:"undoError,omitempty"` // 无法保存修改前的状态时，操作不能撤销的原因
+}
+
+// PolicyErrorResponse 操作被规则拒绝或需要确认时的响应
+type PolicyErrorResponse struct {
+	Success     bool            `json:"success"`
+	Message     string          `json:"message"`
+	Error       string          `json:"error"`
+	Environment string          `json:"environment,omitempty"`
+	Policy      policy.Decision `json:"policy"`
+}
+
+// HandleKeys 处理 /api/redis/keys/ 下会改变键的操作：
+// POST delete 删除一个或多个键，POST rename 重命名键，POST set 写入字符串值，POST expire 修改TTL
+// 都会按连接的环境标签检查危险命令规则，并在执行前保存键的状态以便撤销
+func (h *RedisConnectHandler) HandleKeys(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	handlers := map[string]http.HandlerFunc{
+		"delete": h.deleteKeys,
+		"rename": h.renameKey,
+		"set":    h.setKey,
+		"expire": h.expireKey,
+	}
+	handler, ok := handlers[strings.TrimPrefix(r.URL.Path, "/api/redis/keys/")]
+	if !ok {
+		h.sendErrorResponse(w, http.StatusNotFound, "Not found", "")
+		return
+	}
+	if r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "")
+		return
+	}
+
+	handler(w, r)
+}
+
+// HandleFlushDB 清空会话当前的数据库，POST请求体为PolicyRequest
+func (h *RedisConnectHandler) HandleFlushDB(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "")
+		return
+	}
+
+	tokenInfo, conn, err := h.authorize(r, "FLUSHDB")
+	if err != nil {
+		h.sendAuthError(w, err)
+		return
+	}
+	if conn.Mode == pool.ModeCluster {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Not supported", "flushing a cluster connection is not supported")
+		return
+	}
+	var req PolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), commandTimeout)
+	defer cancel()
+
+	op := policy.Operation{Target: conn.ID, Command: "FLUSHDB"}
+	if req.DryRun {
+		size, err := conn.Client.DBSize(ctx).Result()
+		if err != nil {
+			h.sendErrorResponse(w, rediserr.HTTPStatus(err), "Failed to preview flush", err.Error())
+			return
+		}
+		h.sendPreview(w, conn, op, &OperationPreview{Affected: size})
+		return
+	}
+
+	decision, ok := h.checkPolicy(w, r, tokenInfo, conn, op, req)
+	if !ok {
+		return
+	}
+	size, _ := conn.Client.DBSize(ctx).Result()
+	err = conn.Client.FlushDB(ctx).Err()
+	entry := audit.Entry{Command: op.Command, Affected: size}
+	entry.Result, entry.Error = auditResult(err)
+	h.recordAudit(r, tokenInfo, conn, entry)
+	if err != nil {
+		h.sendErrorResponse(w, rediserr.HTTPStatus(err), "Failed to flush database", err.Error())
+		return
+	}
+
+	log.Printf("Database flushed: %s (%d keys, environment %q)", conn.ID, size, conn.Environment)
+	h.sendKeyOperation(w, conn, "Database flushed successfully", decision, size, 0, "")
+}
+
+// deleteKeys 删除请求中的键
+func (h *RedisConnectHandler) deleteKeys(w http.ResponseWriter, r *http.Request) {
+	tokenInfo, conn, err := h.authorize(r, "DEL")
+	if err != nil {
+		h.sendAuthError(w, err)
+		return
+	}
+	var req DeleteKeysRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if len(req.Keys) == 0 {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request parameters", "keys are required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), commandTimeout)
+	defer cancel()
+
+	op := policy.Operation{Target: conn.ID, Command: "DEL", Keys: req.Keys}
+	if req.DryRun {
+		preview, err := previewKeys(ctx, conn, req.Keys)
+		if err != nil {
+			h.sendErrorResponse(w, rediserr.HTTPStatus(err), "Failed to preview delete", err.Error())
+			return
+		}
+		h.sendPreview(w, conn, op, preview)
+		return
+	}
+
+	decision, ok := h.checkPolicy(w, r, tokenInfo, conn, op, req.PolicyRequest)
+	if !ok {
+		return
+	}
+	before, err := keyStates(ctx, conn.Client, req.Keys)
+	if err != nil {
+		h.sendErrorResponse(w, rediserr.HTTPStatus(err), "Failed to check keys", err.Error())
+		return
+	}
+	snapshots, captureErr := undo.Capture(ctx, conn.Client, req.Keys...)
+	deleted, err := conn.Client.Del(ctx, req.Keys...).Result()
+	entry := audit.Entry{Command: op.Command, Keys: req.Keys, Before: before, Affected: deleted}
+	entry.Result, entry.Error = auditResult(err)
+	h.recordAudit(r, tokenInfo, conn, entry)
+	if err != nil {
+		h.sendErrorResponse(w, rediserr.HTTPStatus(err), "Failed to delete keys", err.Error())
+		return
+	}
+
+	log.Printf("Keys deleted: %s (%d of %d keys)", conn.ID, deleted, len(req.Keys))
+	undoID, undoErr := h.recordUndo(tokenInfo, conn, op.Command, snapshots, captureErr)
+	h.sendKeyOperation(w, conn, "Keys deleted successfully", decision, deleted, undoID, undoErr)
+}
+
+// DeleteKey 在会话的连接上删除一个键，供 DELETE /api/redis/key/{name} 使用，危险命令规则由调用方检查
+// 与deleteKeys相同，删除前保存快照用于撤销并写入审计日志；键不存在时返回rediserr.Nil
+// undoError为无法保存快照时操作不能撤销的原因
+func (h *RedisConnectHandler) DeleteKey(r *http.Request, key string) (undoID uint64, undoError string, err error) {
+	tokenInfo, conn, err := h.authorize(r, "DEL")
+	if err != nil {
+		return 0, "", err
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), commandTimeout)
+	defer cancel()
+
+	before, err := keyStates(ctx, conn.Client, []string{key})
+	if err != nil {
+		return 0, "", err
+	}
+	if before[0].Type == "none" {
+		return 0, "", fmt.Errorf("key %q does not exist: %w", key, rediserr.Nil)
+	}
+	snapshots, captureErr := undo.Capture(ctx, conn.Client, key)
+	deleted, err := conn.Client.Del(ctx, key).Result()
+	entry := audit.Entry{Command: "DEL", Keys: []string{key}, Before: before, Affected: deleted}
+	entry.Result, entry.Error = auditResult(err)
+	h.recordAudit(r, tokenInfo, conn, entry)
+	if err != nil {
+		return 0, "", err
+	}
+
+	log.Printf("Key deleted: %s (%s)", conn.ID, key)
+	undoID, undoError = h.recordUndo(tokenInfo, conn, "DEL", snapshots, captureErr)
+	return undoID, undoError, nil
+}
+
+// renameKey 重命名键，目标键已存在时会被覆盖
+func (h *RedisConnectHandler) renameKey(w http.ResponseWriter, r *http.Request) {
+	tokenInfo, conn, err := h.authorize(r, "RENAME")
+	if err != nil {
+		h.sendAuthError(w, err)
+		return
+	}
+	var req RenameKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if req.Key == "" || req.NewKey == "" {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request parameters", "key and newKey are required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), commandTimeout)
+	defer cancel()
+
+	preview, err := previewKeys(ctx, conn, []string{req.Key, req.NewKey})
+	if err != nil {
+		h.sendErrorResponse(w, rediserr.HTTPStatus(err), "Failed to check keys", err.Error())
+		return
+	}
+	if !preview.Keys[0].Exists {
+		h.sendErrorResponse(w, http.StatusNotFound, "Key not found", req.Key)
+		return
+	}
+	overwrite := preview.Keys[1].Exists
+	preview.Affected = 0
+	if overwrite {
+		preview.Affected = 1
+	}
+
+	op := policy.Operation{Target: conn.ID, Command: "RENAME", Keys: []string{req.Key, req.NewKey}, Overwrite: overwrite}
+	if req.DryRun {
+		h.sendPreview(w, conn, op, preview)
+		return
+	}
+
+	decision, ok := h.checkPolicy(w, r, tokenInfo, conn, op, req.PolicyRequest)
+	if !ok {
+		return
+	}
+	snapshots, captureErr := undo.Capture(ctx, conn.Client, req.Key, req.NewKey)
+	err = conn.Client.Rename(ctx, req.Key, req.NewKey).Err()
+	entry := audit.Entry{Command: op.Command, Keys: op.Keys, Before: preview.states(), Affected: preview.Affected}
+	entry.Result, entry.Error = auditResult(err)
+	h.recordAudit(r, tokenInfo, conn, entry)
+	if err != nil {
+		h.sendErrorResponse(w, rediserr.HTTPStatus(err), "Failed to rename key", err.Error())
+		return
+	}
+
+	log.Printf("Key renamed: %s (overwrite %t)", conn.ID, overwrite)
+	undoID, undoErr := h.recordUndo(tokenInfo, conn, op.Command, snapshots, captureErr)
+	h.sendKeyOperation(w, conn, "Key renamed successfully", decision, preview.Affected, undoID, undoErr)
+}
+
+// setKey 写入字符串值，键已存在时覆盖原有的值与TTL
+func (h *RedisConnectHandler) setKey(w http.ResponseWriter, r *http.Request) {
+	tokenInfo, conn, err := h.authorize(r, "SET")
+	if err != nil {
+		h.sendAuthError(w, err)
+		return
+	}
+	var req SetKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if req.Key == "" || req.TTL < 0 {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request parameters", "key is required and ttl must not be negative")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), commandTimeout)
+	defer cancel()
+
+	preview, err := previewKeys(ctx, conn, []string{req.Key})
+	if err != nil {
+		h.sendErrorResponse(w, rediserr.HTTPStatus(err), "Failed to check keys", err.Error())
+		return
+	}
+	op := policy.Operation{Target: conn.ID, Command: "SET", Keys: []string{req.Key}}
+	if req.DryRun {
+		h.sendPreview(w, conn, op, preview)
+		return
+	}
+
+	decision, ok := h.checkPolicy(w, r, tokenInfo, conn, op, req.PolicyRequest)
+	if !ok {
+		return
+	}
+	snapshots, captureErr := undo.Capture(ctx, conn.Client, req.Key)
+	err = conn.Client.Set(ctx, req.Key, req.Value, time.Duration(req.TTL)*time.Second).Err()
+	entry := audit.Entry{Command: op.Command, Keys: op.Keys, Before: preview.states(), Affected: 1}
+	entry.Result, entry.Error = auditResult(err)
+	h.recordAudit(r, tokenInfo, conn, entry)
+	if err != nil {
+		h.sendErrorResponse(w, rediserr.HTTPStatus(err), "Failed to set key", err.Error())
+		return
+	}
+
+	log.Printf("Key set: %s (overwrite %t)", conn.ID, preview.Affected > 0)
+	undoID, undoErr := h.recordUndo(tokenInfo, conn, op.Command, snapshots, captureErr)
+	h.sendKeyOperation(w, conn, "Key set successfully", decision, 1, undoID, undoErr)
+}
+
+// expireKey 设置键的TTL，ttl为-1时移除TTL
+func (h *RedisConnectHandler) expireKey(w http.ResponseWriter, r *http.Request) {
+	tokenInfo, conn, err := h.authorize(r, "EXPIRE", "PERSIST")
+	if err != nil {
+		h.sendAuthError(w, err)
+		return
+	}
+	var req ExpireKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	if req.Key == "" || (req.TTL <= 0 && req.TTL != -1) {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request parameters", "key is required and ttl must be positive or -1")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), commandTimeout)
+	defer cancel()
+
+	preview, err := previewKeys(ctx, conn, []string{req.Key})
+	if err != nil {
+		h.sendErrorResponse(w, rediserr.HTTPStatus(err), "Failed to check keys", err.Error())
+		return
+	}
+	if !preview.Keys[0].Exists {
+		h.sendErrorResponse(w, http.StatusNotFound, "Key not found", req.Key)
+		return
+	}
+	op := policy.Operation{Target: conn.ID, Command: "EXPIRE", Keys: []string{req.Key}}
+	if req.TTL == -1 {
+		op.Command = "PERSIST"
+	}
+	if req.DryRun {
+		h.sendPreview(w, conn, op, preview)
+		return
+	}
+
+	decision, ok := h.checkPolicy(w, r, tokenInfo, conn, op, req.PolicyRequest)
+	if !ok {
+		return
+	}
+	snapshots, captureErr := undo.Capture(ctx, conn.Client, req.Key)
+	var changed bool
+	if req.TTL == -1 {
+		changed, err = conn.Client.Persist(ctx, req.Key).Result()
+	} else {
+		changed, err = conn.Client.Expire(ctx, req.Key, time.Duration(req.TTL)*time.Second).Result()
+	}
+	var affected int64
+	if changed {
+		affected = 1
+	}
+	entry := audit.Entry{Command: op.Command, Keys: op.Keys, Before: preview.states(), Affected: affected}
+	entry.Result, entry.Error = auditResult(err)
+	h.recordAudit(r, tokenInfo, conn, entry)
+	if err != nil {
+		h.sendErrorResponse(w, rediserr.HTTPStatus(err), "Failed to change TTL", err.Error())
+		return
+	}
+
+	log.Printf("Key TTL changed: %s (%s)", conn.ID, op.Command)
+	var undoID uint64
+	var undoErr string
+	if changed {
+		undoID, undoErr = h.recordUndo(tokenInfo, conn, op.Command, snapshots, captureErr)
+	}
+	h.sendKeyOperation(w, conn, "Key TTL changed successfully", decision, affected, undoID, undoErr)
+}
+
+// previewKeys 查询键是否存在及其类型与大小，Affected为已存在的键数量
+func previewKeys(ctx context.Context, conn *pool.RedisConnection, keys []string) (*OperationPreview, error) {
+	states, err := keyStates(ctx, conn.Client, keys)
+	if err != nil {
+		return nil, err
+	}
+	preview := &OperationPreview{Keys: make([]KeyPreview, 0, len(keys))}
+	for _, state := range states {
+		key := KeyPreview{Key: state.Key}
+		if state.Type != "none" {
+			key.Exists, key.Type, key.Size = true, state.Type, state.Size
+			preview.Affected++
+		}
+		preview.Keys = append(preview.Keys, key)
+	}
+	return preview, nil
+}
+
+// states 将预览转换为审计记录中的键状态
+func (p *OperationPreview) states() []audit.KeyState {
+	states := make([]audit.KeyState, 0, len(p.Keys))
+	for _, key := range p.Keys {
+		state := audit.KeyState{Key: key.Key, Type: "none"}
+		if key.Exists {
+			state.Type, state.Size = key.Type, key.Size
+		}
+		states = append(states, state)
+	}
+	return states
+}
+
+// checkPolicy 按连接的环境标签检查操作，不允许执行时记录审计、发送错误响应并返回false
+func (h *RedisConnectHandler) checkPolicy(w http.ResponseWriter, r *http.Request, tokenInfo *auth.TokenInfo, conn *pool.RedisConnection, op policy.Operation, req PolicyRequest) (policy.Decision, bool) {
+	decision, err := h.commandPolicy.Check(conn.Environment, op, policy.Approval{Confirm: req.Confirm, PreviewID: req.PreviewID})
+	if err == nil {
+		return decision, true
+	}
+	h.recordAudit(r, tokenInfo, conn, audit.Entry{Command: op.Command, Keys: op.Keys, Result: audit.ResultRejected, Error: err.Error()})
+
+	status := http.StatusPreconditionRequired
+	if errors.Is(err, policy.ErrBlocked) {
+		status = http.StatusForbidden
+	}
+	log.Printf("%s on %s rejected by policy: %v", op.Command, conn.ID, err)
+	response := PolicyErrorResponse{
+		Success:     false,
+		Message:     "Operation not allowed by policy",
+		Error:       err.Error(),
+		Environment: conn.Environment,
+		Policy:      decision,
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+	return decision, false
+}
+
+// sendPreview 记录预览并返回影响范围，操作被禁止时不返回预览ID
+func (h *RedisConnectHandler) sendPreview(w http.ResponseWriter, conn *pool.RedisConnection, op policy.Operation, preview *OperationPreview) {
+	decision, previewID, err := h.commandPolicy.Preview(conn.Environment, op)
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to preview operation", err.Error())
+		return
+	}
+
+	response := KeyOperationResponse{
+		Success:     true,
+		Message:     "Preview generated successfully",
+		Environment: conn.Environment,
+		Policy:      decision,
+		Preview:     preview,
+		PreviewID:   previewID,
+		Affected:    preview.Affected,
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// sendKeyOperation 发送操作成功的响应，undoID为0时操作没有写入撤销日志
+func (h *RedisConnectHandler) sendKeyOperation(w http.ResponseWriter, conn *pool.RedisConnection, message string, decision policy.Decision, affected int64, undoID uint64, undoErr string) {
+	response := KeyOperationResponse{
+		Success:     true,
+		Message:     message,
+		Environment: conn.Environment,
+		Policy:      decision,
+		Affected:    affected,
+		UndoID:      undoID,
+		UndoError:   undoErr,
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/devtoolbox/redis/auth"
+	"github.com/devtoolbox/redis/rediserr"
+	"github.com/devtoolbox/redis/undo"
+)
+
+func TestDeleteKey_CanBeUndone(t *testing.T) {
+	h := newTestHandler(t)
+	token := connectSession(t, h, "conn", 0, auth.ScopeRead, auth.ScopeWrite)
+	conn, err := h.connectionPool.GetConnection("conn")
+	if err != nil {
+		t.Fatalf("Failed to get connection: %v", err)
+	}
+	ctx := context.Background()
+	conn.Client.Set(ctx, "user:1", "alice", 0)
+
+	request := func() *http.Request {
+		req := httptest.NewRequest(http.MethodDelete, "/api/redis/key/user:1", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		return req
+	}
+
+	undoID, undoErr, err := h.DeleteKey(request(), "user:1")
+	if err != nil || undoErr != "" || undoID == 0 {
+		t.Fatalf("Expected delete with an undo ID, got %d, %q, %v", undoID, undoErr, err)
+	}
+	if n := conn.Client.Exists(ctx, "user:1").Val(); n != 0 {
+		t.Fatal("Expected key to be deleted")
+	}
+	if _, _, err := h.DeleteKey(request(), "user:1"); !errors.Is(err, rediserr.Nil) {
+		t.Errorf("Expected Nil for a missing key, got %v", err)
+	}
+
+	change, err := h.undoJournal.Get("conn", undoID)
+	if err != nil {
+		t.Fatalf("Expected change in the undo journal: %v", err)
+	}
+	if err := undo.Apply(ctx, conn.Client, change.Snapshots); err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	if val := conn.Client.Get(ctx, "user:1").Val(); val != "alice" {
+		t.Errorf("Expected restored value alice, got %q", val)
+	}
+}
@@ -0,0 +1,394 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/devtoolbox/redis/auth"
+	"github.com/devtoolbox/redis/config"
+	"github.com/devtoolbox/redis/crypto"
+	"github.com/devtoolbox/redis/pool"
+	"github.com/devtoolbox/redis/profile"
+)
+
+// profileKeyInfo 从RSA私钥派生配置加密密钥时使用的info
+const profileKeyInfo = "devtoolbox redis connection profiles"
+
+// ProfileRequest 创建或更新连接配置的请求，密码与客户端私钥使用RSA加密
+// 更新时不提供加密密码或私钥则保留原值，clearPassword为true时删除已保存的密码；
+// 主机、端口或TLS参数改变时不保留原值，需要重新提供，避免把保存的凭据发送到其他服务器
+// 修改已有配置的environment需要admin权限
+type ProfileRequest struct {
+	Name              string      `json:"name"`
+	Host              string      `json:"host"`
+	Port              int         `json:"port"`
+	Database          int         `json:"database"`
+	Username          string      `json:"username,omitempty"`
+	EncryptedPassword string      `json:"encryptedPassword,omitempty"`
+	ClearPassword     bool        `json:"clearPassword,omitempty"`
+	TLS               *TLSRequest `json:"tls,omitempty"`
+	Environment       string      `json:"environment,omitempty"`
+}
+
+// ProfileConnectRequest 使用保存的配置连接时的可选请求体
+type ProfileConnectRequest struct {
+	Scopes []string `json:"scopes,omitempty"` // 会话的权限，默认为read和write，超过security.maxScope的部分不会授予
+}
+
+// ProfileResponse 单个连接配置的响应
+type ProfileResponse struct {
+	Success bool             `json:"success"`
+	Message string           `json:"message"`
+	Profile *profile.Profile `json:"profile,omitempty"`
+}
+
+// ProfileListResponse 连接配置列表的响应
+type ProfileListResponse struct {
+	Success  bool              `json:"success"`
+	Message  string            `json:"message"`
+	Profiles []profile.Profile `json:"profiles"`
+}
+
+// openProfileStore 打开连接配置文件，返回配置加密密钥所用RSA密钥的ID
+// 设置了REDIS_PROFILE_PASSPHRASE时使用主口令派生密钥，返回的ID为空；否则从后端的RSA私钥派生，
+// 当前密钥无法打开时依次尝试仍在宽限期内的旧密钥，成功后改用当前密钥重新加密
+func openProfileStore(rsaDecryptor *crypto.RSADecryptor) (*profile.Store, string, error) {
+	path, err := dataFile(config.GetRedisBackendConfig().ProfileFile, "redis-profiles.json")
+	if err != nil {
+		return nil, "", err
+	}
+	log.Printf("Using connection profile store: %s", path)
+
+	if passphrase := os.Getenv("REDIS_PROFILE_PASSPHRASE"); passphrase != "" {
+		store, err := profile.Open(path, profile.PassphraseKey(passphrase))
+		return store, "", err
+	}
+
+	current := rsaDecryptor.KeyID()
+	store, err := profile.Open(path, profileKey(rsaDecryptor, current))
+	if !errors.Is(err, profile.ErrKeyMismatch) {
+		return store, current, err
+	}
+	for _, key := range rsaDecryptor.Keyring().PublicKeys()[1:] {
+		previous, openErr := profile.Open(path, profileKey(rsaDecryptor, key.ID))
+		if openErr != nil {
+			continue
+		}
+		if err := previous.Rekey(profileKey(rsaDecryptor, current)); err != nil {
+			log.Printf("Failed to re-encrypt connection profiles with key %s: %v", current, err)
+			return previous, key.ID, nil
+		}
+		log.Printf("Re-encrypted connection profiles with key %s (was %s)", current, key.ID)
+		return previous, current, nil
+	}
+	return nil, "", err
+}
+
+// profileKey 从指定ID的RSA私钥派生配置加密密钥
+func profileKey(rsaDecryptor *crypto.RSADecryptor, keyID string) profile.KeyFunc {
+	return func(salt []byte) ([]byte, error) {
+		return rsaDecryptor.DeriveKeyWith(keyID, salt, profileKeyInfo, profile.KeySize)
+	}
+}
+
+// HandleProfiles 列出（GET）或创建（POST）连接配置
+func (h *RedisConnectHandler) HandleProfiles(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		response := ProfileListResponse{
+			Success:  true,
+			Message:  "Profiles retrieved successfully",
+			Profiles: h.profileStore.List(),
+		}
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+	case http.MethodPost:
+		var req ProfileRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", err.Error())
+			return
+		}
+		p, err := h.profileFromRequest(&req, nil)
+		if err != nil {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid profile", err.Error())
+			return
+		}
+		created, err := h.profileStore.Create(p)
+		if err != nil {
+			h.sendErrorResponse(w, profileErrorStatus(err), "Failed to save profile", err.Error())
+			return
+		}
+		h.sendProfile(w, http.StatusCreated, "Profile created successfully", &created)
+	default:
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "")
+	}
+}
+
+// HandleProfile 处理 /api/redis/profiles/{id} 的查询、更新和删除，
+// 以及 POST /api/redis/profiles/{id}/connect 使用保存的配置建立连接
+func (h *RedisConnectHandler) HandleProfile(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	id, action, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/redis/profiles/"), "/")
+	if id == "" || (action != "" && action != "connect") {
+		h.sendErrorResponse(w, http.StatusNotFound, "Not found", "")
+		return
+	}
+
+	if action == "connect" {
+		if r.Method != http.MethodPost {
+			h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "")
+			return
+		}
+		h.connectProfile(w, r, id)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		p, err := h.profileStore.Get(id)
+		if err != nil {
+			h.sendErrorResponse(w, profileErrorStatus(err), "Failed to get profile", err.Error())
+			return
+		}
+		h.sendProfile(w, http.StatusOK, "Profile retrieved successfully", &p)
+	case http.MethodPut:
+		tokenInfo, err := h.tokenFromRequest(r)
+		if err != nil {
+			h.sendAuthError(w, err)
+			return
+		}
+		var req ProfileRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", err.Error())
+			return
+		}
+		previous, err := h.profileStore.Get(id)
+		if err != nil {
+			h.sendErrorResponse(w, profileErrorStatus(err), "Failed to get profile", err.Error())
+			return
+		}
+		// 环境标签决定危险命令规则，改为其他环境相当于关闭prod等环境的保护
+		if req.Environment != previous.Environment && !tokenInfo.HasScope(auth.ScopeAdmin) {
+			h.sendAuthError(w, fmt.Errorf("%w: changing the environment of a profile requires the %s scope", auth.ErrForbidden, auth.ScopeAdmin))
+			return
+		}
+		p, err := h.profileFromRequest(&req, &previous)
+		if err != nil {
+			h.sendErrorResponse(w, http.StatusBadRequest, "Invalid profile", err.Error())
+			return
+		}
+		updated, err := h.profileStore.Update(id, p)
+		if err != nil {
+			h.sendErrorResponse(w, profileErrorStatus(err), "Failed to save profile", err.Error())
+			return
+		}
+		h.sendProfile(w, http.StatusOK, "Profile updated successfully", &updated)
+	case http.MethodDelete:
+		if err := h.profileStore.Delete(id); err != nil {
+			h.sendErrorResponse(w, profileErrorStatus(err), "Failed to delete profile", err.Error())
+			return
+		}
+		h.sendProfile(w, http.StatusOK, "Profile deleted successfully", nil)
+	default:
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "")
+	}
+}
+
+// connectProfile 使用保存的配置建立连接并返回Token
+// 保存的凭据不需要调用方提供，因此要求有效的Token，授予的权限不超过调用方会话的权限（admin除外）
+// 请求体可以为空，此时使用默认权限
+func (h *RedisConnectHandler) connectProfile(w http.ResponseWriter, r *http.Request, id string) {
+	tokenInfo, err := h.tokenFromRequest(r)
+	if err != nil {
+		h.sendAuthError(w, err)
+		return
+	}
+
+	var req ProfileConnectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	scopes, err := h.grantScopes(r, req.Scopes)
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request parameters", err.Error())
+		return
+	}
+	scopes = auth.ClampScopes(scopes, tokenInfo.MaxScope())
+
+	p, err := h.profileStore.Get(id)
+	if err != nil {
+		h.sendErrorResponse(w, profileErrorStatus(err), "Failed to get profile", err.Error())
+		return
+	}
+
+	connectionID := h.generateConnectionID(p.Host, p.Port, p.DB)
+	conn, err := h.connectionPool.CreateConnectionWithOptions(connectionID, p.Host, p.Port, profileConnectOptions(&p, p.DB))
+	if err != nil {
+		log.Printf("Failed to create Redis connection from profile %s: %v", p.Name, err)
+		h.sendErrorResponse(w, connectErrorStatus(err), "Failed to connect to Redis", err.Error())
+		return
+	}
+	if !h.sendConnected(w, auth.Session{ConnectionID: conn.ID, ProfileID: p.ID, DB: conn.DB, Scopes: scopes}) {
+		return
+	}
+
+	log.Printf("Redis connection ready from profile %s: %s (%s:%d/%d)", p.Name, conn.ID, p.Host, p.Port, p.DB)
+}
+
+// restoreConnection 会话的连接不存在时（例如后端重启后）根据Token中的配置ID重新连接
+// 直接连接的会话没有保存凭据，只能重新连接后获取新的Token
+func (h *RedisConnectHandler) restoreConnection(tokenInfo *auth.TokenInfo) (*pool.RedisConnection, error) {
+	if tokenInfo.ProfileID == "" {
+		return nil, fmt.Errorf("connection %s is no longer available, please reconnect", tokenInfo.ConnectionID)
+	}
+	p, err := h.profileStore.Get(tokenInfo.ProfileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore connection: %w", err)
+	}
+
+	conn, err := h.connectionPool.CreateConnectionWithOptions(tokenInfo.ConnectionID, p.Host, p.Port, profileConnectOptions(&p, tokenInfo.DB))
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore connection: %w", err)
+	}
+	previous, err := h.tokenManager.Attach(tokenInfo, conn.ID)
+	if err != nil {
+		h.connectionPool.Release(conn.ID)
+		return nil, err
+	}
+	if previous != "" {
+		// 并发请求已经恢复过同一个会话，或者会话原来的连接已被关闭
+		h.connectionPool.Release(previous)
+	}
+
+	log.Printf("Redis connection restored from profile %s: %s", p.Name, conn.ID)
+	return conn, nil
+}
+
+// profileConnectOptions 使用保存的配置连接指定的数据库
+func profileConnectOptions(p *profile.Profile, db int) pool.ConnectOptions {
+	return pool.ConnectOptions{
+		DB:          db,
+		Username:    p.Username,
+		Password:    p.Password,
+		Profile:     p.Name,
+		Environment: p.Environment,
+		TLS:         profileTLSOptions(p.TLS),
+	}
+}
+
+// profileFromRequest 解密请求中的凭据并组装配置，previous非nil时未提供的凭据沿用原值
+func (h *RedisConnectHandler) profileFromRequest(req *ProfileRequest, previous *profile.Profile) (profile.Profile, error) {
+	p := profile.Profile{
+		Name:        strings.TrimSpace(req.Name),
+		Host:        req.Host,
+		Port:        req.Port,
+		DB:          req.Database,
+		Username:    req.Username,
+		Environment: req.Environment,
+	}
+	if err := p.Validate(); err != nil {
+		return p, err
+	}
+	if req.TLS != nil {
+		p.TLS = &profile.TLS{
+			ServerName:         req.TLS.ServerName,
+			CACert:             req.TLS.CACert,
+			ClientCert:         req.TLS.ClientCert,
+			InsecureSkipVerify: req.TLS.InsecureSkipVerify,
+		}
+	}
+	// 连接目标改变时不保留原来的密码与客户端私钥
+	if previous != nil && !sameEndpoint(previous, &p) {
+		previous = nil
+	}
+
+	switch {
+	case req.EncryptedPassword != "":
+		password, err := h.rsaDecryptor.DecryptPassword(req.EncryptedPassword)
+		if err != nil {
+			return p, fmt.Errorf("failed to decrypt password: %w", err)
+		}
+		p.Password = password
+	case previous != nil && !req.ClearPassword:
+		p.Password = previous.Password
+	}
+
+	if req.TLS == nil {
+		return p, nil
+	}
+	switch {
+	case req.TLS.EncryptedClientKey != "":
+		key, err := h.rsaDecryptor.DecryptChunks(req.TLS.EncryptedClientKey)
+		if err != nil {
+			return p, fmt.Errorf("failed to decrypt client key: %w", err)
+		}
+		p.TLS.ClientKey = key
+	case previous != nil && req.TLS.ClientCert != "":
+		p.TLS.ClientKey = previous.TLS.ClientKey
+	}
+	if _, err := profileTLSOptions(p.TLS).Config(p.Host); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+// sameEndpoint 检查两个配置是否连接同一个服务器：主机、端口与TLS参数（不包括客户端私钥）都相同
+func sameEndpoint(a, b *profile.Profile) bool {
+	if a.Host != b.Host || a.Port != b.Port || (a.TLS == nil) != (b.TLS == nil) {
+		return false
+	}
+	if a.TLS == nil {
+		return true
+	}
+	return a.TLS.ServerName == b.TLS.ServerName &&
+		a.TLS.CACert == b.TLS.CACert &&
+		a.TLS.ClientCert == b.TLS.ClientCert &&
+		a.TLS.InsecureSkipVerify == b.TLS.InsecureSkipVerify
+}
+
+// profileTLSOptions 将保存的TLS参数转换为连接参数
+func profileTLSOptions(t *profile.TLS) *pool.TLSOptions {
+	if t == nil {
+		return nil
+	}
+	return &pool.TLSOptions{
+		ServerName:         t.ServerName,
+		CACert:             t.CACert,
+		ClientCert:         t.ClientCert,
+		ClientKey:          t.ClientKey,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+}
+
+// profileErrorStatus 将配置存储的错误映射为HTTP状态码
+func profileErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, profile.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, profile.ErrDuplicateName):
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// sendProfile 发送单个配置的响应，删除后p为nil
+func (h *RedisConnectHandler) sendProfile(w http.ResponseWriter, statusCode int, message string, p *profile.Profile) {
+	response := ProfileResponse{
+		Success: true,
+		Message: message,
+		Profile: p,
+	}
+
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(response)
+}
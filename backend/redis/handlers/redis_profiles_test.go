@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/devtoolbox/redis/auth"
+	"github.com/devtoolbox/redis/profile"
+)
+
+// withProfileStore 为处理器配置保存在临时目录中的连接配置
+func withProfileStore(t *testing.T, h *RedisConnectHandler) *profile.Store {
+	t.Helper()
+	store, err := profile.Open(filepath.Join(t.TempDir(), "profiles.json"), profile.PassphraseKey("test"))
+	if err != nil {
+		t.Fatalf("Failed to open profile store: %v", err)
+	}
+	h.profileStore = store
+	return store
+}
+
+func TestHandleProfile_UpdateProtectsSecrets(t *testing.T) {
+	h := newTestHandler(t)
+	store := withProfileStore(t, h)
+	prod, err := store.Create(profile.Profile{Name: "prod", Host: "redis.internal", Port: 6379, Environment: "prod", Password: "secret"})
+	if err != nil {
+		t.Fatalf("Failed to create profile: %v", err)
+	}
+	write := connectSession(t, h, "write", 0, auth.ScopeRead, auth.ScopeWrite)
+	admin := connectSession(t, h, "admin", 1, auth.ScopeRead, auth.ScopeWrite, auth.ScopeAdmin)
+
+	update := func(token string, req ProfileRequest) int {
+		t.Helper()
+		body, _ := json.Marshal(req)
+		httpReq := httptest.NewRequest(http.MethodPut, "/api/redis/profiles/"+prod.ID, bytes.NewReader(body))
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		h.HandleProfile(recorder, httpReq)
+		return recorder.Code
+	}
+	password := func() string {
+		t.Helper()
+		p, err := store.Get(prod.ID)
+		if err != nil {
+			t.Fatalf("Failed to get profile: %v", err)
+		}
+		return p.Password
+	}
+
+	// 只修改名称时保留密码
+	if code := update(write, ProfileRequest{Name: "prod-main", Host: "redis.internal", Port: 6379, Environment: "prod"}); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if password() != "secret" {
+		t.Error("Expected password to be kept when the endpoint is unchanged")
+	}
+
+	// 写权限不能修改环境标签
+	if code := update(write, ProfileRequest{Name: "prod-main", Host: "redis.internal", Port: 6379, Environment: "dev"}); code != http.StatusForbidden {
+		t.Errorf("Expected 403 when a write session relabels the profile, got %d", code)
+	}
+	if code := update(admin, ProfileRequest{Name: "prod-main", Host: "redis.internal", Port: 6379, Environment: "staging"}); code != http.StatusOK {
+		t.Errorf("Expected admin to relabel the profile, got %d", code)
+	}
+
+	// 修改主机后不再使用保存的密码
+	if code := update(write, ProfileRequest{Name: "prod-main", Host: "attacker.example", Port: 6379, Environment: "staging"}); code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if password() != "" {
+		t.Error("Expected password to be cleared when the host changes")
+	}
+}
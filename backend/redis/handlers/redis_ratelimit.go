@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxLimiterClients 记录的客户端数量达到该值时清理已经恢复满额的客户端
+const maxLimiterClients = 4096
+
+// clientLimiter 按客户端地址的令牌桶限流：每个客户端最多连续消耗burst个令牌，之后每interval恢复一个
+type clientLimiter struct {
+	mutex    sync.Mutex
+	burst    int
+	interval time.Duration
+	clients  map[string]*clientBucket
+}
+
+// clientBucket 一个客户端的令牌桶
+type clientBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// newClientLimiter 创建限流器
+func newClientLimiter(burst int, interval time.Duration) *clientLimiter {
+	return &clientLimiter{burst: burst, interval: interval, clients: make(map[string]*clientBucket)}
+}
+
+// allow 为客户端消耗n个令牌，令牌不足时不消耗并返回需要等待的时间
+func (l *clientLimiter) allow(client string, n int, now time.Time) (bool, time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	bucket, ok := l.clients[client]
+	if !ok {
+		if len(l.clients) >= maxLimiterClients {
+			l.sweep(now)
+		}
+		bucket = &clientBucket{tokens: float64(l.burst), updated: now}
+		l.clients[client] = bucket
+	}
+	bucket.tokens = l.refill(bucket, now)
+	bucket.updated = now
+
+	if bucket.tokens < float64(n) {
+		return false, time.Duration((float64(n) - bucket.tokens) * float64(l.interval))
+	}
+	bucket.tokens -= float64(n)
+	return true, 0
+}
+
+// refill 返回令牌桶到now时的令牌数
+func (l *clientLimiter) refill(bucket *clientBucket, now time.Time) float64 {
+	tokens := bucket.tokens + float64(now.Sub(bucket.updated))/float64(l.interval)
+	if tokens > float64(l.burst) {
+		tokens = float64(l.burst)
+	}
+	return tokens
+}
+
+// sweep 删除已经恢复满额的客户端，与重新创建的令牌桶没有区别，调用方需持有锁
+func (l *clientLimiter) sweep(now time.Time) {
+	for client, bucket := range l.clients {
+		if l.refill(bucket, now) >= float64(l.burst) {
+			delete(l.clients, client)
+		}
+	}
+}
+
+// clientAddress 返回请求来源的IP地址，用作限流的客户端标识
+func clientAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestClientLimiter(t *testing.T) {
+	limiter := newClientLimiter(4, time.Second)
+	now := time.Now()
+
+	if ok, _ := limiter.allow("a", 4, now); !ok {
+		t.Fatal("Expected burst to be allowed")
+	}
+	ok, wait := limiter.allow("a", 2, now)
+	if ok || wait != 2*time.Second {
+		t.Errorf("Expected to wait 2s, got %t, %v", ok, wait)
+	}
+	if ok, _ := limiter.allow("a", 2, now.Add(2*time.Second)); !ok {
+		t.Error("Expected tokens to be refilled")
+	}
+	if ok, _ := limiter.allow("b", 4, now); !ok {
+		t.Error("Expected clients to be limited independently")
+	}
+
+	// 恢复满额的客户端在记录过多时被清理
+	for i := len(limiter.clients); i < maxLimiterClients; i++ {
+		limiter.allow(fmt.Sprintf("client-%d", i), 1, now)
+	}
+	limiter.allow("new", 1, now.Add(time.Minute))
+	if len(limiter.clients) != 1 {
+		t.Errorf("Expected idle clients to be swept, got %d", len(limiter.clients))
+	}
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/devtoolbox/redis/auth"
+	"github.com/devtoolbox/redis/pool"
+)
+
+// RequireScope 所有/api/redis/*接口的鉴权中间件：校验Token，并按command.Classify检查会话的权限
+// 是否允许执行接口将要执行的命令（见routeCommands）
+// 直接建立连接的接口需要提供凭据，不要求Token，但携带的Token必须有效，admin权限的Token可以签发更高的权限
+func (h *RedisConnectHandler) RequireScope(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		commands, public := routeCommands(r)
+		if public && r.Header.Get("Authorization") == "" {
+			next(w, r)
+			return
+		}
+
+		tokenInfo, err := h.tokenFromRequest(r)
+		if err == nil {
+			err = tokenInfo.Allows(commands...)
+		}
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			h.sendAuthError(w, err)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// routeCommands 返回请求将要执行的Redis命令，public为true时不要求Token
+// 不直接执行Redis命令的接口使用影响相当的命令表示；未列出的接口按CONFIG处理，需要admin权限
+func routeCommands(r *http.Request) (commands []string, public bool) {
+	path := r.URL.Path
+	switch {
+	case path == "/api/redis/connect":
+		return nil, true
+	case strings.HasPrefix(path, "/api/redis/profiles/") && strings.HasSuffix(path, "/connect"):
+		// 使用保存的凭据连接，授予的权限不超过会话自己的权限，由处理函数检查
+		return []string{"PING"}, false
+	case path == "/api/redis/connections", strings.HasPrefix(path, "/api/redis/connections/"),
+		strings.HasPrefix(path, "/api/redis/token/"):
+		// 只能管理会话自己的连接，由处理函数检查
+		return []string{"PING"}, false
+	case path == "/api/redis/profiles", strings.HasPrefix(path, "/api/redis/profiles/"):
+		// 保存的配置对所有会话可见，修改按写入处理
+		if r.Method == http.MethodGet {
+			return []string{"PING"}, false
+		}
+		return []string{"SET"}, false
+	case path == "/api/redis/cluster":
+		return []string{"CLUSTER SLOTS", "CLUSTER NODES"}, false
+	case path == "/api/redis/scan":
+		return []string{"SCAN"}, false
+	case path == "/api/redis/db":
+		if r.Method == http.MethodPost {
+			return []string{"INFO", "SELECT"}, false
+		}
+		return []string{"INFO"}, false
+	case strings.HasPrefix(path, "/api/redis/keys/"):
+		switch strings.TrimPrefix(path, "/api/redis/keys/") {
+		case "delete":
+			return []string{"DEL"}, false
+		case "rename":
+			return []string{"RENAME"}, false
+		case "set":
+			return []string{"SET"}, false
+		case "expire":
+			return []string{"EXPIRE", "PERSIST"}, false
+		}
+	case path == "/api/redis/flushdb":
+		return []string{"FLUSHDB"}, false
+	case path == "/api/redis/undo":
+		return []string{"DUMP"}, false
+	case strings.HasPrefix(path, "/api/redis/undo/"):
+		return []string{"RESTORE", "DEL"}, false
+	case strings.HasPrefix(path, "/api/redis/key/"):
+		switch r.Method {
+		case http.MethodGet:
+			return []string{"GET"}, false
+		case http.MethodDelete:
+			return []string{"DEL"}, false
+		}
+	case strings.HasPrefix(path, "/api/redis/geo/"):
+		return []string{"GEOPOS"}, false
+	case path == "/api/audit":
+		// 审计日志包含所有连接的操作
+		return []string{"CONFIG GET"}, false
+	}
+	return []string{"CONFIG"}, false
+}
+
+// grantScopes 解析连接时请求的权限，去掉超过security.maxScope（默认write）的部分
+// 请求携带admin权限的有效Token时不受限制
+func (h *RedisConnectHandler) grantScopes(r *http.Request, requested []string) ([]string, error) {
+	scopes, err := auth.ParseScopes(requested)
+	if err != nil {
+		return nil, err
+	}
+
+	max := h.maxScope
+	if max == "" {
+		max = auth.ScopeWrite
+	}
+	if r.Header.Get("Authorization") != "" {
+		if tokenInfo, err := h.tokenFromRequest(r); err == nil && tokenInfo.HasScope(auth.ScopeAdmin) {
+			max = auth.ScopeAdmin
+		}
+	}
+
+	granted := auth.ClampScopes(scopes, max)
+	if len(granted) < len(scopes) {
+		log.Printf("Requested scopes %v exceed the maximum %s, granting %v", scopes, max, granted)
+	}
+	return granted, nil
+}
+
+// tokenFromRequest 校验Authorization: Bearer <token>
+func (h *RedisConnectHandler) tokenFromRequest(r *http.Request) (*auth.TokenInfo, error) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		return nil, fmt.Errorf("missing token")
+	}
+	return h.tokenManager.ValidateToken(token)
+}
+
+// authorize 校验Token并检查会话的权限是否允许执行commands，然后返回会话的连接
+// 所有访问Redis数据的接口都通过这里鉴权，commands为接口将要执行的命令
+func (h *RedisConnectHandler) authorize(r *http.Request, commands ...string) (*auth.TokenInfo, *pool.RedisConnection, error) {
+	tokenInfo, err := h.tokenFromRequest(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := tokenInfo.Allows(commands...); err != nil {
+		return nil, nil, err
+	}
+	conn, err := h.sessionConnection(tokenInfo)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokenInfo, conn, nil
+}
+
+// SessionConnection 返回请求Token对应会话的连接，供handlers包之外的接口按连接的环境标签检查规则
+func (h *RedisConnectHandler) SessionConnection(r *http.Request) (*pool.RedisConnection, error) {
+	tokenInfo, err := h.tokenFromRequest(r)
+	if err != nil {
+		return nil, err
+	}
+	return h.sessionConnection(tokenInfo)
+}
+
+// sendAuthError 发送鉴权失败的响应，权限不足时返回403
+func (h *RedisConnectHandler) sendAuthError(w http.ResponseWriter, err error) {
+	if errors.Is(err, auth.ErrForbidden) {
+		h.sendErrorResponse(w, http.StatusForbidden, "Forbidden", err.Error())
+		return
+	}
+	h.sendErrorResponse(w, http.StatusUnauthorized, "Unauthorized", err.Error())
+}
+
+// sessionConnection 返回会话当前的连接，连接不存在时尝试根据保存的配置恢复
+func (h *RedisConnectHandler) sessionConnection(tokenInfo *auth.TokenInfo) (*pool.RedisConnection, error) {
+	conn, err := h.connectionPool.GetConnection(tokenInfo.ConnectionID)
+	if err == nil {
+		return conn, nil
+	}
+	return h.restoreConnection(tokenInfo)
+}
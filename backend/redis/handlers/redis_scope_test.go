@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/devtoolbox/redis/auth"
+	"github.com/devtoolbox/redis/profile"
+)
+
+func TestRequireScope_ReadOnlySession(t *testing.T) {
+	h := newTestHandler(t)
+	read := connectSession(t, h, "read", 0, auth.ScopeRead)
+	write := connectSession(t, h, "write", 1, auth.ScopeRead, auth.ScopeWrite)
+
+	called := false
+	next := func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}
+	guarded := h.RequireScope(next)
+
+	tests := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodPost, "/api/redis/keys/set"},
+		{http.MethodPost, "/api/redis/keys/delete"},
+		{http.MethodPost, "/api/redis/flushdb"},
+		{http.MethodDelete, "/api/redis/key/name"},
+		{http.MethodPost, "/api/redis/profiles"},
+		{http.MethodDelete, "/api/redis/profiles/id"},
+	}
+	for _, tt := range tests {
+		called = false
+		if resp := serve(guarded, tt.method, tt.path, read); resp.Code != http.StatusForbidden || called {
+			t.Errorf("%s %s: expected 403 for read-only session, got %d (handler called: %t)", tt.method, tt.path, resp.Code, called)
+		}
+	}
+
+	// 处理函数自身也会检查权限
+	for _, path := range []string{"/api/redis/keys/set", "/api/redis/keys/delete"} {
+		if resp := serve(h.HandleKeys, http.MethodPost, path, read); resp.Code != http.StatusForbidden {
+			t.Errorf("%s: expected 403 from handler, got %d", path, resp.Code)
+		}
+	}
+	if resp := serve(h.HandleFlushDB, http.MethodPost, "/api/redis/flushdb", read); resp.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 from flush handler, got %d", resp.Code)
+	}
+
+	// 写权限可以写入和删除，清空数据库与审计日志需要admin权限
+	for _, path := range []string{"/api/redis/keys/set", "/api/redis/keys/delete"} {
+		called = false
+		if resp := serve(guarded, http.MethodPost, path, write); resp.Code != http.StatusOK || !called {
+			t.Errorf("%s: expected write session to pass, got %d", path, resp.Code)
+		}
+	}
+	for _, path := range []string{"/api/redis/flushdb", "/api/audit", "/api/redis/unknown"} {
+		if resp := serve(guarded, http.MethodPost, path, write); resp.Code != http.StatusForbidden {
+			t.Errorf("%s: expected 403 for write session, got %d", path, resp.Code)
+		}
+	}
+
+	// 没有Token时只能提供凭据直接建立连接，使用保存的配置连接需要Token
+	for _, path := range []string{"/api/redis/profiles", "/api/redis/connections", "/api/redis/key/name"} {
+		if resp := serve(guarded, http.MethodGet, path, ""); resp.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected 401 without token, got %d", path, resp.Code)
+		}
+	}
+	called = false
+	if resp := serve(guarded, http.MethodPost, "/api/redis/connect", ""); resp.Code != http.StatusOK || !called {
+		t.Errorf("Expected connect without token to pass, got %d", resp.Code)
+	}
+	for _, token := range []string{"", "invalid"} {
+		for _, path := range []string{"/api/redis/connect", "/api/redis/profiles/id/connect"} {
+			if token == "" && path == "/api/redis/connect" {
+				continue
+			}
+			called = false
+			if resp := serve(guarded, http.MethodPost, path, token); resp.Code != http.StatusUnauthorized || called {
+				t.Errorf("%s with token %q: expected 401, got %d", path, token, resp.Code)
+			}
+		}
+	}
+}
+
+func TestConnectProfile_ClampsToSessionScopes(t *testing.T) {
+	h := newTestHandler(t)
+	store := withProfileStore(t, h)
+	prod, err := store.Create(profile.Profile{Name: "prod", Host: "localhost", Port: 6379, DB: 5, Environment: "prod", Password: "secret"})
+	if err != nil {
+		t.Fatalf("Failed to create profile: %v", err)
+	}
+	read := connectSession(t, h, "read", 0, auth.ScopeRead)
+	admin := connectSession(t, h, "admin", 1, auth.ScopeRead, auth.ScopeWrite, auth.ScopeAdmin)
+
+	connect := func(token string, scopes ...string) (int, []string) {
+		t.Helper()
+		body, _ := json.Marshal(ProfileConnectRequest{Scopes: scopes})
+		req := httptest.NewRequest(http.MethodPost, "/api/redis/profiles/"+prod.ID+"/connect", bytes.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		h.RequireScope(h.HandleProfile)(recorder, req)
+		var resp ConnectResponse
+		json.NewDecoder(recorder.Body).Decode(&resp)
+		return recorder.Code, resp.Scopes
+	}
+
+	// 只读会话去掉Token或请求更高权限都不能获得写权限
+	if code, _ := connect(""); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without token, got %d", code)
+	}
+	readOnly := []string{auth.ScopeRead}
+	if code, scopes := connect(read, auth.ScopeWrite); code != http.StatusOK || !reflect.DeepEqual(scopes, readOnly) {
+		t.Errorf("Expected read-only session to get %v, got %d %v", readOnly, code, scopes)
+	}
+	all := []string{auth.ScopeRead, auth.ScopeWrite, auth.ScopeAdmin}
+	if code, scopes := connect(admin, auth.ScopeAdmin); code != http.StatusOK || !reflect.DeepEqual(scopes, all) {
+		t.Errorf("Expected admin session to get %v, got %d %v", all, code, scopes)
+	}
+}
+
+func TestGrantScopes(t *testing.T) {
+	h := newTestHandler(t)
+	write := connectSession(t, h, "write", 0, auth.ScopeRead, auth.ScopeWrite)
+	admin := connectSession(t, h, "admin", 1, auth.ScopeRead, auth.ScopeWrite, auth.ScopeAdmin)
+
+	grant := func(token string, scopes ...string) []string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/redis/connect", strings.NewReader("{}"))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		granted, err := h.grantScopes(req, scopes)
+		if err != nil {
+			t.Fatalf("grantScopes failed: %v", err)
+		}
+		return granted
+	}
+
+	// 默认最高为write，客户端不能自行获得admin权限
+	readWrite := []string{auth.ScopeRead, auth.ScopeWrite}
+	if got := grant("", auth.ScopeAdmin); !reflect.DeepEqual(got, readWrite) {
+		t.Errorf("Expected admin request to be clamped to %v, got %v", readWrite, got)
+	}
+	if got := grant(write, auth.ScopeAdmin); !reflect.DeepEqual(got, readWrite) {
+		t.Errorf("Expected write session not to grant admin, got %v", got)
+	}
+	all := []string{auth.ScopeRead, auth.ScopeWrite, auth.ScopeAdmin}
+	if got := grant(admin, auth.ScopeAdmin); !reflect.DeepEqual(got, all) {
+		t.Errorf("Expected admin session to grant admin, got %v", got)
+	}
+
+	h.maxScope = auth.ScopeRead
+	if got := grant(""); !reflect.DeepEqual(got, []string{auth.ScopeRead}) {
+		t.Errorf("Expected default scopes to be clamped to read, got %v", got)
+	}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TokenResponse 刷新或撤销Token的响应
+type TokenResponse struct {
+	Success   bool     `json:"success"`
+	Message   string   `json:"message"`
+	SessionID string   `json:"sessionId,omitempty"`
+	Token     string   `json:"token,omitempty"`
+	ExpiresAt int64    `json:"expiresAt,omitempty"`
+	Scopes    []string `json:"scopes,omitempty"`
+}
+
+// RevokedSession 撤销列表中的一个会话
+type RevokedSession struct {
+	SessionID string    `json:"sessionId"`
+	Until     time.Time `json:"until"` // 撤销记录保留到的时间，此后该会话的Token都已过期
+}
+
+// RevokedListResponse 撤销列表的响应
+type RevokedListResponse struct {
+	Success bool             `json:"success"`
+	Message string           `json:"message"`
+	Revoked []RevokedSession `json:"revoked"`
+}
+
+// HandleToken 处理 /api/redis/token/ 下的会话操作：
+// POST refresh 签发同一会话的新Token，POST revoke 撤销当前会话，GET revoked 查看撤销列表
+func (h *RedisConnectHandler) HandleToken(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch action := strings.TrimPrefix(r.URL.Path, "/api/redis/token/"); {
+	case action == "refresh" && r.Method == http.MethodPost:
+		h.refreshToken(w, r)
+	case action == "revoke" && r.Method == http.MethodPost:
+		h.revokeToken(w, r)
+	case action == "revoked" && r.Method == http.MethodGet:
+		h.listRevoked(w)
+	case action == "refresh" || action == "revoke" || action == "revoked":
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "")
+	default:
+		h.sendErrorResponse(w, http.StatusNotFound, "Not found", "")
+	}
+}
+
+// refreshToken 延长会话的有效期，原Token在其过期前仍然有效
+func (h *RedisConnectHandler) refreshToken(w http.ResponseWriter, r *http.Request) {
+	tokenInfo, err := h.tokenFromRequest(r)
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+	tokenInfo, err = h.tokenManager.RefreshToken(tokenInfo.Token)
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+
+	h.sendToken(w, TokenResponse{
+		Success:   true,
+		Message:   "Token refreshed successfully",
+		SessionID: tokenInfo.SessionID,
+		Token:     tokenInfo.Token,
+		ExpiresAt: tokenInfo.ExpiresAt.Unix(),
+		Scopes:    tokenInfo.Scopes,
+	})
+}
+
+// revokeToken 撤销当前会话并释放其连接的引用
+func (h *RedisConnectHandler) revokeToken(w http.ResponseWriter, r *http.Request) {
+	tokenInfo, err := h.tokenFromRequest(r)
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusUnauthorized, "Unauthorized", err.Error())
+		return
+	}
+	session, err := h.tokenManager.RevokeToken(tokenInfo.Token)
+	if session != nil {
+		h.connectionPool.Release(session.ConnectionID)
+	}
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusInternalServerError, "Failed to revoke token", err.Error())
+		return
+	}
+
+	log.Printf("Token revoked: session %s", tokenInfo.SessionID)
+	h.sendToken(w, TokenResponse{
+		Success:   true,
+		Message:   "Token revoked successfully",
+		SessionID: tokenInfo.SessionID,
+	})
+}
+
+// listRevoked 返回撤销列表，按到期时间排序
+func (h *RedisConnectHandler) listRevoked(w http.ResponseWriter) {
+	revoked := make([]RevokedSession, 0)
+	for id, until := range h.tokenManager.RevokedSessions() {
+		revoked = append(revoked, RevokedSession{SessionID: id, Until: until})
+	}
+	sort.Slice(revoked, func(i, j int) bool {
+		return revoked[i].Until.Before(revoked[j].Until)
+	})
+
+	response := RevokedListResponse{
+		Success: true,
+		Message: "Revoked sessions retrieved successfully",
+		Revoked: revoked,
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// sendToken 发送Token响应
+func (h *RedisConnectHandler) sendToken(w http.ResponseWriter, response TokenResponse) {
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
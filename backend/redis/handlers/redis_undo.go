@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/devtoolbox/redis/audit"
+	"github.com/devtoolbox/redis/auth"
+	"github.com/devtoolbox/redis/policy"
+	"github.com/devtoolbox/redis/pool"
+	"github.com/devtoolbox/redis/rediserr"
+	"github.com/devtoolbox/redis/undo"
+)
+
+// UndoListResponse 会话当前连接最近的可撤销修改，按时间从新到旧排列
+type UndoListResponse struct {
+	Success      bool          `json:"success"`
+	Message      string        `json:"message"`
+	ConnectionID string        `json:"connectionId"`
+	Count        int           `json:"count"`
+	Changes      []undo.Change `json:"changes"`
+}
+
+// HandleUndo 处理 /api/redis/undo：
+// GET /api/redis/undo 列出最近的修改，POST /api/redis/undo/{id} 将修改涉及的键恢复为修改前的状态
+func (h *RedisConnectHandler) HandleUndo(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/redis/undo"), "/")
+	wantMethod := http.MethodPost
+	if path == "" {
+		wantMethod = http.MethodGet
+	}
+	if r.Method != wantMethod {
+		h.sendErrorResponse(w, http.StatusMethodNotAllowed, "Method not allowed", "")
+		return
+	}
+
+	if path == "" {
+		h.listChanges(w, r)
+		return
+	}
+	id, err := strconv.ParseUint(path, 10, 64)
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid change id", path)
+		return
+	}
+	h.restoreChange(w, r, id)
+}
+
+// listChanges 列出会话当前连接的修改
+func (h *RedisConnectHandler) listChanges(w http.ResponseWriter, r *http.Request) {
+	_, conn, err := h.authorize(r, "DUMP")
+	if err != nil {
+		h.sendAuthError(w, err)
+		return
+	}
+
+	changes := h.undoJournal.List(conn.ID)
+	response := UndoListResponse{
+		Success:      true,
+		Message:      "Changes retrieved successfully",
+		ConnectionID: conn.ID,
+		Count:        len(changes),
+		Changes:      changes,
+	}
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// restoreChange 撤销一次修改，请求体为可选的PolicyRequest
+// 恢复前会保存键的当前状态，撤销本身也可以再次撤销
+func (h *RedisConnectHandler) restoreChange(w http.ResponseWriter, r *http.Request, id uint64) {
+	tokenInfo, conn, err := h.authorize(r, "RESTORE", "DEL")
+	if err != nil {
+		h.sendAuthError(w, err)
+		return
+	}
+	var req PolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		h.sendErrorResponse(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	change, err := h.undoJournal.Get(conn.ID, id)
+	if err != nil {
+		h.sendErrorResponse(w, http.StatusNotFound, "Change not found", strconv.FormatUint(id, 10))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), commandTimeout)
+	defer cancel()
+
+	op := policy.Operation{Target: conn.ID, Command: "RESTORE", Keys: change.Keys}
+	if req.DryRun {
+		preview, err := previewKeys(ctx, conn, change.Keys)
+		if err != nil {
+			h.sendErrorResponse(w, rediserr.HTTPStatus(err), "Failed to preview restore", err.Error())
+			return
+		}
+		h.sendPreview(w, conn, op, preview)
+		return
+	}
+
+	decision, ok := h.checkPolicy(w, r, tokenInfo, conn, op, req)
+	if !ok {
+		return
+	}
+	change, err = h.undoJournal.Claim(conn.ID, id)
+	if err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, undo.ErrRestored) {
+			status = http.StatusConflict
+		}
+		h.sendErrorResponse(w, status, "Change cannot be restored", err.Error())
+		return
+	}
+
+	before, err := keyStates(ctx, conn.Client, change.Keys)
+	if err != nil {
+		h.undoJournal.Release(conn.ID, id)
+		h.sendErrorResponse(w, rediserr.HTTPStatus(err), "Failed to check keys", err.Error())
+		return
+	}
+	snapshots, captureErr := undo.Capture(ctx, conn.Client, change.Keys...)
+	err = undo.Apply(ctx, conn.Client, change.Snapshots)
+	entry := audit.Entry{Command: op.Command, Keys: change.Keys, Before: before, Affected: int64(len(change.Snapshots))}
+	entry.Result, entry.Error = auditResult(err)
+	h.recordAudit(r, tokenInfo, conn, entry)
+	if err != nil {
+		h.undoJournal.Release(conn.ID, id)
+		h.sendErrorResponse(w, rediserr.HTTPStatus(err), "Failed to restore change", err.Error())
+		return
+	}
+
+	log.Printf("Change %d restored: %s (%s, %d keys)", id, conn.ID, change.Command, len(change.Keys))
+	undoID, undoErr := h.recordUndo(tokenInfo, conn, op.Command, snapshots, captureErr)
+	h.sendKeyOperation(w, conn, "Change restored successfully", decision, entry.Affected, undoID, undoErr)
+}
+
+// recordUndo 将修改前的快照写入撤销日志，返回修改的ID
+// 快照失败时（例如模拟模式下的Stream）操作照常执行，返回的错误文本提示该修改无法撤销
+func (h *RedisConnectHandler) recordUndo(tokenInfo *auth.TokenInfo, conn *pool.RedisConnection, command string, snapshots []undo.Snapshot, captureErr error) (uint64, string) {
+	if captureErr != nil {
+		log.Printf("%s on %s cannot be undone: %v", command, conn.ID, captureErr)
+		return 0, captureErr.Error()
+	}
+	change := undo.Change{ConnectionID: conn.ID, Command: command, Snapshots: snapshots}
+	if tokenInfo != nil {
+		change.SessionID = tokenInfo.SessionID
+	}
+	return h.undoJournal.Record(change).ID, ""
+}
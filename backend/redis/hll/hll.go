@@ -0,0 +1,384 @@
+// Package hll 实现与Redis二进制兼容的HyperLogLog编码
+// 编码格式、哈希函数和基数估计算法与Redis hyperloglog.c保持一致，
+// 生成的字符串可以直接通过DUMP/RESTORE或SET在真实Redis中使用。
+package hll
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/bits"
+)
+
+const (
+	// P 寄存器索引位数
+	P = 14
+	// Registers 寄存器数量
+	Registers = 1 << P
+	// Q 参与计算前导零的哈希位数
+	Q = 64 - P
+	// RegisterBits 稠密编码中每个寄存器的位数
+	RegisterBits = 6
+	// HeaderSize 头部长度：魔数4字节、编码1字节、保留3字节、缓存基数8字节
+	HeaderSize = 16
+	// DenseSize 稠密编码的总长度
+	DenseSize = HeaderSize + (Registers*RegisterBits+7)/8
+	// SparseMaxBytes 稀疏编码的最大长度，超过后转换为稠密编码，与Redis默认配置一致
+	SparseMaxBytes = 3000
+
+	registerMax   = 1<<RegisterBits - 1
+	sparseValMax  = 32
+	sparseValLen  = 4
+	zeroMaxLen    = 64
+	xzeroMaxLen   = 16384
+	encodingDense = 0
+	encodingSpars = 1
+	hashSeed      = 0xadc83b19
+	alphaInf      = 0.721347520444481703680
+)
+
+var magic = []byte("HYLL")
+
+var (
+	// ErrInvalid 不是合法的HyperLogLog字符串
+	ErrInvalid = errors.New("hll: not a valid HyperLogLog string value")
+	// ErrCorrupted HyperLogLog数据已损坏
+	ErrCorrupted = errors.New("hll: corrupted HLL object")
+)
+
+// HLL HyperLogLog计数器
+type HLL struct {
+	registers [Registers]uint8
+	dense     bool
+	card      uint64
+	cardValid bool
+}
+
+// New 创建空的HyperLogLog，使用稀疏编码
+func New() *HLL {
+	return &HLL{cardValid: true}
+}
+
+// IsHLL 判断字符串是否带有HyperLogLog头部
+func IsHLL(data []byte) bool {
+	_, err := Parse(data)
+	return err == nil
+}
+
+// Parse 解析Redis格式的HyperLogLog字符串
+func Parse(data []byte) (*HLL, error) {
+	if len(data) < HeaderSize || !bytes.Equal(data[:4], magic) {
+		return nil, ErrInvalid
+	}
+
+	h := &HLL{}
+	card := binary.LittleEndian.Uint64(data[8:HeaderSize])
+	if card&(1<<63) == 0 {
+		h.card = card
+		h.cardValid = true
+	}
+
+	switch data[4] {
+	case encodingDense:
+		if len(data) != DenseSize {
+			return nil, ErrInvalid
+		}
+		h.dense = true
+		for i := 0; i < Registers; i++ {
+			h.registers[i] = denseGet(data[HeaderSize:], i)
+		}
+	case encodingSpars:
+		if err := h.decodeSparse(data[HeaderSize:]); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrInvalid
+	}
+
+	return h, nil
+}
+
+// decodeSparse 解析稀疏编码的操作码
+func (h *HLL) decodeSparse(data []byte) error {
+	index := 0
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		var runLen int
+		var value uint8
+
+		switch {
+		case b&0x80 != 0: // VAL: 1vvvvvxx
+			value = (b>>2)&0x1f + 1
+			runLen = int(b&0x03) + 1
+		case b&0x40 != 0: // XZERO: 01xxxxxx yyyyyyyy
+			if i+1 >= len(data) {
+				return ErrCorrupted
+			}
+			runLen = (int(b&0x3f)<<8 | int(data[i+1])) + 1
+			i++
+		default: // ZERO: 00xxxxxx
+			runLen = int(b&0x3f) + 1
+		}
+
+		if index+runLen > Registers {
+			return ErrCorrupted
+		}
+		for j := 0; j < runLen; j++ {
+			h.registers[index+j] = value
+		}
+		index += runLen
+	}
+
+	if index != Registers {
+		return ErrCorrupted
+	}
+	return nil
+}
+
+// denseGet 读取稠密编码中的寄存器
+func denseGet(registers []byte, index int) uint8 {
+	byteIndex := index * RegisterBits / 8
+	fb := uint(index * RegisterBits & 7)
+	value := uint(registers[byteIndex]) >> fb
+	if byteIndex+1 < len(registers) {
+		value |= uint(registers[byteIndex+1]) << (8 - fb)
+	}
+	return uint8(value & registerMax)
+}
+
+// denseSet 写入稠密编码中的寄存器
+func denseSet(registers []byte, index int, value uint8) {
+	byteIndex := index * RegisterBits / 8
+	fb := uint(index * RegisterBits & 7)
+	v := uint(value)
+	registers[byteIndex] &^= byte(registerMax << fb)
+	registers[byteIndex] |= byte(v << fb)
+	if byteIndex+1 < len(registers) {
+		registers[byteIndex+1] &^= byte(registerMax >> (8 - fb))
+		registers[byteIndex+1] |= byte(v >> (8 - fb))
+	}
+}
+
+// Dense 是否使用稠密编码
+func (h *HLL) Dense() bool {
+	return h.dense
+}
+
+// Add 添加元素，寄存器发生变化时返回true
+func (h *HLL) Add(element []byte) bool {
+	index, count := patLen(element)
+	if h.registers[index] >= count {
+		return false
+	}
+	h.registers[index] = count
+	h.cardValid = false
+	return true
+}
+
+// Merge 合并另一个HyperLogLog，寄存器发生变化时返回true
+// 任一方为稠密编码时结果使用稠密编码
+func (h *HLL) Merge(other *HLL) bool {
+	changed := false
+	for i, value := range other.registers {
+		if value > h.registers[i] {
+			h.registers[i] = value
+			changed = true
+		}
+	}
+	if other.dense {
+		h.dense = true
+	}
+	if changed {
+		h.cardValid = false
+	}
+	return changed
+}
+
+// Count 返回估计基数，并像PFCOUNT一样缓存结果
+func (h *HLL) Count() uint64 {
+	if !h.cardValid {
+		h.card = estimate(&h.registers)
+		h.cardValid = true
+	}
+	return h.card
+}
+
+// CachedCount 返回缓存的基数，缓存失效时ok为false
+func (h *HLL) CachedCount() (uint64, bool) {
+	return h.card, h.cardValid
+}
+
+// Union 计算多个HyperLogLog合并后的基数，不修改参数
+func Union(hlls ...*HLL) uint64 {
+	var registers [Registers]uint8
+	for _, h := range hlls {
+		for i, value := range h.registers {
+			if value > registers[i] {
+				registers[i] = value
+			}
+		}
+	}
+	return estimate(&registers)
+}
+
+// Bytes 序列化为Redis格式的字符串
+// 寄存器值超过稀疏编码上限或长度超过SparseMaxBytes时转换为稠密编码
+func (h *HLL) Bytes() []byte {
+	if !h.dense {
+		if sparse, ok := h.encodeSparse(); ok {
+			return sparse
+		}
+		h.dense = true
+	}
+
+	data := make([]byte, DenseSize)
+	h.writeHeader(data, encodingDense)
+	for i, value := range h.registers {
+		denseSet(data[HeaderSize:], i, value)
+	}
+	return data
+}
+
+// writeHeader 写入头部与缓存基数
+func (h *HLL) writeHeader(data []byte, encoding byte) {
+	copy(data, magic)
+	data[4] = encoding
+	card := h.card
+	if !h.cardValid {
+		card = 1 << 63
+	}
+	binary.LittleEndian.PutUint64(data[8:HeaderSize], card)
+}
+
+// encodeSparse 生成稀疏编码
+func (h *HLL) encodeSparse() ([]byte, bool) {
+	data := make([]byte, HeaderSize, HeaderSize+64)
+	h.writeHeader(data, encodingSpars)
+
+	for i := 0; i < Registers; {
+		value := h.registers[i]
+		runLen := 1
+		for i+runLen < Registers && h.registers[i+runLen] == value {
+			runLen++
+		}
+		i += runLen
+
+		if value > sparseValMax {
+			return nil, false
+		}
+
+		for runLen > 0 {
+			switch {
+			case value != 0:
+				n := min(runLen, sparseValLen)
+				data = append(data, 0x80|(value-1)<<2|byte(n-1))
+				runLen -= n
+			case runLen > zeroMaxLen:
+				n := min(runLen, xzeroMaxLen)
+				data = append(data, 0x40|byte((n-1)>>8), byte(n-1))
+				runLen -= n
+			default:
+				data = append(data, byte(runLen-1))
+				runLen = 0
+			}
+		}
+
+		if len(data) > SparseMaxBytes {
+			return nil, false
+		}
+	}
+
+	return data, true
+}
+
+// patLen 计算元素对应的寄存器索引与计数值
+func patLen(element []byte) (int, uint8) {
+	hash := murmurHash64A(element, hashSeed)
+	index := int(hash & (Registers - 1))
+	hash >>= P
+	hash |= 1 << Q
+	return index, uint8(bits.TrailingZeros64(hash) + 1)
+}
+
+// murmurHash64A Redis使用的MurmurHash2 64位版本
+func murmurHash64A(key []byte, seed uint64) uint64 {
+	const m = 0xc6a4a7935bd1e995
+	const r = 47
+
+	h := seed ^ (uint64(len(key)) * m)
+
+	n := len(key) - len(key)&7
+	for i := 0; i < n; i += 8 {
+		k := binary.LittleEndian.Uint64(key[i:])
+		k *= m
+		k ^= k >> r
+		k *= m
+		h ^= k
+		h *= m
+	}
+
+	if rest := key[n:]; len(rest) > 0 {
+		for i := len(rest) - 1; i >= 0; i-- {
+			h ^= uint64(rest[i]) << (8 * uint(i))
+		}
+		h *= m
+	}
+
+	h ^= h >> r
+	h *= m
+	h ^= h >> r
+	return h
+}
+
+// estimate 基于寄存器直方图估计基数（Ertl改进算法，与Redis 5.0+一致）
+func estimate(registers *[Registers]uint8) uint64 {
+	var histogram [Q + 2]int
+	for _, value := range registers {
+		histogram[value]++
+	}
+
+	m := float64(Registers)
+	z := m * tau((m-float64(histogram[Q+1]))/m)
+	for j := Q; j >= 1; j-- {
+		z += float64(histogram[j])
+		z *= 0.5
+	}
+	z += m * sigma(float64(histogram[0])/m)
+
+	return uint64(math.Round(alphaInf * m * m / z))
+}
+
+func sigma(x float64) float64 {
+	if x == 1 {
+		return math.Inf(1)
+	}
+	y := 1.0
+	z := x
+	for {
+		x *= x
+		zPrime := z
+		z += x * y
+		y += y
+		if zPrime == z {
+			return z
+		}
+	}
+}
+
+func tau(x float64) float64 {
+	if x == 0 || x == 1 {
+		return 0
+	}
+	y := 1.0
+	z := 1 - x
+	for {
+		x = math.Sqrt(x)
+		zPrime := z
+		y *= 0.5
+		z -= math.Pow(1-x, 2) * y
+		if zPrime == z {
+			return z / 3
+		}
+	}
+}
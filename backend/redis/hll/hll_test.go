@@ -0,0 +1,112 @@
+package hll
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestNew_EmptySparseEncoding(t *testing.T) {
+	// 与Redis新建的HyperLogLog完全一致：头部加一个覆盖全部寄存器的XZERO
+	expected := append([]byte("HYLL\x01\x00\x00\x00"), make([]byte, 8)...)
+	expected = append(expected, 0x7f, 0xff)
+
+	if got := New().Bytes(); !bytes.Equal(got, expected) {
+		t.Errorf("Unexpected empty encoding: %x", got)
+	}
+}
+
+func TestHLL_CountSmallExact(t *testing.T) {
+	h := New()
+	for i := 0; i < 10; i++ {
+		h.Add([]byte(fmt.Sprintf("element:%d", i)))
+	}
+	if count := h.Count(); count != 10 {
+		t.Errorf("Expected exact count 10, got %d", count)
+	}
+	if h.Add([]byte("element:0")) {
+		t.Error("Expected re-adding an element not to change registers")
+	}
+}
+
+func TestHLL_CountAccuracy(t *testing.T) {
+	h := New()
+	const n = 100000
+	for i := 0; i < n; i++ {
+		h.Add([]byte(fmt.Sprintf("user:%d", i)))
+	}
+	count := float64(h.Count())
+	if math.Abs(count-n)/n > 0.02 {
+		t.Errorf("Expected count within 2%% of %d, got %.0f", n, count)
+	}
+	if data := h.Bytes(); !h.Dense() || len(data) != DenseSize {
+		t.Error("Expected large HLL to be promoted to dense encoding")
+	}
+}
+
+func TestHLL_RoundTrip(t *testing.T) {
+	for _, n := range []int{5, 50000} {
+		h := New()
+		for i := 0; i < n; i++ {
+			h.Add([]byte(fmt.Sprintf("k%d", i)))
+		}
+		data := h.Bytes()
+
+		parsed, err := Parse(data)
+		if err != nil {
+			t.Fatalf("Parse failed for %d elements: %v", n, err)
+		}
+		if parsed.registers != h.registers {
+			t.Errorf("Registers differ after round trip for %d elements", n)
+		}
+		if _, cached := parsed.CachedCount(); cached {
+			t.Error("Expected cache to be invalid after Add")
+		}
+		if parsed.Count() != h.Count() {
+			t.Errorf("Count differs after round trip for %d elements", n)
+		}
+
+		// 缓存的基数写入头部
+		reparsed, _ := Parse(parsed.Bytes())
+		if card, cached := reparsed.CachedCount(); !cached || card != h.Count() {
+			t.Errorf("Expected cached count %d, got %d (%v)", h.Count(), card, cached)
+		}
+	}
+}
+
+func TestHLL_MergeAndUnion(t *testing.T) {
+	a, b := New(), New()
+	for i := 0; i < 100; i++ {
+		a.Add([]byte(fmt.Sprintf("a%d", i)))
+		b.Add([]byte(fmt.Sprintf("b%d", i)))
+	}
+
+	union := Union(a, b)
+	a.Merge(b)
+	if a.Count() != union {
+		t.Errorf("Expected merged count %d, got %d", union, a.Count())
+	}
+	if math.Abs(float64(union)-200) > 4 {
+		t.Errorf("Expected union close to 200, got %d", union)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	if _, err := Parse([]byte("not an hll")); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid, got %v", err)
+	}
+
+	dense := New()
+	dense.dense = true
+	if _, err := Parse(dense.Bytes()[:DenseSize-1]); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for truncated dense encoding, got %v", err)
+	}
+
+	// 两个XZERO覆盖的寄存器超过总数
+	corrupted := append(New().Bytes(), 0x7f, 0xff)
+	if _, err := Parse(corrupted); !errors.Is(err, ErrCorrupted) {
+		t.Errorf("Expected ErrCorrupted, got %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/devtoolbox/redis/hll"
+	"github.com/devtoolbox/redis/rejson"
+)
+
+// 字符串值的展示格式
+const (
+	// FormatHyperLogLog HyperLogLog字符串，展示估计基数
+	FormatHyperLogLog = "hyperloglog"
+	// FormatBitmap 二进制字符串，按位图展示
+	FormatBitmap = "bitmap"
+	// FormatHex 二进制字符串，按十六进制展示
+	FormatHex = "hex"
+	// FormatJSON RedisJSON文档，展示解析后的文档
+	FormatJSON = "json"
+)
+
+// bitmapRowBytes 位图每行展示的字节数
+const bitmapRowBytes = 8
+
+// bitmapMaxRows 位图最多展示的行数
+const bitmapMaxRows = 128
+
+// bitmapAutoBytes 未指定view时，不超过该长度的二进制字符串按位图展示，更长的按十六进制展示
+// 压缩数据、序列化对象等二进制值通常较长，按位图展示没有意义
+const bitmapAutoBytes = 64
+
+// hexMaxBytes 十六进制最多展示的字节数
+const hexMaxBytes = 4096
+
+// HyperLogLogView HyperLogLog展示结构
+type HyperLogLogView struct {
+	Encoding    string `json:"encoding"`
+	Cardinality uint64 `json:"cardinality"`
+	Bytes       int    `json:"bytes"`
+}
+
+// BitmapRow 位图的一行，Offset为该行第一个位的偏移
+type BitmapRow struct {
+	Offset int64  `json:"offset"`
+	Bits   string `json:"bits"`
+}
+
+// BitmapView 位图展示结构
+type BitmapView struct {
+	Bits      int64       `json:"bits"`
+	SetBits   int64       `json:"set_bits"`
+	Rows      []BitmapRow `json:"rows"`
+	Truncated bool        `json:"truncated,omitempty"`
+}
+
+// HexView 二进制字符串的十六进制展示结构
+type HexView struct {
+	Bytes     int    `json:"bytes"`
+	Hex       string `json:"hex"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// renderStringValue 识别HyperLogLog和二进制字符串，返回展示格式和展示值
+// view为bitmap或hex时按指定格式展示；为空时较短的二进制字符串按位图展示，较长的按十六进制展示
+// 普通文本返回空格式和原值
+func renderStringValue(value, view string) (string, interface{}) {
+	switch view {
+	case FormatBitmap:
+		return FormatBitmap, renderBitmap([]byte(value))
+	case FormatHex:
+		return FormatHex, renderHex([]byte(value))
+	}
+
+	if h, err := hll.Parse([]byte(value)); err == nil {
+		encoding := "sparse"
+		if h.Dense() {
+			encoding = "dense"
+		}
+		return FormatHyperLogLog, HyperLogLogView{
+			Encoding:    encoding,
+			Cardinality: h.Count(),
+			Bytes:       len(value),
+		}
+	}
+
+	if isBinaryString(value) {
+		if len(value) <= bitmapAutoBytes {
+			return FormatBitmap, renderBitmap([]byte(value))
+		}
+		return FormatHex, renderHex([]byte(value))
+	}
+
+	return "", value
+}
+
+// renderJSONValue 解析RedisJSON文档，值可以是JSON文本或已解析的文档
+// 无法解析时原样返回，不影响键信息的展示
+func renderJSONValue(value interface{}) (string, interface{}) {
+	var data []byte
+	switch v := value.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return FormatJSON, value
+	}
+
+	doc, err := rejson.Parse(data)
+	if err != nil {
+		return "", value
+	}
+	return FormatJSON, doc
+}
+
+// isBinaryString 包含非UTF-8或控制字符的字符串视为二进制数据
+func isBinaryString(value string) bool {
+	if !utf8.ValidString(value) {
+		return true
+	}
+	for _, r := range value {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			return true
+		}
+	}
+	return false
+}
+
+// renderBitmap 将位图按行渲染为0/1网格，每字节之间以空格分隔
+func renderBitmap(data []byte) BitmapView {
+	view := BitmapView{
+		Bits: int64(len(data)) * 8,
+		Rows: make([]BitmapRow, 0),
+	}
+
+	for _, b := range data {
+		view.SetBits += int64(bits.OnesCount8(b))
+	}
+
+	for start := 0; start < len(data); start += bitmapRowBytes {
+		if len(view.Rows) >= bitmapMaxRows {
+			view.Truncated = true
+			break
+		}
+
+		end := min(start+bitmapRowBytes, len(data))
+		cells := make([]string, 0, end-start)
+		for _, b := range data[start:end] {
+			cells = append(cells, fmt.Sprintf("%08b", b))
+		}
+		view.Rows = append(view.Rows, BitmapRow{
+			Offset: int64(start) * 8,
+			Bits:   strings.Join(cells, " "),
+		})
+	}
+
+	return view
+}
+
+// renderHex 将二进制字符串渲染为十六进制，超过hexMaxBytes的部分截断
+func renderHex(data []byte) HexView {
+	view := HexView{Bytes: len(data)}
+	if len(data) > hexMaxBytes {
+		data = data[:hexMaxBytes]
+		view.Truncated = true
+	}
+	view.Hex = hex.EncodeToString(data)
+	return view
+}
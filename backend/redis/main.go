@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -11,14 +12,27 @@ import (
 	"strings"
 	"time"
 
+	"github.com/devtoolbox/redis/audit"
+	"github.com/devtoolbox/redis/auth"
 	"github.com/devtoolbox/redis/config"
 	"github.com/devtoolbox/redis/handlers"
+	"github.com/devtoolbox/redis/policy"
+	"github.com/devtoolbox/redis/pool"
+	"github.com/devtoolbox/redis/rediserr"
+	"github.com/devtoolbox/redis/supervisor"
 )
 
 // 全局Redis管理器
 var (
 	redisManager RedisManager
 	redisMode    RedisMode = MockMode // 默认使用Mock模式
+	keyPolicy    *policy.Engine       // 危险命令规则，按请求会话连接的环境标签选择
+	// sessionConnection 返回请求Token对应会话的连接
+	sessionConnection func(r *http.Request) (*pool.RedisConnection, error)
+	// deleteSessionKey 在会话的连接上删除键，删除前保存快照用于撤销
+	deleteSessionKey func(r *http.Request, key string) (uint64, string, error)
+	// recordAudit 写入审计日志，按请求的Token补全会话与连接信息
+	recordAudit func(r *http.Request, conn *pool.RedisConnection, entry audit.Entry)
 )
 
 // initRedisManager 初始化Redis管理器
@@ -94,7 +108,7 @@ func originValidationMiddleware(next http.HandlerFunc) http.HandlerFunc {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 		}
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Rotation-Token")
 		
 		// 处理预检请求
 		if r.Method == "OPTIONS" {
@@ -211,10 +225,17 @@ func keyInfoHandler(w http.ResponseWriter, r *http.Request) {
 		keyName = parts[0]
 	}
 	
+	// 二进制字符串的展示格式，为空时自动选择
+	view := r.URL.Query().Get("view")
+	if view != "" && view != FormatBitmap && view != FormatHex {
+		http.Error(w, "view参数只能为bitmap或hex", http.StatusBadRequest)
+		return
+	}
+	
 	log.Printf("查询Redis键信息: %s", keyName)
 	
 	// 获取键信息
-	keyInfo, err := redisManager.GetKeyInfo(keyName)
+	keyInfo, err := redisManager.GetKeyInfo(keyName, view)
 	if err != nil {
 		log.Printf("获取键信息失败: %v", err)
 		response := KeyInfoResponse{
@@ -222,7 +243,7 @@ func keyInfoHandler(w http.ResponseWriter, r *http.Request) {
 			Message: fmt.Sprintf("获取键信息失败: %v", err),
 		}
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
+		w.WriteHeader(rediserr.HTTPStatus(err))
 		json.NewEncoder(w).Encode(response)
 		return
 	}
@@ -271,28 +292,80 @@ func deleteKeyHandler(w http.ResponseWriter, r *http.Request) {
 		keyName = parts[0]
 	}
 	
+	// 按会话连接的环境标签检查危险命令规则，查询参数dryRun=true时只返回检查结果与预览ID
+	conn, err := sessionConnection(r)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("未授权: %v", err), http.StatusUnauthorized)
+		return
+	}
+	query := r.URL.Query()
+	op := policy.Operation{Target: "global", Command: "DEL", Keys: []string{keyName}}
+	if query.Get("dryRun") == "true" {
+		decision, previewID, err := keyPolicy.Preview(conn.Environment, op)
+		if err != nil {
+			http.Error(w, "内部服务器错误", http.StatusInternalServerError)
+			return
+		}
+		response := DeleteKeyResponse{
+			Status:    "success",
+			Message:   "预览成功，键未删除",
+			Success:   true,
+			Policy:    &decision,
+			PreviewID: previewID,
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	decision, err := keyPolicy.Check(conn.Environment, op, policy.Approval{Confirm: query.Get("confirm"), PreviewID: query.Get("previewId")})
+	if err != nil {
+		log.Printf("删除键被规则拒绝: %s: %v", keyName, err)
+		recordAudit(r, conn, audit.Entry{Command: "DEL", Keys: []string{keyName}, Result: audit.ResultRejected, Error: err.Error()})
+		status := http.StatusPreconditionRequired
+		if errors.Is(err, policy.ErrBlocked) {
+			status = http.StatusForbidden
+		}
+		response := DeleteKeyResponse{
+			Status:  "error",
+			Message: fmt.Sprintf("删除键被拒绝: %v", err),
+			Success: false,
+			Policy:  &decision,
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(response)
+		return
+	}
+	
 	log.Printf("删除Redis键: %s", keyName)
 	
-	// 删除键
-	err := redisManager.DeleteKey(keyName)
+	// 在会话的连接上删除键，删除前保存快照并写入审计日志，可以通过 /api/redis/undo/{id} 撤销
+	undoID, undoErr, err := deleteSessionKey(r, keyName)
 	if err != nil {
 		log.Printf("删除键失败: %v", err)
+		status := rediserr.HTTPStatus(err)
+		if errors.Is(err, auth.ErrForbidden) {
+			status = http.StatusForbidden
+		}
 		response := DeleteKeyResponse{
 			Status:  "error",
 			Message: fmt.Sprintf("删除键失败: %v", err),
 			Success: false,
 		}
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
+		w.WriteHeader(status)
 		json.NewEncoder(w).Encode(response)
 		return
 	}
 	
 	// 构造响应数据
 	response := DeleteKeyResponse{
-		Status:  "success",
-		Message: "键删除成功",
-		Success: true,
+		Status:    "success",
+		Message:   "键删除成功",
+		Success:   true,
+		UndoID:    undoID,
+		UndoError: undoErr,
 	}
 	
 	// 设置响应头
@@ -518,12 +591,46 @@ func main() {
 		log.Fatalf("Failed to create Redis connect handler: %v", err)
 	}
 
-	// 注册路由（使用来源验证中间件）
+	// 启动后台维护：清理过期Token与空闲连接，检查连接健康状况
+	maintenance := supervisor.New(
+		redisConnectHandler.GetConnectionPool(),
+		redisConnectHandler.GetTokenManager(),
+		supervisor.ConfigFromSecurity(config.GetSecurityConfig()),
+	)
+	maintenance.SetEncryptionKeys(redisConnectHandler)
+	maintenance.Start()
+	defer maintenance.Stop()
+
+	keyPolicy = redisConnectHandler.GetCommandPolicy()
+	sessionConnection = redisConnectHandler.SessionConnection
+	deleteSessionKey = redisConnectHandler.DeleteKey
+	recordAudit = redisConnectHandler.RecordAudit
+	defer redisConnectHandler.GetAuditLog().Close()
+
+	// 注册路由（使用来源验证中间件），/api/redis/*与审计日志还需按接口将要执行的命令检查会话权限
+	requireScope := redisConnectHandler.RequireScope
 	http.HandleFunc("/ping", originValidationMiddleware(pingHandler))
 	http.HandleFunc("/health", originValidationMiddleware(healthHandler))
 	http.HandleFunc("/api/configs", originValidationMiddleware(configsHandler))
-	http.HandleFunc("/api/redis/connect", originValidationMiddleware(redisConnectHandler.HandleConnect))
-	http.HandleFunc("/api/redis/key/", originValidationMiddleware(redisKeyHandler))
+	http.HandleFunc("/api/crypto/public-key", originValidationMiddleware(redisConnectHandler.HandlePublicKey))
+	http.HandleFunc("/api/crypto/nonce", originValidationMiddleware(redisConnectHandler.HandleNonce))
+	http.HandleFunc("/api/crypto/rotate", originValidationMiddleware(redisConnectHandler.HandleRotateKey))
+	http.HandleFunc("/api/redis/connect", originValidationMiddleware(requireScope(redisConnectHandler.HandleConnect)))
+	http.HandleFunc("/api/redis/connections", originValidationMiddleware(requireScope(redisConnectHandler.HandleConnections)))
+	http.HandleFunc("/api/redis/connections/", originValidationMiddleware(requireScope(redisConnectHandler.HandleConnection)))
+	http.HandleFunc("/api/redis/profiles", originValidationMiddleware(requireScope(redisConnectHandler.HandleProfiles)))
+	http.HandleFunc("/api/redis/profiles/", originValidationMiddleware(requireScope(redisConnectHandler.HandleProfile)))
+	http.HandleFunc("/api/redis/cluster", originValidationMiddleware(requireScope(redisConnectHandler.HandleClusterInfo)))
+	http.HandleFunc("/api/redis/scan", originValidationMiddleware(requireScope(redisConnectHandler.HandleScan)))
+	http.HandleFunc("/api/redis/db", originValidationMiddleware(requireScope(redisConnectHandler.HandleDatabase)))
+	http.HandleFunc("/api/redis/token/", originValidationMiddleware(requireScope(redisConnectHandler.HandleToken)))
+	http.HandleFunc("/api/redis/keys/", originValidationMiddleware(requireScope(redisConnectHandler.HandleKeys)))
+	http.HandleFunc("/api/redis/flushdb", originValidationMiddleware(requireScope(redisConnectHandler.HandleFlushDB)))
+	http.HandleFunc("/api/redis/undo", originValidationMiddleware(requireScope(redisConnectHandler.HandleUndo)))
+	http.HandleFunc("/api/redis/undo/", originValidationMiddleware(requireScope(redisConnectHandler.HandleUndo)))
+	http.HandleFunc("/api/audit", originValidationMiddleware(requireScope(redisConnectHandler.HandleAudit)))
+	http.HandleFunc("/api/redis/key/", originValidationMiddleware(requireScope(redisKeyHandler)))
+	http.HandleFunc("/api/redis/geo/", originValidationMiddleware(requireScope(geoJSONHandler)))
 	
 	// 启动服务器
 	port := fmt.Sprintf(":%d", redisConfig.Port)
@@ -540,9 +647,26 @@ func main() {
 	fmt.Printf("Ping接口: http://%s%s/ping\n", host, port)
 	fmt.Printf("健康检查: http://%s%s/health\n", host, port)
 	fmt.Printf("配置文件接口: http://%s%s/api/configs\n", host, port)
+	fmt.Printf("加密公钥: http://%s%s/api/crypto/public-key (GET)\n", host, port)
+	fmt.Printf("加密信封nonce: http://%s%s/api/crypto/nonce?count=1 (POST)\n", host, port)
+	fmt.Printf("轮换加密密钥: http://%s%s/api/crypto/rotate (POST, 需要X-Rotation-Token)\n", host, port)
 	fmt.Printf("Redis连接接口: http://%s%s/api/redis/connect\n", host, port)
-	fmt.Printf("Redis键查询: http://%s%s/api/redis/key/{keyName}\n", host, port)
-	fmt.Printf("Redis键删除: http://%s%s/api/redis/key/{keyName} (DELETE)\n", host, port)
+	fmt.Printf("Redis连接列表: http://%s%s/api/redis/connections\n", host, port)
+	fmt.Printf("Redis连接管理: http://%s%s/api/redis/connections/{id} (GET/DELETE), /api/redis/connections/{id}/ping (POST)\n", host, port)
+	fmt.Printf("Redis连接配置: http://%s%s/api/redis/profiles (GET/POST), /api/redis/profiles/{id} (GET/PUT/DELETE)\n", host, port)
+	fmt.Printf("使用连接配置连接: http://%s%s/api/redis/profiles/{id}/connect (POST)\n", host, port)
+	fmt.Printf("Redis集群信息: http://%s%s/api/redis/cluster\n", host, port)
+	fmt.Printf("Redis键遍历: http://%s%s/api/redis/scan?cursor=0&match=*&count=100\n", host, port)
+	fmt.Printf("Redis数据库: http://%s%s/api/redis/db (GET查看键数量, POST切换数据库)\n", host, port)
+	fmt.Printf("Token管理: http://%s%s/api/redis/token/refresh (POST), /api/redis/token/revoke (POST), /api/redis/token/revoked (GET)\n", host, port)
+	fmt.Printf("Redis键删除与重命名: http://%s%s/api/redis/keys/delete (POST), /api/redis/keys/rename (POST)\n", host, port)
+	fmt.Printf("Redis键写入与TTL: http://%s%s/api/redis/keys/set (POST), /api/redis/keys/expire (POST)\n", host, port)
+	fmt.Printf("Redis清空数据库: http://%s%s/api/redis/flushdb (POST)\n", host, port)
+	fmt.Printf("撤销修改: http://%s%s/api/redis/undo (GET), /api/redis/undo/{id} (POST)\n", host, port)
+	fmt.Printf("审计日志: http://%s%s/api/audit?connection=&key=*&since=&until=&limit=100\n", host, port)
+	fmt.Printf("Redis键查询: http://%s%s/api/redis/key/{keyName} (二进制字符串支持view=bitmap或hex)\n", host, port)
+	fmt.Printf("Redis键删除: http://%s%s/api/redis/key/{keyName} (DELETE, 在会话的连接上删除并返回undoId, 支持dryRun、confirm、previewId查询参数)\n", host, port)
+	fmt.Printf("地理位置键GeoJSON: http://%s%s/api/redis/geo/{keyName}\n", host, port)
 	fmt.Println("按 Ctrl+C 停止服务")
 	fmt.Println("")
 	fmt.Println("环境变量支持:")
@@ -550,6 +674,13 @@ func main() {
 	fmt.Println("  REDIS_API_HOST - 覆盖服务主机")
 	fmt.Println("  REDIS_API_LOG_LEVEL - 覆盖日志级别")
 	fmt.Println("  REDIS_CONFIG_DIR - 覆盖配置目录")
+	fmt.Println("  REDIS_TRACE_DIR - 开启命令轨迹录制并指定目录")
+	fmt.Println("  REDIS_PROFILE_FILE - 覆盖连接配置文件路径")
+	fmt.Println("  REDIS_PROFILE_PASSPHRASE - 使用主口令加密连接配置中的密码（默认由RSA私钥派生密钥）")
+	fmt.Println("  REDIS_TOKEN_KEY_FILE - 覆盖Token签名密钥文件路径")
+	fmt.Println("  REDIS_ENCRYPTION_KEY_FILE - 覆盖自动生成的RSA密钥文件路径（未配置私钥时使用）")
+	fmt.Println("  REDIS_ALLOW_LEGACY_RSA - 为true时兼容旧版前端，接受不带信封的PKCS#1 v1.5密文")
+	fmt.Println("  REDIS_AUDIT_FILE - 覆盖审计日志文件路径")
 	fmt.Println("")
 	
 	// 启动HTTP服务器
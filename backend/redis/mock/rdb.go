@@ -0,0 +1,473 @@
+package mock
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+// DUMP/RESTORE使用的RDB值序列化：<类型><值><RDB版本(2字节小端)><CRC64(8字节小端)>
+// 写入时使用所有Redis版本都能加载的基础类型，读取时还支持整数与LZF压缩的字符串
+
+// RDB值类型
+const (
+	rdbTypeString = 0
+	rdbTypeList   = 1
+	rdbTypeSet    = 2
+	rdbTypeZSet   = 3
+	rdbTypeHash   = 4
+	rdbTypeZSet2  = 5
+)
+
+// rdbVersion 写入的RDB版本，rdbMaxVersion 可以读取的最高版本
+const (
+	rdbVersion    = 6
+	rdbMaxVersion = 12
+)
+
+// 长度编码
+const (
+	rdb6BitLen  = 0
+	rdb14BitLen = 1
+	rdb32BitLen = 0x80
+	rdb64BitLen = 0x81
+	rdbEncVal   = 3
+
+	rdbEncInt8  = 0
+	rdbEncInt16 = 1
+	rdbEncInt32 = 2
+	rdbEncLZF   = 3
+)
+
+var (
+	errDumpPayload = rediserr.Err("DUMP payload version or checksum are wrong")
+	errBadFormat   = rediserr.Err("Bad data format")
+)
+
+// dumpValue 将值序列化为DUMP格式
+func dumpValue(value *RedisValue) ([]byte, error) {
+	var buf bytes.Buffer
+	switch value.Type {
+	case "string":
+		buf.WriteByte(rdbTypeString)
+		writeRDBString(&buf, fmt.Sprintf("%v", value.Value))
+	case "list":
+		list, _ := value.Value.([]string)
+		buf.WriteByte(rdbTypeList)
+		writeRDBLength(&buf, uint64(len(list)))
+		for _, element := range list {
+			writeRDBString(&buf, element)
+		}
+	case "set":
+		set, _ := value.Value.(map[string]bool)
+		buf.WriteByte(rdbTypeSet)
+		writeRDBLength(&buf, uint64(len(set)))
+		for _, member := range sortedKeys(set) {
+			writeRDBString(&buf, member)
+		}
+	case "zset":
+		zset, _ := value.Value.(map[string]float64)
+		buf.WriteByte(rdbTypeZSet)
+		writeRDBLength(&buf, uint64(len(zset)))
+		for _, member := range sortedKeys(zset) {
+			writeRDBString(&buf, member)
+			writeRDBScore(&buf, zset[member])
+		}
+	case "hash":
+		hash, _ := value.Value.(map[string]string)
+		buf.WriteByte(rdbTypeHash)
+		writeRDBLength(&buf, uint64(len(hash)))
+		for _, field := range sortedKeys(hash) {
+			writeRDBString(&buf, field)
+			writeRDBString(&buf, hash[field])
+		}
+	default:
+		return nil, rediserr.Err(fmt.Sprintf("DUMP is not supported for %s values", value.Type))
+	}
+
+	binary.Write(&buf, binary.LittleEndian, uint16(rdbVersion))
+	binary.Write(&buf, binary.LittleEndian, crc64Jones(0, buf.Bytes()))
+	return buf.Bytes(), nil
+}
+
+// restoreValue 校验DUMP格式的数据并解析为值
+func restoreValue(payload []byte) (*RedisValue, error) {
+	if len(payload) < 10 {
+		return nil, errDumpPayload
+	}
+	footer := payload[len(payload)-10:]
+	version := binary.LittleEndian.Uint16(footer[:2])
+	checksum := binary.LittleEndian.Uint64(footer[2:])
+	if version > rdbMaxVersion || crc64Jones(0, payload[:len(payload)-8]) != checksum {
+		return nil, errDumpPayload
+	}
+
+	r := &rdbReader{data: payload[:len(payload)-10]}
+	value, err := r.readValue()
+	if err != nil || r.pos != len(r.data) {
+		return nil, errBadFormat
+	}
+	return value, nil
+}
+
+// rdbReader 按顺序读取RDB数据
+type rdbReader struct {
+	data []byte
+	pos  int
+}
+
+var errShortRDB = errors.New("unexpected end of rdb data")
+
+func (r *rdbReader) readValue() (*RedisValue, error) {
+	kind, err := r.readByte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case rdbTypeString:
+		s, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+		return &RedisValue{Type: "string", Value: s}, nil
+	case rdbTypeList:
+		n, err := r.readCount()
+		if err != nil {
+			return nil, err
+		}
+		list := make([]string, 0, n)
+		for i := 0; i < n; i++ {
+			element, err := r.readString()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, element)
+		}
+		return &RedisValue{Type: "list", Value: list}, nil
+	case rdbTypeSet:
+		n, err := r.readCount()
+		if err != nil {
+			return nil, err
+		}
+		set := make(map[string]bool, n)
+		for i := 0; i < n; i++ {
+			member, err := r.readString()
+			if err != nil {
+				return nil, err
+			}
+			set[member] = true
+		}
+		return &RedisValue{Type: "set", Value: set}, nil
+	case rdbTypeZSet, rdbTypeZSet2:
+		n, err := r.readCount()
+		if err != nil {
+			return nil, err
+		}
+		zset := make(map[string]float64, n)
+		for i := 0; i < n; i++ {
+			member, err := r.readString()
+			if err != nil {
+				return nil, err
+			}
+			var score float64
+			if kind == rdbTypeZSet {
+				score, err = r.readScore()
+			} else {
+				score, err = r.readBinaryScore()
+			}
+			if err != nil {
+				return nil, err
+			}
+			zset[member] = score
+		}
+		return &RedisValue{Type: "zset", Value: zset}, nil
+	case rdbTypeHash:
+		n, err := r.readCount()
+		if err != nil {
+			return nil, err
+		}
+		hash := make(map[string]string, n)
+		for i := 0; i < n; i++ {
+			field, err := r.readString()
+			if err != nil {
+				return nil, err
+			}
+			value, err := r.readString()
+			if err != nil {
+				return nil, err
+			}
+			hash[field] = value
+		}
+		return &RedisValue{Type: "hash", Value: hash}, nil
+	}
+	return nil, fmt.Errorf("unsupported rdb type %d", kind)
+}
+
+func (r *rdbReader) readByte() (byte, error) {
+	if r.pos >= len(r.data) {
+		return 0, errShortRDB
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *rdbReader) readBytes(n int) ([]byte, error) {
+	if n < 0 || r.pos+n > len(r.data) {
+		return nil, errShortRDB
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b, nil
+}
+
+// readLength 读取长度，encoded为true时返回的是特殊编码的类型
+func (r *rdbReader) readLength() (uint64, bool, error) {
+	first, err := r.readByte()
+	if err != nil {
+		return 0, false, err
+	}
+	switch first >> 6 {
+	case rdb6BitLen:
+		return uint64(first & 0x3f), false, nil
+	case rdb14BitLen:
+		next, err := r.readByte()
+		if err != nil {
+			return 0, false, err
+		}
+		return uint64(first&0x3f)<<8 | uint64(next), false, nil
+	case rdbEncVal:
+		return uint64(first & 0x3f), true, nil
+	}
+	switch first {
+	case rdb32BitLen:
+		b, err := r.readBytes(4)
+		if err != nil {
+			return 0, false, err
+		}
+		return uint64(binary.BigEndian.Uint32(b)), false, nil
+	case rdb64BitLen:
+		b, err := r.readBytes(8)
+		if err != nil {
+			return 0, false, err
+		}
+		return binary.BigEndian.Uint64(b), false, nil
+	}
+	return 0, false, fmt.Errorf("unknown length encoding %#x", first)
+}
+
+// readCount 读取元素数量，数量不可能超过剩余的字节数
+func (r *rdbReader) readCount() (int, error) {
+	n, encoded, err := r.readLength()
+	if err != nil {
+		return 0, err
+	}
+	if encoded || n > uint64(len(r.data)-r.pos) {
+		return 0, errShortRDB
+	}
+	return int(n), nil
+}
+
+func (r *rdbReader) readString() (string, error) {
+	n, encoded, err := r.readLength()
+	if err != nil {
+		return "", err
+	}
+	if !encoded {
+		if n > uint64(len(r.data)-r.pos) {
+			return "", errShortRDB
+		}
+		b, err := r.readBytes(int(n))
+		return string(b), err
+	}
+
+	switch n {
+	case rdbEncInt8:
+		b, err := r.readBytes(1)
+		if err != nil {
+			return "", err
+		}
+		return strconv.Itoa(int(int8(b[0]))), nil
+	case rdbEncInt16:
+		b, err := r.readBytes(2)
+		if err != nil {
+			return "", err
+		}
+		return strconv.Itoa(int(int16(binary.LittleEndian.Uint16(b)))), nil
+	case rdbEncInt32:
+		b, err := r.readBytes(4)
+		if err != nil {
+			return "", err
+		}
+		return strconv.Itoa(int(int32(binary.LittleEndian.Uint32(b)))), nil
+	case rdbEncLZF:
+		compressed, _, err := r.readLength()
+		if err != nil {
+			return "", err
+		}
+		length, _, err := r.readLength()
+		if err != nil {
+			return "", err
+		}
+		if compressed > uint64(len(r.data)-r.pos) || length > 512<<20 {
+			return "", errShortRDB
+		}
+		b, err := r.readBytes(int(compressed))
+		if err != nil {
+			return "", err
+		}
+		out, err := lzfDecompress(b, int(length))
+		return string(out), err
+	}
+	return "", fmt.Errorf("unknown string encoding %d", n)
+}
+
+// readScore 读取字符串形式的分数，253、254、255分别表示NaN、+inf、-inf
+func (r *rdbReader) readScore() (float64, error) {
+	n, err := r.readByte()
+	if err != nil {
+		return 0, err
+	}
+	switch n {
+	case 253:
+		return math.NaN(), nil
+	case 254:
+		return math.Inf(1), nil
+	case 255:
+		return math.Inf(-1), nil
+	}
+	b, err := r.readBytes(int(n))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(string(b), 64)
+}
+
+func (r *rdbReader) readBinaryScore() (float64, error) {
+	b, err := r.readBytes(8)
+	if err != nil {
+		return 0, err
+	}
+	return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+}
+
+func writeRDBLength(buf *bytes.Buffer, n uint64) {
+	switch {
+	case n < 1<<6:
+		buf.WriteByte(byte(n))
+	case n < 1<<14:
+		buf.WriteByte(byte(n>>8) | rdb14BitLen<<6)
+		buf.WriteByte(byte(n))
+	case n <= math.MaxUint32:
+		buf.WriteByte(rdb32BitLen)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	default:
+		buf.WriteByte(rdb64BitLen)
+		binary.Write(buf, binary.BigEndian, n)
+	}
+}
+
+func writeRDBString(buf *bytes.Buffer, s string) {
+	writeRDBLength(buf, uint64(len(s)))
+	buf.WriteString(s)
+}
+
+func writeRDBScore(buf *bytes.Buffer, score float64) {
+	switch {
+	case math.IsNaN(score):
+		buf.WriteByte(253)
+	case math.IsInf(score, 1):
+		buf.WriteByte(254)
+	case math.IsInf(score, -1):
+		buf.WriteByte(255)
+	default:
+		s := strconv.FormatFloat(score, 'g', 17, 64)
+		buf.WriteByte(byte(len(s)))
+		buf.WriteString(s)
+	}
+}
+
+// lzfDecompress 解压LZF数据，length为解压后的长度
+func lzfDecompress(in []byte, length int) ([]byte, error) {
+	out := make([]byte, 0, length)
+	for i := 0; i < len(in); {
+		ctrl := int(in[i])
+		i++
+		if ctrl < 32 {
+			// 字面量
+			n := ctrl + 1
+			if i+n > len(in) {
+				return nil, errShortRDB
+			}
+			out = append(out, in[i:i+n]...)
+			i += n
+			continue
+		}
+
+		// 回溯引用
+		n := ctrl >> 5
+		if n == 7 {
+			if i >= len(in) {
+				return nil, errShortRDB
+			}
+			n += int(in[i])
+			i++
+		}
+		if i >= len(in) {
+			return nil, errShortRDB
+		}
+		ref := len(out) - ((ctrl&0x1f)<<8 + 1) - int(in[i])
+		i++
+		if ref < 0 {
+			return nil, errShortRDB
+		}
+		for j := 0; j < n+2; j++ {
+			out = append(out, out[ref+j])
+		}
+	}
+	if len(out) != length {
+		return nil, errShortRDB
+	}
+	return out, nil
+}
+
+// crc64Table Redis使用的CRC-64/Jones（反射多项式0x95ac9329ac4bc9b5，初值0，不取反）
+var crc64Table = func() [256]uint64 {
+	var table [256]uint64
+	for i := range table {
+		crc := uint64(i)
+		for j := 0; j < 8; j++ {
+			if crc&1 == 1 {
+				crc = crc>>1 ^ 0x95ac9329ac4bc9b5
+			} else {
+				crc >>= 1
+			}
+		}
+		table[i] = crc
+	}
+	return table
+}()
+
+func crc64Jones(crc uint64, data []byte) uint64 {
+	for _, b := range data {
+		crc = crc64Table[byte(crc)^b] ^ crc>>8
+	}
+	return crc
+}
+
+// sortedKeys 按字典序返回map的键，使DUMP的结果稳定
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
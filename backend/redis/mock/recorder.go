@@ -0,0 +1,1072 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// TraceEntry 命令轨迹记录，每条记录对应JSONL文件中的一行
+type TraceEntry struct {
+	ConnectionID string        `json:"connectionId"`
+	Seq          int64         `json:"seq"`
+	Time         time.Time     `json:"time"`
+	Command      string        `json:"command"`
+	Args         []interface{} `json:"args,omitempty"`
+	Result       interface{}   `json:"result,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	LatencyUs    int64         `json:"latencyUs"`
+}
+
+// RedisRecorder 命令录制器，包装RedisInterface并把每条命令写入JSONL轨迹
+// 时间间隔参数与结果统一以毫秒记录，避免JSON数字精度丢失
+type RedisRecorder struct {
+	next         RedisInterface
+	connectionID string
+	writer       io.Writer
+	encoder      *json.Encoder
+	mutex        sync.Mutex
+	seq          int64
+}
+
+// NewRedisRecorder 创建新的命令录制器
+// 如果writer实现了io.Closer，会在Close时一并关闭
+func NewRedisRecorder(next RedisInterface, connectionID string, writer io.Writer) *RedisRecorder {
+	return &RedisRecorder{
+		next:         next,
+		connectionID: connectionID,
+		writer:       writer,
+		encoder:      json.NewEncoder(writer),
+	}
+}
+
+// Unwrap 获取被包装的客户端
+func (r *RedisRecorder) Unwrap() RedisInterface {
+	return r.next
+}
+
+// record 写入一条轨迹记录
+func (r *RedisRecorder) record(start time.Time, command string, args []interface{}, cmd interface{}) {
+	latency := time.Since(start)
+	result, err := cmdResult(cmd)
+
+	entry := TraceEntry{
+		ConnectionID: r.connectionID,
+		Time:         start,
+		Command:      command,
+		Args:         encodeTraceArgs(args),
+		Result:       encodeTraceValue(result),
+		LatencyUs:    latency.Microseconds(),
+	}
+	if err != nil {
+		entry.Error = err.Error()
+		entry.Result = nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.seq++
+	entry.Seq = r.seq
+	// 录制失败不影响命令本身的执行结果
+	_ = r.encoder.Encode(&entry)
+}
+
+// binaryValue 二进制参数或结果，JSON中记录为{"$bytes":"<Base64>"}
+// []byte直接编码会变成Base64文本，非UTF-8字符串会被替换为U+FFFD，回放时都无法还原
+type binaryValue struct {
+	Bytes []byte `json:"$bytes"`
+}
+
+// encodeTraceArgs 将二进制参数转换为binaryValue
+func encodeTraceArgs(args []interface{}) []interface{} {
+	if args == nil {
+		return nil
+	}
+	encoded := make([]interface{}, len(args))
+	for i, arg := range args {
+		encoded[i] = encodeTraceValue(arg)
+	}
+	return encoded
+}
+
+// encodeTraceValue 将[]byte与非UTF-8字符串（包括切片中的元素）转换为binaryValue
+func encodeTraceValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return binaryValue{Bytes: v}
+	case string:
+		if !utf8.ValidString(v) {
+			return binaryValue{Bytes: []byte(v)}
+		}
+	case []string:
+		for _, item := range v {
+			if !utf8.ValidString(item) {
+				encoded := make([]interface{}, len(v))
+				for i, item := range v {
+					encoded[i] = encodeTraceValue(item)
+				}
+				return encoded
+			}
+		}
+	case []interface{}:
+		return encodeTraceArgs(v)
+	}
+	return value
+}
+
+// cmdResult 从命令结果中提取可序列化的值和错误
+func cmdResult(cmd interface{}) (interface{}, error) {
+	switch c := cmd.(type) {
+	case *StatusCmd:
+		return c.val, c.err
+	case *StringCmd:
+		return c.val, c.err
+	case *IntCmd:
+		return c.val, c.err
+	case *BoolCmd:
+		return c.val, c.err
+	case *FloatCmd:
+		return c.val, c.err
+	case *SliceCmd:
+		return c.val, c.err
+	case *IntSliceCmd:
+		return c.val, c.err
+	case *IntPointerSliceCmd:
+		return c.val, c.err
+	case *ScanCmd:
+		// 与Redis的回复一致：[游标, 键列表]
+		return []interface{}{c.cursor, c.page}, c.err
+	case *ClusterSlotsCmd:
+		return c.val, c.err
+	case *GeoPosCmd:
+		return c.val, c.err
+	case *GeoLocationCmd:
+		return c.val, c.err
+	case *DurationCmd:
+		return durationToMs(c.val), c.err
+	case *StringSliceCmd:
+		return c.val, c.err
+	case *StringStringMapCmd:
+		return c.val, c.err
+	case *ZSliceCmd:
+		return c.val, c.err
+	case *ZWithKeyCmd:
+		return c.val, c.err
+	case *XMessageSliceCmd:
+		return c.val, c.err
+	case *XStreamSliceCmd:
+		return c.val, c.err
+	default:
+		return nil, nil
+	}
+}
+
+// jsonText 将RedisJSON的值转换为JSON文本，编码失败时原样记录
+func jsonText(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	return string(data)
+}
+
+// durationToMs 时间间隔转毫秒
+// 不足1毫秒的负值是KeepTTL等哨兵值，原样保留
+func durationToMs(d time.Duration) int64 {
+	if d < 0 && d > -time.Millisecond {
+		return int64(d)
+	}
+	return d.Milliseconds()
+}
+
+// zArgs 将有序集合成员展开为score, member交替的参数列表
+func zArgs(key string, members []*Z) []interface{} {
+	args := make([]interface{}, 0, 1+len(members)*2)
+	args = append(args, key)
+	for _, member := range members {
+		args = append(args, member.Score, member.Member)
+	}
+	return args
+}
+
+// keyArgs 将键名与其余参数拼接为参数列表
+func keyArgs(key string, rest ...interface{}) []interface{} {
+	return append([]interface{}{key}, rest...)
+}
+
+// xAddArgs 将XADD参数按Redis命令格式展开
+func xAddArgs(a *XAddArgs) []interface{} {
+	args := []interface{}{a.Stream}
+	if a.NoMkStream {
+		args = append(args, "NOMKSTREAM")
+	}
+	if a.MaxLen > 0 {
+		args = append(args, "MAXLEN")
+		if a.Approx {
+			args = append(args, "~")
+		}
+		args = append(args, a.MaxLen)
+	}
+	id := a.ID
+	if id == "" {
+		id = "*"
+	}
+	args = append(args, id)
+	if fields, err := streamFieldValues(a.Values); err == nil {
+		args = append(args, stringArgs(fields)...)
+	}
+	return args
+}
+
+// xReadArgs 将XREAD参数按Redis命令格式展开，时间间隔以毫秒记录
+func xReadArgs(a *XReadArgs) []interface{} {
+	var args []interface{}
+	if a.Count > 0 {
+		args = append(args, "COUNT", a.Count)
+	}
+	if a.Block >= 0 {
+		args = append(args, "BLOCK", durationToMs(a.Block))
+	}
+	args = append(args, "STREAMS")
+	return append(args, stringArgs(a.Streams)...)
+}
+
+// setArgs 将SET的完整参数按Redis命令格式展开，EXAT以秒、PX以毫秒记录
+func setArgs(key string, value interface{}, a SetArgs) []interface{} {
+	args := []interface{}{key, value}
+	if a.KeepTTL {
+		args = append(args, "KEEPTTL")
+	}
+	if !a.ExpireAt.IsZero() {
+		args = append(args, "EXAT", a.ExpireAt.Unix())
+	}
+	if a.TTL > 0 {
+		args = append(args, "PX", durationToMs(a.TTL))
+	}
+	if a.Mode != "" {
+		args = append(args, strings.ToUpper(a.Mode))
+	}
+	if a.Get {
+		args = append(args, "GET")
+	}
+	return args
+}
+
+// geoUnit 与go-redis一致，未指定距离单位时使用km
+func geoUnit(unit string) string {
+	if unit == "" {
+		return "km"
+	}
+	return unit
+}
+
+// geoWithArgs 展开WITHCOORD、WITHDIST、WITHHASH选项
+func geoWithArgs(withCoord, withDist, withHash bool) []interface{} {
+	var args []interface{}
+	if withCoord {
+		args = append(args, "WITHCOORD")
+	}
+	if withDist {
+		args = append(args, "WITHDIST")
+	}
+	if withHash {
+		args = append(args, "WITHHASH")
+	}
+	return args
+}
+
+// geoRadiusArgs 将GEORADIUS查询参数按Redis命令格式展开
+func geoRadiusArgs(q *GeoRadiusQuery) []interface{} {
+	args := []interface{}{q.Radius, geoUnit(q.Unit)}
+	args = append(args, geoWithArgs(q.WithCoord, q.WithDist, q.WithGeoHash)...)
+	if q.Count > 0 {
+		args = append(args, "COUNT", q.Count)
+	}
+	if q.Sort != "" {
+		args = append(args, strings.ToUpper(q.Sort))
+	}
+	return args
+}
+
+// geoSearchArgs 将GEOSEARCH查询参数按Redis命令格式展开
+func geoSearchArgs(q *GeoSearchQuery) []interface{} {
+	var args []interface{}
+	if q.Member != "" {
+		args = append(args, "FROMMEMBER", q.Member)
+	} else {
+		args = append(args, "FROMLONLAT", q.Longitude, q.Latitude)
+	}
+	if q.Radius > 0 {
+		args = append(args, "BYRADIUS", q.Radius, geoUnit(q.RadiusUnit))
+	} else {
+		args = append(args, "BYBOX", q.BoxWidth, q.BoxHeight, geoUnit(q.BoxUnit))
+	}
+	if q.Sort != "" {
+		args = append(args, strings.ToUpper(q.Sort))
+	}
+	if q.Count > 0 {
+		args = append(args, "COUNT", q.Count)
+		if q.CountAny {
+			args = append(args, "ANY")
+		}
+	}
+	return args
+}
+
+// stringArgs 将字符串切片转换为参数列表
+func stringArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+// 基础操作
+func (r *RedisRecorder) Ping(ctx context.Context) *StatusCmd {
+	start := time.Now()
+	cmd := r.next.Ping(ctx)
+	r.record(start, "PING", nil, cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) Close() error {
+	err := r.next.Close()
+	if closer, ok := r.writer.(io.Closer); ok {
+		r.mutex.Lock()
+		closer.Close()
+		r.mutex.Unlock()
+	}
+	return err
+}
+
+// 字符串操作
+func (r *RedisRecorder) Get(ctx context.Context, key string) *StringCmd {
+	start := time.Now()
+	cmd := r.next.Get(ctx, key)
+	r.record(start, "GET", keyArgs(key), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd {
+	start := time.Now()
+	cmd := r.next.Set(ctx, key, value, expiration)
+	r.record(start, "SET", keyArgs(key, value, durationToMs(expiration)), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *BoolCmd {
+	start := time.Now()
+	cmd := r.next.SetNX(ctx, key, value, expiration)
+	r.record(start, "SETNX", keyArgs(key, value, durationToMs(expiration)), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) Del(ctx context.Context, keys ...string) *IntCmd {
+	start := time.Now()
+	cmd := r.next.Del(ctx, keys...)
+	r.record(start, "DEL", stringArgs(keys), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) Exists(ctx context.Context, keys ...string) *IntCmd {
+	start := time.Now()
+	cmd := r.next.Exists(ctx, keys...)
+	r.record(start, "EXISTS", stringArgs(keys), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) Expire(ctx context.Context, key string, expiration time.Duration) *BoolCmd {
+	start := time.Now()
+	cmd := r.next.Expire(ctx, key, expiration)
+	r.record(start, "EXPIRE", keyArgs(key, durationToMs(expiration)), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) TTL(ctx context.Context, key string) *DurationCmd {
+	start := time.Now()
+	cmd := r.next.TTL(ctx, key)
+	r.record(start, "TTL", keyArgs(key), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) PTTL(ctx context.Context, key string) *DurationCmd {
+	start := time.Now()
+	cmd := r.next.PTTL(ctx, key)
+	r.record(start, "PTTL", keyArgs(key), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) Persist(ctx context.Context, key string) *BoolCmd {
+	start := time.Now()
+	cmd := r.next.Persist(ctx, key)
+	r.record(start, "PERSIST", keyArgs(key), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) SetEX(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd {
+	start := time.Now()
+	cmd := r.next.SetEX(ctx, key, value, expiration)
+	r.record(start, "SETEX", keyArgs(key, value, durationToMs(expiration)), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) PSetEX(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd {
+	start := time.Now()
+	cmd := r.next.PSetEX(ctx, key, value, expiration)
+	r.record(start, "PSETEX", keyArgs(key, value, durationToMs(expiration)), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) SetArgs(ctx context.Context, key string, value interface{}, a SetArgs) *StatusCmd {
+	start := time.Now()
+	cmd := r.next.SetArgs(ctx, key, value, a)
+	r.record(start, "SETARGS", setArgs(key, value, a), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) GetSet(ctx context.Context, key string, value interface{}) *StringCmd {
+	start := time.Now()
+	cmd := r.next.GetSet(ctx, key, value)
+	r.record(start, "GETSET", keyArgs(key, value), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) GetDel(ctx context.Context, key string) *StringCmd {
+	start := time.Now()
+	cmd := r.next.GetDel(ctx, key)
+	r.record(start, "GETDEL", keyArgs(key), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) GetEx(ctx context.Context, key string, expiration time.Duration) *StringCmd {
+	start := time.Now()
+	cmd := r.next.GetEx(ctx, key, expiration)
+	r.record(start, "GETEX", keyArgs(key, durationToMs(expiration)), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) StrLen(ctx context.Context, key string) *IntCmd {
+	start := time.Now()
+	cmd := r.next.StrLen(ctx, key)
+	r.record(start, "STRLEN", keyArgs(key), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) MGet(ctx context.Context, keys ...string) *SliceCmd {
+	start := time.Now()
+	cmd := r.next.MGet(ctx, keys...)
+	r.record(start, "MGET", stringArgs(keys), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) MSet(ctx context.Context, values ...interface{}) *StatusCmd {
+	start := time.Now()
+	cmd := r.next.MSet(ctx, values...)
+	r.record(start, "MSET", stringArgs(pairArgs(values)), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) MSetNX(ctx context.Context, values ...interface{}) *BoolCmd {
+	start := time.Now()
+	cmd := r.next.MSetNX(ctx, values...)
+	r.record(start, "MSETNX", stringArgs(pairArgs(values)), cmd)
+	return cmd
+}
+
+// 计数器操作
+func (r *RedisRecorder) Incr(ctx context.Context, key string) *IntCmd {
+	start := time.Now()
+	cmd := r.next.Incr(ctx, key)
+	r.record(start, "INCR", keyArgs(key), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) IncrBy(ctx context.Context, key string, value int64) *IntCmd {
+	start := time.Now()
+	cmd := r.next.IncrBy(ctx, key, value)
+	r.record(start, "INCRBY", keyArgs(key, value), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) Decr(ctx context.Context, key string) *IntCmd {
+	start := time.Now()
+	cmd := r.next.Decr(ctx, key)
+	r.record(start, "DECR", keyArgs(key), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) DecrBy(ctx context.Context, key string, decrement int64) *IntCmd {
+	start := time.Now()
+	cmd := r.next.DecrBy(ctx, key, decrement)
+	r.record(start, "DECRBY", keyArgs(key, decrement), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) IncrByFloat(ctx context.Context, key string, value float64) *FloatCmd {
+	start := time.Now()
+	cmd := r.next.IncrByFloat(ctx, key, value)
+	r.record(start, "INCRBYFLOAT", keyArgs(key, value), cmd)
+	return cmd
+}
+
+// 位图操作
+func (r *RedisRecorder) SetBit(ctx context.Context, key string, offset int64, value int) *IntCmd {
+	start := time.Now()
+	cmd := r.next.SetBit(ctx, key, offset, value)
+	r.record(start, "SETBIT", keyArgs(key, offset, value), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) GetBit(ctx context.Context, key string, offset int64) *IntCmd {
+	start := time.Now()
+	cmd := r.next.GetBit(ctx, key, offset)
+	r.record(start, "GETBIT", keyArgs(key, offset), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) BitCount(ctx context.Context, key string, bitCount *BitCount) *IntCmd {
+	start := time.Now()
+	cmd := r.next.BitCount(ctx, key, bitCount)
+	args := keyArgs(key)
+	if bitCount != nil {
+		args = append(args, bitCount.Start, bitCount.End)
+	}
+	r.record(start, "BITCOUNT", args, cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) BitPos(ctx context.Context, key string, bit int64, pos ...int64) *IntCmd {
+	start := time.Now()
+	cmd := r.next.BitPos(ctx, key, bit, pos...)
+	args := keyArgs(key, bit)
+	for _, p := range pos {
+		args = append(args, p)
+	}
+	r.record(start, "BITPOS", args, cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) BitField(ctx context.Context, key string, args ...interface{}) *IntSliceCmd {
+	start := time.Now()
+	cmd := r.next.BitField(ctx, key, args...)
+	r.record(start, "BITFIELD", keyArgs(key, args...), cmd)
+	return cmd
+}
+
+// HyperLogLog操作
+func (r *RedisRecorder) PFAdd(ctx context.Context, key string, els ...interface{}) *IntCmd {
+	start := time.Now()
+	cmd := r.next.PFAdd(ctx, key, els...)
+	r.record(start, "PFADD", keyArgs(key, els...), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) PFCount(ctx context.Context, keys ...string) *IntCmd {
+	start := time.Now()
+	cmd := r.next.PFCount(ctx, keys...)
+	r.record(start, "PFCOUNT", stringArgs(keys), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) PFMerge(ctx context.Context, dest string, keys ...string) *StatusCmd {
+	start := time.Now()
+	cmd := r.next.PFMerge(ctx, dest, keys...)
+	r.record(start, "PFMERGE", keyArgs(dest, stringArgs(keys)...), cmd)
+	return cmd
+}
+
+// 哈希操作
+func (r *RedisRecorder) HGet(ctx context.Context, key, field string) *StringCmd {
+	start := time.Now()
+	cmd := r.next.HGet(ctx, key, field)
+	r.record(start, "HGET", keyArgs(key, field), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) HSet(ctx context.Context, key string, values ...interface{}) *IntCmd {
+	start := time.Now()
+	cmd := r.next.HSet(ctx, key, values...)
+	r.record(start, "HSET", keyArgs(key, values...), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) HDel(ctx context.Context, key string, fields ...string) *IntCmd {
+	start := time.Now()
+	cmd := r.next.HDel(ctx, key, fields...)
+	r.record(start, "HDEL", keyArgs(key, stringArgs(fields)...), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) HExists(ctx context.Context, key, field string) *BoolCmd {
+	start := time.Now()
+	cmd := r.next.HExists(ctx, key, field)
+	r.record(start, "HEXISTS", keyArgs(key, field), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) HGetAll(ctx context.Context, key string) *StringStringMapCmd {
+	start := time.Now()
+	cmd := r.next.HGetAll(ctx, key)
+	r.record(start, "HGETALL", keyArgs(key), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) HKeys(ctx context.Context, key string) *StringSliceCmd {
+	start := time.Now()
+	cmd := r.next.HKeys(ctx, key)
+	r.record(start, "HKEYS", keyArgs(key), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) HVals(ctx context.Context, key string) *StringSliceCmd {
+	start := time.Now()
+	cmd := r.next.HVals(ctx, key)
+	r.record(start, "HVALS", keyArgs(key), cmd)
+	return cmd
+}
+
+// 列表操作
+func (r *RedisRecorder) LPush(ctx context.Context, key string, values ...interface{}) *IntCmd {
+	start := time.Now()
+	cmd := r.next.LPush(ctx, key, values...)
+	r.record(start, "LPUSH", keyArgs(key, values...), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) RPush(ctx context.Context, key string, values ...interface{}) *IntCmd {
+	start := time.Now()
+	cmd := r.next.RPush(ctx, key, values...)
+	r.record(start, "RPUSH", keyArgs(key, values...), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) LPop(ctx context.Context, key string) *StringCmd {
+	start := time.Now()
+	cmd := r.next.LPop(ctx, key)
+	r.record(start, "LPOP", keyArgs(key), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) RPop(ctx context.Context, key string) *StringCmd {
+	start := time.Now()
+	cmd := r.next.RPop(ctx, key)
+	r.record(start, "RPOP", keyArgs(key), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) LLen(ctx context.Context, key string) *IntCmd {
+	start := time.Now()
+	cmd := r.next.LLen(ctx, key)
+	r.record(start, "LLEN", keyArgs(key), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) LRange(ctx context.Context, key string, start, stop int64) *StringSliceCmd {
+	begin := time.Now()
+	cmd := r.next.LRange(ctx, key, start, stop)
+	r.record(begin, "LRANGE", keyArgs(key, start, stop), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) LMove(ctx context.Context, source, destination, srcpos, destpos string) *StringCmd {
+	start := time.Now()
+	cmd := r.next.LMove(ctx, source, destination, srcpos, destpos)
+	r.record(start, "LMOVE", []interface{}{source, destination, srcpos, destpos}, cmd)
+	return cmd
+}
+
+// 阻塞列表操作
+func (r *RedisRecorder) BLPop(ctx context.Context, timeout time.Duration, keys ...string) *StringSliceCmd {
+	start := time.Now()
+	cmd := r.next.BLPop(ctx, timeout, keys...)
+	r.record(start, "BLPOP", append([]interface{}{durationToMs(timeout)}, stringArgs(keys)...), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) BRPop(ctx context.Context, timeout time.Duration, keys ...string) *StringSliceCmd {
+	start := time.Now()
+	cmd := r.next.BRPop(ctx, timeout, keys...)
+	r.record(start, "BRPOP", append([]interface{}{durationToMs(timeout)}, stringArgs(keys)...), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) BLMove(ctx context.Context, source, destination, srcpos, destpos string, timeout time.Duration) *StringCmd {
+	start := time.Now()
+	cmd := r.next.BLMove(ctx, source, destination, srcpos, destpos, timeout)
+	r.record(start, "BLMOVE", []interface{}{source, destination, srcpos, destpos, durationToMs(timeout)}, cmd)
+	return cmd
+}
+
+// 集合操作
+func (r *RedisRecorder) SAdd(ctx context.Context, key string, members ...interface{}) *IntCmd {
+	start := time.Now()
+	cmd := r.next.SAdd(ctx, key, members...)
+	r.record(start, "SADD", keyArgs(key, members...), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) SRem(ctx context.Context, key string, members ...interface{}) *IntCmd {
+	start := time.Now()
+	cmd := r.next.SRem(ctx, key, members...)
+	r.record(start, "SREM", keyArgs(key, members...), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) SMembers(ctx context.Context, key string) *StringSliceCmd {
+	start := time.Now()
+	cmd := r.next.SMembers(ctx, key)
+	r.record(start, "SMEMBERS", keyArgs(key), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) SIsMember(ctx context.Context, key string, member interface{}) *BoolCmd {
+	start := time.Now()
+	cmd := r.next.SIsMember(ctx, key, member)
+	r.record(start, "SISMEMBER", keyArgs(key, member), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) SCard(ctx context.Context, key string) *IntCmd {
+	start := time.Now()
+	cmd := r.next.SCard(ctx, key)
+	r.record(start, "SCARD", keyArgs(key), cmd)
+	return cmd
+}
+
+// 有序集合操作
+func (r *RedisRecorder) ZAdd(ctx context.Context, key string, members ...*Z) *IntCmd {
+	start := time.Now()
+	cmd := r.next.ZAdd(ctx, key, members...)
+	r.record(start, "ZADD", zArgs(key, members), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) ZRem(ctx context.Context, key string, members ...interface{}) *IntCmd {
+	start := time.Now()
+	cmd := r.next.ZRem(ctx, key, members...)
+	r.record(start, "ZREM", keyArgs(key, members...), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) ZRange(ctx context.Context, key string, start, stop int64) *StringSliceCmd {
+	begin := time.Now()
+	cmd := r.next.ZRange(ctx, key, start, stop)
+	r.record(begin, "ZRANGE", keyArgs(key, start, stop), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) ZRangeWithScores(ctx context.Context, key string, start, stop int64) *ZSliceCmd {
+	begin := time.Now()
+	cmd := r.next.ZRangeWithScores(ctx, key, start, stop)
+	r.record(begin, "ZRANGEWITHSCORES", keyArgs(key, start, stop), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) ZCard(ctx context.Context, key string) *IntCmd {
+	start := time.Now()
+	cmd := r.next.ZCard(ctx, key)
+	r.record(start, "ZCARD", keyArgs(key), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) ZScore(ctx context.Context, key, member string) *FloatCmd {
+	start := time.Now()
+	cmd := r.next.ZScore(ctx, key, member)
+	r.record(start, "ZSCORE", keyArgs(key, member), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) BZPopMin(ctx context.Context, timeout time.Duration, keys ...string) *ZWithKeyCmd {
+	start := time.Now()
+	cmd := r.next.BZPopMin(ctx, timeout, keys...)
+	r.record(start, "BZPOPMIN", append([]interface{}{durationToMs(timeout)}, stringArgs(keys)...), cmd)
+	return cmd
+}
+
+// 地理位置操作
+func (r *RedisRecorder) GeoAdd(ctx context.Context, key string, geoLocation ...*GeoLocation) *IntCmd {
+	start := time.Now()
+	cmd := r.next.GeoAdd(ctx, key, geoLocation...)
+	args := keyArgs(key)
+	for _, location := range geoLocation {
+		args = append(args, location.Longitude, location.Latitude, location.Name)
+	}
+	r.record(start, "GEOADD", args, cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) GeoPos(ctx context.Context, key string, members ...string) *GeoPosCmd {
+	start := time.Now()
+	cmd := r.next.GeoPos(ctx, key, members...)
+	r.record(start, "GEOPOS", keyArgs(key, stringArgs(members)...), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) GeoDist(ctx context.Context, key string, member1, member2, unit string) *FloatCmd {
+	start := time.Now()
+	cmd := r.next.GeoDist(ctx, key, member1, member2, unit)
+	r.record(start, "GEODIST", keyArgs(key, member1, member2, unit), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) GeoHash(ctx context.Context, key string, members ...string) *StringSliceCmd {
+	start := time.Now()
+	cmd := r.next.GeoHash(ctx, key, members...)
+	r.record(start, "GEOHASH", keyArgs(key, stringArgs(members)...), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) GeoRadius(ctx context.Context, key string, longitude, latitude float64, query *GeoRadiusQuery) *GeoLocationCmd {
+	start := time.Now()
+	cmd := r.next.GeoRadius(ctx, key, longitude, latitude, query)
+	r.record(start, "GEORADIUS", append(keyArgs(key, longitude, latitude), geoRadiusArgs(query)...), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) GeoRadiusByMember(ctx context.Context, key, member string, query *GeoRadiusQuery) *GeoLocationCmd {
+	start := time.Now()
+	cmd := r.next.GeoRadiusByMember(ctx, key, member, query)
+	r.record(start, "GEORADIUSBYMEMBER", append(keyArgs(key, member), geoRadiusArgs(query)...), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) GeoSearch(ctx context.Context, key string, q *GeoSearchQuery) *StringSliceCmd {
+	start := time.Now()
+	cmd := r.next.GeoSearch(ctx, key, q)
+	r.record(start, "GEOSEARCH", keyArgs(key, geoSearchArgs(q)...), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) GeoSearchLocation(ctx context.Context, key string, q *GeoSearchLocationQuery) *GeoLocationCmd {
+	start := time.Now()
+	cmd := r.next.GeoSearchLocation(ctx, key, q)
+	args := keyArgs(key, geoSearchArgs(&q.GeoSearchQuery)...)
+	args = append(args, geoWithArgs(q.WithCoord, q.WithDist, q.WithHash)...)
+	r.record(start, "GEOSEARCHLOCATION", args, cmd)
+	return cmd
+}
+
+// 流操作
+func (r *RedisRecorder) XAdd(ctx context.Context, a *XAddArgs) *StringCmd {
+	start := time.Now()
+	cmd := r.next.XAdd(ctx, a)
+	r.record(start, "XADD", xAddArgs(a), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) XLen(ctx context.Context, stream string) *IntCmd {
+	start := time.Now()
+	cmd := r.next.XLen(ctx, stream)
+	r.record(start, "XLEN", keyArgs(stream), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) XRange(ctx context.Context, stream, start, stop string) *XMessageSliceCmd {
+	begin := time.Now()
+	cmd := r.next.XRange(ctx, stream, start, stop)
+	r.record(begin, "XRANGE", keyArgs(stream, start, stop), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) XRead(ctx context.Context, a *XReadArgs) *XStreamSliceCmd {
+	start := time.Now()
+	cmd := r.next.XRead(ctx, a)
+	r.record(start, "XREAD", xReadArgs(a), cmd)
+	return cmd
+}
+
+// RedisJSON操作，值以JSON文本记录
+func (r *RedisRecorder) JSONSet(ctx context.Context, key, path string, value interface{}) *StatusCmd {
+	start := time.Now()
+	cmd := r.next.JSONSet(ctx, key, path, value)
+	r.record(start, "JSON.SET", keyArgs(key, path, jsonText(value)), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) JSONSetMode(ctx context.Context, key, path string, value interface{}, mode string) *StatusCmd {
+	start := time.Now()
+	cmd := r.next.JSONSetMode(ctx, key, path, value, mode)
+	args := keyArgs(key, path, jsonText(value))
+	if mode != "" {
+		args = append(args, mode)
+	}
+	r.record(start, "JSON.SET", args, cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) JSONGet(ctx context.Context, key string, paths ...string) *StringCmd {
+	start := time.Now()
+	cmd := r.next.JSONGet(ctx, key, paths...)
+	r.record(start, "JSON.GET", append([]interface{}{key}, stringArgs(paths)...), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) JSONDel(ctx context.Context, key, path string) *IntCmd {
+	start := time.Now()
+	cmd := r.next.JSONDel(ctx, key, path)
+	r.record(start, "JSON.DEL", keyArgs(key, path), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) JSONType(ctx context.Context, key, path string) *StringSliceCmd {
+	start := time.Now()
+	cmd := r.next.JSONType(ctx, key, path)
+	r.record(start, "JSON.TYPE", keyArgs(key, path), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) JSONArrAppend(ctx context.Context, key, path string, values ...interface{}) *IntPointerSliceCmd {
+	start := time.Now()
+	cmd := r.next.JSONArrAppend(ctx, key, path, values...)
+	args := keyArgs(key, path)
+	for _, value := range values {
+		args = append(args, jsonText(value))
+	}
+	r.record(start, "JSON.ARRAPPEND", args, cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) JSONNumIncrBy(ctx context.Context, key, path string, value float64) *StringCmd {
+	start := time.Now()
+	cmd := r.next.JSONNumIncrBy(ctx, key, path, value)
+	r.record(start, "JSON.NUMINCRBY", keyArgs(key, path, value), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) JSONObjKeys(ctx context.Context, key, path string) *SliceCmd {
+	start := time.Now()
+	cmd := r.next.JSONObjKeys(ctx, key, path)
+	r.record(start, "JSON.OBJKEYS", keyArgs(key, path), cmd)
+	return cmd
+}
+
+// 键操作
+func (r *RedisRecorder) Keys(ctx context.Context, pattern string) *StringSliceCmd {
+	start := time.Now()
+	cmd := r.next.Keys(ctx, pattern)
+	r.record(start, "KEYS", keyArgs(pattern), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) Scan(ctx context.Context, cursor uint64, match string, count int64) *ScanCmd {
+	start := time.Now()
+	cmd := r.next.Scan(ctx, cursor, match, count)
+	args := []interface{}{cursor}
+	if match != "" {
+		args = append(args, "MATCH", match)
+	}
+	if count > 0 {
+		args = append(args, "COUNT", count)
+	}
+	r.record(start, "SCAN", args, cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) Type(ctx context.Context, key string) *StatusCmd {
+	start := time.Now()
+	cmd := r.next.Type(ctx, key)
+	r.record(start, "TYPE", keyArgs(key), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) Rename(ctx context.Context, key, newKey string) *StatusCmd {
+	start := time.Now()
+	cmd := r.next.Rename(ctx, key, newKey)
+	r.record(start, "RENAME", keyArgs(key, newKey), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) Dump(ctx context.Context, key string) *StringCmd {
+	start := time.Now()
+	cmd := r.next.Dump(ctx, key)
+	r.record(start, "DUMP", keyArgs(key), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) Restore(ctx context.Context, key string, ttl time.Duration, value string) *StatusCmd {
+	start := time.Now()
+	cmd := r.next.Restore(ctx, key, ttl, value)
+	r.record(start, "RESTORE", keyArgs(key, durationToMs(ttl), value), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) RestoreReplace(ctx context.Context, key string, ttl time.Duration, value string) *StatusCmd {
+	start := time.Now()
+	cmd := r.next.RestoreReplace(ctx, key, ttl, value)
+	r.record(start, "RESTORE", keyArgs(key, durationToMs(ttl), value, "REPLACE"), cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) FlushDB(ctx context.Context) *StatusCmd {
+	start := time.Now()
+	cmd := r.next.FlushDB(ctx)
+	r.record(start, "FLUSHDB", nil, cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) FlushAll(ctx context.Context) *StatusCmd {
+	start := time.Now()
+	cmd := r.next.FlushAll(ctx)
+	r.record(start, "FLUSHALL", nil, cmd)
+	return cmd
+}
+
+// 数据库操作
+func (r *RedisRecorder) Select(ctx context.Context, index int) *StatusCmd {
+	start := time.Now()
+	cmd := r.next.Select(ctx, index)
+	r.record(start, "SELECT", []interface{}{index}, cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) DBSize(ctx context.Context) *IntCmd {
+	start := time.Now()
+	cmd := r.next.DBSize(ctx)
+	r.record(start, "DBSIZE", nil, cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) Info(ctx context.Context, sections ...string) *StringCmd {
+	start := time.Now()
+	cmd := r.next.Info(ctx, sections...)
+	args := make([]interface{}, len(sections))
+	for i, section := range sections {
+		args[i] = section
+	}
+	r.record(start, "INFO", args, cmd)
+	return cmd
+}
+
+// 集群操作
+func (r *RedisRecorder) ClusterSlots(ctx context.Context) *ClusterSlotsCmd {
+	start := time.Now()
+	cmd := r.next.ClusterSlots(ctx)
+	r.record(start, "CLUSTER", []interface{}{"SLOTS"}, cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) ClusterNodes(ctx context.Context) *StringCmd {
+	start := time.Now()
+	cmd := r.next.ClusterNodes(ctx)
+	r.record(start, "CLUSTER", []interface{}{"NODES"}, cmd)
+	return cmd
+}
+
+func (r *RedisRecorder) ClusterKeySlot(ctx context.Context, key string) *IntCmd {
+	start := time.Now()
+	cmd := r.next.ClusterKeySlot(ctx, key)
+	r.record(start, "CLUSTER", []interface{}{"KEYSLOT", key}, cmd)
+	return cmd
+}
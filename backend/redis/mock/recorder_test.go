@@ -0,0 +1,151 @@
+package mock
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRedisRecorder_RecordAndReplay(t *testing.T) {
+	var trace bytes.Buffer
+	recorder := NewRedisRecorder(NewRedisMock(), "conn1", &trace)
+	defer recorder.Close()
+	ctx := context.Background()
+
+	recorder.Set(ctx, "str", "value", time.Minute)
+	recorder.Get(ctx, "str")
+	recorder.Get(ctx, "missing")
+	recorder.HSet(ctx, "hash", "f1", "v1", "f2", "v2")
+	recorder.HGetAll(ctx, "hash")
+	recorder.RPush(ctx, "list", "a", "b", "c")
+	recorder.LRange(ctx, "list", 0, -1)
+	recorder.SAdd(ctx, "set", "x", "y")
+	recorder.SMembers(ctx, "set")
+	recorder.ZAdd(ctx, "zset", &Z{Score: 1.5, Member: "m1"}, &Z{Score: 2, Member: "m2"})
+	recorder.ZRangeWithScores(ctx, "zset", 0, -1)
+	recorder.TTL(ctx, "str")
+	recorder.Del(ctx, "str", "hash")
+	recorder.DBSize(ctx)
+
+	entries, err := ReadTrace(bytes.NewReader(trace.Bytes()))
+	if err != nil {
+		t.Fatalf("ReadTrace failed: %v", err)
+	}
+	if len(entries) != 14 {
+		t.Fatalf("Expected 14 trace entries, got %d", len(entries))
+	}
+	if entries[0].Command != "SET" || entries[0].ConnectionID != "conn1" || entries[0].Seq != 1 {
+		t.Errorf("Unexpected first entry: %+v", entries[0])
+	}
+	if entries[2].Error != "redis: nil" {
+		t.Errorf("Expected redis: nil error for missing key, got %q", entries[2].Error)
+	}
+
+	// 回放到全新的Mock，结果应当完全一致
+	replayer := NewMockReplayer()
+	defer replayer.Close()
+	diffs, err := replayer.Replay(ctx, bytes.NewReader(trace.Bytes()))
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("Expected no diffs, got %+v", diffs)
+	}
+}
+
+func TestRedisReplayer_ReportsDiffs(t *testing.T) {
+	trace := strings.Join([]string{
+		`{"connectionId":"c","seq":1,"command":"SET","args":["k","v",0],"result":"OK"}`,
+		`{"connectionId":"c","seq":2,"command":"GET","args":["k"],"result":"other"}`,
+		`{"connectionId":"c","seq":3,"command":"LLEN","args":["k"],"result":0}`,
+		`{"connectionId":"c","seq":4,"command":"LLEN","args":["k"],"error":"WRONGTYPE Operation against a key holding the wrong kind of value"}`,
+	}, "\n")
+
+	replayer := NewMockReplayer()
+	defer replayer.Close()
+	diffs, err := replayer.Replay(context.Background(), strings.NewReader(trace))
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(diffs) != 2 {
+		t.Fatalf("Expected 2 diffs, got %d: %+v", len(diffs), diffs)
+	}
+	if diffs[0].Entry.Seq != 2 || diffs[0].Result != "v" {
+		t.Errorf("Unexpected diff for GET: %+v", diffs[0])
+	}
+}
+
+func TestRedisReplayer_UnsupportedCommand(t *testing.T) {
+	replayer := NewMockReplayer()
+	defer replayer.Close()
+
+	_, err := replayer.Replay(context.Background(), strings.NewReader(`{"connectionId":"c","seq":1,"command":"NOPE","args":["k"]}`))
+	if err == nil {
+		t.Error("Expected error for unsupported command")
+	}
+}
+
+func TestRedisRecorder_BinaryValues(t *testing.T) {
+	var trace bytes.Buffer
+	recorder := NewRedisRecorder(NewRedisMock(), "conn1", &trace)
+	defer recorder.Close()
+	ctx := context.Background()
+
+	binary := "\x00\xff\x80gzip\x1f\x8b"
+	recorder.Set(ctx, "bytes", []byte(binary), 0)
+	recorder.Set(ctx, "str", binary, 0)
+	recorder.Get(ctx, "str")
+	recorder.HSet(ctx, "hash", "field", []byte(binary))
+
+	if strings.Contains(trace.String(), "\\ufffd") {
+		t.Errorf("Expected binary values not to be replaced with U+FFFD:\n%s", trace.String())
+	}
+
+	replayer := NewMockReplayer()
+	defer replayer.Close()
+	diffs, err := replayer.Replay(ctx, bytes.NewReader(trace.Bytes()))
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("Expected no diffs, got %+v", diffs)
+	}
+
+	client := replayer.Client("conn1")
+	for _, key := range []string{"bytes", "str"} {
+		if val, _ := client.Get(ctx, key).Result(); val != binary {
+			t.Errorf("%s: expected original bytes after replay, got %q", key, val)
+		}
+	}
+	if val, _ := client.HGet(ctx, "hash", "field").Result(); val != binary {
+		t.Errorf("Expected original bytes in hash after replay, got %q", val)
+	}
+}
+
+func TestRedisReplayer_SharedKeyspace(t *testing.T) {
+	// 连接共享同一个Redis服务：b能读到a写入的键，c的BLPOP被a的LPUSH唤醒；SELECT只影响执行它的连接
+	trace := strings.Join([]string{
+		`{"connectionId":"a","seq":1,"command":"SET","args":["k","db0",0],"result":"OK"}`,
+		`{"connectionId":"b","seq":1,"command":"GET","args":["k"],"result":"db0"}`,
+		`{"connectionId":"a","seq":2,"command":"LPUSH","args":["queue","job"],"result":1}`,
+		`{"connectionId":"c","seq":1,"command":"BLPOP","args":[1000,"queue"],"result":["queue","job"]}`,
+		`{"connectionId":"a","seq":3,"command":"SELECT","args":[1],"result":"OK"}`,
+		`{"connectionId":"a","seq":4,"command":"SET","args":["k","db1",0],"result":"OK"}`,
+		`{"connectionId":"b","seq":2,"command":"GET","args":["k"],"result":"db0"}`,
+		`{"connectionId":"a","seq":5,"command":"GET","args":["k"],"result":"db1"}`,
+		`{"connectionId":"b","seq":3,"command":"SELECT","args":[1],"result":"OK"}`,
+		`{"connectionId":"b","seq":4,"command":"GET","args":["k"],"result":"db1"}`,
+	}, "\n")
+
+	replayer := NewMockReplayer()
+	defer replayer.Close()
+	diffs, err := replayer.Replay(context.Background(), strings.NewReader(trace))
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("Expected no diffs, got %+v", diffs)
+	}
+}
@@ -2,18 +2,27 @@ package mock
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
 	"time"
 
+	"github.com/devtoolbox/redis/rediserr"
 	"github.com/go-redis/redis/v8"
 )
 
+// errPooledSelect 在连接池客户端上执行SELECT时返回
+var errPooledSelect = rediserr.Err("SELECT is not supported on a pooled client, connect to the target database instead")
+
 // RedisClientAdapter 真实Redis客户端适配器，实现RedisInterface接口
+// 返回的错误统一经过rediserr.FromClient转换，与RedisMock保持一致
 type RedisClientAdapter struct {
-	client *redis.Client
+	client redis.UniversalClient
 }
 
 // NewRedisClientAdapter 创建新的Redis客户端适配器
-func NewRedisClientAdapter(client *redis.Client) *RedisClientAdapter {
+func NewRedisClientAdapter(client redis.UniversalClient) *RedisClientAdapter {
 	return &RedisClientAdapter{
 		client: client,
 	}
@@ -24,7 +33,7 @@ func (r *RedisClientAdapter) Ping(ctx context.Context) *StatusCmd {
 	cmd := r.client.Ping(ctx)
 	return &StatusCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -37,7 +46,7 @@ func (r *RedisClientAdapter) Get(ctx context.Context, key string) *StringCmd {
 	cmd := r.client.Get(ctx, key)
 	return &StringCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -45,7 +54,7 @@ func (r *RedisClientAdapter) Set(ctx context.Context, key string, value interfac
 	cmd := r.client.Set(ctx, key, value, expiration)
 	return &StatusCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -53,7 +62,7 @@ func (r *RedisClientAdapter) SetNX(ctx context.Context, key string, value interf
 	cmd := r.client.SetNX(ctx, key, value, expiration)
 	return &BoolCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -61,7 +70,7 @@ func (r *RedisClientAdapter) Del(ctx context.Context, keys ...string) *IntCmd {
 	cmd := r.client.Del(ctx, keys...)
 	return &IntCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -69,7 +78,7 @@ func (r *RedisClientAdapter) Exists(ctx context.Context, keys ...string) *IntCmd
 	cmd := r.client.Exists(ctx, keys...)
 	return &IntCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -77,7 +86,7 @@ func (r *RedisClientAdapter) Expire(ctx context.Context, key string, expiration
 	cmd := r.client.Expire(ctx, key, expiration)
 	return &BoolCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -85,7 +94,222 @@ func (r *RedisClientAdapter) TTL(ctx context.Context, key string) *DurationCmd {
 	cmd := r.client.TTL(ctx, key)
 	return &DurationCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) PTTL(ctx context.Context, key string) *DurationCmd {
+	cmd := r.client.PTTL(ctx, key)
+	return &DurationCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) Persist(ctx context.Context, key string) *BoolCmd {
+	cmd := r.client.Persist(ctx, key)
+	return &BoolCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) SetEX(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd {
+	cmd := r.client.SetEX(ctx, key, value, expiration)
+	return &StatusCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+// PSetEX go-redis v8未提供PSETEX，通过Do发送
+func (r *RedisClientAdapter) PSetEX(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd {
+	cmd := r.client.Do(ctx, "psetex", key, expiration.Milliseconds(), value)
+	val, err := cmd.Text()
+	return &StatusCmd{
+		val: val,
+		err: rediserr.FromClient(err),
+	}
+}
+
+func (r *RedisClientAdapter) SetArgs(ctx context.Context, key string, value interface{}, a SetArgs) *StatusCmd {
+	cmd := r.client.SetArgs(ctx, key, value, redis.SetArgs{
+		Mode:     a.Mode,
+		TTL:      a.TTL,
+		ExpireAt: a.ExpireAt,
+		Get:      a.Get,
+		KeepTTL:  a.KeepTTL,
+	})
+	return &StatusCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) GetSet(ctx context.Context, key string, value interface{}) *StringCmd {
+	cmd := r.client.GetSet(ctx, key, value)
+	return &StringCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) GetDel(ctx context.Context, key string) *StringCmd {
+	cmd := r.client.GetDel(ctx, key)
+	return &StringCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) GetEx(ctx context.Context, key string, expiration time.Duration) *StringCmd {
+	cmd := r.client.GetEx(ctx, key, expiration)
+	return &StringCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) StrLen(ctx context.Context, key string) *IntCmd {
+	cmd := r.client.StrLen(ctx, key)
+	return &IntCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) MGet(ctx context.Context, keys ...string) *SliceCmd {
+	cmd := r.client.MGet(ctx, keys...)
+	return &SliceCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) MSet(ctx context.Context, values ...interface{}) *StatusCmd {
+	cmd := r.client.MSet(ctx, values...)
+	return &StatusCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) MSetNX(ctx context.Context, values ...interface{}) *BoolCmd {
+	cmd := r.client.MSetNX(ctx, values...)
+	return &BoolCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+// 计数器操作
+func (r *RedisClientAdapter) Incr(ctx context.Context, key string) *IntCmd {
+	cmd := r.client.Incr(ctx, key)
+	return &IntCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) IncrBy(ctx context.Context, key string, value int64) *IntCmd {
+	cmd := r.client.IncrBy(ctx, key, value)
+	return &IntCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) Decr(ctx context.Context, key string) *IntCmd {
+	cmd := r.client.Decr(ctx, key)
+	return &IntCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) DecrBy(ctx context.Context, key string, decrement int64) *IntCmd {
+	cmd := r.client.DecrBy(ctx, key, decrement)
+	return &IntCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) IncrByFloat(ctx context.Context, key string, value float64) *FloatCmd {
+	cmd := r.client.IncrByFloat(ctx, key, value)
+	return &FloatCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+// 位图操作
+func (r *RedisClientAdapter) SetBit(ctx context.Context, key string, offset int64, value int) *IntCmd {
+	cmd := r.client.SetBit(ctx, key, offset, value)
+	return &IntCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) GetBit(ctx context.Context, key string, offset int64) *IntCmd {
+	cmd := r.client.GetBit(ctx, key, offset)
+	return &IntCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) BitCount(ctx context.Context, key string, bitCount *BitCount) *IntCmd {
+	var arg *redis.BitCount
+	if bitCount != nil {
+		arg = &redis.BitCount{Start: bitCount.Start, End: bitCount.End}
+	}
+	cmd := r.client.BitCount(ctx, key, arg)
+	return &IntCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) BitPos(ctx context.Context, key string, bit int64, pos ...int64) *IntCmd {
+	cmd := r.client.BitPos(ctx, key, bit, pos...)
+	return &IntCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) BitField(ctx context.Context, key string, args ...interface{}) *IntSliceCmd {
+	cmd := r.client.BitField(ctx, key, args...)
+	return &IntSliceCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+// HyperLogLog操作
+func (r *RedisClientAdapter) PFAdd(ctx context.Context, key string, els ...interface{}) *IntCmd {
+	cmd := r.client.PFAdd(ctx, key, els...)
+	return &IntCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) PFCount(ctx context.Context, keys ...string) *IntCmd {
+	cmd := r.client.PFCount(ctx, keys...)
+	return &IntCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) PFMerge(ctx context.Context, dest string, keys ...string) *StatusCmd {
+	cmd := r.client.PFMerge(ctx, dest, keys...)
+	return &StatusCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -94,7 +318,7 @@ func (r *RedisClientAdapter) HGet(ctx context.Context, key, field string) *Strin
 	cmd := r.client.HGet(ctx, key, field)
 	return &StringCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -102,7 +326,7 @@ func (r *RedisClientAdapter) HSet(ctx context.Context, key string, values ...int
 	cmd := r.client.HSet(ctx, key, values...)
 	return &IntCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -110,7 +334,7 @@ func (r *RedisClientAdapter) HDel(ctx context.Context, key string, fields ...str
 	cmd := r.client.HDel(ctx, key, fields...)
 	return &IntCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -118,7 +342,7 @@ func (r *RedisClientAdapter) HExists(ctx context.Context, key, field string) *Bo
 	cmd := r.client.HExists(ctx, key, field)
 	return &BoolCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -126,7 +350,7 @@ func (r *RedisClientAdapter) HGetAll(ctx context.Context, key string) *StringStr
 	cmd := r.client.HGetAll(ctx, key)
 	return &StringStringMapCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -135,7 +359,7 @@ func (r *RedisClientAdapter) LPush(ctx context.Context, key string, values ...in
 	cmd := r.client.LPush(ctx, key, values...)
 	return &IntCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -143,7 +367,7 @@ func (r *RedisClientAdapter) RPush(ctx context.Context, key string, values ...in
 	cmd := r.client.RPush(ctx, key, values...)
 	return &IntCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -151,7 +375,7 @@ func (r *RedisClientAdapter) LPop(ctx context.Context, key string) *StringCmd {
 	cmd := r.client.LPop(ctx, key)
 	return &StringCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -159,7 +383,7 @@ func (r *RedisClientAdapter) RPop(ctx context.Context, key string) *StringCmd {
 	cmd := r.client.RPop(ctx, key)
 	return &StringCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -167,7 +391,7 @@ func (r *RedisClientAdapter) LLen(ctx context.Context, key string) *IntCmd {
 	cmd := r.client.LLen(ctx, key)
 	return &IntCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -175,7 +399,7 @@ func (r *RedisClientAdapter) LRange(ctx context.Context, key string, start, stop
 	cmd := r.client.LRange(ctx, key, start, stop)
 	return &StringSliceCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -184,7 +408,7 @@ func (r *RedisClientAdapter) SAdd(ctx context.Context, key string, members ...in
 	cmd := r.client.SAdd(ctx, key, members...)
 	return &IntCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -192,7 +416,7 @@ func (r *RedisClientAdapter) SRem(ctx context.Context, key string, members ...in
 	cmd := r.client.SRem(ctx, key, members...)
 	return &IntCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -200,7 +424,7 @@ func (r *RedisClientAdapter) SMembers(ctx context.Context, key string) *StringSl
 	cmd := r.client.SMembers(ctx, key)
 	return &StringSliceCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -208,7 +432,7 @@ func (r *RedisClientAdapter) SIsMember(ctx context.Context, key string, member i
 	cmd := r.client.SIsMember(ctx, key, member)
 	return &BoolCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -216,7 +440,7 @@ func (r *RedisClientAdapter) SCard(ctx context.Context, key string) *IntCmd {
 	cmd := r.client.SCard(ctx, key)
 	return &IntCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -233,7 +457,7 @@ func (r *RedisClientAdapter) ZAdd(ctx context.Context, key string, members ...*Z
 	cmd := r.client.ZAdd(ctx, key, redisMembers...)
 	return &IntCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -241,7 +465,7 @@ func (r *RedisClientAdapter) ZRem(ctx context.Context, key string, members ...in
 	cmd := r.client.ZRem(ctx, key, members...)
 	return &IntCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -249,7 +473,7 @@ func (r *RedisClientAdapter) ZRange(ctx context.Context, key string, start, stop
 	cmd := r.client.ZRange(ctx, key, start, stop)
 	return &StringSliceCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -266,7 +490,7 @@ func (r *RedisClientAdapter) ZRangeWithScores(ctx context.Context, key string, s
 	}
 	return &ZSliceCmd{
 		val: zs,
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -274,7 +498,7 @@ func (r *RedisClientAdapter) ZCard(ctx context.Context, key string) *IntCmd {
 	cmd := r.client.ZCard(ctx, key)
 	return &IntCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -282,7 +506,7 @@ func (r *RedisClientAdapter) ZScore(ctx context.Context, key, member string) *Fl
 	cmd := r.client.ZScore(ctx, key, member)
 	return &FloatCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -291,7 +515,7 @@ func (r *RedisClientAdapter) DBSize(ctx context.Context) *IntCmd {
 	cmd := r.client.DBSize(ctx)
 	return &IntCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -299,7 +523,7 @@ func (r *RedisClientAdapter) Keys(ctx context.Context, pattern string) *StringSl
 	cmd := r.client.Keys(ctx, pattern)
 	return &StringSliceCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -307,7 +531,7 @@ func (r *RedisClientAdapter) FlushDB(ctx context.Context) *StatusCmd {
 	cmd := r.client.FlushDB(ctx)
 	return &StatusCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -315,7 +539,7 @@ func (r *RedisClientAdapter) FlushAll(ctx context.Context) *StatusCmd {
 	cmd := r.client.FlushAll(ctx)
 	return &StatusCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -324,16 +548,57 @@ func (r *RedisClientAdapter) Type(ctx context.Context, key string) *StatusCmd {
 	cmd := r.client.Type(ctx, key)
 	return &StatusCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) Rename(ctx context.Context, key, newKey string) *StatusCmd {
+	cmd := r.client.Rename(ctx, key, newKey)
+	return &StatusCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) Dump(ctx context.Context, key string) *StringCmd {
+	cmd := r.client.Dump(ctx, key)
+	return &StringCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) Restore(ctx context.Context, key string, ttl time.Duration, value string) *StatusCmd {
+	cmd := r.client.Restore(ctx, key, ttl, value)
+	return &StatusCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) RestoreReplace(ctx context.Context, key string, ttl time.Duration, value string) *StatusCmd {
+	cmd := r.client.RestoreReplace(ctx, key, ttl, value)
+	return &StatusCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
 // 数据库操作
+// Select 连接池中的每个socket各自记录当前数据库，SELECT只会影响其中一个，
+// 因此不支持在适配器上切换数据库，需要改用目标数据库的客户端
 func (r *RedisClientAdapter) Select(ctx context.Context, index int) *StatusCmd {
-	cmd := r.client.Do(ctx, "SELECT", index)
-	return &StatusCmd{
-		val: cmd.Val().(string),
-		err: cmd.Err(),
+	if err := ctx.Err(); err != nil {
+		return &StatusCmd{err: err}
+	}
+	return &StatusCmd{err: errPooledSelect}
+}
+
+func (r *RedisClientAdapter) Info(ctx context.Context, sections ...string) *StringCmd {
+	cmd := r.client.Info(ctx, sections...)
+	return &StringCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -342,7 +607,7 @@ func (r *RedisClientAdapter) HKeys(ctx context.Context, key string) *StringSlice
 	cmd := r.client.HKeys(ctx, key)
 	return &StringSliceCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
 }
 
@@ -350,6 +615,442 @@ func (r *RedisClientAdapter) HVals(ctx context.Context, key string) *StringSlice
 	cmd := r.client.HVals(ctx, key)
 	return &StringSliceCmd{
 		val: cmd.Val(),
-		err: cmd.Err(),
+		err: rediserr.FromClient(cmd.Err()),
 	}
-}
\ No newline at end of file
+}
+
+// 列表移动与阻塞操作
+func (r *RedisClientAdapter) LMove(ctx context.Context, source, destination, srcpos, destpos string) *StringCmd {
+	cmd := r.client.LMove(ctx, source, destination, srcpos, destpos)
+	return &StringCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) BLPop(ctx context.Context, timeout time.Duration, keys ...string) *StringSliceCmd {
+	cmd := r.client.BLPop(ctx, timeout, keys...)
+	return &StringSliceCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) BRPop(ctx context.Context, timeout time.Duration, keys ...string) *StringSliceCmd {
+	cmd := r.client.BRPop(ctx, timeout, keys...)
+	return &StringSliceCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) BLMove(ctx context.Context, source, destination, srcpos, destpos string, timeout time.Duration) *StringCmd {
+	cmd := r.client.BLMove(ctx, source, destination, srcpos, destpos, timeout)
+	return &StringCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) BZPopMin(ctx context.Context, timeout time.Duration, keys ...string) *ZWithKeyCmd {
+	cmd := r.client.BZPopMin(ctx, timeout, keys...)
+	var val *ZWithKey
+	if redisZ := cmd.Val(); redisZ != nil {
+		val = &ZWithKey{
+			Z:   Z{Score: redisZ.Score, Member: redisZ.Member},
+			Key: redisZ.Key,
+		}
+	}
+	return &ZWithKeyCmd{
+		val: val,
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+// 地理位置操作
+func (r *RedisClientAdapter) GeoAdd(ctx context.Context, key string, geoLocation ...*GeoLocation) *IntCmd {
+	locations := make([]*redis.GeoLocation, len(geoLocation))
+	for i, location := range geoLocation {
+		locations[i] = &redis.GeoLocation{
+			Name:      location.Name,
+			Longitude: location.Longitude,
+			Latitude:  location.Latitude,
+		}
+	}
+	cmd := r.client.GeoAdd(ctx, key, locations...)
+	return &IntCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) GeoPos(ctx context.Context, key string, members ...string) *GeoPosCmd {
+	cmd := r.client.GeoPos(ctx, key, members...)
+	positions := make([]*GeoPos, len(cmd.Val()))
+	for i, pos := range cmd.Val() {
+		if pos != nil {
+			positions[i] = &GeoPos{Longitude: pos.Longitude, Latitude: pos.Latitude}
+		}
+	}
+	return &GeoPosCmd{
+		val: positions,
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) GeoDist(ctx context.Context, key string, member1, member2, unit string) *FloatCmd {
+	cmd := r.client.GeoDist(ctx, key, member1, member2, unit)
+	return &FloatCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) GeoHash(ctx context.Context, key string, members ...string) *StringSliceCmd {
+	cmd := r.client.GeoHash(ctx, key, members...)
+	return &StringSliceCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) GeoRadius(ctx context.Context, key string, longitude, latitude float64, query *GeoRadiusQuery) *GeoLocationCmd {
+	cmd := r.client.GeoRadius(ctx, key, longitude, latitude, convertGeoRadiusQuery(query))
+	return &GeoLocationCmd{
+		val: convertGeoLocations(cmd.Val()),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) GeoRadiusByMember(ctx context.Context, key, member string, query *GeoRadiusQuery) *GeoLocationCmd {
+	cmd := r.client.GeoRadiusByMember(ctx, key, member, convertGeoRadiusQuery(query))
+	return &GeoLocationCmd{
+		val: convertGeoLocations(cmd.Val()),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) GeoSearch(ctx context.Context, key string, q *GeoSearchQuery) *StringSliceCmd {
+	query := redis.GeoSearchQuery(*q)
+	cmd := r.client.GeoSearch(ctx, key, &query)
+	return &StringSliceCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) GeoSearchLocation(ctx context.Context, key string, q *GeoSearchLocationQuery) *GeoLocationCmd {
+	cmd := r.client.GeoSearchLocation(ctx, key, &redis.GeoSearchLocationQuery{
+		GeoSearchQuery: redis.GeoSearchQuery(q.GeoSearchQuery),
+		WithCoord:      q.WithCoord,
+		WithDist:       q.WithDist,
+		WithHash:       q.WithHash,
+	})
+	return &GeoLocationCmd{
+		val: convertGeoLocations(cmd.Val()),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+// convertGeoRadiusQuery 转换GEORADIUS查询参数
+func convertGeoRadiusQuery(query *GeoRadiusQuery) *redis.GeoRadiusQuery {
+	return &redis.GeoRadiusQuery{
+		Radius:      query.Radius,
+		Unit:        query.Unit,
+		WithCoord:   query.WithCoord,
+		WithDist:    query.WithDist,
+		WithGeoHash: query.WithGeoHash,
+		Count:       query.Count,
+		Sort:        query.Sort,
+	}
+}
+
+// convertGeoLocations 转换redis.GeoLocation到我们的GeoLocation结构
+func convertGeoLocations(locations []redis.GeoLocation) []GeoLocation {
+	result := make([]GeoLocation, len(locations))
+	for i, location := range locations {
+		result[i] = GeoLocation(location)
+	}
+	return result
+}
+
+// 流操作
+func (r *RedisClientAdapter) XAdd(ctx context.Context, a *XAddArgs) *StringCmd {
+	cmd := r.client.XAdd(ctx, &redis.XAddArgs{
+		Stream:     a.Stream,
+		NoMkStream: a.NoMkStream,
+		MaxLen:     a.MaxLen,
+		Approx:     a.Approx,
+		ID:         a.ID,
+		Values:     a.Values,
+	})
+	return &StringCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) XLen(ctx context.Context, stream string) *IntCmd {
+	cmd := r.client.XLen(ctx, stream)
+	return &IntCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) XRange(ctx context.Context, stream, start, stop string) *XMessageSliceCmd {
+	cmd := r.client.XRange(ctx, stream, start, stop)
+	return &XMessageSliceCmd{
+		val: convertXMessages(cmd.Val()),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) XRead(ctx context.Context, a *XReadArgs) *XStreamSliceCmd {
+	cmd := r.client.XRead(ctx, &redis.XReadArgs{
+		Streams: a.Streams,
+		Count:   a.Count,
+		Block:   a.Block,
+	})
+	// 转换redis.XStream到我们的XStream结构
+	redisStreams := cmd.Val()
+	streams := make([]XStream, len(redisStreams))
+	for i, redisStream := range redisStreams {
+		streams[i] = XStream{
+			Stream:   redisStream.Stream,
+			Messages: convertXMessages(redisStream.Messages),
+		}
+	}
+	return &XStreamSliceCmd{
+		val: streams,
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+// RedisJSON操作，go-redis v8未提供模块命令，通过Do发送
+func (r *RedisClientAdapter) JSONSet(ctx context.Context, key, path string, value interface{}) *StatusCmd {
+	return r.JSONSetMode(ctx, key, path, value, "")
+}
+
+func (r *RedisClientAdapter) JSONSetMode(ctx context.Context, key, path string, value interface{}, mode string) *StatusCmd {
+	data, err := jsonArg(value)
+	if err != nil {
+		return &StatusCmd{err: err}
+	}
+	args := []interface{}{"json.set", key, path, data}
+	if mode != "" {
+		args = append(args, mode)
+	}
+	val, err := r.client.Do(ctx, args...).Text()
+	return &StatusCmd{
+		val: val,
+		err: rediserr.FromClient(err),
+	}
+}
+
+func (r *RedisClientAdapter) JSONGet(ctx context.Context, key string, paths ...string) *StringCmd {
+	args := []interface{}{"json.get", key}
+	for _, path := range paths {
+		args = append(args, path)
+	}
+	val, err := r.client.Do(ctx, args...).Text()
+	return &StringCmd{
+		val: val,
+		err: rediserr.FromClient(err),
+	}
+}
+
+func (r *RedisClientAdapter) JSONDel(ctx context.Context, key, path string) *IntCmd {
+	val, err := r.client.Do(ctx, "json.del", key, path).Int64()
+	return &IntCmd{
+		val: val,
+		err: rediserr.FromClient(err),
+	}
+}
+
+// JSONType 旧版路径返回单个类型，JSONPath返回数组，统一转换为字符串切片
+func (r *RedisClientAdapter) JSONType(ctx context.Context, key, path string) *StringSliceCmd {
+	reply, err := r.client.Do(ctx, "json.type", key, path).Result()
+	if err != nil {
+		return &StringSliceCmd{err: rediserr.FromClient(err)}
+	}
+	var types []string
+	switch v := reply.(type) {
+	case string:
+		types = []string{v}
+	case []interface{}:
+		types = make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+	return &StringSliceCmd{val: types}
+}
+
+// JSONArrAppend 旧版路径返回单个整数，JSONPath返回可能包含nil的数组
+func (r *RedisClientAdapter) JSONArrAppend(ctx context.Context, key, path string, values ...interface{}) *IntPointerSliceCmd {
+	args := []interface{}{"json.arrappend", key, path}
+	for _, value := range values {
+		data, err := jsonArg(value)
+		if err != nil {
+			return &IntPointerSliceCmd{err: err}
+		}
+		args = append(args, data)
+	}
+	reply, err := r.client.Do(ctx, args...).Result()
+	if err != nil {
+		return &IntPointerSliceCmd{err: rediserr.FromClient(err)}
+	}
+	var lengths []*int64
+	switch v := reply.(type) {
+	case int64:
+		lengths = []*int64{&v}
+	case []interface{}:
+		lengths = make([]*int64, len(v))
+		for i, item := range v {
+			if n, ok := item.(int64); ok {
+				lengths[i] = &n
+			}
+		}
+	}
+	return &IntPointerSliceCmd{val: lengths}
+}
+
+func (r *RedisClientAdapter) JSONNumIncrBy(ctx context.Context, key, path string, value float64) *StringCmd {
+	val, err := r.client.Do(ctx, "json.numincrby", key, path, value).Text()
+	return &StringCmd{
+		val: val,
+		err: rediserr.FromClient(err),
+	}
+}
+
+func (r *RedisClientAdapter) JSONObjKeys(ctx context.Context, key, path string) *SliceCmd {
+	val, err := r.client.Do(ctx, "json.objkeys", key, path).Slice()
+	return &SliceCmd{
+		val: val,
+		err: rediserr.FromClient(err),
+	}
+}
+
+// jsonArg 将值编码为JSON文本，string和[]byte视为已编码的JSON
+func jsonArg(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("encode json value: %w", err)
+	}
+	return string(data), nil
+}
+
+// scanNodeBits 集群SCAN游标中节点游标占用的低位数，高位保存主节点下标
+const scanNodeBits = 48
+
+// Scan 集群客户端依次遍历每个主节点，返回的游标由主节点下标和节点游标组成
+func (r *RedisClientAdapter) Scan(ctx context.Context, cursor uint64, match string, count int64) *ScanCmd {
+	clusterClient, ok := r.client.(*redis.ClusterClient)
+	if !ok {
+		page, next, err := r.client.Scan(ctx, cursor, match, count).Result()
+		return &ScanCmd{
+			page:   page,
+			cursor: next,
+			err:    rediserr.FromClient(err),
+		}
+	}
+
+	masters, err := clusterMasters(ctx, clusterClient)
+	if err != nil {
+		return &ScanCmd{err: rediserr.FromClient(err)}
+	}
+	index := int(cursor >> scanNodeBits)
+	if index >= len(masters) {
+		return &ScanCmd{err: rediserr.Err("invalid cursor")}
+	}
+
+	page, next, err := masters[index].Scan(ctx, cursor&(1<<scanNodeBits-1), match, count).Result()
+	if err != nil {
+		return &ScanCmd{err: rediserr.FromClient(err)}
+	}
+	if next >= 1<<scanNodeBits {
+		return &ScanCmd{err: fmt.Errorf("node cursor %d exceeds %d bits", next, scanNodeBits)}
+	}
+	if next == 0 {
+		// 当前节点遍历完毕，转到下一个主节点
+		index++
+		if index == len(masters) {
+			return &ScanCmd{page: page, cursor: 0}
+		}
+	}
+	return &ScanCmd{page: page, cursor: uint64(index)<<scanNodeBits | next}
+}
+
+// clusterMasters 返回按地址排序的主节点客户端，保证多次SCAN时下标一致
+func clusterMasters(ctx context.Context, client *redis.ClusterClient) ([]*redis.Client, error) {
+	var mutex sync.Mutex
+	var masters []*redis.Client
+	err := client.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+		mutex.Lock()
+		defer mutex.Unlock()
+		masters = append(masters, master)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(masters, func(i, j int) bool {
+		return masters[i].Options().Addr < masters[j].Options().Addr
+	})
+	return masters, nil
+}
+
+// 集群操作
+func (r *RedisClientAdapter) ClusterSlots(ctx context.Context) *ClusterSlotsCmd {
+	cmd := r.client.ClusterSlots(ctx)
+	redisSlots := cmd.Val()
+	slots := make([]ClusterSlot, len(redisSlots))
+	for i, redisSlot := range redisSlots {
+		nodes := make([]ClusterNode, len(redisSlot.Nodes))
+		for j, node := range redisSlot.Nodes {
+			nodes[j] = ClusterNode{ID: node.ID, Addr: node.Addr}
+		}
+		slots[i] = ClusterSlot{Start: redisSlot.Start, End: redisSlot.End, Nodes: nodes}
+	}
+	return &ClusterSlotsCmd{
+		val: slots,
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) ClusterNodes(ctx context.Context) *StringCmd {
+	cmd := r.client.ClusterNodes(ctx)
+	return &StringCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+func (r *RedisClientAdapter) ClusterKeySlot(ctx context.Context, key string) *IntCmd {
+	cmd := r.client.ClusterKeySlot(ctx, key)
+	return &IntCmd{
+		val: cmd.Val(),
+		err: rediserr.FromClient(cmd.Err()),
+	}
+}
+
+// convertXMessages 转换redis.XMessage到我们的XMessage结构
+func convertXMessages(redisMessages []redis.XMessage) []XMessage {
+	messages := make([]XMessage, len(redisMessages))
+	for i, redisMessage := range redisMessages {
+		messages[i] = XMessage{
+			ID:     redisMessage.ID,
+			Values: redisMessage.Values,
+		}
+	}
+	return messages
+}
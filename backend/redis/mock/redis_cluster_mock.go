@@ -0,0 +1,1090 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/devtoolbox/redis/cluster"
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+// clusterMaxRedirects 跟随MOVED、ASK和TRYAGAIN的最大次数，与go-redis的默认值一致
+const clusterMaxRedirects = 3
+
+// clusterTryAgainDelay 收到TRYAGAIN后重试前的等待时间
+const clusterTryAgainDelay = 10 * time.Millisecond
+
+// RedisClusterMock 由多个模拟节点组成的Redis集群
+// 本身作为集群客户端实现RedisInterface：按缓存的槽位分配选择节点，并像go-redis一样跟随MOVED和ASK重定向
+type RedisClusterMock struct {
+	state *clusterState
+
+	cacheMutex sync.RWMutex
+	cache      [cluster.SlotCount]int // 客户端缓存的槽位分配，收到MOVED时更新
+
+	moved atomic.Int64
+	asked atomic.Int64
+}
+
+// NewRedisClusterMock 创建包含shards个主节点的模拟集群，槽位平均分配
+func NewRedisClusterMock(shards int) *RedisClusterMock {
+	if shards < 1 {
+		shards = 1
+	}
+	c := &RedisClusterMock{state: newClusterState(shards)}
+	c.cache = c.state.owners
+	return c
+}
+
+// Nodes 返回所有节点，按地址排序
+func (c *RedisClusterMock) Nodes() []*RedisClusterNode {
+	return append([]*RedisClusterNode(nil), c.state.nodes...)
+}
+
+// Node 返回指定下标的节点
+func (c *RedisClusterMock) Node(index int) *RedisClusterNode {
+	return c.state.nodes[index]
+}
+
+// SlotOwner 返回当前负责槽位的节点下标
+func (c *RedisClusterMock) SlotOwner(slot int) int {
+	owner, _ := c.state.slotState(slot)
+	return owner
+}
+
+// Redirects 返回客户端已跟随的MOVED和ASK次数
+func (c *RedisClusterMock) Redirects() (moved, asked int64) {
+	return c.moved.Load(), c.asked.Load()
+}
+
+// BeginMigration 开始把槽位迁移到target节点
+// 迁移期间源节点仍负责已有的键，不存在的键返回ASK
+func (c *RedisClusterMock) BeginMigration(slot, target int) error {
+	if slot < 0 || slot >= cluster.SlotCount {
+		return fmt.Errorf("invalid slot %d", slot)
+	}
+	if target < 0 || target >= len(c.state.nodes) {
+		return fmt.Errorf("invalid node index %d", target)
+	}
+
+	c.state.mutex.Lock()
+	defer c.state.mutex.Unlock()
+
+	if c.state.owners[slot] == target {
+		return fmt.Errorf("slot %d is already served by node %d", slot, target)
+	}
+	if _, migrating := c.state.migrating[slot]; migrating {
+		return fmt.Errorf("slot %d is already migrating", slot)
+	}
+	c.state.migrating[slot] = target
+	return nil
+}
+
+// MigrateKey 把迁移中槽位的一个键移动到目标节点，相当于MIGRATE
+func (c *RedisClusterMock) MigrateKey(key string) error {
+	slot := cluster.Slot(key)
+
+	c.state.mutex.Lock()
+	defer c.state.mutex.Unlock()
+
+	target, migrating := c.state.migrating[slot]
+	if !migrating {
+		return fmt.Errorf("slot %d is not migrating", slot)
+	}
+	source := c.state.owners[slot]
+	values := c.state.nodes[source].next.takeKeys(func(k string) bool { return k == key })
+	c.state.nodes[target].next.putKeys(values)
+	return nil
+}
+
+// FinishMigration 移动槽位中剩余的键并把槽位分配给目标节点，之后源节点对该槽位返回MOVED
+func (c *RedisClusterMock) FinishMigration(slot int) error {
+	c.state.mutex.Lock()
+	defer c.state.mutex.Unlock()
+
+	target, migrating := c.state.migrating[slot]
+	if !migrating {
+		return fmt.Errorf("slot %d is not migrating", slot)
+	}
+	source := c.state.owners[slot]
+	values := c.state.nodes[source].next.takeKeys(func(k string) bool { return cluster.Slot(k) == slot })
+	c.state.nodes[target].next.putKeys(values)
+	c.state.owners[slot] = target
+	delete(c.state.migrating, slot)
+	return nil
+}
+
+// do 在负责keys的节点上执行fn，跟随MOVED、ASK并在TRYAGAIN后重试
+// 没有键的命令在第一个节点上执行
+func (c *RedisClusterMock) do(ctx context.Context, fn func(n *RedisClusterNode) error, keys ...string) {
+	node := c.state.nodes[0]
+	if len(keys) > 0 {
+		c.cacheMutex.RLock()
+		node = c.state.nodes[c.cache[cluster.Slot(keys[0])]]
+		c.cacheMutex.RUnlock()
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := fn(node)
+		var redisErr *rediserr.Error
+		if attempt == clusterMaxRedirects || !errors.As(err, &redisErr) {
+			return
+		}
+
+		switch redisErr.Code {
+		case "MOVED", "ASK":
+			slot, target, ok := c.parseRedirect(redisErr.Message)
+			if !ok {
+				return
+			}
+			if redisErr.Code == "ASK" {
+				c.asked.Add(1)
+				node = target.Asking()
+				continue
+			}
+			c.moved.Add(1)
+			c.cacheMutex.Lock()
+			c.cache[slot] = target.index
+			c.cacheMutex.Unlock()
+			node = target
+		case "TRYAGAIN":
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(clusterTryAgainDelay):
+			}
+		default:
+			return
+		}
+	}
+}
+
+// parseRedirect 解析MOVED和ASK错误中的"<slot> <addr>"
+func (c *RedisClusterMock) parseRedirect(message string) (int, *RedisClusterNode, bool) {
+	fields := strings.Fields(message)
+	if len(fields) != 2 {
+		return 0, nil, false
+	}
+	var slot int
+	if _, err := fmt.Sscan(fields[0], &slot); err != nil || slot < 0 || slot >= cluster.SlotCount {
+		return 0, nil, false
+	}
+	node := c.state.nodeByAddr(fields[1])
+	return slot, node, node != nil
+}
+
+// 基础操作
+func (c *RedisClusterMock) Ping(ctx context.Context) *StatusCmd {
+	var cmd *StatusCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.Ping(ctx)
+		return cmd.Err()
+	})
+	return cmd
+}
+
+// Close 关闭所有节点
+func (c *RedisClusterMock) Close() error {
+	var firstErr error
+	for _, node := range c.state.nodes {
+		if err := node.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Keys 合并所有节点的结果
+func (c *RedisClusterMock) Keys(ctx context.Context, pattern string) *StringSliceCmd {
+	keys := []string{}
+	for _, node := range c.state.nodes {
+		nodeKeys, err := node.Keys(ctx, pattern).Result()
+		if err != nil {
+			return &StringSliceCmd{err: err}
+		}
+		keys = append(keys, nodeKeys...)
+	}
+	sort.Strings(keys)
+	return &StringSliceCmd{val: keys}
+}
+
+// Scan 依次遍历每个节点，游标由节点下标和节点游标组成，与RedisClientAdapter一致
+func (c *RedisClusterMock) Scan(ctx context.Context, cursor uint64, match string, count int64) *ScanCmd {
+	index := int(cursor >> scanNodeBits)
+	if index >= len(c.state.nodes) {
+		return &ScanCmd{err: rediserr.Err("invalid cursor")}
+	}
+
+	page, next, err := c.state.nodes[index].Scan(ctx, cursor&(1<<scanNodeBits-1), match, count).Result()
+	if err != nil {
+		return &ScanCmd{err: err}
+	}
+	if next == 0 {
+		index++
+		if index == len(c.state.nodes) {
+			return &ScanCmd{page: page, cursor: 0}
+		}
+	}
+	return &ScanCmd{page: page, cursor: uint64(index)<<scanNodeBits | next}
+}
+
+// FlushDB 清空所有节点
+func (c *RedisClusterMock) FlushDB(ctx context.Context) *StatusCmd {
+	for _, node := range c.state.nodes {
+		if cmd := node.FlushDB(ctx); cmd.Err() != nil {
+			return cmd
+		}
+	}
+	return &StatusCmd{val: "OK"}
+}
+
+// FlushAll 清空所有节点
+func (c *RedisClusterMock) FlushAll(ctx context.Context) *StatusCmd {
+	for _, node := range c.state.nodes {
+		if cmd := node.FlushAll(ctx); cmd.Err() != nil {
+			return cmd
+		}
+	}
+	return &StatusCmd{val: "OK"}
+}
+
+// DBSize 所有节点的键数量之和
+func (c *RedisClusterMock) DBSize(ctx context.Context) *IntCmd {
+	var total int64
+	for _, node := range c.state.nodes {
+		size, err := node.DBSize(ctx).Result()
+		if err != nil {
+			return &IntCmd{err: err}
+		}
+		total += size
+	}
+	return &IntCmd{val: total}
+}
+
+func (c *RedisClusterMock) Select(ctx context.Context, db int) *StatusCmd {
+	var cmd *StatusCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.Select(ctx, db)
+		return cmd.Err()
+	})
+	return cmd
+}
+
+// Info 没有键的命令在第一个节点上执行，keyspace只包含该节点的键
+func (c *RedisClusterMock) Info(ctx context.Context, sections ...string) *StringCmd {
+	var cmd *StringCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.Info(ctx, sections...)
+		return cmd.Err()
+	})
+	return cmd
+}
+
+// 集群操作
+func (c *RedisClusterMock) ClusterSlots(ctx context.Context) *ClusterSlotsCmd {
+	var cmd *ClusterSlotsCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.ClusterSlots(ctx)
+		return cmd.Err()
+	})
+	return cmd
+}
+
+func (c *RedisClusterMock) ClusterNodes(ctx context.Context) *StringCmd {
+	var cmd *StringCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.ClusterNodes(ctx)
+		return cmd.Err()
+	})
+	return cmd
+}
+
+func (c *RedisClusterMock) ClusterKeySlot(ctx context.Context, key string) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.ClusterKeySlot(ctx, key)
+		return cmd.Err()
+	})
+	return cmd
+}
+
+// 键操作，按槽位路由到负责的节点
+func (c *RedisClusterMock) Get(ctx context.Context, key string) *StringCmd {
+	var cmd *StringCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.Get(ctx, key)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd {
+	var cmd *StatusCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.Set(ctx, key, value, expiration)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *BoolCmd {
+	var cmd *BoolCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.SetNX(ctx, key, value, expiration)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) Del(ctx context.Context, keys ...string) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.Del(ctx, keys...)
+		return cmd.Err()
+	}, keys...)
+	return cmd
+}
+
+func (c *RedisClusterMock) Exists(ctx context.Context, keys ...string) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.Exists(ctx, keys...)
+		return cmd.Err()
+	}, keys...)
+	return cmd
+}
+
+func (c *RedisClusterMock) Expire(ctx context.Context, key string, expiration time.Duration) *BoolCmd {
+	var cmd *BoolCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.Expire(ctx, key, expiration)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) TTL(ctx context.Context, key string) *DurationCmd {
+	var cmd *DurationCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.TTL(ctx, key)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) PTTL(ctx context.Context, key string) *DurationCmd {
+	var cmd *DurationCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.PTTL(ctx, key)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) Persist(ctx context.Context, key string) *BoolCmd {
+	var cmd *BoolCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.Persist(ctx, key)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) SetEX(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd {
+	var cmd *StatusCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.SetEX(ctx, key, value, expiration)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) PSetEX(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd {
+	var cmd *StatusCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.PSetEX(ctx, key, value, expiration)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) SetArgs(ctx context.Context, key string, value interface{}, a SetArgs) *StatusCmd {
+	var cmd *StatusCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.SetArgs(ctx, key, value, a)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) GetSet(ctx context.Context, key string, value interface{}) *StringCmd {
+	var cmd *StringCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.GetSet(ctx, key, value)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) GetDel(ctx context.Context, key string) *StringCmd {
+	var cmd *StringCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.GetDel(ctx, key)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) GetEx(ctx context.Context, key string, expiration time.Duration) *StringCmd {
+	var cmd *StringCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.GetEx(ctx, key, expiration)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) StrLen(ctx context.Context, key string) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.StrLen(ctx, key)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) MGet(ctx context.Context, keys ...string) *SliceCmd {
+	var cmd *SliceCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.MGet(ctx, keys...)
+		return cmd.Err()
+	}, keys...)
+	return cmd
+}
+
+func (c *RedisClusterMock) MSet(ctx context.Context, values ...interface{}) *StatusCmd {
+	var cmd *StatusCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.MSet(ctx, values...)
+		return cmd.Err()
+	}, pairKeys(values)...)
+	return cmd
+}
+
+func (c *RedisClusterMock) MSetNX(ctx context.Context, values ...interface{}) *BoolCmd {
+	var cmd *BoolCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.MSetNX(ctx, values...)
+		return cmd.Err()
+	}, pairKeys(values)...)
+	return cmd
+}
+
+func (c *RedisClusterMock) Incr(ctx context.Context, key string) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.Incr(ctx, key)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) IncrBy(ctx context.Context, key string, value int64) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.IncrBy(ctx, key, value)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) Decr(ctx context.Context, key string) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.Decr(ctx, key)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) DecrBy(ctx context.Context, key string, decrement int64) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.DecrBy(ctx, key, decrement)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) IncrByFloat(ctx context.Context, key string, value float64) *FloatCmd {
+	var cmd *FloatCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.IncrByFloat(ctx, key, value)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) SetBit(ctx context.Context, key string, offset int64, value int) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.SetBit(ctx, key, offset, value)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) GetBit(ctx context.Context, key string, offset int64) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.GetBit(ctx, key, offset)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) BitCount(ctx context.Context, key string, bitCount *BitCount) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.BitCount(ctx, key, bitCount)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) BitPos(ctx context.Context, key string, bit int64, pos ...int64) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.BitPos(ctx, key, bit, pos...)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) BitField(ctx context.Context, key string, args ...interface{}) *IntSliceCmd {
+	var cmd *IntSliceCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.BitField(ctx, key, args...)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) PFAdd(ctx context.Context, key string, els ...interface{}) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.PFAdd(ctx, key, els...)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) PFCount(ctx context.Context, keys ...string) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.PFCount(ctx, keys...)
+		return cmd.Err()
+	}, keys...)
+	return cmd
+}
+
+func (c *RedisClusterMock) PFMerge(ctx context.Context, dest string, keys ...string) *StatusCmd {
+	var cmd *StatusCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.PFMerge(ctx, dest, keys...)
+		return cmd.Err()
+	}, append([]string{dest}, keys...)...)
+	return cmd
+}
+
+func (c *RedisClusterMock) HGet(ctx context.Context, key, field string) *StringCmd {
+	var cmd *StringCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.HGet(ctx, key, field)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) HSet(ctx context.Context, key string, values ...interface{}) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.HSet(ctx, key, values...)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) HDel(ctx context.Context, key string, fields ...string) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.HDel(ctx, key, fields...)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) HExists(ctx context.Context, key, field string) *BoolCmd {
+	var cmd *BoolCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.HExists(ctx, key, field)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) HGetAll(ctx context.Context, key string) *StringStringMapCmd {
+	var cmd *StringStringMapCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.HGetAll(ctx, key)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) HKeys(ctx context.Context, key string) *StringSliceCmd {
+	var cmd *StringSliceCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.HKeys(ctx, key)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) HVals(ctx context.Context, key string) *StringSliceCmd {
+	var cmd *StringSliceCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.HVals(ctx, key)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) LPush(ctx context.Context, key string, values ...interface{}) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.LPush(ctx, key, values...)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) RPush(ctx context.Context, key string, values ...interface{}) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.RPush(ctx, key, values...)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) LPop(ctx context.Context, key string) *StringCmd {
+	var cmd *StringCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.LPop(ctx, key)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) RPop(ctx context.Context, key string) *StringCmd {
+	var cmd *StringCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.RPop(ctx, key)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) LLen(ctx context.Context, key string) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.LLen(ctx, key)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) LRange(ctx context.Context, key string, start, stop int64) *StringSliceCmd {
+	var cmd *StringSliceCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.LRange(ctx, key, start, stop)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) LMove(ctx context.Context, source, destination, srcpos, destpos string) *StringCmd {
+	var cmd *StringCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.LMove(ctx, source, destination, srcpos, destpos)
+		return cmd.Err()
+	}, source, destination)
+	return cmd
+}
+
+func (c *RedisClusterMock) BLPop(ctx context.Context, timeout time.Duration, keys ...string) *StringSliceCmd {
+	var cmd *StringSliceCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.BLPop(ctx, timeout, keys...)
+		return cmd.Err()
+	}, keys...)
+	return cmd
+}
+
+func (c *RedisClusterMock) BRPop(ctx context.Context, timeout time.Duration, keys ...string) *StringSliceCmd {
+	var cmd *StringSliceCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.BRPop(ctx, timeout, keys...)
+		return cmd.Err()
+	}, keys...)
+	return cmd
+}
+
+func (c *RedisClusterMock) BLMove(ctx context.Context, source, destination, srcpos, destpos string, timeout time.Duration) *StringCmd {
+	var cmd *StringCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.BLMove(ctx, source, destination, srcpos, destpos, timeout)
+		return cmd.Err()
+	}, source, destination)
+	return cmd
+}
+
+func (c *RedisClusterMock) SAdd(ctx context.Context, key string, members ...interface{}) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.SAdd(ctx, key, members...)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) SRem(ctx context.Context, key string, members ...interface{}) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.SRem(ctx, key, members...)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) SMembers(ctx context.Context, key string) *StringSliceCmd {
+	var cmd *StringSliceCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.SMembers(ctx, key)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) SIsMember(ctx context.Context, key string, member interface{}) *BoolCmd {
+	var cmd *BoolCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.SIsMember(ctx, key, member)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) SCard(ctx context.Context, key string) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.SCard(ctx, key)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) ZAdd(ctx context.Context, key string, members ...*Z) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.ZAdd(ctx, key, members...)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) ZRem(ctx context.Context, key string, members ...interface{}) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.ZRem(ctx, key, members...)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) ZRange(ctx context.Context, key string, start, stop int64) *StringSliceCmd {
+	var cmd *StringSliceCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.ZRange(ctx, key, start, stop)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) ZRangeWithScores(ctx context.Context, key string, start, stop int64) *ZSliceCmd {
+	var cmd *ZSliceCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.ZRangeWithScores(ctx, key, start, stop)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) ZCard(ctx context.Context, key string) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.ZCard(ctx, key)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) ZScore(ctx context.Context, key, member string) *FloatCmd {
+	var cmd *FloatCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.ZScore(ctx, key, member)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) BZPopMin(ctx context.Context, timeout time.Duration, keys ...string) *ZWithKeyCmd {
+	var cmd *ZWithKeyCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.BZPopMin(ctx, timeout, keys...)
+		return cmd.Err()
+	}, keys...)
+	return cmd
+}
+
+func (c *RedisClusterMock) GeoAdd(ctx context.Context, key string, geoLocation ...*GeoLocation) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.GeoAdd(ctx, key, geoLocation...)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) GeoPos(ctx context.Context, key string, members ...string) *GeoPosCmd {
+	var cmd *GeoPosCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.GeoPos(ctx, key, members...)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) GeoDist(ctx context.Context, key string, member1, member2, unit string) *FloatCmd {
+	var cmd *FloatCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.GeoDist(ctx, key, member1, member2, unit)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) GeoHash(ctx context.Context, key string, members ...string) *StringSliceCmd {
+	var cmd *StringSliceCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.GeoHash(ctx, key, members...)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) GeoRadius(ctx context.Context, key string, longitude, latitude float64, query *GeoRadiusQuery) *GeoLocationCmd {
+	var cmd *GeoLocationCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.GeoRadius(ctx, key, longitude, latitude, query)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) GeoRadiusByMember(ctx context.Context, key, member string, query *GeoRadiusQuery) *GeoLocationCmd {
+	var cmd *GeoLocationCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.GeoRadiusByMember(ctx, key, member, query)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) GeoSearch(ctx context.Context, key string, q *GeoSearchQuery) *StringSliceCmd {
+	var cmd *StringSliceCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.GeoSearch(ctx, key, q)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) GeoSearchLocation(ctx context.Context, key string, q *GeoSearchLocationQuery) *GeoLocationCmd {
+	var cmd *GeoLocationCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.GeoSearchLocation(ctx, key, q)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) XAdd(ctx context.Context, a *XAddArgs) *StringCmd {
+	var cmd *StringCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.XAdd(ctx, a)
+		return cmd.Err()
+	}, a.Stream)
+	return cmd
+}
+
+func (c *RedisClusterMock) XLen(ctx context.Context, stream string) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.XLen(ctx, stream)
+		return cmd.Err()
+	}, stream)
+	return cmd
+}
+
+func (c *RedisClusterMock) XRange(ctx context.Context, stream, start, stop string) *XMessageSliceCmd {
+	var cmd *XMessageSliceCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.XRange(ctx, stream, start, stop)
+		return cmd.Err()
+	}, stream)
+	return cmd
+}
+
+func (c *RedisClusterMock) XRead(ctx context.Context, a *XReadArgs) *XStreamSliceCmd {
+	var cmd *XStreamSliceCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.XRead(ctx, a)
+		return cmd.Err()
+	}, xReadKeys(a)...)
+	return cmd
+}
+
+func (c *RedisClusterMock) JSONSet(ctx context.Context, key, path string, value interface{}) *StatusCmd {
+	var cmd *StatusCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.JSONSet(ctx, key, path, value)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) JSONSetMode(ctx context.Context, key, path string, value interface{}, mode string) *StatusCmd {
+	var cmd *StatusCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.JSONSetMode(ctx, key, path, value, mode)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) JSONGet(ctx context.Context, key string, paths ...string) *StringCmd {
+	var cmd *StringCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.JSONGet(ctx, key, paths...)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) JSONDel(ctx context.Context, key, path string) *IntCmd {
+	var cmd *IntCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.JSONDel(ctx, key, path)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) JSONType(ctx context.Context, key, path string) *StringSliceCmd {
+	var cmd *StringSliceCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.JSONType(ctx, key, path)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) JSONArrAppend(ctx context.Context, key, path string, values ...interface{}) *IntPointerSliceCmd {
+	var cmd *IntPointerSliceCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.JSONArrAppend(ctx, key, path, values...)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) JSONNumIncrBy(ctx context.Context, key, path string, value float64) *StringCmd {
+	var cmd *StringCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.JSONNumIncrBy(ctx, key, path, value)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) JSONObjKeys(ctx context.Context, key, path string) *SliceCmd {
+	var cmd *SliceCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.JSONObjKeys(ctx, key, path)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) Type(ctx context.Context, key string) *StatusCmd {
+	var cmd *StatusCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.Type(ctx, key)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) Rename(ctx context.Context, key, newKey string) *StatusCmd {
+	var cmd *StatusCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.Rename(ctx, key, newKey)
+		return cmd.Err()
+	}, key, newKey)
+	return cmd
+}
+
+func (c *RedisClusterMock) Dump(ctx context.Context, key string) *StringCmd {
+	var cmd *StringCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.Dump(ctx, key)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) Restore(ctx context.Context, key string, ttl time.Duration, value string) *StatusCmd {
+	var cmd *StatusCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.Restore(ctx, key, ttl, value)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
+
+func (c *RedisClusterMock) RestoreReplace(ctx context.Context, key string, ttl time.Duration, value string) *StatusCmd {
+	var cmd *StatusCmd
+	c.do(ctx, func(n *RedisClusterNode) error {
+		cmd = n.RestoreReplace(ctx, key, ttl, value)
+		return cmd.Err()
+	}, key)
+	return cmd
+}
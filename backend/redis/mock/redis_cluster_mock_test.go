@@ -0,0 +1,173 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/devtoolbox/redis/cluster"
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+func TestRedisClusterNode_Routing(t *testing.T) {
+	c := NewRedisClusterMock(3)
+	defer c.Close()
+	ctx := context.Background()
+
+	// foo在12182号槽位（第3个节点），bar在5061号槽位（第1个节点）
+	var node RedisInterface = c.Node(0)
+	err := node.Get(ctx, "foo").Err()
+	if !errors.Is(err, rediserr.Moved) || err.Error() != "MOVED 12182 127.0.0.1:30003" {
+		t.Errorf("Expected MOVED to third node, got %v", err)
+	}
+	if err := node.Set(ctx, "bar", "1", 0).Err(); err != nil {
+		t.Errorf("Expected node to serve its own slot, got %v", err)
+	}
+	if err := node.MGet(ctx, "bar", "user:1000").Err(); !errors.Is(err, rediserr.CrossSlot) {
+		t.Errorf("Expected CROSSSLOT, got %v", err)
+	}
+	if slot := node.ClusterKeySlot(ctx, "foo").Val(); slot != 12182 {
+		t.Errorf("Expected slot 12182, got %d", slot)
+	}
+	if err := node.Select(ctx, 1).Err(); err == nil {
+		t.Error("Expected SELECT to be rejected in cluster mode")
+	}
+
+	want := []ClusterSlot{
+		{Start: 0, End: 5460, Nodes: []ClusterNode{{ID: c.Node(0).ID(), Addr: "127.0.0.1:30001"}}},
+		{Start: 5461, End: 10922, Nodes: []ClusterNode{{ID: c.Node(1).ID(), Addr: "127.0.0.1:30002"}}},
+		{Start: 10923, End: 16383, Nodes: []ClusterNode{{ID: c.Node(2).ID(), Addr: "127.0.0.1:30003"}}},
+	}
+	if slots := node.ClusterSlots(ctx).Val(); !reflect.DeepEqual(slots, want) {
+		t.Errorf("Unexpected slots: %+v", slots)
+	}
+}
+
+func TestRedisClusterMock_Client(t *testing.T) {
+	c := NewRedisClusterMock(3)
+	defer c.Close()
+	ctx := context.Background()
+	var client RedisInterface = c
+
+	for i := 0; i < 30; i++ {
+		client.Set(ctx, fmt.Sprintf("key:%02d", i), i, 0)
+	}
+	for i, node := range c.Nodes() {
+		if size := node.DBSize(ctx).Val(); size == 0 {
+			t.Errorf("Expected keys on node %d", i)
+		}
+	}
+	if size := client.DBSize(ctx).Val(); size != 30 {
+		t.Errorf("Expected 30 keys, got %d", size)
+	}
+	if value := client.Get(ctx, "key:07").Val(); value != "7" {
+		t.Errorf("Expected 7, got %q", value)
+	}
+
+	// 同一hashtag的键位于同一槽位，可以一起操作
+	if err := client.MSet(ctx, "{user1000}.name", "Alice", "{user1000}.age", "30").Err(); err != nil {
+		t.Fatalf("MSet with hashtag failed: %v", err)
+	}
+	if values := client.MGet(ctx, "{user1000}.name", "{user1000}.age").Val(); !reflect.DeepEqual(values, []interface{}{"Alice", "30"}) {
+		t.Errorf("Unexpected MGet result: %v", values)
+	}
+	if err := client.MGet(ctx, "key:01", "key:02").Err(); !errors.Is(err, rediserr.CrossSlot) {
+		t.Errorf("Expected CROSSSLOT, got %v", err)
+	}
+	if err := client.Select(ctx, 2).Err(); err == nil {
+		t.Error("Expected SELECT to be rejected in cluster mode")
+	}
+
+	var keys []string
+	var cursor uint64
+	for {
+		page, next, err := client.Scan(ctx, cursor, "key:*", 7).Result()
+		if err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+		keys = append(keys, page...)
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+	if len(keys) != 30 {
+		t.Errorf("Expected to scan 30 keys across nodes, got %d", len(keys))
+	}
+	if all := client.Keys(ctx, "*").Val(); len(all) != 32 {
+		t.Errorf("Expected 32 keys, got %d", len(all))
+	}
+}
+
+func TestRedisClusterMock_Migration(t *testing.T) {
+	c := NewRedisClusterMock(3)
+	defer c.Close()
+	ctx := context.Background()
+
+	slot := cluster.Slot("foo")
+	c.Set(ctx, "foo", "v1", 0)
+	if err := c.BeginMigration(slot, 0); err != nil {
+		t.Fatalf("BeginMigration failed: %v", err)
+	}
+	if err := c.BeginMigration(slot, 1); err == nil {
+		t.Error("Expected error for slot already migrating")
+	}
+
+	// 已有的键仍由源节点负责，新键通过ASK写入目标节点
+	if value := c.Get(ctx, "foo").Val(); value != "v1" {
+		t.Errorf("Expected v1 from source node, got %q", value)
+	}
+	if err := c.Set(ctx, "{foo}new", "v2", 0).Err(); err != nil {
+		t.Fatalf("Set during migration failed: %v", err)
+	}
+	if moved, asked := c.Redirects(); moved != 0 || asked != 1 {
+		t.Errorf("Expected one ASK redirect, got moved=%d asked=%d", moved, asked)
+	}
+	if err := c.Node(2).Get(ctx, "{foo}new").Err(); !errors.Is(err, rediserr.Ask) {
+		t.Errorf("Expected ASK from source node, got %v", err)
+	}
+	if err := c.Node(0).Get(ctx, "{foo}new").Err(); !errors.Is(err, rediserr.Moved) {
+		t.Errorf("Expected MOVED from target node without ASKING, got %v", err)
+	}
+	if value := c.Node(0).Asking().Get(ctx, "{foo}new").Val(); value != "v2" {
+		t.Errorf("Expected v2 from target node with ASKING, got %q", value)
+	}
+	if err := c.MGet(ctx, "foo", "{foo}new").Err(); !errors.Is(err, rediserr.TryAgain) {
+		t.Errorf("Expected TRYAGAIN for keys split by migration, got %v", err)
+	}
+
+	nodes, err := cluster.ParseNodes(c.Node(2).ClusterNodes(ctx).Val())
+	if err != nil {
+		t.Fatalf("ParseNodes failed: %v", err)
+	}
+	if len(nodes) != 3 || !nodes[2].IsMyself() || !strings.Contains(c.Node(2).ClusterNodes(ctx).Val(), fmt.Sprintf("[%d->-%s]", slot, c.Node(0).ID())) {
+		t.Errorf("Unexpected cluster nodes: %+v", nodes)
+	}
+
+	if err := c.MigrateKey("foo"); err != nil {
+		t.Fatalf("MigrateKey failed: %v", err)
+	}
+	if values := c.MGet(ctx, "foo", "{foo}new").Val(); !reflect.DeepEqual(values, []interface{}{"v1", "v2"}) {
+		t.Errorf("Unexpected values after migrating key: %v", values)
+	}
+
+	if err := c.FinishMigration(slot); err != nil {
+		t.Fatalf("FinishMigration failed: %v", err)
+	}
+	if owner := c.SlotOwner(slot); owner != 0 {
+		t.Errorf("Expected slot to belong to node 0, got %d", owner)
+	}
+	if value := c.Get(ctx, "foo").Val(); value != "v1" {
+		t.Errorf("Expected v1 after migration, got %q", value)
+	}
+	c.Get(ctx, "foo")
+	if moved, _ := c.Redirects(); moved != 1 {
+		t.Errorf("Expected slot cache to be updated after one MOVED, got %d", moved)
+	}
+	if err := c.FinishMigration(slot); err == nil {
+		t.Error("Expected error for slot not migrating")
+	}
+}
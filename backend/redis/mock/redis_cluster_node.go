@@ -0,0 +1,907 @@
+package mock
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devtoolbox/redis/cluster"
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+// 集群模拟返回的Redis错误
+var (
+	errCrossSlot     = rediserr.New("CROSSSLOT", "Keys in request don't hash to the same slot")
+	errTryAgain      = rediserr.New("TRYAGAIN", "Multiple keys request during rehashing of slot")
+	errClusterSelect = rediserr.Err("SELECT is not allowed in cluster mode")
+)
+
+// clusterBasePort 模拟节点的起始端口，与redis的create-cluster脚本一致
+const clusterBasePort = 30001
+
+// clusterState 模拟集群的槽位分配与迁移状态，由所有节点共享
+type clusterState struct {
+	mutex     sync.RWMutex
+	nodes     []*RedisClusterNode
+	owners    [cluster.SlotCount]int
+	migrating map[int]int // 槽位 -> 迁入节点下标
+}
+
+// newClusterState 创建shards个节点，按redis-cli的方式平均分配槽位
+func newClusterState(shards int) *clusterState {
+	state := &clusterState{migrating: make(map[int]int)}
+	for i := 0; i < shards; i++ {
+		addr := fmt.Sprintf("127.0.0.1:%d", clusterBasePort+i)
+		sum := sha1.Sum([]byte(addr))
+		state.nodes = append(state.nodes, &RedisClusterNode{
+			state: state,
+			index: i,
+			id:    hex.EncodeToString(sum[:]),
+			addr:  addr,
+			next:  NewRedisMock(),
+		})
+
+		start := int(math.Round(float64(i*cluster.SlotCount) / float64(shards)))
+		end := int(math.Round(float64((i+1)*cluster.SlotCount) / float64(shards)))
+		for slot := start; slot < end; slot++ {
+			state.owners[slot] = i
+		}
+	}
+	return state
+}
+
+// slotState 返回槽位所属节点的下标，以及正在迁入的节点下标（未迁移时为-1）
+func (s *clusterState) slotState(slot int) (owner, target int) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	target, migrating := s.migrating[slot]
+	if !migrating {
+		target = -1
+	}
+	return s.owners[slot], target
+}
+
+// nodeByAddr 根据地址查找节点
+func (s *clusterState) nodeByAddr(addr string) *RedisClusterNode {
+	for _, node := range s.nodes {
+		if node.addr == addr {
+			return node
+		}
+	}
+	return nil
+}
+
+// redirect 构造MOVED或ASK错误，格式为"<slot> <addr>"
+func (s *clusterState) redirect(code string, slot, index int) error {
+	return rediserr.New(code, fmt.Sprintf("%d %s", slot, s.nodes[index].addr))
+}
+
+// RedisClusterNode 模拟集群中的一个主节点
+// 只执行属于本节点槽位的命令，其余命令按Redis的规则返回MOVED、ASK、CROSSSLOT或TRYAGAIN
+type RedisClusterNode struct {
+	state  *clusterState
+	index  int
+	id     string
+	addr   string
+	next   *RedisMock
+	asking bool
+}
+
+// ID 节点ID
+func (n *RedisClusterNode) ID() string {
+	return n.id
+}
+
+// Addr 节点地址
+func (n *RedisClusterNode) Addr() string {
+	return n.addr
+}
+
+// Asking 返回发送过ASKING的连接，可以访问正在迁入本节点的槽位
+func (n *RedisClusterNode) Asking() *RedisClusterNode {
+	asking := *n
+	asking.asking = true
+	return &asking
+}
+
+// route 检查命令中的键是否由本节点负责
+// 多个键必须位于同一槽位；槽位迁出时键都不存在返回ASK，只存在一部分返回TRYAGAIN
+func (n *RedisClusterNode) route(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	slot := cluster.Slot(keys[0])
+	for _, key := range keys[1:] {
+		if cluster.Slot(key) != slot {
+			return errCrossSlot
+		}
+	}
+
+	owner, target := n.state.slotState(slot)
+	if owner != n.index {
+		if n.asking && target == n.index {
+			return nil
+		}
+		return n.state.redirect("MOVED", slot, owner)
+	}
+	if target < 0 {
+		return nil
+	}
+	switch present := n.next.countKeys(keys); present {
+	case len(keys):
+		return nil
+	case 0:
+		return n.state.redirect("ASK", slot, target)
+	default:
+		return errTryAgain
+	}
+}
+
+// 基础操作，不涉及键的命令只作用于本节点
+func (n *RedisClusterNode) Ping(ctx context.Context) *StatusCmd {
+	return n.next.Ping(ctx)
+}
+
+func (n *RedisClusterNode) Close() error {
+	return n.next.Close()
+}
+
+func (n *RedisClusterNode) Keys(ctx context.Context, pattern string) *StringSliceCmd {
+	return n.next.Keys(ctx, pattern)
+}
+
+func (n *RedisClusterNode) Scan(ctx context.Context, cursor uint64, match string, count int64) *ScanCmd {
+	return n.next.Scan(ctx, cursor, match, count)
+}
+
+func (n *RedisClusterNode) FlushDB(ctx context.Context) *StatusCmd {
+	return n.next.FlushDB(ctx)
+}
+
+func (n *RedisClusterNode) FlushAll(ctx context.Context) *StatusCmd {
+	return n.next.FlushAll(ctx)
+}
+
+func (n *RedisClusterNode) DBSize(ctx context.Context) *IntCmd {
+	return n.next.DBSize(ctx)
+}
+
+func (n *RedisClusterNode) Info(ctx context.Context, sections ...string) *StringCmd {
+	return n.next.Info(ctx, sections...)
+}
+
+// Select 集群模式只有0号数据库
+func (n *RedisClusterNode) Select(ctx context.Context, db int) *StatusCmd {
+	if db != 0 {
+		if err := ctx.Err(); err != nil {
+			return &StatusCmd{err: err}
+		}
+		return &StatusCmd{err: errClusterSelect}
+	}
+	return n.next.Select(ctx, db)
+}
+
+// 集群操作
+func (n *RedisClusterNode) ClusterSlots(ctx context.Context) *ClusterSlotsCmd {
+	if err := ctx.Err(); err != nil {
+		return &ClusterSlotsCmd{err: err}
+	}
+	if n.next.isClosed() {
+		return &ClusterSlotsCmd{err: rediserr.Closed}
+	}
+
+	n.state.mutex.RLock()
+	defer n.state.mutex.RUnlock()
+
+	var slots []ClusterSlot
+	for slot := 0; slot < cluster.SlotCount; slot++ {
+		owner := n.state.owners[slot]
+		if len(slots) > 0 && slots[len(slots)-1].Nodes[0].ID == n.state.nodes[owner].id {
+			slots[len(slots)-1].End = slot
+			continue
+		}
+		node := n.state.nodes[owner]
+		slots = append(slots, ClusterSlot{
+			Start: slot,
+			End:   slot,
+			Nodes: []ClusterNode{{ID: node.id, Addr: node.addr}},
+		})
+	}
+	return &ClusterSlotsCmd{val: slots}
+}
+
+// ClusterNodes 输出格式与Redis一致，本节点的行带有迁出（[slot->-id]）和迁入（[slot-<-id]）标记
+func (n *RedisClusterNode) ClusterNodes(ctx context.Context) *StringCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringCmd{err: err}
+	}
+	if n.next.isClosed() {
+		return &StringCmd{err: rediserr.Closed}
+	}
+
+	n.state.mutex.RLock()
+	defer n.state.mutex.RUnlock()
+
+	ranges := make([][]string, len(n.state.nodes))
+	for slot := 0; slot < cluster.SlotCount; {
+		owner := n.state.owners[slot]
+		end := slot
+		for end+1 < cluster.SlotCount && n.state.owners[end+1] == owner {
+			end++
+		}
+		if end == slot {
+			ranges[owner] = append(ranges[owner], fmt.Sprint(slot))
+		} else {
+			ranges[owner] = append(ranges[owner], fmt.Sprintf("%d-%d", slot, end))
+		}
+		slot = end + 1
+	}
+
+	migrating := make([]int, 0, len(n.state.migrating))
+	for slot := range n.state.migrating {
+		migrating = append(migrating, slot)
+	}
+	sort.Ints(migrating)
+	for _, slot := range migrating {
+		source, target := n.state.owners[slot], n.state.migrating[slot]
+		switch n.index {
+		case source:
+			ranges[n.index] = append(ranges[n.index], fmt.Sprintf("[%d->-%s]", slot, n.state.nodes[target].id))
+		case target:
+			ranges[n.index] = append(ranges[n.index], fmt.Sprintf("[%d-<-%s]", slot, n.state.nodes[source].id))
+		}
+	}
+
+	var sb strings.Builder
+	for i, node := range n.state.nodes {
+		flags := "master"
+		if i == n.index {
+			flags = "myself,master"
+		}
+		busPort := clusterBasePort + i + 10000
+		fmt.Fprintf(&sb, "%s %s@%d %s - 0 %d %d connected", node.id, node.addr, busPort, flags, time.Now().UnixMilli(), i+1)
+		for _, r := range ranges[i] {
+			sb.WriteString(" ")
+			sb.WriteString(r)
+		}
+		sb.WriteString("\n")
+	}
+	return &StringCmd{val: sb.String()}
+}
+
+func (n *RedisClusterNode) ClusterKeySlot(ctx context.Context, key string) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+	if n.next.isClosed() {
+		return &IntCmd{err: rediserr.Closed}
+	}
+	return &IntCmd{val: int64(cluster.Slot(key))}
+}
+
+// 键操作，先检查槽位再交给本节点的数据执行
+func (n *RedisClusterNode) Get(ctx context.Context, key string) *StringCmd {
+	if err := n.route(key); err != nil {
+		return &StringCmd{err: err}
+	}
+	return n.next.Get(ctx, key)
+}
+
+func (n *RedisClusterNode) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd {
+	if err := n.route(key); err != nil {
+		return &StatusCmd{err: err}
+	}
+	return n.next.Set(ctx, key, value, expiration)
+}
+
+func (n *RedisClusterNode) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *BoolCmd {
+	if err := n.route(key); err != nil {
+		return &BoolCmd{err: err}
+	}
+	return n.next.SetNX(ctx, key, value, expiration)
+}
+
+func (n *RedisClusterNode) Del(ctx context.Context, keys ...string) *IntCmd {
+	if err := n.route(keys...); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.Del(ctx, keys...)
+}
+
+func (n *RedisClusterNode) Exists(ctx context.Context, keys ...string) *IntCmd {
+	if err := n.route(keys...); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.Exists(ctx, keys...)
+}
+
+func (n *RedisClusterNode) Expire(ctx context.Context, key string, expiration time.Duration) *BoolCmd {
+	if err := n.route(key); err != nil {
+		return &BoolCmd{err: err}
+	}
+	return n.next.Expire(ctx, key, expiration)
+}
+
+func (n *RedisClusterNode) TTL(ctx context.Context, key string) *DurationCmd {
+	if err := n.route(key); err != nil {
+		return &DurationCmd{err: err}
+	}
+	return n.next.TTL(ctx, key)
+}
+
+func (n *RedisClusterNode) PTTL(ctx context.Context, key string) *DurationCmd {
+	if err := n.route(key); err != nil {
+		return &DurationCmd{err: err}
+	}
+	return n.next.PTTL(ctx, key)
+}
+
+func (n *RedisClusterNode) Persist(ctx context.Context, key string) *BoolCmd {
+	if err := n.route(key); err != nil {
+		return &BoolCmd{err: err}
+	}
+	return n.next.Persist(ctx, key)
+}
+
+func (n *RedisClusterNode) SetEX(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd {
+	if err := n.route(key); err != nil {
+		return &StatusCmd{err: err}
+	}
+	return n.next.SetEX(ctx, key, value, expiration)
+}
+
+func (n *RedisClusterNode) PSetEX(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd {
+	if err := n.route(key); err != nil {
+		return &StatusCmd{err: err}
+	}
+	return n.next.PSetEX(ctx, key, value, expiration)
+}
+
+func (n *RedisClusterNode) SetArgs(ctx context.Context, key string, value interface{}, a SetArgs) *StatusCmd {
+	if err := n.route(key); err != nil {
+		return &StatusCmd{err: err}
+	}
+	return n.next.SetArgs(ctx, key, value, a)
+}
+
+func (n *RedisClusterNode) GetSet(ctx context.Context, key string, value interface{}) *StringCmd {
+	if err := n.route(key); err != nil {
+		return &StringCmd{err: err}
+	}
+	return n.next.GetSet(ctx, key, value)
+}
+
+func (n *RedisClusterNode) GetDel(ctx context.Context, key string) *StringCmd {
+	if err := n.route(key); err != nil {
+		return &StringCmd{err: err}
+	}
+	return n.next.GetDel(ctx, key)
+}
+
+func (n *RedisClusterNode) GetEx(ctx context.Context, key string, expiration time.Duration) *StringCmd {
+	if err := n.route(key); err != nil {
+		return &StringCmd{err: err}
+	}
+	return n.next.GetEx(ctx, key, expiration)
+}
+
+func (n *RedisClusterNode) StrLen(ctx context.Context, key string) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.StrLen(ctx, key)
+}
+
+func (n *RedisClusterNode) MGet(ctx context.Context, keys ...string) *SliceCmd {
+	if err := n.route(keys...); err != nil {
+		return &SliceCmd{err: err}
+	}
+	return n.next.MGet(ctx, keys...)
+}
+
+func (n *RedisClusterNode) MSet(ctx context.Context, values ...interface{}) *StatusCmd {
+	if err := n.route(pairKeys(values)...); err != nil {
+		return &StatusCmd{err: err}
+	}
+	return n.next.MSet(ctx, values...)
+}
+
+func (n *RedisClusterNode) MSetNX(ctx context.Context, values ...interface{}) *BoolCmd {
+	if err := n.route(pairKeys(values)...); err != nil {
+		return &BoolCmd{err: err}
+	}
+	return n.next.MSetNX(ctx, values...)
+}
+
+func (n *RedisClusterNode) Incr(ctx context.Context, key string) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.Incr(ctx, key)
+}
+
+func (n *RedisClusterNode) IncrBy(ctx context.Context, key string, value int64) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.IncrBy(ctx, key, value)
+}
+
+func (n *RedisClusterNode) Decr(ctx context.Context, key string) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.Decr(ctx, key)
+}
+
+func (n *RedisClusterNode) DecrBy(ctx context.Context, key string, decrement int64) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.DecrBy(ctx, key, decrement)
+}
+
+func (n *RedisClusterNode) IncrByFloat(ctx context.Context, key string, value float64) *FloatCmd {
+	if err := n.route(key); err != nil {
+		return &FloatCmd{err: err}
+	}
+	return n.next.IncrByFloat(ctx, key, value)
+}
+
+func (n *RedisClusterNode) SetBit(ctx context.Context, key string, offset int64, value int) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.SetBit(ctx, key, offset, value)
+}
+
+func (n *RedisClusterNode) GetBit(ctx context.Context, key string, offset int64) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.GetBit(ctx, key, offset)
+}
+
+func (n *RedisClusterNode) BitCount(ctx context.Context, key string, bitCount *BitCount) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.BitCount(ctx, key, bitCount)
+}
+
+func (n *RedisClusterNode) BitPos(ctx context.Context, key string, bit int64, pos ...int64) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.BitPos(ctx, key, bit, pos...)
+}
+
+func (n *RedisClusterNode) BitField(ctx context.Context, key string, args ...interface{}) *IntSliceCmd {
+	if err := n.route(key); err != nil {
+		return &IntSliceCmd{err: err}
+	}
+	return n.next.BitField(ctx, key, args...)
+}
+
+func (n *RedisClusterNode) PFAdd(ctx context.Context, key string, els ...interface{}) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.PFAdd(ctx, key, els...)
+}
+
+func (n *RedisClusterNode) PFCount(ctx context.Context, keys ...string) *IntCmd {
+	if err := n.route(keys...); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.PFCount(ctx, keys...)
+}
+
+func (n *RedisClusterNode) PFMerge(ctx context.Context, dest string, keys ...string) *StatusCmd {
+	if err := n.route(append([]string{dest}, keys...)...); err != nil {
+		return &StatusCmd{err: err}
+	}
+	return n.next.PFMerge(ctx, dest, keys...)
+}
+
+func (n *RedisClusterNode) HGet(ctx context.Context, key, field string) *StringCmd {
+	if err := n.route(key); err != nil {
+		return &StringCmd{err: err}
+	}
+	return n.next.HGet(ctx, key, field)
+}
+
+func (n *RedisClusterNode) HSet(ctx context.Context, key string, values ...interface{}) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.HSet(ctx, key, values...)
+}
+
+func (n *RedisClusterNode) HDel(ctx context.Context, key string, fields ...string) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.HDel(ctx, key, fields...)
+}
+
+func (n *RedisClusterNode) HExists(ctx context.Context, key, field string) *BoolCmd {
+	if err := n.route(key); err != nil {
+		return &BoolCmd{err: err}
+	}
+	return n.next.HExists(ctx, key, field)
+}
+
+func (n *RedisClusterNode) HGetAll(ctx context.Context, key string) *StringStringMapCmd {
+	if err := n.route(key); err != nil {
+		return &StringStringMapCmd{err: err}
+	}
+	return n.next.HGetAll(ctx, key)
+}
+
+func (n *RedisClusterNode) HKeys(ctx context.Context, key string) *StringSliceCmd {
+	if err := n.route(key); err != nil {
+		return &StringSliceCmd{err: err}
+	}
+	return n.next.HKeys(ctx, key)
+}
+
+func (n *RedisClusterNode) HVals(ctx context.Context, key string) *StringSliceCmd {
+	if err := n.route(key); err != nil {
+		return &StringSliceCmd{err: err}
+	}
+	return n.next.HVals(ctx, key)
+}
+
+func (n *RedisClusterNode) LPush(ctx context.Context, key string, values ...interface{}) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.LPush(ctx, key, values...)
+}
+
+func (n *RedisClusterNode) RPush(ctx context.Context, key string, values ...interface{}) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.RPush(ctx, key, values...)
+}
+
+func (n *RedisClusterNode) LPop(ctx context.Context, key string) *StringCmd {
+	if err := n.route(key); err != nil {
+		return &StringCmd{err: err}
+	}
+	return n.next.LPop(ctx, key)
+}
+
+func (n *RedisClusterNode) RPop(ctx context.Context, key string) *StringCmd {
+	if err := n.route(key); err != nil {
+		return &StringCmd{err: err}
+	}
+	return n.next.RPop(ctx, key)
+}
+
+func (n *RedisClusterNode) LLen(ctx context.Context, key string) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.LLen(ctx, key)
+}
+
+func (n *RedisClusterNode) LRange(ctx context.Context, key string, start, stop int64) *StringSliceCmd {
+	if err := n.route(key); err != nil {
+		return &StringSliceCmd{err: err}
+	}
+	return n.next.LRange(ctx, key, start, stop)
+}
+
+func (n *RedisClusterNode) LMove(ctx context.Context, source, destination, srcpos, destpos string) *StringCmd {
+	if err := n.route(source, destination); err != nil {
+		return &StringCmd{err: err}
+	}
+	return n.next.LMove(ctx, source, destination, srcpos, destpos)
+}
+
+func (n *RedisClusterNode) BLPop(ctx context.Context, timeout time.Duration, keys ...string) *StringSliceCmd {
+	if err := n.route(keys...); err != nil {
+		return &StringSliceCmd{err: err}
+	}
+	return n.next.BLPop(ctx, timeout, keys...)
+}
+
+func (n *RedisClusterNode) BRPop(ctx context.Context, timeout time.Duration, keys ...string) *StringSliceCmd {
+	if err := n.route(keys...); err != nil {
+		return &StringSliceCmd{err: err}
+	}
+	return n.next.BRPop(ctx, timeout, keys...)
+}
+
+func (n *RedisClusterNode) BLMove(ctx context.Context, source, destination, srcpos, destpos string, timeout time.Duration) *StringCmd {
+	if err := n.route(source, destination); err != nil {
+		return &StringCmd{err: err}
+	}
+	return n.next.BLMove(ctx, source, destination, srcpos, destpos, timeout)
+}
+
+func (n *RedisClusterNode) SAdd(ctx context.Context, key string, members ...interface{}) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.SAdd(ctx, key, members...)
+}
+
+func (n *RedisClusterNode) SRem(ctx context.Context, key string, members ...interface{}) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.SRem(ctx, key, members...)
+}
+
+func (n *RedisClusterNode) SMembers(ctx context.Context, key string) *StringSliceCmd {
+	if err := n.route(key); err != nil {
+		return &StringSliceCmd{err: err}
+	}
+	return n.next.SMembers(ctx, key)
+}
+
+func (n *RedisClusterNode) SIsMember(ctx context.Context, key string, member interface{}) *BoolCmd {
+	if err := n.route(key); err != nil {
+		return &BoolCmd{err: err}
+	}
+	return n.next.SIsMember(ctx, key, member)
+}
+
+func (n *RedisClusterNode) SCard(ctx context.Context, key string) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.SCard(ctx, key)
+}
+
+func (n *RedisClusterNode) ZAdd(ctx context.Context, key string, members ...*Z) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.ZAdd(ctx, key, members...)
+}
+
+func (n *RedisClusterNode) ZRem(ctx context.Context, key string, members ...interface{}) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.ZRem(ctx, key, members...)
+}
+
+func (n *RedisClusterNode) ZRange(ctx context.Context, key string, start, stop int64) *StringSliceCmd {
+	if err := n.route(key); err != nil {
+		return &StringSliceCmd{err: err}
+	}
+	return n.next.ZRange(ctx, key, start, stop)
+}
+
+func (n *RedisClusterNode) ZRangeWithScores(ctx context.Context, key string, start, stop int64) *ZSliceCmd {
+	if err := n.route(key); err != nil {
+		return &ZSliceCmd{err: err}
+	}
+	return n.next.ZRangeWithScores(ctx, key, start, stop)
+}
+
+func (n *RedisClusterNode) ZCard(ctx context.Context, key string) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.ZCard(ctx, key)
+}
+
+func (n *RedisClusterNode) ZScore(ctx context.Context, key, member string) *FloatCmd {
+	if err := n.route(key); err != nil {
+		return &FloatCmd{err: err}
+	}
+	return n.next.ZScore(ctx, key, member)
+}
+
+func (n *RedisClusterNode) BZPopMin(ctx context.Context, timeout time.Duration, keys ...string) *ZWithKeyCmd {
+	if err := n.route(keys...); err != nil {
+		return &ZWithKeyCmd{err: err}
+	}
+	return n.next.BZPopMin(ctx, timeout, keys...)
+}
+
+func (n *RedisClusterNode) GeoAdd(ctx context.Context, key string, geoLocation ...*GeoLocation) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.GeoAdd(ctx, key, geoLocation...)
+}
+
+func (n *RedisClusterNode) GeoPos(ctx context.Context, key string, members ...string) *GeoPosCmd {
+	if err := n.route(key); err != nil {
+		return &GeoPosCmd{err: err}
+	}
+	return n.next.GeoPos(ctx, key, members...)
+}
+
+func (n *RedisClusterNode) GeoDist(ctx context.Context, key string, member1, member2, unit string) *FloatCmd {
+	if err := n.route(key); err != nil {
+		return &FloatCmd{err: err}
+	}
+	return n.next.GeoDist(ctx, key, member1, member2, unit)
+}
+
+func (n *RedisClusterNode) GeoHash(ctx context.Context, key string, members ...string) *StringSliceCmd {
+	if err := n.route(key); err != nil {
+		return &StringSliceCmd{err: err}
+	}
+	return n.next.GeoHash(ctx, key, members...)
+}
+
+func (n *RedisClusterNode) GeoRadius(ctx context.Context, key string, longitude, latitude float64, query *GeoRadiusQuery) *GeoLocationCmd {
+	if err := n.route(key); err != nil {
+		return &GeoLocationCmd{err: err}
+	}
+	return n.next.GeoRadius(ctx, key, longitude, latitude, query)
+}
+
+func (n *RedisClusterNode) GeoRadiusByMember(ctx context.Context, key, member string, query *GeoRadiusQuery) *GeoLocationCmd {
+	if err := n.route(key); err != nil {
+		return &GeoLocationCmd{err: err}
+	}
+	return n.next.GeoRadiusByMember(ctx, key, member, query)
+}
+
+func (n *RedisClusterNode) GeoSearch(ctx context.Context, key string, q *GeoSearchQuery) *StringSliceCmd {
+	if err := n.route(key); err != nil {
+		return &StringSliceCmd{err: err}
+	}
+	return n.next.GeoSearch(ctx, key, q)
+}
+
+func (n *RedisClusterNode) GeoSearchLocation(ctx context.Context, key string, q *GeoSearchLocationQuery) *GeoLocationCmd {
+	if err := n.route(key); err != nil {
+		return &GeoLocationCmd{err: err}
+	}
+	return n.next.GeoSearchLocation(ctx, key, q)
+}
+
+func (n *RedisClusterNode) XAdd(ctx context.Context, a *XAddArgs) *StringCmd {
+	if err := n.route(a.Stream); err != nil {
+		return &StringCmd{err: err}
+	}
+	return n.next.XAdd(ctx, a)
+}
+
+func (n *RedisClusterNode) XLen(ctx context.Context, stream string) *IntCmd {
+	if err := n.route(stream); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.XLen(ctx, stream)
+}
+
+func (n *RedisClusterNode) XRange(ctx context.Context, stream, start, stop string) *XMessageSliceCmd {
+	if err := n.route(stream); err != nil {
+		return &XMessageSliceCmd{err: err}
+	}
+	return n.next.XRange(ctx, stream, start, stop)
+}
+
+func (n *RedisClusterNode) XRead(ctx context.Context, a *XReadArgs) *XStreamSliceCmd {
+	if err := n.route(xReadKeys(a)...); err != nil {
+		return &XStreamSliceCmd{err: err}
+	}
+	return n.next.XRead(ctx, a)
+}
+
+func (n *RedisClusterNode) JSONSet(ctx context.Context, key, path string, value interface{}) *StatusCmd {
+	if err := n.route(key); err != nil {
+		return &StatusCmd{err: err}
+	}
+	return n.next.JSONSet(ctx, key, path, value)
+}
+
+func (n *RedisClusterNode) JSONSetMode(ctx context.Context, key, path string, value interface{}, mode string) *StatusCmd {
+	if err := n.route(key); err != nil {
+		return &StatusCmd{err: err}
+	}
+	return n.next.JSONSetMode(ctx, key, path, value, mode)
+}
+
+func (n *RedisClusterNode) JSONGet(ctx context.Context, key string, paths ...string) *StringCmd {
+	if err := n.route(key); err != nil {
+		return &StringCmd{err: err}
+	}
+	return n.next.JSONGet(ctx, key, paths...)
+}
+
+func (n *RedisClusterNode) JSONDel(ctx context.Context, key, path string) *IntCmd {
+	if err := n.route(key); err != nil {
+		return &IntCmd{err: err}
+	}
+	return n.next.JSONDel(ctx, key, path)
+}
+
+func (n *RedisClusterNode) JSONType(ctx context.Context, key, path string) *StringSliceCmd {
+	if err := n.route(key); err != nil {
+		return &StringSliceCmd{err: err}
+	}
+	return n.next.JSONType(ctx, key, path)
+}
+
+func (n *RedisClusterNode) JSONArrAppend(ctx context.Context, key, path string, values ...interface{}) *IntPointerSliceCmd {
+	if err := n.route(key); err != nil {
+		return &IntPointerSliceCmd{err: err}
+	}
+	return n.next.JSONArrAppend(ctx, key, path, values...)
+}
+
+func (n *RedisClusterNode) JSONNumIncrBy(ctx context.Context, key, path string, value float64) *StringCmd {
+	if err := n.route(key); err != nil {
+		return &StringCmd{err: err}
+	}
+	return n.next.JSONNumIncrBy(ctx, key, path, value)
+}
+
+func (n *RedisClusterNode) JSONObjKeys(ctx context.Context, key, path string) *SliceCmd {
+	if err := n.route(key); err != nil {
+		return &SliceCmd{err: err}
+	}
+	return n.next.JSONObjKeys(ctx, key, path)
+}
+
+func (n *RedisClusterNode) Type(ctx context.Context, key string) *StatusCmd {
+	if err := n.route(key); err != nil {
+		return &StatusCmd{err: err}
+	}
+	return n.next.Type(ctx, key)
+}
+
+func (n *RedisClusterNode) Rename(ctx context.Context, key, newKey string) *StatusCmd {
+	if err := n.route(key, newKey); err != nil {
+		return &StatusCmd{err: err}
+	}
+	return n.next.Rename(ctx, key, newKey)
+}
+
+func (n *RedisClusterNode) Dump(ctx context.Context, key string) *StringCmd {
+	if err := n.route(key); err != nil {
+		return &StringCmd{err: err}
+	}
+	return n.next.Dump(ctx, key)
+}
+
+func (n *RedisClusterNode) Restore(ctx context.Context, key string, ttl time.Duration, value string) *StatusCmd {
+	if err := n.route(key); err != nil {
+		return &StatusCmd{err: err}
+	}
+	return n.next.Restore(ctx, key, ttl, value)
+}
+
+func (n *RedisClusterNode) RestoreReplace(ctx context.Context, key string, ttl time.Duration, value string) *StatusCmd {
+	if err := n.route(key); err != nil {
+		return &StatusCmd{err: err}
+	}
+	return n.next.RestoreReplace(ctx, key, ttl, value)
+}
+
+// pairKeys 返回MSET形式参数中的键
+func pairKeys(values []interface{}) []string {
+	args := pairArgs(values)
+	keys := make([]string, 0, len(args)/2)
+	for i := 0; i < len(args); i += 2 {
+		keys = append(keys, args[i])
+	}
+	return keys
+}
+
+// xReadKeys 返回XREAD参数中的流名称
+func xReadKeys(a *XReadArgs) []string {
+	if a == nil {
+		return nil
+	}
+	return a.Streams[:len(a.Streams)/2]
+}
@@ -20,6 +20,37 @@ type RedisInterface interface {
 	Exists(ctx context.Context, keys ...string) *IntCmd
 	Expire(ctx context.Context, key string, expiration time.Duration) *BoolCmd
 	TTL(ctx context.Context, key string) *DurationCmd
+	PTTL(ctx context.Context, key string) *DurationCmd
+	Persist(ctx context.Context, key string) *BoolCmd
+	SetEX(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd
+	PSetEX(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd
+	SetArgs(ctx context.Context, key string, value interface{}, a SetArgs) *StatusCmd
+	GetSet(ctx context.Context, key string, value interface{}) *StringCmd
+	GetDel(ctx context.Context, key string) *StringCmd
+	GetEx(ctx context.Context, key string, expiration time.Duration) *StringCmd
+	StrLen(ctx context.Context, key string) *IntCmd
+	MGet(ctx context.Context, keys ...string) *SliceCmd
+	MSet(ctx context.Context, values ...interface{}) *StatusCmd
+	MSetNX(ctx context.Context, values ...interface{}) *BoolCmd
+	
+	// 计数器操作
+	Incr(ctx context.Context, key string) *IntCmd
+	IncrBy(ctx context.Context, key string, value int64) *IntCmd
+	Decr(ctx context.Context, key string) *IntCmd
+	DecrBy(ctx context.Context, key string, decrement int64) *IntCmd
+	IncrByFloat(ctx context.Context, key string, value float64) *FloatCmd
+	
+	// 位图操作
+	SetBit(ctx context.Context, key string, offset int64, value int) *IntCmd
+	GetBit(ctx context.Context, key string, offset int64) *IntCmd
+	BitCount(ctx context.Context, key string, bitCount *BitCount) *IntCmd
+	BitPos(ctx context.Context, key string, bit int64, pos ...int64) *IntCmd
+	BitField(ctx context.Context, key string, args ...interface{}) *IntSliceCmd
+	
+	// HyperLogLog操作
+	PFAdd(ctx context.Context, key string, els ...interface{}) *IntCmd
+	PFCount(ctx context.Context, keys ...string) *IntCmd
+	PFMerge(ctx context.Context, dest string, keys ...string) *StatusCmd
 	
 	// 哈希操作
 	HGet(ctx context.Context, key, field string) *StringCmd
@@ -37,6 +68,12 @@ type RedisInterface interface {
 	RPop(ctx context.Context, key string) *StringCmd
 	LLen(ctx context.Context, key string) *IntCmd
 	LRange(ctx context.Context, key string, start, stop int64) *StringSliceCmd
+	LMove(ctx context.Context, source, destination, srcpos, destpos string) *StringCmd
+	
+	// 阻塞列表操作，timeout为0时一直阻塞直到有数据或ctx取消
+	BLPop(ctx context.Context, timeout time.Duration, keys ...string) *StringSliceCmd
+	BRPop(ctx context.Context, timeout time.Duration, keys ...string) *StringSliceCmd
+	BLMove(ctx context.Context, source, destination, srcpos, destpos string, timeout time.Duration) *StringCmd
 	
 	// 集合操作
 	SAdd(ctx context.Context, key string, members ...interface{}) *IntCmd
@@ -52,16 +89,76 @@ type RedisInterface interface {
 	ZRangeWithScores(ctx context.Context, key string, start, stop int64) *ZSliceCmd
 	ZCard(ctx context.Context, key string) *IntCmd
 	ZScore(ctx context.Context, key, member string) *FloatCmd
+	BZPopMin(ctx context.Context, timeout time.Duration, keys ...string) *ZWithKeyCmd
+	
+	// 地理位置操作，坐标以geohash分数保存在有序集合中
+	GeoAdd(ctx context.Context, key string, geoLocation ...*GeoLocation) *IntCmd
+	GeoPos(ctx context.Context, key string, members ...string) *GeoPosCmd
+	GeoDist(ctx context.Context, key string, member1, member2, unit string) *FloatCmd
+	GeoHash(ctx context.Context, key string, members ...string) *StringSliceCmd
+	GeoRadius(ctx context.Context, key string, longitude, latitude float64, query *GeoRadiusQuery) *GeoLocationCmd
+	GeoRadiusByMember(ctx context.Context, key, member string, query *GeoRadiusQuery) *GeoLocationCmd
+	GeoSearch(ctx context.Context, key string, q *GeoSearchQuery) *StringSliceCmd
+	GeoSearchLocation(ctx context.Context, key string, q *GeoSearchLocationQuery) *GeoLocationCmd
+	
+	// 流操作
+	XAdd(ctx context.Context, a *XAddArgs) *StringCmd
+	XLen(ctx context.Context, stream string) *IntCmd
+	XRange(ctx context.Context, stream, start, stop string) *XMessageSliceCmd
+	XRead(ctx context.Context, a *XReadArgs) *XStreamSliceCmd
+	
+	// RedisJSON操作，路径以$开头时为JSONPath，否则为旧版路径
+	// value为string或[]byte时视为JSON文本，其他值按encoding/json编码
+	JSONSet(ctx context.Context, key, path string, value interface{}) *StatusCmd
+	JSONSetMode(ctx context.Context, key, path string, value interface{}, mode string) *StatusCmd
+	JSONGet(ctx context.Context, key string, paths ...string) *StringCmd
+	JSONDel(ctx context.Context, key, path string) *IntCmd
+	JSONType(ctx context.Context, key, path string) *StringSliceCmd
+	JSONArrAppend(ctx context.Context, key, path string, values ...interface{}) *IntPointerSliceCmd
+	JSONNumIncrBy(ctx context.Context, key, path string, value float64) *StringCmd
+	JSONObjKeys(ctx context.Context, key, path string) *SliceCmd
 	
 	// 键操作
 	Keys(ctx context.Context, pattern string) *StringSliceCmd
+	Scan(ctx context.Context, cursor uint64, match string, count int64) *ScanCmd
 	Type(ctx context.Context, key string) *StatusCmd
+	Rename(ctx context.Context, key, newKey string) *StatusCmd
+	Dump(ctx context.Context, key string) *StringCmd
+	Restore(ctx context.Context, key string, ttl time.Duration, value string) *StatusCmd
+	RestoreReplace(ctx context.Context, key string, ttl time.Duration, value string) *StatusCmd
 	FlushDB(ctx context.Context) *StatusCmd
 	FlushAll(ctx context.Context) *StatusCmd
 	
 	// 数据库操作
 	Select(ctx context.Context, index int) *StatusCmd
 	DBSize(ctx context.Context) *IntCmd
+	Info(ctx context.Context, sections ...string) *StringCmd
+	
+	// 集群操作，非集群实例返回错误
+	ClusterSlots(ctx context.Context) *ClusterSlotsCmd
+	ClusterNodes(ctx context.Context) *StringCmd
+	ClusterKeySlot(ctx context.Context, key string) *IntCmd
+}
+
+// KeepTTL 作为Set的过期时间传入时保留键原有的TTL，与go-redis一致
+const KeepTTL = -1
+
+// SetArgs SET命令的完整参数
+type SetArgs struct {
+	// Mode 为NX、XX或空
+	Mode string
+	// TTL与ExpireAt为零值时表示不过期
+	TTL      time.Duration
+	ExpireAt time.Time
+	// Get 为true时返回键的旧值，键不存在时返回Nil
+	Get bool
+	// KeepTTL 保留键原有的TTL
+	KeepTTL bool
+}
+
+// BitCount BITCOUNT的字节范围，为nil时统计整个字符串
+type BitCount struct {
+	Start, End int64
 }
 
 // Z 有序集合成员结构
@@ -70,6 +167,106 @@ type Z struct {
 	Member interface{}
 }
 
+// ZWithKey 带键名的有序集合成员，用于BZPOPMIN等命令
+type ZWithKey struct {
+	Z
+	Key string
+}
+
+// GeoLocation 地理位置成员，Dist和GeoHash只在查询时按需返回
+type GeoLocation struct {
+	Name                      string
+	Longitude, Latitude, Dist float64
+	GeoHash                   int64
+}
+
+// GeoPos 成员坐标
+type GeoPos struct {
+	Longitude, Latitude float64
+}
+
+// GeoRadiusQuery GEORADIUS查询参数，Unit为空时使用km
+type GeoRadiusQuery struct {
+	Radius      float64
+	Unit        string
+	WithCoord   bool
+	WithDist    bool
+	WithGeoHash bool
+	Count       int
+	// Sort 为ASC、DESC或空
+	Sort string
+}
+
+// GeoSearchQuery GEOSEARCH查询参数
+// Member非空时以成员为中心，否则以经纬度为中心；Radius大于0时按圆形查询，否则按矩形查询
+type GeoSearchQuery struct {
+	Member     string
+	Longitude  float64
+	Latitude   float64
+	Radius     float64
+	RadiusUnit string
+	BoxWidth   float64
+	BoxHeight  float64
+	BoxUnit    string
+	// Sort 为ASC、DESC或空
+	Sort     string
+	Count    int
+	CountAny bool
+}
+
+// GeoSearchLocationQuery 带坐标、距离、geohash返回选项的GEOSEARCH查询参数
+type GeoSearchLocationQuery struct {
+	GeoSearchQuery
+
+	WithCoord bool
+	WithDist  bool
+	WithHash  bool
+}
+
+// XAddArgs XADD命令参数
+type XAddArgs struct {
+	Stream     string
+	NoMkStream bool
+	MaxLen     int64 // MAXLEN N
+	Approx     bool  // MAXLEN ~ N
+	ID         string
+	Values     interface{} // map[string]interface{}、[]interface{}或[]string
+}
+
+// XReadArgs XREAD命令参数
+// Streams依次为流名和起始ID，例如 stream1 stream2 id1 id2
+// Block小于0时不阻塞，等于0时一直阻塞，与go-redis一致
+type XReadArgs struct {
+	Streams []string
+	Count   int64
+	Block   time.Duration
+}
+
+// XMessage 流消息
+type XMessage struct {
+	ID     string
+	Values map[string]interface{}
+}
+
+// XStream 流及其消息
+type XStream struct {
+	Stream   string
+	Messages []XMessage
+}
+
+// ClusterNode CLUSTER SLOTS中的节点
+type ClusterNode struct {
+	ID   string
+	Addr string
+}
+
+// ClusterSlot CLUSTER SLOTS中的槽位区间，Nodes第一个为主节点
+type ClusterSlot struct {
+	Start int
+	End   int
+	Nodes []ClusterNode
+}
+
 // 命令结果接口
 type Cmder interface {
 	Err() error
@@ -211,6 +408,72 @@ func (cmd *DurationCmd) String() string {
 	return cmd.val.String()
 }
 
+// IntSliceCmd 整数切片命令结果
+type IntSliceCmd struct {
+	val []int64
+	err error
+}
+
+func (cmd *IntSliceCmd) Result() ([]int64, error) {
+	return cmd.val, cmd.err
+}
+
+func (cmd *IntSliceCmd) Val() []int64 {
+	return cmd.val
+}
+
+func (cmd *IntSliceCmd) Err() error {
+	return cmd.err
+}
+
+func (cmd *IntSliceCmd) String() string {
+	return fmt.Sprintf("%v", cmd.val)
+}
+
+// IntPointerSliceCmd 可空整数切片命令结果，不适用的元素为nil
+type IntPointerSliceCmd struct {
+	val []*int64
+	err error
+}
+
+func (cmd *IntPointerSliceCmd) Result() ([]*int64, error) {
+	return cmd.val, cmd.err
+}
+
+func (cmd *IntPointerSliceCmd) Val() []*int64 {
+	return cmd.val
+}
+
+func (cmd *IntPointerSliceCmd) Err() error {
+	return cmd.err
+}
+
+func (cmd *IntPointerSliceCmd) String() string {
+	return fmt.Sprintf("%v", cmd.val)
+}
+
+// SliceCmd 通用切片命令结果，不存在的元素为nil
+type SliceCmd struct {
+	val []interface{}
+	err error
+}
+
+func (cmd *SliceCmd) Result() ([]interface{}, error) {
+	return cmd.val, cmd.err
+}
+
+func (cmd *SliceCmd) Val() []interface{} {
+	return cmd.val
+}
+
+func (cmd *SliceCmd) Err() error {
+	return cmd.err
+}
+
+func (cmd *SliceCmd) String() string {
+	return fmt.Sprintf("%v", cmd.val)
+}
+
 // StringSliceCmd 字符串切片命令结果
 type StringSliceCmd struct {
 	val []string
@@ -275,4 +538,159 @@ func (cmd *ZSliceCmd) Err() error {
 
 func (cmd *ZSliceCmd) String() string {
 	return fmt.Sprintf("%v", cmd.val)
-}
\ No newline at end of file
+}
+
+// ZWithKeyCmd 带键名的有序集合成员命令结果
+type ZWithKeyCmd struct {
+	val *ZWithKey
+	err error
+}
+
+func (cmd *ZWithKeyCmd) Result() (*ZWithKey, error) {
+	return cmd.val, cmd.err
+}
+
+func (cmd *ZWithKeyCmd) Val() *ZWithKey {
+	return cmd.val
+}
+
+func (cmd *ZWithKeyCmd) Err() error {
+	return cmd.err
+}
+
+func (cmd *ZWithKeyCmd) String() string {
+	return fmt.Sprintf("%v", cmd.val)
+}
+
+// GeoPosCmd 地理坐标命令结果，不存在的成员为nil
+type GeoPosCmd struct {
+	val []*GeoPos
+	err error
+}
+
+func (cmd *GeoPosCmd) Result() ([]*GeoPos, error) {
+	return cmd.val, cmd.err
+}
+
+func (cmd *GeoPosCmd) Val() []*GeoPos {
+	return cmd.val
+}
+
+func (cmd *GeoPosCmd) Err() error {
+	return cmd.err
+}
+
+func (cmd *GeoPosCmd) String() string {
+	return fmt.Sprintf("%v", cmd.val)
+}
+
+// GeoLocationCmd 地理位置查询命令结果
+type GeoLocationCmd struct {
+	val []GeoLocation
+	err error
+}
+
+func (cmd *GeoLocationCmd) Result() ([]GeoLocation, error) {
+	return cmd.val, cmd.err
+}
+
+func (cmd *GeoLocationCmd) Val() []GeoLocation {
+	return cmd.val
+}
+
+func (cmd *GeoLocationCmd) Err() error {
+	return cmd.err
+}
+
+func (cmd *GeoLocationCmd) String() string {
+	return fmt.Sprintf("%v", cmd.val)
+}
+
+// XMessageSliceCmd 流消息切片命令结果
+type XMessageSliceCmd struct {
+	val []XMessage
+	err error
+}
+
+func (cmd *XMessageSliceCmd) Result() ([]XMessage, error) {
+	return cmd.val, cmd.err
+}
+
+func (cmd *XMessageSliceCmd) Val() []XMessage {
+	return cmd.val
+}
+
+func (cmd *XMessageSliceCmd) Err() error {
+	return cmd.err
+}
+
+func (cmd *XMessageSliceCmd) String() string {
+	return fmt.Sprintf("%v", cmd.val)
+}
+
+// XStreamSliceCmd 流切片命令结果
+type XStreamSliceCmd struct {
+	val []XStream
+	err error
+}
+
+func (cmd *XStreamSliceCmd) Result() ([]XStream, error) {
+	return cmd.val, cmd.err
+}
+
+func (cmd *XStreamSliceCmd) Val() []XStream {
+	return cmd.val
+}
+
+func (cmd *XStreamSliceCmd) Err() error {
+	return cmd.err
+}
+
+func (cmd *XStreamSliceCmd) String() string {
+	return fmt.Sprintf("%v", cmd.val)
+}
+
+// ScanCmd SCAN命令结果，游标为0表示遍历结束
+type ScanCmd struct {
+	page   []string
+	cursor uint64
+	err    error
+}
+
+func (cmd *ScanCmd) Result() ([]string, uint64, error) {
+	return cmd.page, cmd.cursor, cmd.err
+}
+
+func (cmd *ScanCmd) Val() ([]string, uint64) {
+	return cmd.page, cmd.cursor
+}
+
+func (cmd *ScanCmd) Err() error {
+	return cmd.err
+}
+
+func (cmd *ScanCmd) String() string {
+	return fmt.Sprintf("%v %d", cmd.page, cmd.cursor)
+}
+
+// ClusterSlotsCmd 集群槽位命令结果
+type ClusterSlotsCmd struct {
+	val []ClusterSlot
+	err error
+}
+
+func (cmd *ClusterSlotsCmd) Result() ([]ClusterSlot, error) {
+	return cmd.val, cmd.err
+}
+
+func (cmd *ClusterSlotsCmd) Val() []ClusterSlot {
+	return cmd.val
+}
+
+func (cmd *ClusterSlotsCmd) Err() error {
+	return cmd.err
+}
+
+func (cmd *ClusterSlotsCmd) String() string {
+	return fmt.Sprintf("%v", cmd.val)
+}
@@ -5,14 +5,17 @@ import (
 	"fmt"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/devtoolbox/redis/rediserr"
 )
 
 // RedisValue Redis值结构
 type RedisValue struct {
 	Value     interface{}
-	Type      string // string, hash, list, set, zset
+	Type      string // string, hash, list, set, zset, stream
 	ExpireAt  *time.Time
 	CreatedAt time.Time
 }
@@ -21,6 +24,7 @@ type RedisValue struct {
 type RedisMock struct {
 	data     map[string]*RedisValue
 	mutex    sync.RWMutex
+	cond     *sync.Cond // 阻塞命令等待数据写入，基于mutex的写锁
 	db       int
 	closed   bool
 	cleanup  *time.Ticker
@@ -35,6 +39,7 @@ func NewRedisMock() *RedisMock {
 		closed:   false,
 		stopChan: make(chan struct{}),
 	}
+	mock.cond = sync.NewCond(&mock.mutex)
 	
 	// 启动过期键清理协程
 	mock.startCleanup()
@@ -70,23 +75,24 @@ func (r *RedisMock) cleanExpiredKeys() {
 	}
 }
 
-// isExpired 检查键是否过期
+// isExpired 检查键是否不存在或已过期，持有读锁即可调用
+// 过期的键不在这里删除，由写操作覆盖或由cleanExpiredKeys在写锁下清理
 func (r *RedisMock) isExpired(key string) bool {
 	value, exists := r.data[key]
 	if !exists {
 		return true
 	}
-	if value.ExpireAt != nil && time.Now().After(*value.ExpireAt) {
-		delete(r.data, key)
-		return true
-	}
-	return false
+	return value.ExpireAt != nil && time.Now().After(*value.ExpireAt)
 }
 
 // 基础操作
 func (r *RedisMock) Ping(ctx context.Context) *StatusCmd {
+	if err := ctx.Err(); err != nil {
+		return &StatusCmd{err: err}
+	}
+	
 	if r.closed {
-		return &StatusCmd{err: fmt.Errorf("redis connection closed")}
+		return &StatusCmd{err: rediserr.Closed}
 	}
 	return &StatusCmd{val: "PONG"}
 }
@@ -104,36 +110,49 @@ func (r *RedisMock) Close() error {
 		r.cleanup.Stop()
 	}
 	close(r.stopChan)
+	// 唤醒所有阻塞中的命令
+	r.cond.Broadcast()
 	return nil
 }
 
 // 字符串操作
 func (r *RedisMock) Get(ctx context.Context, key string) *StringCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringCmd{err: err}
+	}
+	
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	if r.closed {
-		return &StringCmd{err: fmt.Errorf("redis connection closed")}
+		return &StringCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
-		return &StringCmd{err: fmt.Errorf("redis: nil")}
+		return &StringCmd{err: rediserr.Nil}
 	}
 	
 	value, exists := r.data[key]
-	if !exists || value.Type != "string" {
-		return &StringCmd{err: fmt.Errorf("redis: nil")}
+	if !exists {
+		return &StringCmd{err: rediserr.Nil}
+	}
+	if value.Type != "string" {
+		return &StringCmd{err: rediserr.WrongType}
 	}
 	
 	return &StringCmd{val: fmt.Sprintf("%v", value.Value)}
 }
 
 func (r *RedisMock) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd {
+	if err := ctx.Err(); err != nil {
+		return &StatusCmd{err: err}
+	}
+	
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
 	if r.closed {
-		return &StatusCmd{err: fmt.Errorf("redis connection closed")}
+		return &StatusCmd{err: rediserr.Closed}
 	}
 	
 	redisValue := &RedisValue{
@@ -145,6 +164,8 @@ func (r *RedisMock) Set(ctx context.Context, key string, value interface{}, expi
 	if expiration > 0 {
 		expireAt := time.Now().Add(expiration)
 		redisValue.ExpireAt = &expireAt
+	} else if expiration == KeepTTL && !r.isExpired(key) {
+		redisValue.ExpireAt = r.data[key].ExpireAt
 	}
 	
 	r.data[key] = redisValue
@@ -152,11 +173,15 @@ func (r *RedisMock) Set(ctx context.Context, key string, value interface{}, expi
 }
 
 func (r *RedisMock) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) *BoolCmd {
+	if err := ctx.Err(); err != nil {
+		return &BoolCmd{err: err}
+	}
+	
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
 	if r.closed {
-		return &BoolCmd{err: fmt.Errorf("redis connection closed")}
+		return &BoolCmd{err: rediserr.Closed}
 	}
 	
 	if !r.isExpired(key) {
@@ -181,30 +206,38 @@ func (r *RedisMock) SetNX(ctx context.Context, key string, value interface{}, ex
 }
 
 func (r *RedisMock) Del(ctx context.Context, keys ...string) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+	
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
 	if r.closed {
-		return &IntCmd{err: fmt.Errorf("redis connection closed")}
+		return &IntCmd{err: rediserr.Closed}
 	}
 	
 	count := int64(0)
 	for _, key := range keys {
-		if _, exists := r.data[key]; exists {
-			delete(r.data, key)
+		if !r.isExpired(key) {
 			count++
 		}
+		delete(r.data, key)
 	}
 	
 	return &IntCmd{val: count}
 }
 
 func (r *RedisMock) Exists(ctx context.Context, keys ...string) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+	
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	if r.closed {
-		return &IntCmd{err: fmt.Errorf("redis connection closed")}
+		return &IntCmd{err: rediserr.Closed}
 	}
 	
 	count := int64(0)
@@ -220,11 +253,15 @@ func (r *RedisMock) Exists(ctx context.Context, keys ...string) *IntCmd {
 }
 
 func (r *RedisMock) Expire(ctx context.Context, key string, expiration time.Duration) *BoolCmd {
+	if err := ctx.Err(); err != nil {
+		return &BoolCmd{err: err}
+	}
+	
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
 	if r.closed {
-		return &BoolCmd{err: fmt.Errorf("redis connection closed")}
+		return &BoolCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
@@ -241,12 +278,40 @@ func (r *RedisMock) Expire(ctx context.Context, key string, expiration time.Dura
 	return &BoolCmd{val: true}
 }
 
+// Rename 将键连同其TTL移动到newKey，覆盖newKey原有的值
+func (r *RedisMock) Rename(ctx context.Context, key, newKey string) *StatusCmd {
+	if err := ctx.Err(); err != nil {
+		return &StatusCmd{err: err}
+	}
+	
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	
+	if r.closed {
+		return &StatusCmd{err: rediserr.Closed}
+	}
+	
+	if r.isExpired(key) {
+		return &StatusCmd{err: rediserr.NoSuchKey}
+	}
+	
+	value := r.data[key]
+	delete(r.data, key)
+	r.data[newKey] = value
+	r.cond.Broadcast()
+	return &StatusCmd{val: "OK"}
+}
+
 func (r *RedisMock) TTL(ctx context.Context, key string) *DurationCmd {
+	if err := ctx.Err(); err != nil {
+		return &DurationCmd{err: err}
+	}
+	
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	if r.closed {
-		return &DurationCmd{err: fmt.Errorf("redis connection closed")}
+		return &DurationCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
@@ -272,45 +337,56 @@ func (r *RedisMock) TTL(ctx context.Context, key string) *DurationCmd {
 
 // 哈希操作
 func (r *RedisMock) HGet(ctx context.Context, key, field string) *StringCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringCmd{err: err}
+	}
+	
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	if r.closed {
-		return &StringCmd{err: fmt.Errorf("redis connection closed")}
+		return &StringCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
-		return &StringCmd{err: fmt.Errorf("redis: nil")}
+		return &StringCmd{err: rediserr.Nil}
 	}
 	
 	value, exists := r.data[key]
-	if !exists || value.Type != "hash" {
-		return &StringCmd{err: fmt.Errorf("redis: nil")}
+	if !exists {
+		return &StringCmd{err: rediserr.Nil}
+	}
+	if value.Type != "hash" {
+		return &StringCmd{err: rediserr.WrongType}
 	}
 	
 	hash, ok := value.Value.(map[string]string)
 	if !ok {
-		return &StringCmd{err: fmt.Errorf("redis: nil")}
+		return &StringCmd{err: rediserr.Nil}
 	}
 	
 	fieldValue, exists := hash[field]
 	if !exists {
-		return &StringCmd{err: fmt.Errorf("redis: nil")}
+		return &StringCmd{err: rediserr.Nil}
 	}
 	
 	return &StringCmd{val: fieldValue}
 }
 
 func (r *RedisMock) HSet(ctx context.Context, key string, values ...interface{}) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+	
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
 	if r.closed {
-		return &IntCmd{err: fmt.Errorf("redis connection closed")}
+		return &IntCmd{err: rediserr.Closed}
 	}
 	
 	if len(values)%2 != 0 {
-		return &IntCmd{err: fmt.Errorf("wrong number of arguments")}
+		return &IntCmd{err: rediserr.Err("wrong number of arguments for 'hset' command")}
 	}
 	
 	value, exists := r.data[key]
@@ -324,7 +400,7 @@ func (r *RedisMock) HSet(ctx context.Context, key string, values ...interface{})
 			CreatedAt: time.Now(),
 		}
 	} else if value.Type != "hash" {
-		return &IntCmd{err: fmt.Errorf("WRONGTYPE Operation against a key holding the wrong kind of value")}
+		return &IntCmd{err: rediserr.WrongType}
 	} else {
 		hash = value.Value.(map[string]string)
 	}
@@ -344,11 +420,15 @@ func (r *RedisMock) HSet(ctx context.Context, key string, values ...interface{})
 }
 
 func (r *RedisMock) HDel(ctx context.Context, key string, fields ...string) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+	
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
 	if r.closed {
-		return &IntCmd{err: fmt.Errorf("redis connection closed")}
+		return &IntCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
@@ -356,9 +436,12 @@ func (r *RedisMock) HDel(ctx context.Context, key string, fields ...string) *Int
 	}
 	
 	value, exists := r.data[key]
-	if !exists || value.Type != "hash" {
+	if !exists {
 		return &IntCmd{val: 0}
 	}
+	if value.Type != "hash" {
+		return &IntCmd{err: rediserr.WrongType}
+	}
 	
 	hash := value.Value.(map[string]string)
 	count := int64(0)
@@ -374,11 +457,15 @@ func (r *RedisMock) HDel(ctx context.Context, key string, fields ...string) *Int
 }
 
 func (r *RedisMock) HExists(ctx context.Context, key, field string) *BoolCmd {
+	if err := ctx.Err(); err != nil {
+		return &BoolCmd{err: err}
+	}
+	
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	if r.closed {
-		return &BoolCmd{err: fmt.Errorf("redis connection closed")}
+		return &BoolCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
@@ -386,9 +473,12 @@ func (r *RedisMock) HExists(ctx context.Context, key, field string) *BoolCmd {
 	}
 	
 	value, exists := r.data[key]
-	if !exists || value.Type != "hash" {
+	if !exists {
 		return &BoolCmd{val: false}
 	}
+	if value.Type != "hash" {
+		return &BoolCmd{err: rediserr.WrongType}
+	}
 	
 	hash := value.Value.(map[string]string)
 	_, exists = hash[field]
@@ -397,11 +487,15 @@ func (r *RedisMock) HExists(ctx context.Context, key, field string) *BoolCmd {
 }
 
 func (r *RedisMock) HGetAll(ctx context.Context, key string) *StringStringMapCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringStringMapCmd{err: err}
+	}
+	
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	if r.closed {
-		return &StringStringMapCmd{err: fmt.Errorf("redis connection closed")}
+		return &StringStringMapCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
@@ -409,9 +503,12 @@ func (r *RedisMock) HGetAll(ctx context.Context, key string) *StringStringMapCmd
 	}
 	
 	value, exists := r.data[key]
-	if !exists || value.Type != "hash" {
+	if !exists {
 		return &StringStringMapCmd{val: make(map[string]string)}
 	}
+	if value.Type != "hash" {
+		return &StringStringMapCmd{err: rediserr.WrongType}
+	}
 	
 	hash := value.Value.(map[string]string)
 	result := make(map[string]string)
@@ -423,11 +520,15 @@ func (r *RedisMock) HGetAll(ctx context.Context, key string) *StringStringMapCmd
 }
 
 func (r *RedisMock) HKeys(ctx context.Context, key string) *StringSliceCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringSliceCmd{err: err}
+	}
+	
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	if r.closed {
-		return &StringSliceCmd{err: fmt.Errorf("redis connection closed")}
+		return &StringSliceCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
@@ -435,9 +536,12 @@ func (r *RedisMock) HKeys(ctx context.Context, key string) *StringSliceCmd {
 	}
 	
 	value, exists := r.data[key]
-	if !exists || value.Type != "hash" {
+	if !exists {
 		return &StringSliceCmd{val: []string{}}
 	}
+	if value.Type != "hash" {
+		return &StringSliceCmd{err: rediserr.WrongType}
+	}
 	
 	hash := value.Value.(map[string]string)
 	keys := make([]string, 0, len(hash))
@@ -449,11 +553,15 @@ func (r *RedisMock) HKeys(ctx context.Context, key string) *StringSliceCmd {
 }
 
 func (r *RedisMock) HVals(ctx context.Context, key string) *StringSliceCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringSliceCmd{err: err}
+	}
+	
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	if r.closed {
-		return &StringSliceCmd{err: fmt.Errorf("redis connection closed")}
+		return &StringSliceCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
@@ -461,9 +569,12 @@ func (r *RedisMock) HVals(ctx context.Context, key string) *StringSliceCmd {
 	}
 	
 	value, exists := r.data[key]
-	if !exists || value.Type != "hash" {
+	if !exists {
 		return &StringSliceCmd{val: []string{}}
 	}
+	if value.Type != "hash" {
+		return &StringSliceCmd{err: rediserr.WrongType}
+	}
 	
 	hash := value.Value.(map[string]string)
 	values := make([]string, 0, len(hash))
@@ -476,11 +587,15 @@ func (r *RedisMock) HVals(ctx context.Context, key string) *StringSliceCmd {
 
 // 列表操作
 func (r *RedisMock) LPush(ctx context.Context, key string, values ...interface{}) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+	
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
 	if r.closed {
-		return &IntCmd{err: fmt.Errorf("redis connection closed")}
+		return &IntCmd{err: rediserr.Closed}
 	}
 	
 	value, exists := r.data[key]
@@ -494,7 +609,7 @@ func (r *RedisMock) LPush(ctx context.Context, key string, values ...interface{}
 			CreatedAt: time.Now(),
 		}
 	} else if value.Type != "list" {
-		return &IntCmd{err: fmt.Errorf("WRONGTYPE Operation against a key holding the wrong kind of value")}
+		return &IntCmd{err: rediserr.WrongType}
 	} else {
 		list = value.Value.([]string)
 	}
@@ -505,15 +620,20 @@ func (r *RedisMock) LPush(ctx context.Context, key string, values ...interface{}
 	}
 	
 	r.data[key].Value = list
+	r.cond.Broadcast()
 	return &IntCmd{val: int64(len(list))}
 }
 
 func (r *RedisMock) RPush(ctx context.Context, key string, values ...interface{}) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+	
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
 	if r.closed {
-		return &IntCmd{err: fmt.Errorf("redis connection closed")}
+		return &IntCmd{err: rediserr.Closed}
 	}
 	
 	value, exists := r.data[key]
@@ -527,7 +647,7 @@ func (r *RedisMock) RPush(ctx context.Context, key string, values ...interface{}
 			CreatedAt: time.Now(),
 		}
 	} else if value.Type != "list" {
-		return &IntCmd{err: fmt.Errorf("WRONGTYPE Operation against a key holding the wrong kind of value")}
+		return &IntCmd{err: rediserr.WrongType}
 	} else {
 		list = value.Value.([]string)
 	}
@@ -538,29 +658,37 @@ func (r *RedisMock) RPush(ctx context.Context, key string, values ...interface{}
 	}
 	
 	r.data[key].Value = list
+	r.cond.Broadcast()
 	return &IntCmd{val: int64(len(list))}
 }
 
 func (r *RedisMock) LPop(ctx context.Context, key string) *StringCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringCmd{err: err}
+	}
+	
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
 	if r.closed {
-		return &StringCmd{err: fmt.Errorf("redis connection closed")}
+		return &StringCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
-		return &StringCmd{err: fmt.Errorf("redis: nil")}
+		return &StringCmd{err: rediserr.Nil}
 	}
 	
 	value, exists := r.data[key]
-	if !exists || value.Type != "list" {
-		return &StringCmd{err: fmt.Errorf("redis: nil")}
+	if !exists {
+		return &StringCmd{err: rediserr.Nil}
+	}
+	if value.Type != "list" {
+		return &StringCmd{err: rediserr.WrongType}
 	}
 	
 	list := value.Value.([]string)
 	if len(list) == 0 {
-		return &StringCmd{err: fmt.Errorf("redis: nil")}
+		return &StringCmd{err: rediserr.Nil}
 	}
 	
 	result := list[0]
@@ -575,25 +703,32 @@ func (r *RedisMock) LPop(ctx context.Context, key string) *StringCmd {
 }
 
 func (r *RedisMock) RPop(ctx context.Context, key string) *StringCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringCmd{err: err}
+	}
+	
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
 	if r.closed {
-		return &StringCmd{err: fmt.Errorf("redis connection closed")}
+		return &StringCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
-		return &StringCmd{err: fmt.Errorf("redis: nil")}
+		return &StringCmd{err: rediserr.Nil}
 	}
 	
 	value, exists := r.data[key]
-	if !exists || value.Type != "list" {
-		return &StringCmd{err: fmt.Errorf("redis: nil")}
+	if !exists {
+		return &StringCmd{err: rediserr.Nil}
+	}
+	if value.Type != "list" {
+		return &StringCmd{err: rediserr.WrongType}
 	}
 	
 	list := value.Value.([]string)
 	if len(list) == 0 {
-		return &StringCmd{err: fmt.Errorf("redis: nil")}
+		return &StringCmd{err: rediserr.Nil}
 	}
 	
 	result := list[len(list)-1]
@@ -608,11 +743,15 @@ func (r *RedisMock) RPop(ctx context.Context, key string) *StringCmd {
 }
 
 func (r *RedisMock) LLen(ctx context.Context, key string) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+	
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	if r.closed {
-		return &IntCmd{err: fmt.Errorf("redis connection closed")}
+		return &IntCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
@@ -620,20 +759,27 @@ func (r *RedisMock) LLen(ctx context.Context, key string) *IntCmd {
 	}
 	
 	value, exists := r.data[key]
-	if !exists || value.Type != "list" {
+	if !exists {
 		return &IntCmd{val: 0}
 	}
+	if value.Type != "list" {
+		return &IntCmd{err: rediserr.WrongType}
+	}
 	
 	list := value.Value.([]string)
 	return &IntCmd{val: int64(len(list))}
 }
 
 func (r *RedisMock) LRange(ctx context.Context, key string, start, stop int64) *StringSliceCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringSliceCmd{err: err}
+	}
+	
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	if r.closed {
-		return &StringSliceCmd{err: fmt.Errorf("redis connection closed")}
+		return &StringSliceCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
@@ -641,9 +787,12 @@ func (r *RedisMock) LRange(ctx context.Context, key string, start, stop int64) *
 	}
 	
 	value, exists := r.data[key]
-	if !exists || value.Type != "list" {
+	if !exists {
 		return &StringSliceCmd{val: []string{}}
 	}
+	if value.Type != "list" {
+		return &StringSliceCmd{err: rediserr.WrongType}
+	}
 	
 	list := value.Value.([]string)
 	length := int64(len(list))
@@ -675,11 +824,15 @@ func (r *RedisMock) LRange(ctx context.Context, key string, start, stop int64) *
 
 // 集合操作
 func (r *RedisMock) SAdd(ctx context.Context, key string, members ...interface{}) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+	
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
 	if r.closed {
-		return &IntCmd{err: fmt.Errorf("redis connection closed")}
+		return &IntCmd{err: rediserr.Closed}
 	}
 	
 	value, exists := r.data[key]
@@ -693,7 +846,7 @@ func (r *RedisMock) SAdd(ctx context.Context, key string, members ...interface{}
 			CreatedAt: time.Now(),
 		}
 	} else if value.Type != "set" {
-		return &IntCmd{err: fmt.Errorf("WRONGTYPE Operation against a key holding the wrong kind of value")}
+		return &IntCmd{err: rediserr.WrongType}
 	} else {
 		set = value.Value.(map[string]bool)
 	}
@@ -711,11 +864,15 @@ func (r *RedisMock) SAdd(ctx context.Context, key string, members ...interface{}
 }
 
 func (r *RedisMock) SRem(ctx context.Context, key string, members ...interface{}) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+	
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
 	if r.closed {
-		return &IntCmd{err: fmt.Errorf("redis connection closed")}
+		return &IntCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
@@ -723,9 +880,12 @@ func (r *RedisMock) SRem(ctx context.Context, key string, members ...interface{}
 	}
 	
 	value, exists := r.data[key]
-	if !exists || value.Type != "set" {
+	if !exists {
 		return &IntCmd{val: 0}
 	}
+	if value.Type != "set" {
+		return &IntCmd{err: rediserr.WrongType}
+	}
 	
 	set := value.Value.(map[string]bool)
 	count := int64(0)
@@ -742,11 +902,15 @@ func (r *RedisMock) SRem(ctx context.Context, key string, members ...interface{}
 }
 
 func (r *RedisMock) SMembers(ctx context.Context, key string) *StringSliceCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringSliceCmd{err: err}
+	}
+	
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	if r.closed {
-		return &StringSliceCmd{err: fmt.Errorf("redis connection closed")}
+		return &StringSliceCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
@@ -754,9 +918,12 @@ func (r *RedisMock) SMembers(ctx context.Context, key string) *StringSliceCmd {
 	}
 	
 	value, exists := r.data[key]
-	if !exists || value.Type != "set" {
+	if !exists {
 		return &StringSliceCmd{val: []string{}}
 	}
+	if value.Type != "set" {
+		return &StringSliceCmd{err: rediserr.WrongType}
+	}
 	
 	set := value.Value.(map[string]bool)
 	members := make([]string, 0, len(set))
@@ -768,11 +935,15 @@ func (r *RedisMock) SMembers(ctx context.Context, key string) *StringSliceCmd {
 }
 
 func (r *RedisMock) SIsMember(ctx context.Context, key string, member interface{}) *BoolCmd {
+	if err := ctx.Err(); err != nil {
+		return &BoolCmd{err: err}
+	}
+	
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	if r.closed {
-		return &BoolCmd{err: fmt.Errorf("redis connection closed")}
+		return &BoolCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
@@ -780,9 +951,12 @@ func (r *RedisMock) SIsMember(ctx context.Context, key string, member interface{
 	}
 	
 	value, exists := r.data[key]
-	if !exists || value.Type != "set" {
+	if !exists {
 		return &BoolCmd{val: false}
 	}
+	if value.Type != "set" {
+		return &BoolCmd{err: rediserr.WrongType}
+	}
 	
 	set := value.Value.(map[string]bool)
 	memberStr := fmt.Sprintf("%v", member)
@@ -791,11 +965,15 @@ func (r *RedisMock) SIsMember(ctx context.Context, key string, member interface{
 }
 
 func (r *RedisMock) SCard(ctx context.Context, key string) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+	
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	if r.closed {
-		return &IntCmd{err: fmt.Errorf("redis connection closed")}
+		return &IntCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
@@ -803,9 +981,12 @@ func (r *RedisMock) SCard(ctx context.Context, key string) *IntCmd {
 	}
 	
 	value, exists := r.data[key]
-	if !exists || value.Type != "set" {
+	if !exists {
 		return &IntCmd{val: 0}
 	}
+	if value.Type != "set" {
+		return &IntCmd{err: rediserr.WrongType}
+	}
 	
 	set := value.Value.(map[string]bool)
 	return &IntCmd{val: int64(len(set))}
@@ -813,11 +994,15 @@ func (r *RedisMock) SCard(ctx context.Context, key string) *IntCmd {
 
 // 有序集合操作
 func (r *RedisMock) ZAdd(ctx context.Context, key string, members ...*Z) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+	
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
 	if r.closed {
-		return &IntCmd{err: fmt.Errorf("redis connection closed")}
+		return &IntCmd{err: rediserr.Closed}
 	}
 	
 	value, exists := r.data[key]
@@ -831,7 +1016,7 @@ func (r *RedisMock) ZAdd(ctx context.Context, key string, members ...*Z) *IntCmd
 			CreatedAt: time.Now(),
 		}
 	} else if value.Type != "zset" {
-		return &IntCmd{err: fmt.Errorf("WRONGTYPE Operation against a key holding the wrong kind of value")}
+		return &IntCmd{err: rediserr.WrongType}
 	} else {
 		zset = value.Value.(map[string]float64)
 	}
@@ -845,15 +1030,20 @@ func (r *RedisMock) ZAdd(ctx context.Context, key string, members ...*Z) *IntCmd
 		zset[memberStr] = member.Score
 	}
 	
+	r.cond.Broadcast()
 	return &IntCmd{val: count}
 }
 
 func (r *RedisMock) ZRem(ctx context.Context, key string, members ...interface{}) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+	
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
 	if r.closed {
-		return &IntCmd{err: fmt.Errorf("redis connection closed")}
+		return &IntCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
@@ -861,9 +1051,12 @@ func (r *RedisMock) ZRem(ctx context.Context, key string, members ...interface{}
 	}
 	
 	value, exists := r.data[key]
-	if !exists || value.Type != "zset" {
+	if !exists {
 		return &IntCmd{val: 0}
 	}
+	if value.Type != "zset" {
+		return &IntCmd{err: rediserr.WrongType}
+	}
 	
 	zset := value.Value.(map[string]float64)
 	count := int64(0)
@@ -880,11 +1073,15 @@ func (r *RedisMock) ZRem(ctx context.Context, key string, members ...interface{}
 }
 
 func (r *RedisMock) ZRange(ctx context.Context, key string, start, stop int64) *StringSliceCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringSliceCmd{err: err}
+	}
+	
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	if r.closed {
-		return &StringSliceCmd{err: fmt.Errorf("redis connection closed")}
+		return &StringSliceCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
@@ -892,9 +1089,12 @@ func (r *RedisMock) ZRange(ctx context.Context, key string, start, stop int64) *
 	}
 	
 	value, exists := r.data[key]
-	if !exists || value.Type != "zset" {
+	if !exists {
 		return &StringSliceCmd{val: []string{}}
 	}
+	if value.Type != "zset" {
+		return &StringSliceCmd{err: rediserr.WrongType}
+	}
 	
 	zset := value.Value.(map[string]float64)
 	
@@ -943,11 +1143,15 @@ func (r *RedisMock) ZRange(ctx context.Context, key string, start, stop int64) *
 }
 
 func (r *RedisMock) ZRangeWithScores(ctx context.Context, key string, start, stop int64) *ZSliceCmd {
+	if err := ctx.Err(); err != nil {
+		return &ZSliceCmd{err: err}
+	}
+	
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	if r.closed {
-		return &ZSliceCmd{err: fmt.Errorf("redis connection closed")}
+		return &ZSliceCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
@@ -955,9 +1159,12 @@ func (r *RedisMock) ZRangeWithScores(ctx context.Context, key string, start, sto
 	}
 	
 	value, exists := r.data[key]
-	if !exists || value.Type != "zset" {
+	if !exists {
 		return &ZSliceCmd{val: []Z{}}
 	}
+	if value.Type != "zset" {
+		return &ZSliceCmd{err: rediserr.WrongType}
+	}
 	
 	zset := value.Value.(map[string]float64)
 	
@@ -1009,11 +1216,15 @@ func (r *RedisMock) ZRangeWithScores(ctx context.Context, key string, start, sto
 }
 
 func (r *RedisMock) ZCard(ctx context.Context, key string) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+	
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	if r.closed {
-		return &IntCmd{err: fmt.Errorf("redis connection closed")}
+		return &IntCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
@@ -1021,35 +1232,45 @@ func (r *RedisMock) ZCard(ctx context.Context, key string) *IntCmd {
 	}
 	
 	value, exists := r.data[key]
-	if !exists || value.Type != "zset" {
+	if !exists {
 		return &IntCmd{val: 0}
 	}
+	if value.Type != "zset" {
+		return &IntCmd{err: rediserr.WrongType}
+	}
 	
 	zset := value.Value.(map[string]float64)
 	return &IntCmd{val: int64(len(zset))}
 }
 
 func (r *RedisMock) ZScore(ctx context.Context, key, member string) *FloatCmd {
+	if err := ctx.Err(); err != nil {
+		return &FloatCmd{err: err}
+	}
+	
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	if r.closed {
-		return &FloatCmd{err: fmt.Errorf("redis connection closed")}
+		return &FloatCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
-		return &FloatCmd{err: fmt.Errorf("redis: nil")}
+		return &FloatCmd{err: rediserr.Nil}
 	}
 	
 	value, exists := r.data[key]
-	if !exists || value.Type != "zset" {
-		return &FloatCmd{err: fmt.Errorf("redis: nil")}
+	if !exists {
+		return &FloatCmd{err: rediserr.Nil}
+	}
+	if value.Type != "zset" {
+		return &FloatCmd{err: rediserr.WrongType}
 	}
 	
 	zset := value.Value.(map[string]float64)
 	score, exists := zset[member]
 	if !exists {
-		return &FloatCmd{err: fmt.Errorf("redis: nil")}
+		return &FloatCmd{err: rediserr.Nil}
 	}
 	
 	return &FloatCmd{val: score}
@@ -1057,11 +1278,15 @@ func (r *RedisMock) ZScore(ctx context.Context, key, member string) *FloatCmd {
 
 // 键操作
 func (r *RedisMock) Keys(ctx context.Context, pattern string) *StringSliceCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringSliceCmd{err: err}
+	}
+	
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	if r.closed {
-		return &StringSliceCmd{err: fmt.Errorf("redis connection closed")}
+		return &StringSliceCmd{err: rediserr.Closed}
 	}
 	
 	keys := make([]string, 0)
@@ -1077,12 +1302,65 @@ func (r *RedisMock) Keys(ctx context.Context, pattern string) *StringSliceCmd {
 	return &StringSliceCmd{val: keys}
 }
 
+// Scan 按键名排序遍历，游标为已遍历的键数量
+// 与Redis一致，先按count取出一批键再按match过滤，因此一页可能为空
+func (r *RedisMock) Scan(ctx context.Context, cursor uint64, match string, count int64) *ScanCmd {
+	if err := ctx.Err(); err != nil {
+		return &ScanCmd{err: err}
+	}
+	
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	
+	if r.closed {
+		return &ScanCmd{err: rediserr.Closed}
+	}
+	
+	if count <= 0 {
+		count = 10
+	}
+	
+	keys := make([]string, 0, len(r.data))
+	for key := range r.data {
+		if !r.isExpired(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	
+	if cursor >= uint64(len(keys)) {
+		return &ScanCmd{page: []string{}, cursor: 0}
+	}
+	end := cursor + uint64(count)
+	next := end
+	if end >= uint64(len(keys)) {
+		end = uint64(len(keys))
+		next = 0
+	}
+	
+	page := make([]string, 0, end-cursor)
+	for _, key := range keys[cursor:end] {
+		if match != "" {
+			if matched, _ := filepath.Match(match, key); !matched {
+				continue
+			}
+		}
+		page = append(page, key)
+	}
+	
+	return &ScanCmd{page: page, cursor: next}
+}
+
 func (r *RedisMock) Type(ctx context.Context, key string) *StatusCmd {
+	if err := ctx.Err(); err != nil {
+		return &StatusCmd{err: err}
+	}
+	
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	if r.closed {
-		return &StatusCmd{err: fmt.Errorf("redis connection closed")}
+		return &StatusCmd{err: rediserr.Closed}
 	}
 	
 	if r.isExpired(key) {
@@ -1098,11 +1376,15 @@ func (r *RedisMock) Type(ctx context.Context, key string) *StatusCmd {
 }
 
 func (r *RedisMock) FlushDB(ctx context.Context) *StatusCmd {
+	if err := ctx.Err(); err != nil {
+		return &StatusCmd{err: err}
+	}
+	
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
 	if r.closed {
-		return &StatusCmd{err: fmt.Errorf("redis connection closed")}
+		return &StatusCmd{err: rediserr.Closed}
 	}
 	
 	r.data = make(map[string]*RedisValue)
@@ -1110,11 +1392,15 @@ func (r *RedisMock) FlushDB(ctx context.Context) *StatusCmd {
 }
 
 func (r *RedisMock) FlushAll(ctx context.Context) *StatusCmd {
+	if err := ctx.Err(); err != nil {
+		return &StatusCmd{err: err}
+	}
+	
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
 	if r.closed {
-		return &StatusCmd{err: fmt.Errorf("redis connection closed")}
+		return &StatusCmd{err: rediserr.Closed}
 	}
 	
 	r.data = make(map[string]*RedisValue)
@@ -1123,15 +1409,19 @@ func (r *RedisMock) FlushAll(ctx context.Context) *StatusCmd {
 
 // 数据库操作
 func (r *RedisMock) Select(ctx context.Context, index int) *StatusCmd {
+	if err := ctx.Err(); err != nil {
+		return &StatusCmd{err: err}
+	}
+	
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 	
 	if r.closed {
-		return &StatusCmd{err: fmt.Errorf("redis connection closed")}
+		return &StatusCmd{err: rediserr.Closed}
 	}
 	
 	if index < 0 || index > 15 {
-		return &StatusCmd{err: fmt.Errorf("invalid DB index")}
+		return &StatusCmd{err: rediserr.InvalidDB}
 	}
 	
 	r.db = index
@@ -1139,11 +1429,15 @@ func (r *RedisMock) Select(ctx context.Context, index int) *StatusCmd {
 }
 
 func (r *RedisMock) DBSize(ctx context.Context) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+	
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 	
 	if r.closed {
-		return &IntCmd{err: fmt.Errorf("redis connection closed")}
+		return &IntCmd{err: rediserr.Closed}
 	}
 	
 	count := int64(0)
@@ -1156,6 +1450,59 @@ func (r *RedisMock) DBSize(ctx context.Context) *IntCmd {
 	return &IntCmd{val: count}
 }
 
+// Info 返回server与keyspace两个部分，未指定部分或指定all、default、everything时全部返回
+// 模拟实例只有当前数据库的数据，keyspace中只会出现当前数据库，没有键时为空
+func (r *RedisMock) Info(ctx context.Context, sections ...string) *StringCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringCmd{err: err}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.closed {
+		return &StringCmd{err: rediserr.Closed}
+	}
+
+	wanted := make(map[string]bool)
+	for _, section := range sections {
+		wanted[strings.ToLower(section)] = true
+	}
+	all := len(wanted) == 0 || wanted["all"] || wanted["default"] || wanted["everything"]
+
+	var parts []string
+	if all || wanted["server"] {
+		parts = append(parts, "# Server\r\nredis_version:7.0.0\r\nredis_mode:standalone\r\n")
+	}
+	if all || wanted["keyspace"] {
+		part := "# Keyspace\r\n"
+		now := time.Now()
+		var keys, expires int64
+		var ttl time.Duration
+		for _, value := range r.data {
+			if value.ExpireAt == nil {
+				keys++
+				continue
+			}
+			if now.After(*value.ExpireAt) {
+				continue
+			}
+			keys++
+			expires++
+			ttl += value.ExpireAt.Sub(now)
+		}
+		if keys > 0 {
+			var avgTTL int64
+			if expires > 0 {
+				avgTTL = ttl.Milliseconds() / expires
+			}
+			part += fmt.Sprintf("db%d:keys=%d,expires=%d,avg_ttl=%d\r\n", r.db, keys, expires, avgTTL)
+		}
+		parts = append(parts, part)
+	}
+	return &StringCmd{val: strings.Join(parts, "\r\n")}
+}
+
 // GetDB 获取当前数据库索引
 func (r *RedisMock) GetDB() int {
 	r.mutex.RLock()
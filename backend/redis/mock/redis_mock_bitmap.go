@@ -0,0 +1,485 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+// 位图命令使用的Redis错误
+var (
+	errBitOffset     = rediserr.Err("bit offset is not an integer or out of range")
+	errBitValue      = rediserr.Err("bit is not an integer or out of range")
+	errBitPosArg     = rediserr.Err("The bit argument must be 1 or 0.")
+	errBitFieldType  = rediserr.Err("Invalid bitfield type. Use something like i16 u8. Note that u64 is not supported but i64 is.")
+	errBitFieldOFlow = rediserr.Err("Invalid OVERFLOW type specified")
+)
+
+// maxBitOffset 位偏移上限，对应512MB的字符串
+const maxBitOffset = 1<<32 - 1
+
+// getBits 读取位图字节，键不存在时返回nil，调用方需持有锁
+func (r *RedisMock) getBits(key string) ([]byte, error) {
+	value, exists, err := r.getString(key)
+	if err != nil || !exists {
+		return nil, err
+	}
+	return []byte(value), nil
+}
+
+// setBits 写回位图字节并保留TTL，调用方需持有写锁
+func (r *RedisMock) setBits(key string, data []byte) {
+	r.setString(key, string(data), r.currentExpireAt(key))
+}
+
+// growBits 将位图扩展到能容纳指定位偏移
+func growBits(data []byte, bitOffset uint64) []byte {
+	need := int(bitOffset/8) + 1
+	if len(data) >= need {
+		return data
+	}
+	return append(data, make([]byte, need-len(data))...)
+}
+
+// bitAt 读取位，0号位是第一个字节的最高位
+func bitAt(data []byte, offset uint64) int64 {
+	byteIndex := offset / 8
+	if byteIndex >= uint64(len(data)) {
+		return 0
+	}
+	return int64(data[byteIndex]>>(7-offset%8)) & 1
+}
+
+// setBitAt 写入位，调用方需保证data足够长
+func setBitAt(data []byte, offset uint64, value int64) {
+	mask := byte(1) << (7 - offset%8)
+	if value != 0 {
+		data[offset/8] |= mask
+	} else {
+		data[offset/8] &^= mask
+	}
+}
+
+// byteRange 按GETRANGE规则换算字节范围，ok为false表示范围为空
+func byteRange(length, start, end int64) (int64, int64, bool) {
+	if start < 0 {
+		start += length
+	}
+	if end < 0 {
+		end += length
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end < 0 {
+		end = 0
+	}
+	if end >= length {
+		end = length - 1
+	}
+	return start, end, length > 0 && start <= end
+}
+
+func (r *RedisMock) SetBit(ctx context.Context, key string, offset int64, value int) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+	if offset < 0 || offset > maxBitOffset {
+		return &IntCmd{err: errBitOffset}
+	}
+	if value != 0 && value != 1 {
+		return &IntCmd{err: errBitValue}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &IntCmd{err: rediserr.Closed}
+	}
+
+	data, err := r.getBits(key)
+	if err != nil {
+		return &IntCmd{err: err}
+	}
+
+	data = growBits(data, uint64(offset))
+	old := bitAt(data, uint64(offset))
+	setBitAt(data, uint64(offset), int64(value))
+	r.setBits(key, data)
+
+	return &IntCmd{val: old}
+}
+
+func (r *RedisMock) GetBit(ctx context.Context, key string, offset int64) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+	if offset < 0 || offset > maxBitOffset {
+		return &IntCmd{err: errBitOffset}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.closed {
+		return &IntCmd{err: rediserr.Closed}
+	}
+
+	data, err := r.getBits(key)
+	if err != nil {
+		return &IntCmd{err: err}
+	}
+
+	return &IntCmd{val: bitAt(data, uint64(offset))}
+}
+
+func (r *RedisMock) BitCount(ctx context.Context, key string, bitCount *BitCount) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.closed {
+		return &IntCmd{err: rediserr.Closed}
+	}
+
+	data, err := r.getBits(key)
+	if err != nil {
+		return &IntCmd{err: err}
+	}
+
+	start, end := int64(0), int64(-1)
+	if bitCount != nil {
+		start, end = bitCount.Start, bitCount.End
+	}
+	start, end, ok := byteRange(int64(len(data)), start, end)
+	if !ok {
+		return &IntCmd{val: 0}
+	}
+
+	var count int64
+	for _, b := range data[start : end+1] {
+		count += int64(bits.OnesCount8(b))
+	}
+	return &IntCmd{val: count}
+}
+
+// BitPos pos依次为起始字节和结束字节
+func (r *RedisMock) BitPos(ctx context.Context, key string, bit int64, pos ...int64) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+	if bit != 0 && bit != 1 {
+		return &IntCmd{err: errBitPosArg}
+	}
+	if len(pos) > 2 {
+		return &IntCmd{err: rediserr.Syntax}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.closed {
+		return &IntCmd{err: rediserr.Closed}
+	}
+
+	data, err := r.getBits(key)
+	if err != nil {
+		return &IntCmd{err: err}
+	}
+
+	// 键不存在时视为全0的字符串
+	if data == nil {
+		if bit == 1 {
+			return &IntCmd{val: -1}
+		}
+		return &IntCmd{val: 0}
+	}
+
+	start, end := int64(0), int64(-1)
+	if len(pos) > 0 {
+		start = pos[0]
+	}
+	endGiven := len(pos) > 1
+	if endGiven {
+		end = pos[1]
+	}
+	start, end, ok := byteRange(int64(len(data)), start, end)
+	if !ok {
+		return &IntCmd{val: -1}
+	}
+
+	for i := start; i <= end; i++ {
+		for j := uint64(0); j < 8; j++ {
+			offset := uint64(i)*8 + j
+			if bitAt(data, offset) == bit {
+				return &IntCmd{val: int64(offset)}
+			}
+		}
+	}
+
+	// 查找0且未指定结束位置时，字符串右侧视为补0
+	if bit == 0 && !endGiven {
+		return &IntCmd{val: (end + 1) * 8}
+	}
+	return &IntCmd{val: -1}
+}
+
+// bitfieldType BITFIELD的整数类型
+type bitfieldType struct {
+	signed bool
+	bits   uint
+}
+
+// parseBitfieldType 解析i8、u16等类型
+func parseBitfieldType(s string) (bitfieldType, error) {
+	if len(s) < 2 {
+		return bitfieldType{}, errBitFieldType
+	}
+	signed := s[0] == 'i' || s[0] == 'I'
+	if !signed && s[0] != 'u' && s[0] != 'U' {
+		return bitfieldType{}, errBitFieldType
+	}
+	n, err := strconv.Atoi(s[1:])
+	if err != nil || n < 1 || (signed && n > 64) || (!signed && n > 63) {
+		return bitfieldType{}, errBitFieldType
+	}
+	return bitfieldType{signed: signed, bits: uint(n)}, nil
+}
+
+// parseBitfieldOffset 解析位偏移，#N表示按类型宽度计算的第N个字段
+func parseBitfieldOffset(s string, t bitfieldType) (uint64, error) {
+	multiply := strings.HasPrefix(s, "#")
+	n, err := strconv.ParseInt(strings.TrimPrefix(s, "#"), 10, 64)
+	if err != nil || n < 0 {
+		return 0, errBitOffset
+	}
+	if multiply {
+		n *= int64(t.bits)
+	}
+	if n < 0 || n > maxBitOffset || uint64(n)+uint64(t.bits) > maxBitOffset+1 {
+		return 0, errBitOffset
+	}
+	return uint64(n), nil
+}
+
+// readBitfield 按大端位序读取整数
+func readBitfield(data []byte, offset uint64, t bitfieldType) int64 {
+	var value uint64
+	for i := uint64(0); i < uint64(t.bits); i++ {
+		value = value<<1 | uint64(bitAt(data, offset+i))
+	}
+	if t.signed && t.bits < 64 && value&(1<<(t.bits-1)) != 0 {
+		value |= math.MaxUint64 << t.bits
+	}
+	return int64(value)
+}
+
+// writeBitfield 按大端位序写入整数，调用方需保证data足够长
+func writeBitfield(data []byte, offset uint64, t bitfieldType, value int64) {
+	for i := uint64(0); i < uint64(t.bits); i++ {
+		bit := (uint64(value) >> (uint64(t.bits) - 1 - i)) & 1
+		setBitAt(data, offset+i, int64(bit))
+	}
+}
+
+// bitfieldOverflow 按OVERFLOW策略计算value+incr，ok为false表示FAIL策略下溢出
+// 与Redis的checkSignedBitfieldOverflow/checkUnsignedBitfieldOverflow一致
+func bitfieldOverflow(value, incr int64, t bitfieldType, policy string) (int64, bool) {
+	if !t.signed {
+		max := uint64(1)<<t.bits - 1
+		v := uint64(value)
+		wrap := func() int64 {
+			return int64((v + uint64(incr)) & max)
+		}
+		if v > max || (incr > 0 && uint64(incr) > max-v) {
+			switch policy {
+			case "WRAP":
+				return wrap(), true
+			case "SAT":
+				return int64(max), true
+			}
+			return 0, false
+		}
+		if incr < 0 && uint64(-incr) > v {
+			switch policy {
+			case "WRAP":
+				return wrap(), true
+			case "SAT":
+				return 0, true
+			}
+			return 0, false
+		}
+		return int64(v + uint64(incr)), true
+	}
+
+	max := int64(math.MaxInt64)
+	if t.bits < 64 {
+		max = int64(1)<<(t.bits-1) - 1
+	}
+	min := -max - 1
+	wrap := func() int64 {
+		c := uint64(value) + uint64(incr)
+		if t.bits < 64 {
+			mask := uint64(math.MaxUint64) << t.bits
+			if c&(1<<(t.bits-1)) != 0 {
+				c |= mask
+			} else {
+				c &^= mask
+			}
+		}
+		return int64(c)
+	}
+
+	if value > max || (incr > 0 && value > max-incr) {
+		switch policy {
+		case "WRAP":
+			return wrap(), true
+		case "SAT":
+			return max, true
+		}
+		return 0, false
+	}
+	if value < min || (incr < 0 && value < min-incr) {
+		switch policy {
+		case "WRAP":
+			return wrap(), true
+		case "SAT":
+			return min, true
+		}
+		return 0, false
+	}
+	return value + incr, true
+}
+
+// bitfieldOp 解析后的BITFIELD子命令
+type bitfieldOp struct {
+	name   string
+	t      bitfieldType
+	offset uint64
+	value  int64
+	policy string
+}
+
+// parseBitfieldArgs 解析BITFIELD参数，OVERFLOW只影响其后的子命令
+func parseBitfieldArgs(args []interface{}) ([]bitfieldOp, error) {
+	var ops []bitfieldOp
+	policy := "WRAP"
+
+	arg := func(i int) string {
+		return fmt.Sprintf("%v", args[i])
+	}
+
+	for i := 0; i < len(args); i++ {
+		name := strings.ToUpper(arg(i))
+		switch name {
+		case "OVERFLOW":
+			if i+1 >= len(args) {
+				return nil, rediserr.Syntax
+			}
+			policy = strings.ToUpper(arg(i + 1))
+			if policy != "WRAP" && policy != "SAT" && policy != "FAIL" {
+				return nil, errBitFieldOFlow
+			}
+			i++
+		case "GET", "SET", "INCRBY":
+			need := 3
+			if name == "GET" {
+				need = 2
+			}
+			if i+need >= len(args) {
+				return nil, rediserr.Syntax
+			}
+			t, err := parseBitfieldType(arg(i + 1))
+			if err != nil {
+				return nil, err
+			}
+			offset, err := parseBitfieldOffset(arg(i+2), t)
+			if err != nil {
+				return nil, err
+			}
+			op := bitfieldOp{name: name, t: t, offset: offset, policy: policy}
+			if name != "GET" {
+				op.value, err = strconv.ParseInt(arg(i+3), 10, 64)
+				if err != nil {
+					return nil, rediserr.NotInteger
+				}
+			}
+			ops = append(ops, op)
+			i += need
+		default:
+			return nil, rediserr.Syntax
+		}
+	}
+
+	return ops, nil
+}
+
+// BitField FAIL策略下溢出的子命令在结果中返回0且不写入
+func (r *RedisMock) BitField(ctx context.Context, key string, args ...interface{}) *IntSliceCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntSliceCmd{err: err}
+	}
+
+	ops, err := parseBitfieldArgs(args)
+	if err != nil {
+		return &IntSliceCmd{err: err}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &IntSliceCmd{err: rediserr.Closed}
+	}
+
+	data, err := r.getBits(key)
+	if err != nil {
+		return &IntSliceCmd{err: err}
+	}
+
+	results := make([]int64, 0, len(ops))
+	written := false
+	for _, op := range ops {
+		old := readBitfield(data, op.offset, op.t)
+		if op.name == "GET" {
+			results = append(results, old)
+			continue
+		}
+
+		var value int64
+		var ok bool
+		if op.name == "SET" {
+			value, ok = bitfieldOverflow(op.value, 0, op.t, op.policy)
+		} else {
+			value, ok = bitfieldOverflow(old, op.value, op.t, op.policy)
+		}
+		if !ok {
+			results = append(results, 0)
+			continue
+		}
+
+		data = growBits(data, op.offset+uint64(op.t.bits)-1)
+		writeBitfield(data, op.offset, op.t, value)
+		written = true
+
+		if op.name == "SET" {
+			results = append(results, old)
+		} else {
+			results = append(results, value)
+		}
+	}
+
+	if written {
+		r.setBits(key, data)
+	}
+	return &IntSliceCmd{val: results}
+}
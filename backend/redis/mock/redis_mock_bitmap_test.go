@@ -0,0 +1,148 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/devtoolbox/redis/hll"
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+func TestRedisMock_BitmapOperations(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	for _, day := range []int64{0, 1, 2, 4, 7, 20} {
+		mock.SetBit(ctx, "signin", day, 1)
+	}
+	if old := mock.SetBit(ctx, "signin", 1, 1).Val(); old != 1 {
+		t.Errorf("Expected old bit 1, got %d", old)
+	}
+	if val := mock.Get(ctx, "signin").Val(); val != "\xe9\x00\x08" {
+		t.Errorf("Unexpected bitmap bytes: %q", val)
+	}
+	if mock.GetBit(ctx, "signin", 4).Val() != 1 || mock.GetBit(ctx, "signin", 1000).Val() != 0 {
+		t.Error("Unexpected GetBit result")
+	}
+
+	if count := mock.BitCount(ctx, "signin", nil).Val(); count != 6 {
+		t.Errorf("Expected 6 set bits, got %d", count)
+	}
+	if count := mock.BitCount(ctx, "signin", &BitCount{Start: -2, End: -1}).Val(); count != 1 {
+		t.Errorf("Expected 1 set bit in last two bytes, got %d", count)
+	}
+
+	if pos := mock.BitPos(ctx, "signin", 0).Val(); pos != 3 {
+		t.Errorf("Expected first zero at 3, got %d", pos)
+	}
+	if pos := mock.BitPos(ctx, "signin", 1, 1).Val(); pos != 20 {
+		t.Errorf("Expected first one from byte 1 at 20, got %d", pos)
+	}
+	mock.Set(ctx, "ones", "\xff", 0)
+	if pos := mock.BitPos(ctx, "ones", 0).Val(); pos != 8 {
+		t.Errorf("Expected zero search past the end to return 8, got %d", pos)
+	}
+	if pos := mock.BitPos(ctx, "ones", 0, 0, 0).Val(); pos != -1 {
+		t.Errorf("Expected -1 with explicit end, got %d", pos)
+	}
+	if pos := mock.BitPos(ctx, "missing", 1).Val(); pos != -1 {
+		t.Errorf("Expected -1 for missing key, got %d", pos)
+	}
+
+	if err := mock.SetBit(ctx, "signin", -1, 1).Err(); err == nil {
+		t.Error("Expected error for negative offset")
+	}
+	mock.RPush(ctx, "list", "a")
+	if err := mock.GetBit(ctx, "list", 0).Err(); !errors.Is(err, rediserr.WrongType) {
+		t.Errorf("Expected WrongType, got %v", err)
+	}
+}
+
+func TestRedisMock_BitField(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	vals, err := mock.BitField(ctx, "bf", "SET", "i8", 0, 100, "GET", "i8", 0, "INCRBY", "u4", "#2", 3).Result()
+	if err != nil {
+		t.Fatalf("BitField failed: %v", err)
+	}
+	if len(vals) != 3 || vals[0] != 0 || vals[1] != 100 || vals[2] != 3 {
+		t.Errorf("Unexpected BitField result: %v", vals)
+	}
+
+	// WRAP为默认策略
+	if vals := mock.BitField(ctx, "bf", "INCRBY", "i8", 0, 100).Val(); vals[0] != -56 {
+		t.Errorf("Expected wrapped value -56, got %v", vals)
+	}
+	vals = mock.BitField(ctx, "bf", "OVERFLOW", "SAT", "INCRBY", "i8", 0, -100, "OVERFLOW", "FAIL", "INCRBY", "u4", "#2", 20).Val()
+	if vals[0] != -128 || vals[1] != 0 {
+		t.Errorf("Unexpected overflow results: %v", vals)
+	}
+	if val := mock.BitField(ctx, "bf", "GET", "u4", "#2").Val(); val[0] != 3 {
+		t.Errorf("Expected FAIL not to write, got %v", val)
+	}
+
+	if err := mock.BitField(ctx, "bf", "GET", "u64", 0).Err(); err == nil {
+		t.Error("Expected error for u64 type")
+	}
+	if err := mock.BitField(ctx, "bf", "GET", "i8").Err(); !errors.Is(err, rediserr.Syntax) {
+		t.Errorf("Expected syntax error, got %v", err)
+	}
+}
+
+func TestRedisMock_HyperLogLog(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	if mock.PFAdd(ctx, "uv").Val() != 1 {
+		t.Error("Expected PFADD to report creation")
+	}
+	if mock.PFAdd(ctx, "uv", "a", "b", "c").Val() != 1 {
+		t.Error("Expected PFADD to report change")
+	}
+	if mock.PFAdd(ctx, "uv", "a").Val() != 0 {
+		t.Error("Expected PFADD of existing element to report no change")
+	}
+	if count := mock.PFCount(ctx, "uv").Val(); count != 3 {
+		t.Errorf("Expected count 3, got %d", count)
+	}
+
+	// PFCOUNT把基数缓存到头部
+	h, err := hll.Parse([]byte(mock.Get(ctx, "uv").Val()))
+	if err != nil {
+		t.Fatalf("Stored value is not a valid HLL: %v", err)
+	}
+	if card, cached := h.CachedCount(); !cached || card != 3 {
+		t.Errorf("Expected cached count 3, got %d (%v)", card, cached)
+	}
+
+	for i := 0; i < 100; i++ {
+		mock.PFAdd(ctx, "uv2", fmt.Sprintf("user:%d", i))
+	}
+	if count := mock.PFCount(ctx, "uv", "uv2", "missing").Val(); count < 100 || count > 106 {
+		t.Errorf("Expected union count near 103, got %d", count)
+	}
+	if err := mock.PFMerge(ctx, "total", "uv", "uv2").Err(); err != nil {
+		t.Fatalf("PFMerge failed: %v", err)
+	}
+	if mock.PFCount(ctx, "total").Val() != mock.PFCount(ctx, "uv", "uv2").Val() {
+		t.Error("Expected merged count to equal union count")
+	}
+
+	// 写入的值可以通过SET原样恢复
+	mock.Set(ctx, "copy", mock.Get(ctx, "total").Val(), time.Minute)
+	if mock.PFCount(ctx, "copy").Val() != mock.PFCount(ctx, "total").Val() {
+		t.Error("Expected copied HLL to keep its count")
+	}
+
+	mock.Set(ctx, "str", "plain", 0)
+	if err := mock.PFAdd(ctx, "str", "x").Err(); !errors.Is(err, rediserr.WrongType) {
+		t.Errorf("Expected WRONGTYPE for non-HLL string, got %v", err)
+	}
+}
@@ -0,0 +1,332 @@
+package mock
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+// blockingWait 阻塞命令的等待状态，字段只在持有写锁时访问
+type blockingWait struct {
+	timedOut bool
+	stops    []func() bool
+}
+
+// newBlockingWait 创建阻塞等待，ctx取消或超时时唤醒等待者
+// 调用方需持有写锁，timeout为0表示不超时
+func (r *RedisMock) newBlockingWait(ctx context.Context, timeout time.Duration) *blockingWait {
+	wait := &blockingWait{}
+
+	wait.stops = append(wait.stops, context.AfterFunc(ctx, func() {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		r.cond.Broadcast()
+	}))
+
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, func() {
+			r.mutex.Lock()
+			defer r.mutex.Unlock()
+			wait.timedOut = true
+			r.cond.Broadcast()
+		})
+		wait.stops = append(wait.stops, timer.Stop)
+	}
+
+	return wait
+}
+
+// stop 释放等待相关的定时器与回调
+func (w *blockingWait) stop() {
+	for _, stop := range w.stops {
+		stop()
+	}
+}
+
+// blockUntil 在持有写锁时反复调用try，直到取到数据、出错、超时或ctx取消
+// try返回false表示暂无数据，继续等待写入方唤醒
+func (r *RedisMock) blockUntil(ctx context.Context, timeout time.Duration, try func() (bool, error)) error {
+	wait := r.newBlockingWait(ctx, timeout)
+	defer wait.stop()
+
+	for {
+		if r.closed {
+			return rediserr.Closed
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		ok, err := try()
+		if err != nil || ok {
+			return err
+		}
+
+		if wait.timedOut {
+			return rediserr.Nil
+		}
+		r.cond.Wait()
+	}
+}
+
+// popList 从列表头部或尾部弹出元素，调用方需持有写锁
+func (r *RedisMock) popList(key string, left bool) (string, bool, error) {
+	if r.isExpired(key) {
+		return "", false, nil
+	}
+
+	value := r.data[key]
+	if value.Type != "list" {
+		return "", false, rediserr.WrongType
+	}
+
+	list := value.Value.([]string)
+	if len(list) == 0 {
+		return "", false, nil
+	}
+
+	var result string
+	if left {
+		result = list[0]
+		list = list[1:]
+	} else {
+		result = list[len(list)-1]
+		list = list[:len(list)-1]
+	}
+
+	if len(list) == 0 {
+		delete(r.data, key)
+	} else {
+		value.Value = list
+	}
+
+	return result, true, nil
+}
+
+// checkListType 检查键是否为列表或不存在，调用方需持有锁
+func (r *RedisMock) checkListType(key string) error {
+	if r.isExpired(key) {
+		return nil
+	}
+	if r.data[key].Type != "list" {
+		return rediserr.WrongType
+	}
+	return nil
+}
+
+// pushList 向列表头部或尾部插入元素，调用方需持有写锁并已检查类型
+func (r *RedisMock) pushList(key, element string, left bool) {
+	var list []string
+	if r.isExpired(key) {
+		r.data[key] = &RedisValue{
+			Type:      "list",
+			CreatedAt: time.Now(),
+		}
+	} else {
+		list = r.data[key].Value.([]string)
+	}
+
+	if left {
+		list = append([]string{element}, list...)
+	} else {
+		list = append(list, element)
+	}
+	r.data[key].Value = list
+	r.cond.Broadcast()
+}
+
+// parseListDirection 解析LEFT/RIGHT方向参数
+func parseListDirection(pos string) (bool, error) {
+	switch strings.ToUpper(pos) {
+	case "LEFT":
+		return true, nil
+	case "RIGHT":
+		return false, nil
+	}
+	return false, rediserr.Syntax
+}
+
+// moveList 将元素从source移动到destination，调用方需持有写锁
+func (r *RedisMock) moveList(source, destination, srcpos, destpos string) (string, bool, error) {
+	srcLeft, err := parseListDirection(srcpos)
+	if err != nil {
+		return "", false, err
+	}
+	destLeft, err := parseListDirection(destpos)
+	if err != nil {
+		return "", false, err
+	}
+
+	// 与Redis一致，弹出前先检查两端类型
+	if err := r.checkListType(source); err != nil {
+		return "", false, err
+	}
+	if err := r.checkListType(destination); err != nil {
+		return "", false, err
+	}
+
+	element, ok, err := r.popList(source, srcLeft)
+	if err != nil || !ok {
+		return "", ok, err
+	}
+
+	r.pushList(destination, element, destLeft)
+	return element, true, nil
+}
+
+// blockingPop BLPOP/BRPOP的公共实现
+func (r *RedisMock) blockingPop(ctx context.Context, timeout time.Duration, left bool, keys []string) *StringSliceCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringSliceCmd{err: err}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var result []string
+	err := r.blockUntil(ctx, timeout, func() (bool, error) {
+		for _, key := range keys {
+			element, ok, err := r.popList(key, left)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				result = []string{key, element}
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return &StringSliceCmd{err: err}
+	}
+
+	return &StringSliceCmd{val: result}
+}
+
+func (r *RedisMock) LMove(ctx context.Context, source, destination, srcpos, destpos string) *StringCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringCmd{err: err}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &StringCmd{err: rediserr.Closed}
+	}
+
+	element, ok, err := r.moveList(source, destination, srcpos, destpos)
+	if err != nil {
+		return &StringCmd{err: err}
+	}
+	if !ok {
+		return &StringCmd{err: rediserr.Nil}
+	}
+
+	return &StringCmd{val: element}
+}
+
+// 阻塞列表操作
+func (r *RedisMock) BLPop(ctx context.Context, timeout time.Duration, keys ...string) *StringSliceCmd {
+	return r.blockingPop(ctx, timeout, true, keys)
+}
+
+func (r *RedisMock) BRPop(ctx context.Context, timeout time.Duration, keys ...string) *StringSliceCmd {
+	return r.blockingPop(ctx, timeout, false, keys)
+}
+
+func (r *RedisMock) BLMove(ctx context.Context, source, destination, srcpos, destpos string, timeout time.Duration) *StringCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringCmd{err: err}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var element string
+	err := r.blockUntil(ctx, timeout, func() (bool, error) {
+		var ok bool
+		var err error
+		element, ok, err = r.moveList(source, destination, srcpos, destpos)
+		return ok, err
+	})
+	if err != nil {
+		return &StringCmd{err: err}
+	}
+
+	return &StringCmd{val: element}
+}
+
+// popZSetMin 弹出有序集合中分数最小的成员，调用方需持有写锁
+func (r *RedisMock) popZSetMin(key string) (*ZWithKey, error) {
+	if r.isExpired(key) {
+		return nil, nil
+	}
+
+	value := r.data[key]
+	if value.Type != "zset" {
+		return nil, rediserr.WrongType
+	}
+
+	zset := value.Value.(map[string]float64)
+	if len(zset) == 0 {
+		return nil, nil
+	}
+
+	members := make([]string, 0, len(zset))
+	for member := range zset {
+		members = append(members, member)
+	}
+	// 分数相同时按成员字典序排列
+	sort.Slice(members, func(i, j int) bool {
+		if zset[members[i]] != zset[members[j]] {
+			return zset[members[i]] < zset[members[j]]
+		}
+		return members[i] < members[j]
+	})
+
+	member := members[0]
+	score := zset[member]
+	delete(zset, member)
+	if len(zset) == 0 {
+		delete(r.data, key)
+	}
+
+	return &ZWithKey{
+		Z:   Z{Score: score, Member: member},
+		Key: key,
+	}, nil
+}
+
+func (r *RedisMock) BZPopMin(ctx context.Context, timeout time.Duration, keys ...string) *ZWithKeyCmd {
+	if err := ctx.Err(); err != nil {
+		return &ZWithKeyCmd{err: err}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var result *ZWithKey
+	err := r.blockUntil(ctx, timeout, func() (bool, error) {
+		for _, key := range keys {
+			z, err := r.popZSetMin(key)
+			if err != nil {
+				return false, err
+			}
+			if z != nil {
+				result = z
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return &ZWithKeyCmd{err: err}
+	}
+
+	return &ZWithKeyCmd{val: result}
+}
@@ -0,0 +1,198 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRedisMock_ContextCancellation(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := mock.Set(ctx, "key", "value", 0).Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if mock.GetDataSize() != 0 {
+		t.Error("Expected canceled Set not to write data")
+	}
+
+	deadlineCtx, cancelDeadline := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelDeadline()
+	if err := mock.Get(deadlineCtx, "key").Err(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestRedisMock_BLPopWakesOnPush(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	done := make(chan *StringSliceCmd, 1)
+	go func() {
+		done <- mock.BLPop(ctx, 0, "queue:a", "queue:b")
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	mock.RPush(ctx, "queue:b", "job1")
+
+	select {
+	case cmd := <-done:
+		val, err := cmd.Result()
+		if err != nil {
+			t.Fatalf("BLPop failed: %v", err)
+		}
+		if len(val) != 2 || val[0] != "queue:b" || val[1] != "job1" {
+			t.Errorf("Expected [queue:b job1], got %v", val)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("BLPop was not woken by RPush")
+	}
+
+	if mock.LLen(ctx, "queue:b").Val() != 0 {
+		t.Error("Expected element to be consumed")
+	}
+}
+
+func TestRedisMock_BRPopTimeoutAndCancel(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+
+	start := time.Now()
+	err := mock.BRPop(context.Background(), 100*time.Millisecond, "empty").Err()
+	if err == nil || err.Error() != "redis: nil" {
+		t.Errorf("Expected redis: nil on timeout, got %v", err)
+	}
+	if time.Since(start) < 100*time.Millisecond {
+		t.Error("Expected BRPop to wait for the timeout")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if err := mock.BRPop(ctx, 0, "empty").Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	mock.Set(context.Background(), "str", "value", 0)
+	if err := mock.BRPop(context.Background(), time.Second, "str").Err(); err == nil {
+		t.Error("Expected WRONGTYPE error for string key")
+	}
+}
+
+func TestRedisMock_BLMoveAndBZPopMin(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	done := make(chan *StringCmd, 1)
+	go func() {
+		done <- mock.BLMove(ctx, "src", "dst", "LEFT", "RIGHT", time.Second)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	mock.LPush(ctx, "src", "item")
+
+	if val, err := (<-done).Result(); err != nil || val != "item" {
+		t.Errorf("Expected item, got %q (%v)", val, err)
+	}
+	if got := mock.LRange(ctx, "dst", 0, -1).Val(); len(got) != 1 || got[0] != "item" {
+		t.Errorf("Expected dst [item], got %v", got)
+	}
+
+	mock.ZAdd(ctx, "zset", &Z{Score: 3, Member: "c"}, &Z{Score: 1, Member: "a"})
+	z, err := mock.BZPopMin(ctx, time.Second, "missing", "zset").Result()
+	if err != nil {
+		t.Fatalf("BZPopMin failed: %v", err)
+	}
+	if z.Key != "zset" || z.Member != "a" || z.Score != 1 {
+		t.Errorf("Unexpected BZPopMin result: %+v", z)
+	}
+}
+
+func TestRedisMock_BlockingWokenByClose(t *testing.T) {
+	mock := NewRedisMock()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- mock.BLPop(context.Background(), 0, "queue").Err()
+	}()
+	time.Sleep(50 * time.Millisecond)
+	mock.Close()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("Expected error after Close")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("BLPop was not woken by Close")
+	}
+}
+
+func TestRedisMock_StreamOperations(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	id1, err := mock.XAdd(ctx, &XAddArgs{Stream: "events", ID: "1-1", Values: map[string]interface{}{"type": "login"}}).Result()
+	if err != nil || id1 != "1-1" {
+		t.Fatalf("XAdd failed: %q (%v)", id1, err)
+	}
+	if err := mock.XAdd(ctx, &XAddArgs{Stream: "events", ID: "1-1", Values: []string{"a", "b"}}).Err(); err == nil {
+		t.Error("Expected error for non-increasing ID")
+	}
+	id2 := mock.XAdd(ctx, &XAddArgs{Stream: "events", Values: []string{"type", "logout"}}).Val()
+
+	if mock.XLen(ctx, "events").Val() != 2 {
+		t.Errorf("Expected stream length 2")
+	}
+	if mock.Type(ctx, "events").Val() != "stream" {
+		t.Errorf("Expected type stream, got %s", mock.Type(ctx, "events").Val())
+	}
+
+	messages := mock.XRange(ctx, "events", "-", "+").Val()
+	if len(messages) != 2 || messages[1].ID != id2 || messages[1].Values["type"] != "logout" {
+		t.Errorf("Unexpected XRange result: %+v", messages)
+	}
+
+	streams, err := mock.XRead(ctx, &XReadArgs{Streams: []string{"events", "0"}, Count: 1, Block: -1}).Result()
+	if err != nil || len(streams) != 1 || len(streams[0].Messages) != 1 || streams[0].Messages[0].ID != "1-1" {
+		t.Errorf("Unexpected XRead result: %+v (%v)", streams, err)
+	}
+
+	if err := mock.XRead(ctx, &XReadArgs{Streams: []string{"events", "$"}, Block: -1}).Err(); err == nil || err.Error() != "redis: nil" {
+		t.Errorf("Expected redis: nil for non-blocking read past the end, got %v", err)
+	}
+}
+
+func TestRedisMock_XReadBlock(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	done := make(chan *XStreamSliceCmd, 1)
+	go func() {
+		done <- mock.XRead(ctx, &XReadArgs{Streams: []string{"events", "$"}, Block: 0})
+	}()
+	time.Sleep(50 * time.Millisecond)
+	id := mock.XAdd(ctx, &XAddArgs{Stream: "events", Values: []string{"k", "v"}}).Val()
+
+	select {
+	case cmd := <-done:
+		streams, err := cmd.Result()
+		if err != nil || len(streams) != 1 || streams[0].Messages[0].ID != id {
+			t.Errorf("Unexpected XRead result: %+v (%v)", streams, err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("XRead BLOCK was not woken by XAdd")
+	}
+
+	err := mock.XRead(ctx, &XReadArgs{Streams: []string{"events", "$"}, Block: 50 * time.Millisecond}).Err()
+	if err == nil || err.Error() != "redis: nil" {
+		t.Errorf("Expected redis: nil on XRead timeout, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+// errClusterDisabled 单节点实例执行集群命令时的错误，与Redis一致
+var errClusterDisabled = rediserr.Err("This instance has cluster support disabled")
+
+func (r *RedisMock) ClusterSlots(ctx context.Context) *ClusterSlotsCmd {
+	if err := ctx.Err(); err != nil {
+		return &ClusterSlotsCmd{err: err}
+	}
+	if r.isClosed() {
+		return &ClusterSlotsCmd{err: rediserr.Closed}
+	}
+	return &ClusterSlotsCmd{err: errClusterDisabled}
+}
+
+func (r *RedisMock) ClusterNodes(ctx context.Context) *StringCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringCmd{err: err}
+	}
+	if r.isClosed() {
+		return &StringCmd{err: rediserr.Closed}
+	}
+	return &StringCmd{err: errClusterDisabled}
+}
+
+func (r *RedisMock) ClusterKeySlot(ctx context.Context, key string) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+	if r.isClosed() {
+		return &IntCmd{err: rediserr.Closed}
+	}
+	return &IntCmd{err: errClusterDisabled}
+}
+
+// isClosed 检查客户端是否已关闭
+func (r *RedisMock) isClosed() bool {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.closed
+}
+
+// countKeys 统计keys中存在的键数量，重复的键分别计数
+func (r *RedisMock) countKeys(keys []string) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	count := 0
+	for _, key := range keys {
+		if !r.isExpired(key) {
+			count++
+		}
+	}
+	return count
+}
+
+// takeKeys 移除并返回满足条件的键，用于集群槽位迁移
+func (r *RedisMock) takeKeys(match func(key string) bool) map[string]*RedisValue {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	taken := make(map[string]*RedisValue)
+	for key, value := range r.data {
+		if match(key) && !r.isExpired(key) {
+			taken[key] = value
+			delete(r.data, key)
+		}
+	}
+	return taken
+}
+
+// putKeys 写入迁移过来的键并唤醒阻塞中的命令
+func (r *RedisMock) putKeys(values map[string]*RedisValue) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for key, value := range values {
+		r.data[key] = value
+	}
+	r.cond.Broadcast()
+}
@@ -0,0 +1,92 @@
+package mock
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestRedisMock_Scan(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 25; i++ {
+		mock.Set(ctx, fmt.Sprintf("user:%02d", i), i, 0)
+	}
+	mock.Set(ctx, "other", "x", 0)
+
+	var keys []string
+	var cursor uint64
+	pages := 0
+	for {
+		page, next, err := mock.Scan(ctx, cursor, "user:*", 10).Result()
+		if err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+		keys = append(keys, page...)
+		pages++
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	if len(keys) != 25 || pages != 3 {
+		t.Errorf("Expected 25 keys in 3 pages, got %d keys in %d pages", len(keys), pages)
+	}
+	if !sort.StringsAreSorted(keys) {
+		t.Errorf("Expected keys in order, got %v", keys)
+	}
+
+	if page, next := mock.Scan(ctx, 1000, "", 0).Val(); len(page) != 0 || next != 0 {
+		t.Errorf("Expected empty final page for cursor past the end, got %v %d", page, next)
+	}
+}
+
+func TestRedisMock_ClusterCommandsDisabled(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	if err := mock.ClusterSlots(ctx).Err(); !errors.Is(err, errClusterDisabled) {
+		t.Errorf("Expected cluster disabled error, got %v", err)
+	}
+	if err := mock.ClusterNodes(ctx).Err(); !errors.Is(err, errClusterDisabled) {
+		t.Errorf("Expected cluster disabled error, got %v", err)
+	}
+	if err := mock.ClusterKeySlot(ctx, "foo").Err(); !errors.Is(err, errClusterDisabled) {
+		t.Errorf("Expected cluster disabled error, got %v", err)
+	}
+}
+
+func TestRedisRecorder_ScanReplay(t *testing.T) {
+	var trace bytes.Buffer
+	recorder := NewRedisRecorder(NewRedisMock(), "conn1", &trace)
+	defer recorder.Close()
+	ctx := context.Background()
+
+	recorder.MSet(ctx, "a:1", "1", "a:2", "2", "b:1", "3")
+	page, next := recorder.Scan(ctx, 0, "a:*", 2).Val()
+	if !reflect.DeepEqual(page, []string{"a:1", "a:2"}) || next != 2 {
+		t.Errorf("Unexpected first page: %v %d", page, next)
+	}
+	recorder.Scan(ctx, next, "a:*", 2)
+	recorder.Scan(ctx, 0, "", 0)
+	recorder.ClusterSlots(ctx)
+	recorder.ClusterKeySlot(ctx, "a:1")
+
+	replayer := NewMockReplayer()
+	defer replayer.Close()
+	diffs, err := replayer.Replay(ctx, bytes.NewReader(trace.Bytes()))
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("Expected no diffs, got %+v", diffs)
+	}
+}
@@ -0,0 +1,124 @@
+package mock
+
+import (
+	"context"
+	"time"
+
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+// errRestoreTTL RESTORE的TTL为负数
+var errRestoreTTL = rediserr.Err("Invalid TTL value, must be >= 0")
+
+// errBusyKey RESTORE的目标键已存在
+var errBusyKey = rediserr.New("BUSYKEY", "Target key name already exists.")
+
+// Dump 返回键的RDB序列化数据，键不存在时返回Nil
+func (r *RedisMock) Dump(ctx context.Context, key string) *StringCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringCmd{err: err}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &StringCmd{err: rediserr.Closed}
+	}
+
+	if r.isExpired(key) {
+		return &StringCmd{err: rediserr.Nil}
+	}
+
+	payload, err := dumpValue(r.data[key])
+	if err != nil {
+		return &StringCmd{err: err}
+	}
+	return &StringCmd{val: string(payload)}
+}
+
+// Restore 使用DUMP的数据创建键，ttl为0时不过期，键已存在时返回BUSYKEY
+func (r *RedisMock) Restore(ctx context.Context, key string, ttl time.Duration, value string) *StatusCmd {
+	return r.restore(ctx, key, ttl, value, false)
+}
+
+// RestoreReplace 与Restore相同，但会覆盖已存在的键
+func (r *RedisMock) RestoreReplace(ctx context.Context, key string, ttl time.Duration, value string) *StatusCmd {
+	return r.restore(ctx, key, ttl, value, true)
+}
+
+func (r *RedisMock) restore(ctx context.Context, key string, ttl time.Duration, payload string, replace bool) *StatusCmd {
+	if err := ctx.Err(); err != nil {
+		return &StatusCmd{err: err}
+	}
+	if ttl < 0 {
+		return &StatusCmd{err: errRestoreTTL}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &StatusCmd{err: rediserr.Closed}
+	}
+
+	if !replace && !r.isExpired(key) {
+		return &StatusCmd{err: errBusyKey}
+	}
+
+	value, err := restoreValue([]byte(payload))
+	if err != nil {
+		return &StatusCmd{err: err}
+	}
+	value.CreatedAt = time.Now()
+	if ttl > 0 {
+		value.ExpireAt = expireAfter(ttl)
+	}
+	r.data[key] = value
+	r.cond.Broadcast()
+	return &StatusCmd{val: "OK"}
+}
+
+// PTTL 返回毫秒精度的剩余生存时间
+// 与go-redis一致，键不存在与没有TTL时分别返回-2与-1（不换算为时间单位）
+func (r *RedisMock) PTTL(ctx context.Context, key string) *DurationCmd {
+	if err := ctx.Err(); err != nil {
+		return &DurationCmd{err: err}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &DurationCmd{err: rediserr.Closed}
+	}
+
+	if r.isExpired(key) {
+		return &DurationCmd{val: -2}
+	}
+	value := r.data[key]
+	if value.ExpireAt == nil {
+		return &DurationCmd{val: -1}
+	}
+	return &DurationCmd{val: time.Until(*value.ExpireAt).Truncate(time.Millisecond)}
+}
+
+// Persist 移除键的TTL，键不存在或没有TTL时返回false
+func (r *RedisMock) Persist(ctx context.Context, key string) *BoolCmd {
+	if err := ctx.Err(); err != nil {
+		return &BoolCmd{err: err}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &BoolCmd{err: rediserr.Closed}
+	}
+
+	if r.isExpired(key) || r.data[key].ExpireAt == nil {
+		return &BoolCmd{val: false}
+	}
+	r.data[key].ExpireAt = nil
+	return &BoolCmd{val: true}
+}
@@ -0,0 +1,121 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+func TestCRC64Jones(t *testing.T) {
+	if crc := crc64Jones(0, []byte("123456789")); crc != 0xe9c6d914c4b8d9ca {
+		t.Errorf("Expected 0xe9c6d914c4b8d9ca, got %#x", crc)
+	}
+}
+
+func TestRedisMock_RestoreRedisPayload(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	// Redis文档中 SET mykey 10 之后 DUMP mykey 的结果（整数编码，RDB版本9）
+	payload := "\x00\xc0\n\t\x00\xbem\x06\x89Z(\x00\n"
+	if err := mock.Restore(ctx, "mykey", 0, payload).Err(); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if val, _ := mock.Get(ctx, "mykey").Result(); val != "10" {
+		t.Errorf("Expected 10, got %q", val)
+	}
+}
+
+func TestRedisMock_DumpRestore(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	long := string(make([]byte, 20000))
+	mock.Set(ctx, "string", long, 0)
+	mock.RPush(ctx, "list", "a", "b", "a")
+	mock.SAdd(ctx, "set", "x", "y")
+	mock.ZAdd(ctx, "zset", &Z{Score: 1.5, Member: "one"}, &Z{Score: math.Inf(-1), Member: "low"})
+	mock.HSet(ctx, "hash", "field", "value", "empty", "")
+
+	for _, key := range []string{"string", "list", "set", "zset", "hash"} {
+		payload, err := mock.Dump(ctx, key).Result()
+		if err != nil {
+			t.Fatalf("Dump %s failed: %v", key, err)
+		}
+		before := mock.data[key].Value
+		if err := mock.RestoreReplace(ctx, key+":copy", 0, payload).Err(); err != nil {
+			t.Fatalf("Restore %s failed: %v", key, err)
+		}
+		if after := mock.data[key+":copy"].Value; !reflect.DeepEqual(before, after) {
+			t.Errorf("%s: expected %v after restore, got %v", key, before, after)
+		}
+	}
+
+	if err := mock.Dump(ctx, "missing").Err(); !errors.Is(err, rediserr.Nil) {
+		t.Errorf("Expected nil for missing key, got %v", err)
+	}
+	mock.XAdd(ctx, &XAddArgs{Stream: "stream", Values: []interface{}{"f", "v"}})
+	if err := mock.Dump(ctx, "stream").Err(); err == nil {
+		t.Error("Expected an error when dumping a stream")
+	}
+}
+
+func TestRedisMock_RestoreErrors(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	mock.Set(ctx, "key", "value", 0)
+	payload := mock.Dump(ctx, "key").Val()
+
+	if err := mock.Restore(ctx, "key", 0, payload).Err(); !errors.Is(err, rediserr.BusyKey) {
+		t.Errorf("Expected BUSYKEY, got %v", err)
+	}
+	corrupted := []byte(payload)
+	corrupted[2] ^= 0xff
+	if err := mock.RestoreReplace(ctx, "key", 0, string(corrupted)).Err(); !errors.Is(err, errDumpPayload) {
+		t.Errorf("Expected checksum error, got %v", err)
+	}
+	if err := mock.Restore(ctx, "other", -time.Second, payload).Err(); err == nil {
+		t.Error("Expected an error for a negative TTL")
+	}
+}
+
+func TestRedisMock_RestoreTTL(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	if ttl := mock.PTTL(ctx, "missing").Val(); ttl != -2 {
+		t.Errorf("Expected -2 for a missing key, got %v", ttl)
+	}
+	mock.Set(ctx, "key", "value", 0)
+	if ttl := mock.PTTL(ctx, "key").Val(); ttl != -1 {
+		t.Errorf("Expected -1 without TTL, got %v", ttl)
+	}
+
+	payload := mock.Dump(ctx, "key").Val()
+	if err := mock.RestoreReplace(ctx, "key", time.Minute, payload).Err(); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if ttl := mock.PTTL(ctx, "key").Val(); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("Expected restored TTL, got %v", ttl)
+	}
+
+	if !mock.Persist(ctx, "key").Val() {
+		t.Error("Expected Persist to remove the TTL")
+	}
+	if ttl := mock.PTTL(ctx, "key").Val(); ttl != -1 {
+		t.Errorf("Expected -1 after Persist, got %v", ttl)
+	}
+	if mock.Persist(ctx, "key").Val() {
+		t.Error("Expected Persist to report no TTL")
+	}
+}
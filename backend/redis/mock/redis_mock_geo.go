@@ -0,0 +1,477 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/devtoolbox/redis/geo"
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+// 地理位置命令使用的Redis错误
+var (
+	errGeoUnit   = rediserr.Err("unsupported unit provided. please use M, KM, FT, MI")
+	errGeoMember = rediserr.Err("could not decode requested zset member")
+)
+
+// getZSet 读取有序集合，键不存在时返回nil，调用方需持有锁
+func (r *RedisMock) getZSet(key string) (map[string]float64, error) {
+	if r.isExpired(key) {
+		return nil, nil
+	}
+	value := r.data[key]
+	if value.Type != "zset" {
+		return nil, rediserr.WrongType
+	}
+	return value.Value.(map[string]float64), nil
+}
+
+// geoPosition 将成员分数解码为坐标，分数不是合法geohash时ok为false
+func geoPosition(score float64) (float64, float64, bool) {
+	if score < 0 || score >= geo.MaxScore || score != math.Trunc(score) {
+		return 0, 0, false
+	}
+	lon, lat := geo.Decode(uint64(score))
+	return lon, lat, true
+}
+
+// roundDistance 与Redis一致，距离保留4位小数
+func roundDistance(distance float64) float64 {
+	return math.Round(distance*10000) / 10000
+}
+
+// geoShape 地理位置查询的中心与范围，距离单位均为米
+type geoShape struct {
+	lon, lat      float64
+	radius        float64
+	width, height float64
+	unit          float64
+}
+
+// contains 判断点是否在范围内并返回以米为单位的距离
+func (s geoShape) contains(lon, lat float64) (float64, bool) {
+	if s.radius > 0 {
+		return geo.InRadius(s.lon, s.lat, lon, lat, s.radius)
+	}
+	return geo.InBox(s.lon, s.lat, lon, lat, s.width, s.height)
+}
+
+// geoOptions 地理位置查询的结果选项
+type geoOptions struct {
+	sort      string
+	count     int
+	any       bool
+	withCoord bool
+	withDist  bool
+	withHash  bool
+}
+
+// newGeoShape 创建查询范围，unit为空时使用km
+func newGeoShape(lon, lat, radius, width, height float64, unit string) (geoShape, error) {
+	if unit == "" {
+		unit = "km"
+	}
+	factor, ok := geo.UnitFactor(unit)
+	if !ok {
+		return geoShape{}, errGeoUnit
+	}
+	return geoShape{
+		lon:    lon,
+		lat:    lat,
+		radius: radius * factor,
+		width:  width * factor,
+		height: height * factor,
+		unit:   factor,
+	}, nil
+}
+
+// memberCenter 以成员坐标作为查询中心，调用方需持有锁
+func (r *RedisMock) memberCenter(key, member string) (float64, float64, error) {
+	zset, err := r.getZSet(key)
+	if err != nil {
+		return 0, 0, err
+	}
+	score, exists := zset[member]
+	if !exists {
+		return 0, 0, errGeoMember
+	}
+	lon, lat, ok := geoPosition(score)
+	if !ok {
+		return 0, 0, errGeoMember
+	}
+	return lon, lat, nil
+}
+
+// geoSearch 在有序集合中查找范围内的成员，调用方需持有锁
+func (r *RedisMock) geoSearch(key string, shape geoShape, opts geoOptions) ([]GeoLocation, error) {
+	zset, err := r.getZSet(key)
+	if err != nil {
+		return nil, err
+	}
+
+	type match struct {
+		location GeoLocation
+		distance float64
+	}
+
+	// 先按有序集合顺序遍历，未指定排序时结果顺序与ZRANGE一致
+	members := make([]string, 0, len(zset))
+	for member := range zset {
+		members = append(members, member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if zset[members[i]] != zset[members[j]] {
+			return zset[members[i]] < zset[members[j]]
+		}
+		return members[i] < members[j]
+	})
+
+	matches := make([]match, 0)
+	for _, member := range members {
+		score := zset[member]
+		lon, lat, ok := geoPosition(score)
+		if !ok {
+			continue
+		}
+		distance, ok := shape.contains(lon, lat)
+		if !ok {
+			continue
+		}
+
+		location := GeoLocation{Name: member}
+		if opts.withCoord {
+			location.Longitude, location.Latitude = lon, lat
+		}
+		if opts.withDist {
+			location.Dist = roundDistance(distance / shape.unit)
+		}
+		if opts.withHash {
+			location.GeoHash = int64(score)
+		}
+		matches = append(matches, match{location: location, distance: distance})
+
+		if opts.any && opts.count > 0 && len(matches) >= opts.count {
+			break
+		}
+	}
+
+	// 与Redis一致，指定COUNT且未使用ANY时默认按距离升序
+	order := strings.ToUpper(opts.sort)
+	if order == "" && opts.count > 0 && !opts.any {
+		order = "ASC"
+	}
+	switch order {
+	case "ASC":
+		sort.SliceStable(matches, func(i, j int) bool { return matches[i].distance < matches[j].distance })
+	case "DESC":
+		sort.SliceStable(matches, func(i, j int) bool { return matches[i].distance > matches[j].distance })
+	}
+
+	if opts.count > 0 && len(matches) > opts.count {
+		matches = matches[:opts.count]
+	}
+
+	locations := make([]GeoLocation, len(matches))
+	for i, m := range matches {
+		locations[i] = m.location
+	}
+	return locations, nil
+}
+
+// 地理位置操作
+func (r *RedisMock) GeoAdd(ctx context.Context, key string, geoLocation ...*GeoLocation) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+
+	for _, location := range geoLocation {
+		if !geo.Valid(location.Longitude, location.Latitude) {
+			return &IntCmd{err: rediserr.Err(fmt.Sprintf("invalid longitude,latitude pair %f,%f", location.Longitude, location.Latitude))}
+		}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &IntCmd{err: rediserr.Closed}
+	}
+
+	zset, err := r.getZSet(key)
+	if err != nil {
+		return &IntCmd{err: err}
+	}
+	if zset == nil {
+		zset = make(map[string]float64)
+		r.data[key] = &RedisValue{
+			Value:     zset,
+			Type:      "zset",
+			CreatedAt: time.Now(),
+		}
+	}
+
+	count := int64(0)
+	for _, location := range geoLocation {
+		if _, exists := zset[location.Name]; !exists {
+			count++
+		}
+		zset[location.Name] = float64(geo.Encode(location.Longitude, location.Latitude))
+	}
+
+	r.cond.Broadcast()
+	return &IntCmd{val: count}
+}
+
+func (r *RedisMock) GeoPos(ctx context.Context, key string, members ...string) *GeoPosCmd {
+	if err := ctx.Err(); err != nil {
+		return &GeoPosCmd{err: err}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.closed {
+		return &GeoPosCmd{err: rediserr.Closed}
+	}
+
+	zset, err := r.getZSet(key)
+	if err != nil {
+		return &GeoPosCmd{err: err}
+	}
+
+	positions := make([]*GeoPos, len(members))
+	for i, member := range members {
+		score, exists := zset[member]
+		if !exists {
+			continue
+		}
+		if lon, lat, ok := geoPosition(score); ok {
+			positions[i] = &GeoPos{Longitude: lon, Latitude: lat}
+		}
+	}
+
+	return &GeoPosCmd{val: positions}
+}
+
+// GeoDist 任一成员不存在时返回Nil，unit为空时使用m
+func (r *RedisMock) GeoDist(ctx context.Context, key string, member1, member2, unit string) *FloatCmd {
+	if err := ctx.Err(); err != nil {
+		return &FloatCmd{err: err}
+	}
+
+	if unit == "" {
+		unit = "m"
+	}
+	factor, ok := geo.UnitFactor(unit)
+	if !ok {
+		return &FloatCmd{err: errGeoUnit}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.closed {
+		return &FloatCmd{err: rediserr.Closed}
+	}
+
+	zset, err := r.getZSet(key)
+	if err != nil {
+		return &FloatCmd{err: err}
+	}
+
+	score1, exists1 := zset[member1]
+	score2, exists2 := zset[member2]
+	if !exists1 || !exists2 {
+		return &FloatCmd{err: rediserr.Nil}
+	}
+	lon1, lat1, ok1 := geoPosition(score1)
+	lon2, lat2, ok2 := geoPosition(score2)
+	if !ok1 || !ok2 {
+		return &FloatCmd{err: rediserr.Nil}
+	}
+
+	return &FloatCmd{val: roundDistance(geo.Distance(lon1, lat1, lon2, lat2) / factor)}
+}
+
+// GeoHash 不存在的成员返回空字符串
+func (r *RedisMock) GeoHash(ctx context.Context, key string, members ...string) *StringSliceCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringSliceCmd{err: err}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.closed {
+		return &StringSliceCmd{err: rediserr.Closed}
+	}
+
+	zset, err := r.getZSet(key)
+	if err != nil {
+		return &StringSliceCmd{err: err}
+	}
+
+	hashes := make([]string, len(members))
+	for i, member := range members {
+		score, exists := zset[member]
+		if !exists {
+			continue
+		}
+		if lon, lat, ok := geoPosition(score); ok {
+			hashes[i] = geo.Hash(lon, lat)
+		}
+	}
+
+	return &StringSliceCmd{val: hashes}
+}
+
+// radiusOptions 将GEORADIUS参数转换为查询选项
+func radiusOptions(query *GeoRadiusQuery) geoOptions {
+	return geoOptions{
+		sort:      query.Sort,
+		count:     query.Count,
+		withCoord: query.WithCoord,
+		withDist:  query.WithDist,
+		withHash:  query.WithGeoHash,
+	}
+}
+
+func (r *RedisMock) GeoRadius(ctx context.Context, key string, longitude, latitude float64, query *GeoRadiusQuery) *GeoLocationCmd {
+	if err := ctx.Err(); err != nil {
+		return &GeoLocationCmd{err: err}
+	}
+
+	shape, err := newGeoShape(longitude, latitude, query.Radius, 0, 0, query.Unit)
+	if err != nil {
+		return &GeoLocationCmd{err: err}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.closed {
+		return &GeoLocationCmd{err: rediserr.Closed}
+	}
+
+	locations, err := r.geoSearch(key, shape, radiusOptions(query))
+	if err != nil {
+		return &GeoLocationCmd{err: err}
+	}
+	return &GeoLocationCmd{val: locations}
+}
+
+func (r *RedisMock) GeoRadiusByMember(ctx context.Context, key, member string, query *GeoRadiusQuery) *GeoLocationCmd {
+	if err := ctx.Err(); err != nil {
+		return &GeoLocationCmd{err: err}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.closed {
+		return &GeoLocationCmd{err: rediserr.Closed}
+	}
+
+	// 键不存在时返回空结果，而不是成员不存在的错误
+	if zset, err := r.getZSet(key); err != nil || zset == nil {
+		return &GeoLocationCmd{val: []GeoLocation{}, err: err}
+	}
+	lon, lat, err := r.memberCenter(key, member)
+	if err != nil {
+		return &GeoLocationCmd{err: err}
+	}
+	shape, err := newGeoShape(lon, lat, query.Radius, 0, 0, query.Unit)
+	if err != nil {
+		return &GeoLocationCmd{err: err}
+	}
+
+	locations, err := r.geoSearch(key, shape, radiusOptions(query))
+	if err != nil {
+		return &GeoLocationCmd{err: err}
+	}
+	return &GeoLocationCmd{val: locations}
+}
+
+// searchLocations GEOSEARCH的公共实现，调用方需持有锁
+func (r *RedisMock) searchLocations(key string, q *GeoSearchQuery, opts geoOptions) ([]GeoLocation, error) {
+	// 键不存在时返回空结果，而不是成员不存在的错误
+	if zset, err := r.getZSet(key); err != nil || zset == nil {
+		return []GeoLocation{}, err
+	}
+
+	lon, lat := q.Longitude, q.Latitude
+	if q.Member != "" {
+		var err error
+		lon, lat, err = r.memberCenter(key, q.Member)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var shape geoShape
+	var err error
+	if q.Radius > 0 {
+		shape, err = newGeoShape(lon, lat, q.Radius, 0, 0, q.RadiusUnit)
+	} else {
+		shape, err = newGeoShape(lon, lat, 0, q.BoxWidth, q.BoxHeight, q.BoxUnit)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	opts.sort = q.Sort
+	opts.count = q.Count
+	opts.any = q.CountAny
+	return r.geoSearch(key, shape, opts)
+}
+
+func (r *RedisMock) GeoSearch(ctx context.Context, key string, q *GeoSearchQuery) *StringSliceCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringSliceCmd{err: err}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.closed {
+		return &StringSliceCmd{err: rediserr.Closed}
+	}
+
+	locations, err := r.searchLocations(key, q, geoOptions{})
+	if err != nil {
+		return &StringSliceCmd{err: err}
+	}
+
+	names := make([]string, len(locations))
+	for i, location := range locations {
+		names[i] = location.Name
+	}
+	return &StringSliceCmd{val: names}
+}
+
+func (r *RedisMock) GeoSearchLocation(ctx context.Context, key string, q *GeoSearchLocationQuery) *GeoLocationCmd {
+	if err := ctx.Err(); err != nil {
+		return &GeoLocationCmd{err: err}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.closed {
+		return &GeoLocationCmd{err: rediserr.Closed}
+	}
+
+	locations, err := r.searchLocations(key, &q.GeoSearchQuery, geoOptions{
+		withCoord: q.WithCoord,
+		withDist:  q.WithDist,
+		withHash:  q.WithHash,
+	})
+	if err != nil {
+		return &GeoLocationCmd{err: err}
+	}
+	return &GeoLocationCmd{val: locations}
+}
@@ -0,0 +1,149 @@
+package mock
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+func addSicily(t *testing.T, client RedisInterface) {
+	t.Helper()
+	added := client.GeoAdd(context.Background(), "Sicily",
+		&GeoLocation{Name: "Palermo", Longitude: 13.361389, Latitude: 38.115556},
+		&GeoLocation{Name: "Catania", Longitude: 15.087269, Latitude: 37.502669},
+	).Val()
+	if added != 2 {
+		t.Fatalf("Expected 2 members added, got %d", added)
+	}
+}
+
+func TestRedisMock_GeoBasics(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+	addSicily(t, mock)
+
+	if score := mock.ZScore(ctx, "Sicily", "Palermo").Val(); score != 3479099956230698 {
+		t.Errorf("Unexpected Palermo score: %f", score)
+	}
+
+	positions := mock.GeoPos(ctx, "Sicily", "Palermo", "NonExisting").Val()
+	if len(positions) != 2 || positions[1] != nil {
+		t.Fatalf("Unexpected positions: %v", positions)
+	}
+	if math.Abs(positions[0].Longitude-13.361389) > 1e-5 || math.Abs(positions[0].Latitude-38.115556) > 1e-5 {
+		t.Errorf("Unexpected Palermo position: %+v", positions[0])
+	}
+
+	if dist := mock.GeoDist(ctx, "Sicily", "Palermo", "Catania", "").Val(); dist != 166274.1516 {
+		t.Errorf("Expected 166274.1516 m, got %f", dist)
+	}
+	if dist := mock.GeoDist(ctx, "Sicily", "Palermo", "Catania", "km").Val(); dist != 166.2742 {
+		t.Errorf("Expected 166.2742 km, got %f", dist)
+	}
+	if err := mock.GeoDist(ctx, "Sicily", "Palermo", "Rome", "m").Err(); !errors.Is(err, rediserr.Nil) {
+		t.Errorf("Expected Nil for missing member, got %v", err)
+	}
+
+	hashes := mock.GeoHash(ctx, "Sicily", "Palermo", "Catania", "Rome").Val()
+	if len(hashes) != 3 || hashes[0] != "sqc8b49rny0" || hashes[1] != "sqdtr74hyu0" || hashes[2] != "" {
+		t.Errorf("Unexpected geohashes: %v", hashes)
+	}
+
+	if err := mock.GeoAdd(ctx, "Sicily", &GeoLocation{Name: "Pole", Longitude: 0, Latitude: 89}).Err(); err == nil {
+		t.Error("Expected error for invalid longitude,latitude pair")
+	}
+	if err := mock.GeoDist(ctx, "Sicily", "Palermo", "Catania", "yd").Err(); err == nil {
+		t.Error("Expected error for unsupported unit")
+	}
+
+	mock.RPush(ctx, "list", "a")
+	if err := mock.GeoPos(ctx, "list", "a").Err(); !errors.Is(err, rediserr.WrongType) {
+		t.Errorf("Expected WRONGTYPE, got %v", err)
+	}
+}
+
+func TestRedisMock_GeoRadiusAndSearch(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+	addSicily(t, mock)
+
+	locations := mock.GeoRadius(ctx, "Sicily", 15, 37, &GeoRadiusQuery{
+		Radius: 200, Unit: "km", WithDist: true, Sort: "ASC",
+	}).Val()
+	if len(locations) != 2 ||
+		locations[0].Name != "Catania" || locations[0].Dist != 56.4413 ||
+		locations[1].Name != "Palermo" || locations[1].Dist != 190.4424 {
+		t.Errorf("Unexpected GeoRadius result: %+v", locations)
+	}
+
+	locations = mock.GeoRadius(ctx, "Sicily", 15, 37, &GeoRadiusQuery{Radius: 100, Unit: "km"}).Val()
+	if len(locations) != 1 || locations[0].Name != "Catania" {
+		t.Errorf("Unexpected GeoRadius result for 100 km: %+v", locations)
+	}
+
+	locations = mock.GeoRadiusByMember(ctx, "Sicily", "Catania", &GeoRadiusQuery{
+		Radius: 200, Unit: "km", Sort: "DESC", Count: 1,
+	}).Val()
+	if len(locations) != 1 || locations[0].Name != "Palermo" {
+		t.Errorf("Unexpected GeoRadiusByMember result: %+v", locations)
+	}
+	if err := mock.GeoRadiusByMember(ctx, "Sicily", "Rome", &GeoRadiusQuery{Radius: 1}).Err(); err == nil {
+		t.Error("Expected error for missing center member")
+	}
+
+	names := mock.GeoSearch(ctx, "Sicily", &GeoSearchQuery{
+		Longitude: 15, Latitude: 37, BoxWidth: 400, BoxHeight: 400, BoxUnit: "km", Sort: "ASC",
+	}).Val()
+	if len(names) != 2 || names[0] != "Catania" || names[1] != "Palermo" {
+		t.Errorf("Unexpected GeoSearch BYBOX result: %v", names)
+	}
+
+	locations = mock.GeoSearchLocation(ctx, "Sicily", &GeoSearchLocationQuery{
+		GeoSearchQuery: GeoSearchQuery{Member: "Palermo", Radius: 10, RadiusUnit: "km"},
+		WithCoord:      true,
+		WithHash:       true,
+	}).Val()
+	if len(locations) != 1 || locations[0].Name != "Palermo" || locations[0].GeoHash != 3479099956230698 ||
+		math.Abs(locations[0].Longitude-13.361389) > 1e-5 {
+		t.Errorf("Unexpected GeoSearchLocation result: %+v", locations)
+	}
+
+	if names := mock.GeoSearch(ctx, "missing", &GeoSearchQuery{Member: "x", Radius: 1}).Val(); len(names) != 0 {
+		t.Errorf("Expected empty result for missing key, got %v", names)
+	}
+}
+
+func TestRedisRecorder_GeoCommandsReplay(t *testing.T) {
+	var trace bytes.Buffer
+	recorder := NewRedisRecorder(NewRedisMock(), "conn1", &trace)
+	defer recorder.Close()
+	ctx := context.Background()
+
+	addSicily(t, recorder)
+	recorder.GeoPos(ctx, "Sicily", "Palermo", "Rome")
+	recorder.GeoDist(ctx, "Sicily", "Palermo", "Catania", "km")
+	recorder.GeoHash(ctx, "Sicily", "Catania")
+	recorder.GeoRadius(ctx, "Sicily", 15, 37, &GeoRadiusQuery{Radius: 200, Unit: "km", WithDist: true, WithCoord: true, Sort: "ASC"})
+	recorder.GeoRadiusByMember(ctx, "Sicily", "Catania", &GeoRadiusQuery{Radius: 200, WithGeoHash: true, Count: 1, Sort: "DESC"})
+	recorder.GeoSearch(ctx, "Sicily", &GeoSearchQuery{Longitude: 15, Latitude: 37, BoxWidth: 400, BoxHeight: 400, BoxUnit: "km", Sort: "ASC"})
+	recorder.GeoSearchLocation(ctx, "Sicily", &GeoSearchLocationQuery{
+		GeoSearchQuery: GeoSearchQuery{Member: "Palermo", Radius: 200, RadiusUnit: "km", Count: 2, CountAny: true},
+		WithDist:       true,
+	})
+
+	replayer := NewMockReplayer()
+	defer replayer.Close()
+	diffs, err := replayer.Replay(ctx, bytes.NewReader(trace.Bytes()))
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("Expected no diffs, got %+v", diffs)
+	}
+}
@@ -0,0 +1,147 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/devtoolbox/redis/hll"
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+// HyperLogLog命令使用的Redis错误
+var (
+	errNotHLL     = rediserr.New("WRONGTYPE", "Key is not a valid HyperLogLog string value.")
+	errCorruptHLL = rediserr.New("INVALIDOBJ", "Corrupted HLL object detected")
+)
+
+// getHLL 读取HyperLogLog，键不存在时返回nil，调用方需持有锁
+func (r *RedisMock) getHLL(key string) (*hll.HLL, error) {
+	value, exists, err := r.getString(key)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	h, err := hll.Parse([]byte(value))
+	if errors.Is(err, hll.ErrCorrupted) {
+		return nil, errCorruptHLL
+	}
+	if err != nil {
+		return nil, errNotHLL
+	}
+	return h, nil
+}
+
+// setHLL 写回HyperLogLog并保留TTL，调用方需持有写锁
+func (r *RedisMock) setHLL(key string, h *hll.HLL) {
+	r.setString(key, string(h.Bytes()), r.currentExpireAt(key))
+}
+
+func (r *RedisMock) PFAdd(ctx context.Context, key string, els ...interface{}) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &IntCmd{err: rediserr.Closed}
+	}
+
+	h, err := r.getHLL(key)
+	if err != nil {
+		return &IntCmd{err: err}
+	}
+
+	// 与Redis一致，创建新键也视为发生变化
+	changed := h == nil
+	if h == nil {
+		h = hll.New()
+	}
+	for _, el := range els {
+		if h.Add([]byte(fmt.Sprintf("%v", el))) {
+			changed = true
+		}
+	}
+
+	if !changed {
+		return &IntCmd{val: 0}
+	}
+	r.setHLL(key, h)
+	return &IntCmd{val: 1}
+}
+
+// PFCount 单个键时会像Redis一样把计算结果缓存到头部
+func (r *RedisMock) PFCount(ctx context.Context, keys ...string) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &IntCmd{err: rediserr.Closed}
+	}
+
+	hlls := make([]*hll.HLL, 0, len(keys))
+	for _, key := range keys {
+		h, err := r.getHLL(key)
+		if err != nil {
+			return &IntCmd{err: err}
+		}
+		if h != nil {
+			hlls = append(hlls, h)
+		}
+	}
+
+	if len(keys) != 1 {
+		return &IntCmd{val: int64(hll.Union(hlls...))}
+	}
+	if len(hlls) == 0 {
+		return &IntCmd{val: 0}
+	}
+
+	h := hlls[0]
+	if _, cached := h.CachedCount(); cached {
+		return &IntCmd{val: int64(h.Count())}
+	}
+	count := h.Count()
+	r.setHLL(keys[0], h)
+	return &IntCmd{val: int64(count)}
+}
+
+func (r *RedisMock) PFMerge(ctx context.Context, dest string, keys ...string) *StatusCmd {
+	if err := ctx.Err(); err != nil {
+		return &StatusCmd{err: err}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &StatusCmd{err: rediserr.Closed}
+	}
+
+	result, err := r.getHLL(dest)
+	if err != nil {
+		return &StatusCmd{err: err}
+	}
+	if result == nil {
+		result = hll.New()
+	}
+
+	for _, key := range keys {
+		h, err := r.getHLL(key)
+		if err != nil {
+			return &StatusCmd{err: err}
+		}
+		if h != nil {
+			result.Merge(h)
+		}
+	}
+
+	r.setHLL(dest, result)
+	return &StatusCmd{val: "OK"}
+}
@@ -0,0 +1,463 @@
+package mock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/devtoolbox/redis/rediserr"
+	"github.com/devtoolbox/redis/rejson"
+)
+
+// RedisJSON命令使用的Redis错误
+var (
+	errJSONNewRoot     = rediserr.Err("new objects must be created at the root")
+	errJSONMissingKey  = rediserr.Err("could not perform this operation on a key that doesn't exist")
+	errJSONInvalid     = rediserr.Err("invalid JSON value")
+	errJSONNotFinite   = rediserr.Err("result is not a finite number")
+	errJSONAppendCount = rediserr.Err("wrong number of arguments for 'json.arrappend' command")
+)
+
+// errJSONPathMissing 旧版路径没有匹配
+func errJSONPathMissing(path string) error {
+	return rediserr.Err(fmt.Sprintf("Path '%s' does not exist", path))
+}
+
+// errJSONPathType 路径上的值类型不符
+func errJSONPathType(expected string, value interface{}) error {
+	return rediserr.Err(fmt.Sprintf("wrong type of path value - expected %s but found %s", expected, rejson.Type(value)))
+}
+
+// getJSON 读取JSON文档，键不存在时ok为false，调用方需持有锁
+func (r *RedisMock) getJSON(key string) (interface{}, bool, error) {
+	if r.isExpired(key) {
+		return nil, false, nil
+	}
+	value := r.data[key]
+	if value.Type != rejson.TypeName {
+		return nil, false, rediserr.WrongType
+	}
+	return value.Value, true, nil
+}
+
+// parseJSONPath 解析路径，语法错误转换为Redis错误
+func parseJSONPath(path string) (*rejson.Path, error) {
+	p, err := rejson.ParsePath(path)
+	if err != nil {
+		return nil, rediserr.Err(err.Error())
+	}
+	return p, nil
+}
+
+// parseJSONValue 将参数转换为文档节点
+func parseJSONValue(value interface{}) (interface{}, error) {
+	doc, err := rejson.FromValue(value)
+	if err != nil {
+		return nil, errJSONInvalid
+	}
+	return doc, nil
+}
+
+// cloneJSON 深拷贝文档节点，写入多个位置时避免共享
+func cloneJSON(value interface{}) interface{} {
+	doc, _ := rejson.Parse(rejson.Marshal(value))
+	return doc
+}
+
+func (r *RedisMock) JSONSet(ctx context.Context, key, path string, value interface{}) *StatusCmd {
+	return r.JSONSetMode(ctx, key, path, value, "")
+}
+
+// JSONSetMode mode为NX、XX或空，条件不满足时返回Nil
+func (r *RedisMock) JSONSetMode(ctx context.Context, key, path string, value interface{}, mode string) *StatusCmd {
+	if err := ctx.Err(); err != nil {
+		return &StatusCmd{err: err}
+	}
+
+	mode = strings.ToUpper(mode)
+	if mode != "" && mode != "NX" && mode != "XX" {
+		return &StatusCmd{err: rediserr.Syntax}
+	}
+	p, err := parseJSONPath(path)
+	if err != nil {
+		return &StatusCmd{err: err}
+	}
+	doc, err := parseJSONValue(value)
+	if err != nil {
+		return &StatusCmd{err: err}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &StatusCmd{err: rediserr.Closed}
+	}
+
+	root, exists, err := r.getJSON(key)
+	if err != nil {
+		return &StatusCmd{err: err}
+	}
+
+	if !exists {
+		if !p.IsRoot() {
+			return &StatusCmd{err: errJSONNewRoot}
+		}
+		if mode == "XX" {
+			return &StatusCmd{err: rediserr.Nil}
+		}
+		r.data[key] = &RedisValue{
+			Value:     doc,
+			Type:      rejson.TypeName,
+			CreatedAt: time.Now(),
+		}
+		return &StatusCmd{val: "OK"}
+	}
+
+	if matches := p.Select(root); len(matches) > 0 {
+		if mode == "NX" {
+			return &StatusCmd{err: rediserr.Nil}
+		}
+		for _, m := range matches {
+			root = rejson.Replace(root, m.Steps, cloneJSON(doc))
+		}
+	} else {
+		parents, name, ok := p.Parents(root)
+		if mode == "XX" || !ok || len(parents) == 0 {
+			return &StatusCmd{err: rediserr.Nil}
+		}
+		for _, parent := range parents {
+			steps := append(parent.Steps[:len(parent.Steps):len(parent.Steps)], name)
+			root = rejson.Replace(root, steps, cloneJSON(doc))
+		}
+	}
+
+	// 与Redis一致，修改文档不影响TTL
+	r.data[key].Value = root
+	return &StatusCmd{val: "OK"}
+}
+
+// JSONGet 多个路径时返回以路径为键的对象，任一路径为JSONPath时所有结果都是数组
+func (r *RedisMock) JSONGet(ctx context.Context, key string, paths ...string) *StringCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringCmd{err: err}
+	}
+
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	parsed := make([]*rejson.Path, 0, len(paths))
+	legacy := true
+	for _, path := range paths {
+		p, err := parseJSONPath(path)
+		if err != nil {
+			return &StringCmd{err: err}
+		}
+		parsed = append(parsed, p)
+		legacy = legacy && p.Legacy()
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.closed {
+		return &StringCmd{err: rediserr.Closed}
+	}
+
+	root, exists, err := r.getJSON(key)
+	if err != nil {
+		return &StringCmd{err: err}
+	}
+	if !exists {
+		return &StringCmd{err: rediserr.Nil}
+	}
+
+	results := make([]interface{}, 0, len(parsed))
+	for _, p := range parsed {
+		matches := p.Select(root)
+		if legacy {
+			if len(matches) == 0 {
+				return &StringCmd{err: errJSONPathMissing(p.String())}
+			}
+			results = append(results, matches[0].Value)
+			continue
+		}
+		values := make([]interface{}, 0, len(matches))
+		for _, m := range matches {
+			values = append(values, m.Value)
+		}
+		results = append(results, values)
+	}
+
+	if len(results) == 1 {
+		return &StringCmd{val: string(rejson.Marshal(results[0]))}
+	}
+	object := rejson.NewObject()
+	for i, p := range parsed {
+		object.Set(p.String(), results[i])
+	}
+	return &StringCmd{val: string(rejson.Marshal(object))}
+}
+
+// JSONDel 返回删除的值的数量，删除根时删除整个键
+func (r *RedisMock) JSONDel(ctx context.Context, key, path string) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+
+	p, err := parseJSONPath(path)
+	if err != nil {
+		return &IntCmd{err: err}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &IntCmd{err: rediserr.Closed}
+	}
+
+	root, exists, err := r.getJSON(key)
+	if err != nil {
+		return &IntCmd{err: err}
+	}
+	if !exists {
+		return &IntCmd{val: 0}
+	}
+
+	matches := p.Select(root)
+	// 按文档逆序删除，保证同一数组中的下标不受影响
+	for i := len(matches) - 1; i >= 0; i-- {
+		var removed bool
+		root, removed = rejson.Remove(root, matches[i].Steps)
+		if removed {
+			delete(r.data, key)
+			return &IntCmd{val: 1}
+		}
+	}
+
+	r.data[key].Value = root
+	return &IntCmd{val: int64(len(matches))}
+}
+
+// JSONType 旧版路径最多返回一个类型，路径不存在时返回Nil
+func (r *RedisMock) JSONType(ctx context.Context, key, path string) *StringSliceCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringSliceCmd{err: err}
+	}
+
+	p, err := parseJSONPath(path)
+	if err != nil {
+		return &StringSliceCmd{err: err}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.closed {
+		return &StringSliceCmd{err: rediserr.Closed}
+	}
+
+	root, exists, err := r.getJSON(key)
+	if err != nil {
+		return &StringSliceCmd{err: err}
+	}
+	if !exists {
+		return &StringSliceCmd{err: rediserr.Nil}
+	}
+
+	matches := p.Select(root)
+	if p.Legacy() && len(matches) == 0 {
+		return &StringSliceCmd{err: rediserr.Nil}
+	}
+	types := make([]string, 0, len(matches))
+	for _, m := range matches {
+		types = append(types, rejson.Type(m.Value))
+	}
+	return &StringSliceCmd{val: types}
+}
+
+// JSONArrAppend 返回追加后的数组长度，JSONPath匹配到非数组时对应元素为nil
+func (r *RedisMock) JSONArrAppend(ctx context.Context, key, path string, values ...interface{}) *IntPointerSliceCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntPointerSliceCmd{err: err}
+	}
+
+	if len(values) == 0 {
+		return &IntPointerSliceCmd{err: errJSONAppendCount}
+	}
+	p, err := parseJSONPath(path)
+	if err != nil {
+		return &IntPointerSliceCmd{err: err}
+	}
+	items := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		item, err := parseJSONValue(value)
+		if err != nil {
+			return &IntPointerSliceCmd{err: err}
+		}
+		items = append(items, item)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &IntPointerSliceCmd{err: rediserr.Closed}
+	}
+
+	root, exists, err := r.getJSON(key)
+	if err != nil {
+		return &IntPointerSliceCmd{err: err}
+	}
+	if !exists {
+		return &IntPointerSliceCmd{err: errJSONMissingKey}
+	}
+
+	matches := p.Select(root)
+	if p.Legacy() {
+		if len(matches) == 0 {
+			return &IntPointerSliceCmd{err: errJSONPathMissing(path)}
+		}
+		if _, ok := matches[0].Value.([]interface{}); !ok {
+			return &IntPointerSliceCmd{err: errJSONPathType("array", matches[0].Value)}
+		}
+	}
+
+	lengths := make([]*int64, 0, len(matches))
+	for _, m := range matches {
+		array, ok := m.Value.([]interface{})
+		if !ok {
+			lengths = append(lengths, nil)
+			continue
+		}
+		for _, item := range items {
+			array = append(array, cloneJSON(item))
+		}
+		root = rejson.Replace(root, m.Steps, array)
+		length := int64(len(array))
+		lengths = append(lengths, &length)
+	}
+
+	r.data[key].Value = root
+	return &IntPointerSliceCmd{val: lengths}
+}
+
+// JSONNumIncrBy 返回JSON文本，JSONPath时为数组，非数字的匹配为null
+func (r *RedisMock) JSONNumIncrBy(ctx context.Context, key, path string, value float64) *StringCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringCmd{err: err}
+	}
+
+	p, err := parseJSONPath(path)
+	if err != nil {
+		return &StringCmd{err: err}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &StringCmd{err: rediserr.Closed}
+	}
+
+	root, exists, err := r.getJSON(key)
+	if err != nil {
+		return &StringCmd{err: err}
+	}
+	if !exists {
+		return &StringCmd{err: errJSONMissingKey}
+	}
+
+	matches := p.Select(root)
+	if p.Legacy() && len(matches) == 0 {
+		return &StringCmd{err: errJSONPathMissing(path)}
+	}
+
+	// 先计算所有结果，出错时不修改文档
+	results := make([]interface{}, len(matches))
+	for i, m := range matches {
+		number, ok := m.Value.(json.Number)
+		if !ok {
+			if p.Legacy() {
+				return &StringCmd{err: errJSONPathType("a number", m.Value)}
+			}
+			continue
+		}
+		sum, err := rejson.IncrBy(number, value)
+		if err != nil {
+			return &StringCmd{err: errJSONNotFinite}
+		}
+		results[i] = sum
+	}
+	for i, m := range matches {
+		if results[i] != nil {
+			root = rejson.Replace(root, m.Steps, results[i])
+		}
+	}
+	r.data[key].Value = root
+
+	if p.Legacy() {
+		return &StringCmd{val: string(rejson.Marshal(results[0]))}
+	}
+	return &StringCmd{val: string(rejson.Marshal(results))}
+}
+
+// JSONObjKeys 旧版路径返回键名列表，JSONPath返回每个匹配的键名列表，非对象为nil
+func (r *RedisMock) JSONObjKeys(ctx context.Context, key, path string) *SliceCmd {
+	if err := ctx.Err(); err != nil {
+		return &SliceCmd{err: err}
+	}
+
+	p, err := parseJSONPath(path)
+	if err != nil {
+		return &SliceCmd{err: err}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.closed {
+		return &SliceCmd{err: rediserr.Closed}
+	}
+
+	root, exists, err := r.getJSON(key)
+	if err != nil {
+		return &SliceCmd{err: err}
+	}
+	if !exists {
+		return &SliceCmd{err: rediserr.Nil}
+	}
+
+	objectKeys := func(value interface{}) interface{} {
+		object, ok := value.(*rejson.Object)
+		if !ok {
+			return nil
+		}
+		keys := make([]interface{}, 0, object.Len())
+		for _, k := range object.Keys() {
+			keys = append(keys, k)
+		}
+		return keys
+	}
+
+	matches := p.Select(root)
+	if p.Legacy() {
+		if len(matches) == 0 {
+			return &SliceCmd{err: errJSONPathMissing(path)}
+		}
+		keys, ok := objectKeys(matches[0].Value).([]interface{})
+		if !ok {
+			return &SliceCmd{err: errJSONPathType("object", matches[0].Value)}
+		}
+		return &SliceCmd{val: keys}
+	}
+
+	result := make([]interface{}, 0, len(matches))
+	for _, m := range matches {
+		result = append(result, objectKeys(m.Value))
+	}
+	return &SliceCmd{val: result}
+}
@@ -0,0 +1,174 @@
+package mock
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+const userJSON = `{"name":"Alice","age":30,"tags":["admin"],"address":{"city":"Beijing","zip":"100000"},"orders":[{"id":1,"total":9.5},{"id":2,"total":20}]}`
+
+func TestRedisMock_JSONSetGet(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	if err := mock.JSONSet(ctx, "user", ".name", `"Bob"`).Err(); !errors.Is(err, rediserr.Err("new objects must be created at the root")) {
+		t.Errorf("Expected root error for new key, got %v", err)
+	}
+	if err := mock.JSONSet(ctx, "user", "$", userJSON).Err(); err != nil {
+		t.Fatalf("JSONSet failed: %v", err)
+	}
+	if typ := mock.Type(ctx, "user").Val(); typ != "ReJSON-RL" {
+		t.Errorf("Expected type ReJSON-RL, got %s", typ)
+	}
+
+	if doc := mock.JSONGet(ctx, "user").Val(); doc != userJSON {
+		t.Errorf("Document changed on round trip: %s", doc)
+	}
+	if name := mock.JSONGet(ctx, "user", ".name").Val(); name != `"Alice"` {
+		t.Errorf("Expected legacy path to return a single value, got %s", name)
+	}
+	if totals := mock.JSONGet(ctx, "user", "$..total").Val(); totals != `[9.5,20]` {
+		t.Errorf("Unexpected totals: %s", totals)
+	}
+	if multi := mock.JSONGet(ctx, "user", "$.name", "$.address.city").Val(); multi != `{"$.name":["Alice"],"$.address.city":["Beijing"]}` {
+		t.Errorf("Unexpected multi-path result: %s", multi)
+	}
+	if err := mock.JSONGet(ctx, "user", ".missing").Err(); err == nil {
+		t.Error("Expected error for missing legacy path")
+	}
+	if missing := mock.JSONGet(ctx, "user", "$.missing").Val(); missing != `[]` {
+		t.Errorf("Expected empty array for missing JSONPath, got %s", missing)
+	}
+	if err := mock.JSONGet(ctx, "nokey").Err(); !errors.Is(err, rediserr.Nil) {
+		t.Errorf("Expected Nil for missing key, got %v", err)
+	}
+
+	// 更新已有值、在对象中新建键，以及NX/XX条件
+	mock.JSONSet(ctx, "user", "$.orders[*].total", 0)
+	if totals := mock.JSONGet(ctx, "user", "$.orders[*].total").Val(); totals != `[0,0]` {
+		t.Errorf("Unexpected totals after set: %s", totals)
+	}
+	if err := mock.JSONSet(ctx, "user", "$.email", `"alice@example.com"`).Err(); err != nil {
+		t.Errorf("Expected new key to be created, got %v", err)
+	}
+	if err := mock.JSONSetMode(ctx, "user", "$.email", `"x"`, "NX").Err(); !errors.Is(err, rediserr.Nil) {
+		t.Errorf("Expected Nil for NX on existing path, got %v", err)
+	}
+	if err := mock.JSONSetMode(ctx, "user", "$.phone", `"x"`, "XX").Err(); !errors.Is(err, rediserr.Nil) {
+		t.Errorf("Expected Nil for XX on missing path, got %v", err)
+	}
+	if err := mock.JSONSet(ctx, "user", "$.a.b", `1`).Err(); !errors.Is(err, rediserr.Nil) {
+		t.Errorf("Expected Nil when parent does not exist, got %v", err)
+	}
+	if err := mock.JSONSet(ctx, "user", "$", `{"a":`).Err(); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+	if err := mock.JSONSet(ctx, "user", "$[", `1`).Err(); err == nil {
+		t.Error("Expected error for invalid path")
+	}
+
+	mock.Set(ctx, "plain", "v", 0)
+	if err := mock.JSONGet(ctx, "plain").Err(); !errors.Is(err, rediserr.WrongType) {
+		t.Errorf("Expected WRONGTYPE, got %v", err)
+	}
+	if err := mock.Get(ctx, "user").Err(); !errors.Is(err, rediserr.WrongType) {
+		t.Errorf("Expected WRONGTYPE for GET on JSON key, got %v", err)
+	}
+}
+
+func TestRedisMock_JSONModify(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+	mock.JSONSet(ctx, "user", ".", userJSON)
+
+	if types := mock.JSONType(ctx, "user", "$.*").Val(); !reflect.DeepEqual(types, []string{"string", "integer", "array", "object", "array"}) {
+		t.Errorf("Unexpected types: %v", types)
+	}
+	if types := mock.JSONType(ctx, "user", ".orders[0].total").Val(); !reflect.DeepEqual(types, []string{"number"}) {
+		t.Errorf("Unexpected legacy type: %v", types)
+	}
+
+	lengths := mock.JSONArrAppend(ctx, "user", "$..tags", `"dev"`, `"ops"`).Val()
+	if len(lengths) != 1 || *lengths[0] != 3 {
+		t.Errorf("Unexpected ArrAppend result: %v", lengths)
+	}
+	lengths = mock.JSONArrAppend(ctx, "user", "$.name", `"x"`).Val()
+	if len(lengths) != 1 || lengths[0] != nil {
+		t.Errorf("Expected nil for non-array match, got %v", lengths)
+	}
+	if err := mock.JSONArrAppend(ctx, "user", ".name", `"x"`).Err(); err == nil {
+		t.Error("Expected error for legacy path to non-array")
+	}
+
+	if age := mock.JSONNumIncrBy(ctx, "user", ".age", 2).Val(); age != "32" {
+		t.Errorf("Expected 32, got %s", age)
+	}
+	if totals := mock.JSONNumIncrBy(ctx, "user", "$..total", 0.5).Val(); totals != "[10,20.5]" {
+		t.Errorf("Unexpected totals: %s", totals)
+	}
+	if result := mock.JSONNumIncrBy(ctx, "user", "$.name", 1).Val(); result != "[null]" {
+		t.Errorf("Expected null for non-number match, got %s", result)
+	}
+	if err := mock.JSONNumIncrBy(ctx, "nokey", ".age", 1).Err(); err == nil {
+		t.Error("Expected error for missing key")
+	}
+
+	if keys := mock.JSONObjKeys(ctx, "user", ".address").Val(); !reflect.DeepEqual(keys, []interface{}{"city", "zip"}) {
+		t.Errorf("Unexpected legacy keys: %v", keys)
+	}
+	keys := mock.JSONObjKeys(ctx, "user", "$.*").Val()
+	if len(keys) != 5 || keys[0] != nil || !reflect.DeepEqual(keys[3], []interface{}{"city", "zip"}) {
+		t.Errorf("Unexpected JSONPath keys: %v", keys)
+	}
+
+	if deleted := mock.JSONDel(ctx, "user", "$.orders[*]").Val(); deleted != 2 {
+		t.Errorf("Expected 2 deleted, got %d", deleted)
+	}
+	if deleted := mock.JSONDel(ctx, "user", "$.missing").Val(); deleted != 0 {
+		t.Errorf("Expected 0 deleted, got %d", deleted)
+	}
+	if doc := mock.JSONGet(ctx, "user").Val(); doc != `{"name":"Alice","age":32,"tags":["admin","dev","ops"],"address":{"city":"Beijing","zip":"100000"},"orders":[]}` {
+		t.Errorf("Unexpected document: %s", doc)
+	}
+	if deleted := mock.JSONDel(ctx, "user", "$").Val(); deleted != 1 {
+		t.Errorf("Expected root delete to return 1, got %d", deleted)
+	}
+	if exists := mock.Exists(ctx, "user").Val(); exists != 0 {
+		t.Error("Expected key to be removed after deleting root")
+	}
+}
+
+func TestRedisRecorder_JSONCommandsReplay(t *testing.T) {
+	var trace bytes.Buffer
+	recorder := NewRedisRecorder(NewRedisMock(), "conn1", &trace)
+	defer recorder.Close()
+	ctx := context.Background()
+
+	recorder.JSONSet(ctx, "user", "$", map[string]interface{}{"name": "Alice", "tags": []string{}})
+	recorder.JSONSetMode(ctx, "user", "$.age", 30, "NX")
+	recorder.JSONGet(ctx, "user")
+	recorder.JSONGet(ctx, "user", "$.name", ".age")
+	recorder.JSONType(ctx, "user", "$..*")
+	recorder.JSONArrAppend(ctx, "user", "$.tags", "admin", `"dev"`)
+	recorder.JSONNumIncrBy(ctx, "user", "$.age", 1.5)
+	recorder.JSONObjKeys(ctx, "user", ".")
+	recorder.JSONDel(ctx, "user", ".tags[0]")
+	recorder.JSONGet(ctx, "user", "$")
+
+	replayer := NewMockReplayer()
+	defer replayer.Close()
+	diffs, err := replayer.Replay(ctx, bytes.NewReader(trace.Bytes()))
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("Expected no diffs, got %+v", diffs)
+	}
+}
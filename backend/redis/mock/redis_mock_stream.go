@@ -0,0 +1,390 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+// streamID 流消息ID，格式为 毫秒时间戳-序号
+type streamID struct {
+	ms  uint64
+	seq uint64
+}
+
+func (id streamID) String() string {
+	return fmt.Sprintf("%d-%d", id.ms, id.seq)
+}
+
+func (id streamID) less(other streamID) bool {
+	if id.ms != other.ms {
+		return id.ms < other.ms
+	}
+	return id.seq < other.seq
+}
+
+// parseStreamID 解析流消息ID，缺省序号时使用defaultSeq
+func parseStreamID(s string, defaultSeq uint64) (streamID, error) {
+	parts := strings.SplitN(s, "-", 2)
+	ms, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return streamID{}, rediserr.Err("Invalid stream ID specified as stream command argument")
+	}
+	if len(parts) == 1 {
+		return streamID{ms: ms, seq: defaultSeq}, nil
+	}
+	seq, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return streamID{}, rediserr.Err("Invalid stream ID specified as stream command argument")
+	}
+	return streamID{ms: ms, seq: seq}, nil
+}
+
+// streamEntry 流中的一条消息，字段按写入顺序保存
+type streamEntry struct {
+	id     streamID
+	fields []string
+}
+
+func (e streamEntry) message() XMessage {
+	values := make(map[string]interface{}, len(e.fields)/2)
+	for i := 0; i+1 < len(e.fields); i += 2 {
+		values[e.fields[i]] = e.fields[i+1]
+	}
+	return XMessage{ID: e.id.String(), Values: values}
+}
+
+// mockStream 流数据
+type mockStream struct {
+	entries []streamEntry
+	lastID  streamID
+}
+
+// after 返回ID大于start的消息，count大于0时限制条数
+func (s *mockStream) after(start streamID, count int64) []XMessage {
+	messages := make([]XMessage, 0)
+	for _, entry := range s.entries {
+		if !start.less(entry.id) {
+			continue
+		}
+		messages = append(messages, entry.message())
+		if count > 0 && int64(len(messages)) >= count {
+			break
+		}
+	}
+	return messages
+}
+
+// streamFieldValues 将XADD的Values参数转换为字段、值交替的列表
+func streamFieldValues(values interface{}) ([]string, error) {
+	var fields []string
+
+	switch v := values.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fields = append(fields, k, fmt.Sprintf("%v", v[k]))
+		}
+	case map[string]string:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fields = append(fields, k, v[k])
+		}
+	case []interface{}:
+		for _, item := range v {
+			fields = append(fields, fmt.Sprintf("%v", item))
+		}
+	case []string:
+		fields = append(fields, v...)
+	default:
+		return nil, rediserr.Err(fmt.Sprintf("unsupported XADD values type %T", values))
+	}
+
+	if len(fields) == 0 || len(fields)%2 != 0 {
+		return nil, rediserr.Err("wrong number of arguments for 'xadd' command")
+	}
+	return fields, nil
+}
+
+// getStream 获取流数据，调用方需持有锁
+func (r *RedisMock) getStream(key string) (*mockStream, error) {
+	if r.isExpired(key) {
+		return nil, nil
+	}
+	value := r.data[key]
+	if value.Type != "stream" {
+		return nil, rediserr.WrongType
+	}
+	return value.Value.(*mockStream), nil
+}
+
+// nextStreamID 计算XADD使用的消息ID
+func nextStreamID(stream *mockStream, requested string) (streamID, error) {
+	last := stream.lastID
+
+	if requested == "" || requested == "*" {
+		now := uint64(time.Now().UnixMilli())
+		if now > last.ms {
+			return streamID{ms: now}, nil
+		}
+		return streamID{ms: last.ms, seq: last.seq + 1}, nil
+	}
+
+	var id streamID
+	if ms, ok := strings.CutSuffix(requested, "-*"); ok {
+		parsed, err := parseStreamID(ms, 0)
+		if err != nil {
+			return streamID{}, err
+		}
+		id = parsed
+		if id.ms == last.ms && len(stream.entries) > 0 {
+			id.seq = last.seq + 1
+		}
+	} else {
+		parsed, err := parseStreamID(requested, 0)
+		if err != nil {
+			return streamID{}, err
+		}
+		id = parsed
+	}
+
+	if id.ms == 0 && id.seq == 0 {
+		return streamID{}, rediserr.Err("The ID specified in XADD must be greater than 0-0")
+	}
+	if !last.less(id) {
+		return streamID{}, rediserr.Err("The ID specified in XADD is equal or smaller than the target stream top item")
+	}
+	return id, nil
+}
+
+// 流操作
+func (r *RedisMock) XAdd(ctx context.Context, a *XAddArgs) *StringCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringCmd{err: err}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &StringCmd{err: rediserr.Closed}
+	}
+
+	fields, err := streamFieldValues(a.Values)
+	if err != nil {
+		return &StringCmd{err: err}
+	}
+
+	stream, err := r.getStream(a.Stream)
+	if err != nil {
+		return &StringCmd{err: err}
+	}
+	if stream == nil && a.NoMkStream {
+		return &StringCmd{err: rediserr.Nil}
+	}
+
+	created := stream == nil
+	if created {
+		stream = &mockStream{}
+	}
+
+	id, err := nextStreamID(stream, a.ID)
+	if err != nil {
+		return &StringCmd{err: err}
+	}
+
+	stream.entries = append(stream.entries, streamEntry{id: id, fields: fields})
+	stream.lastID = id
+
+	// Mock中近似裁剪也按精确长度处理
+	if a.MaxLen > 0 && int64(len(stream.entries)) > a.MaxLen {
+		stream.entries = stream.entries[int64(len(stream.entries))-a.MaxLen:]
+	}
+
+	if created {
+		r.data[a.Stream] = &RedisValue{
+			Value:     stream,
+			Type:      "stream",
+			CreatedAt: time.Now(),
+		}
+	}
+
+	r.cond.Broadcast()
+	return &StringCmd{val: id.String()}
+}
+
+func (r *RedisMock) XLen(ctx context.Context, stream string) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.closed {
+		return &IntCmd{err: rediserr.Closed}
+	}
+
+	s, err := r.getStream(stream)
+	if err != nil {
+		return &IntCmd{err: err}
+	}
+	if s == nil {
+		return &IntCmd{val: 0}
+	}
+
+	return &IntCmd{val: int64(len(s.entries))}
+}
+
+// parseRangeID 解析XRANGE的边界ID，支持-、+和(开头的开区间
+func parseRangeID(s string, isStart bool) (streamID, bool, error) {
+	switch s {
+	case "-":
+		return streamID{}, false, nil
+	case "+":
+		return streamID{ms: math.MaxUint64, seq: math.MaxUint64}, false, nil
+	}
+
+	exclusive := strings.HasPrefix(s, "(")
+	s = strings.TrimPrefix(s, "(")
+
+	defaultSeq := uint64(0)
+	if !isStart {
+		defaultSeq = math.MaxUint64
+	}
+	id, err := parseStreamID(s, defaultSeq)
+	return id, exclusive, err
+}
+
+func (r *RedisMock) XRange(ctx context.Context, stream, start, stop string) *XMessageSliceCmd {
+	if err := ctx.Err(); err != nil {
+		return &XMessageSliceCmd{err: err}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.closed {
+		return &XMessageSliceCmd{err: rediserr.Closed}
+	}
+
+	startID, startExclusive, err := parseRangeID(start, true)
+	if err != nil {
+		return &XMessageSliceCmd{err: err}
+	}
+	stopID, stopExclusive, err := parseRangeID(stop, false)
+	if err != nil {
+		return &XMessageSliceCmd{err: err}
+	}
+
+	s, err := r.getStream(stream)
+	if err != nil {
+		return &XMessageSliceCmd{err: err}
+	}
+
+	messages := make([]XMessage, 0)
+	if s == nil {
+		return &XMessageSliceCmd{val: messages}
+	}
+
+	for _, entry := range s.entries {
+		if entry.id.less(startID) || (startExclusive && entry.id == startID) {
+			continue
+		}
+		if stopID.less(entry.id) || (stopExclusive && entry.id == stopID) {
+			break
+		}
+		messages = append(messages, entry.message())
+	}
+
+	return &XMessageSliceCmd{val: messages}
+}
+
+func (r *RedisMock) XRead(ctx context.Context, a *XReadArgs) *XStreamSliceCmd {
+	if err := ctx.Err(); err != nil {
+		return &XStreamSliceCmd{err: err}
+	}
+
+	if len(a.Streams) == 0 || len(a.Streams)%2 != 0 {
+		return &XStreamSliceCmd{err: rediserr.Err("Unbalanced 'xread' list of streams: for each stream key an ID or '$' must be specified.")}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &XStreamSliceCmd{err: rediserr.Closed}
+	}
+
+	// 在调用时刻解析起始ID，$表示只读取之后写入的消息
+	half := len(a.Streams) / 2
+	keys := a.Streams[:half]
+	starts := make([]streamID, half)
+	for i, key := range keys {
+		stream, err := r.getStream(key)
+		if err != nil {
+			return &XStreamSliceCmd{err: err}
+		}
+
+		if a.Streams[half+i] == "$" {
+			if stream != nil {
+				starts[i] = stream.lastID
+			}
+			continue
+		}
+
+		id, err := parseStreamID(a.Streams[half+i], 0)
+		if err != nil {
+			return &XStreamSliceCmd{err: err}
+		}
+		starts[i] = id
+	}
+
+	var result []XStream
+	try := func() (bool, error) {
+		for i, key := range keys {
+			stream, err := r.getStream(key)
+			if err != nil {
+				return false, err
+			}
+			if stream == nil {
+				continue
+			}
+			if messages := stream.after(starts[i], a.Count); len(messages) > 0 {
+				result = append(result, XStream{Stream: key, Messages: messages})
+			}
+		}
+		return len(result) > 0, nil
+	}
+
+	if a.Block < 0 {
+		ok, err := try()
+		if err != nil {
+			return &XStreamSliceCmd{err: err}
+		}
+		if !ok {
+			return &XStreamSliceCmd{err: rediserr.Nil}
+		}
+		return &XStreamSliceCmd{val: result}
+	}
+
+	if err := r.blockUntil(ctx, a.Block, try); err != nil {
+		return &XStreamSliceCmd{err: err}
+	}
+
+	return &XStreamSliceCmd{val: result}
+}
@@ -0,0 +1,477 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+// 字符串命令使用的Redis错误
+var (
+	errIncrOverflow   = rediserr.Err("increment or decrement would overflow")
+	errIncrFloatNaN   = rediserr.Err("increment would produce NaN or Infinity")
+	errSetExpireTime  = rediserr.Err("invalid expire time in 'set' command")
+	errSetEXTime      = rediserr.Err("invalid expire time in 'setex' command")
+	errPSetEXTime     = rediserr.Err("invalid expire time in 'psetex' command")
+	errMSetArgCount   = rediserr.Err("wrong number of arguments for 'mset' command")
+	errMSetNXArgCount = rediserr.Err("wrong number of arguments for 'msetnx' command")
+)
+
+// getString 读取字符串值，键不存在时ok为false，调用方需持有锁
+func (r *RedisMock) getString(key string) (string, bool, error) {
+	if r.isExpired(key) {
+		return "", false, nil
+	}
+	value := r.data[key]
+	if value.Type != "string" {
+		return "", false, rediserr.WrongType
+	}
+	return fmt.Sprintf("%v", value.Value), true, nil
+}
+
+// setString 写入字符串值并替换原有的TTL，调用方需持有写锁
+func (r *RedisMock) setString(key, value string, expireAt *time.Time) {
+	r.data[key] = &RedisValue{
+		Value:     value,
+		Type:      "string",
+		ExpireAt:  expireAt,
+		CreatedAt: time.Now(),
+	}
+}
+
+// currentExpireAt 返回键当前的过期时间，调用方需持有锁
+func (r *RedisMock) currentExpireAt(key string) *time.Time {
+	if r.isExpired(key) {
+		return nil
+	}
+	return r.data[key].ExpireAt
+}
+
+// parseRedisInt 按Redis的规则解析整数：只允许可选的负号和十进制数字，
+// 不接受正号、空白与前导零（"0"除外）
+func parseRedisInt(s string) (int64, bool) {
+	digits := strings.TrimPrefix(s, "-")
+	if digits == "" || (digits[0] == '0' && (len(digits) > 1 || len(s) > 1)) {
+		return 0, false
+	}
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return 0, false
+		}
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	return n, err == nil
+}
+
+// expireAfter 计算相对过期时间
+func expireAfter(expiration time.Duration) *time.Time {
+	expireAt := time.Now().Add(expiration)
+	return &expireAt
+}
+
+// pairArgs 解析MSET/MSETNX的键值参数，规则与go-redis一致
+func pairArgs(values []interface{}) []string {
+	var args []string
+	appendValue := func(v interface{}) {
+		args = append(args, fmt.Sprintf("%v", v))
+	}
+
+	if len(values) != 1 {
+		for _, v := range values {
+			appendValue(v)
+		}
+		return args
+	}
+
+	switch v := values[0].(type) {
+	case []string:
+		args = append(args, v...)
+	case []interface{}:
+		for _, item := range v {
+			appendValue(item)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			args = append(args, k)
+			appendValue(v[k])
+		}
+	case map[string]string:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			args = append(args, k, v[k])
+		}
+	default:
+		appendValue(v)
+	}
+	return args
+}
+
+func (r *RedisMock) SetEX(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd {
+	if expiration <= 0 {
+		return &StatusCmd{err: errSetEXTime}
+	}
+	return r.Set(ctx, key, value, expiration)
+}
+
+func (r *RedisMock) PSetEX(ctx context.Context, key string, value interface{}, expiration time.Duration) *StatusCmd {
+	if expiration <= 0 {
+		return &StatusCmd{err: errPSetEXTime}
+	}
+	return r.Set(ctx, key, value, expiration)
+}
+
+func (r *RedisMock) SetArgs(ctx context.Context, key string, value interface{}, a SetArgs) *StatusCmd {
+	if err := ctx.Err(); err != nil {
+		return &StatusCmd{err: err}
+	}
+
+	mode := strings.ToUpper(a.Mode)
+	if mode != "" && mode != "NX" && mode != "XX" {
+		return &StatusCmd{err: rediserr.Syntax}
+	}
+
+	// KEEPTTL、EXAT和EX/PX互斥
+	options := 0
+	for _, set := range []bool{a.KeepTTL, !a.ExpireAt.IsZero(), a.TTL > 0} {
+		if set {
+			options++
+		}
+	}
+	if options > 1 {
+		return &StatusCmd{err: rediserr.Syntax}
+	}
+	if a.TTL < 0 || (!a.ExpireAt.IsZero() && a.ExpireAt.Unix() <= 0) {
+		return &StatusCmd{err: errSetExpireTime}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &StatusCmd{err: rediserr.Closed}
+	}
+
+	old, exists := "", !r.isExpired(key)
+	if a.Get {
+		var err error
+		old, exists, err = r.getString(key)
+		if err != nil {
+			return &StatusCmd{err: err}
+		}
+	}
+
+	var expireAt *time.Time
+	switch {
+	case a.KeepTTL:
+		expireAt = r.currentExpireAt(key)
+	case !a.ExpireAt.IsZero():
+		// EXAT精度为秒
+		at := time.Unix(a.ExpireAt.Unix(), 0)
+		expireAt = &at
+	case a.TTL > 0:
+		expireAt = expireAfter(a.TTL)
+	}
+
+	if (mode == "NX" && exists) || (mode == "XX" && !exists) {
+		if a.Get && exists {
+			return &StatusCmd{val: old}
+		}
+		return &StatusCmd{err: rediserr.Nil}
+	}
+
+	r.setString(key, fmt.Sprintf("%v", value), expireAt)
+
+	if a.Get {
+		if !exists {
+			return &StatusCmd{err: rediserr.Nil}
+		}
+		return &StatusCmd{val: old}
+	}
+	return &StatusCmd{val: "OK"}
+}
+
+func (r *RedisMock) GetSet(ctx context.Context, key string, value interface{}) *StringCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringCmd{err: err}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &StringCmd{err: rediserr.Closed}
+	}
+
+	old, exists, err := r.getString(key)
+	if err != nil {
+		return &StringCmd{err: err}
+	}
+
+	r.setString(key, fmt.Sprintf("%v", value), nil)
+
+	if !exists {
+		return &StringCmd{err: rediserr.Nil}
+	}
+	return &StringCmd{val: old}
+}
+
+func (r *RedisMock) GetDel(ctx context.Context, key string) *StringCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringCmd{err: err}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &StringCmd{err: rediserr.Closed}
+	}
+
+	value, exists, err := r.getString(key)
+	if err != nil {
+		return &StringCmd{err: err}
+	}
+	if !exists {
+		return &StringCmd{err: rediserr.Nil}
+	}
+
+	delete(r.data, key)
+	return &StringCmd{val: value}
+}
+
+// GetEx expiration大于0时设置TTL，等于0时移除TTL，小于0时不修改
+func (r *RedisMock) GetEx(ctx context.Context, key string, expiration time.Duration) *StringCmd {
+	if err := ctx.Err(); err != nil {
+		return &StringCmd{err: err}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &StringCmd{err: rediserr.Closed}
+	}
+
+	value, exists, err := r.getString(key)
+	if err != nil {
+		return &StringCmd{err: err}
+	}
+	if !exists {
+		return &StringCmd{err: rediserr.Nil}
+	}
+
+	if expiration > 0 {
+		r.data[key].ExpireAt = expireAfter(expiration)
+	} else if expiration == 0 {
+		r.data[key].ExpireAt = nil
+	}
+
+	return &StringCmd{val: value}
+}
+
+// StrLen 不存在的键返回0
+func (r *RedisMock) StrLen(ctx context.Context, key string) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.closed {
+		return &IntCmd{err: rediserr.Closed}
+	}
+
+	value, _, err := r.getString(key)
+	if err != nil {
+		return &IntCmd{err: err}
+	}
+	return &IntCmd{val: int64(len(value))}
+}
+
+// MGet 不存在或非字符串类型的键返回nil
+func (r *RedisMock) MGet(ctx context.Context, keys ...string) *SliceCmd {
+	if err := ctx.Err(); err != nil {
+		return &SliceCmd{err: err}
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	if r.closed {
+		return &SliceCmd{err: rediserr.Closed}
+	}
+
+	result := make([]interface{}, len(keys))
+	for i, key := range keys {
+		value, exists, err := r.getString(key)
+		if err == nil && exists {
+			result[i] = value
+		}
+	}
+
+	return &SliceCmd{val: result}
+}
+
+func (r *RedisMock) MSet(ctx context.Context, values ...interface{}) *StatusCmd {
+	if err := ctx.Err(); err != nil {
+		return &StatusCmd{err: err}
+	}
+
+	args := pairArgs(values)
+	if len(args) == 0 || len(args)%2 != 0 {
+		return &StatusCmd{err: errMSetArgCount}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &StatusCmd{err: rediserr.Closed}
+	}
+
+	for i := 0; i < len(args); i += 2 {
+		r.setString(args[i], args[i+1], nil)
+	}
+
+	return &StatusCmd{val: "OK"}
+}
+
+// MSetNX 只要有一个键已存在就不写入任何键
+func (r *RedisMock) MSetNX(ctx context.Context, values ...interface{}) *BoolCmd {
+	if err := ctx.Err(); err != nil {
+		return &BoolCmd{err: err}
+	}
+
+	args := pairArgs(values)
+	if len(args) == 0 || len(args)%2 != 0 {
+		return &BoolCmd{err: errMSetNXArgCount}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &BoolCmd{err: rediserr.Closed}
+	}
+
+	for i := 0; i < len(args); i += 2 {
+		if !r.isExpired(args[i]) {
+			return &BoolCmd{val: false}
+		}
+	}
+	for i := 0; i < len(args); i += 2 {
+		r.setString(args[i], args[i+1], nil)
+	}
+
+	return &BoolCmd{val: true}
+}
+
+// 计数器操作
+func (r *RedisMock) Incr(ctx context.Context, key string) *IntCmd {
+	return r.incrBy(ctx, key, 1)
+}
+
+func (r *RedisMock) IncrBy(ctx context.Context, key string, value int64) *IntCmd {
+	return r.incrBy(ctx, key, value)
+}
+
+func (r *RedisMock) Decr(ctx context.Context, key string) *IntCmd {
+	return r.incrBy(ctx, key, -1)
+}
+
+func (r *RedisMock) DecrBy(ctx context.Context, key string, decrement int64) *IntCmd {
+	// 与Redis一致，不能对最小值取反
+	if decrement == math.MinInt64 {
+		return &IntCmd{err: rediserr.Err("decrement would overflow")}
+	}
+	return r.incrBy(ctx, key, -decrement)
+}
+
+// incrBy INCR系列命令的公共实现，保留键原有的TTL
+func (r *RedisMock) incrBy(ctx context.Context, key string, delta int64) *IntCmd {
+	if err := ctx.Err(); err != nil {
+		return &IntCmd{err: err}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &IntCmd{err: rediserr.Closed}
+	}
+
+	value, exists, err := r.getString(key)
+	if err != nil {
+		return &IntCmd{err: err}
+	}
+
+	var current int64
+	if exists {
+		var ok bool
+		if current, ok = parseRedisInt(value); !ok {
+			return &IntCmd{err: rediserr.NotInteger}
+		}
+	}
+
+	if (delta > 0 && current > math.MaxInt64-delta) || (delta < 0 && current < math.MinInt64-delta) {
+		return &IntCmd{err: errIncrOverflow}
+	}
+
+	result := current + delta
+	r.setString(key, strconv.FormatInt(result, 10), r.currentExpireAt(key))
+	return &IntCmd{val: result}
+}
+
+func (r *RedisMock) IncrByFloat(ctx context.Context, key string, value float64) *FloatCmd {
+	if err := ctx.Err(); err != nil {
+		return &FloatCmd{err: err}
+	}
+
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return &FloatCmd{err: rediserr.NotFloat}
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.closed {
+		return &FloatCmd{err: rediserr.Closed}
+	}
+
+	current, exists, err := r.getString(key)
+	if err != nil {
+		return &FloatCmd{err: err}
+	}
+
+	var base float64
+	if exists {
+		base, err = strconv.ParseFloat(current, 64)
+		if err != nil || math.IsNaN(base) || math.IsInf(base, 0) {
+			return &FloatCmd{err: rediserr.NotFloat}
+		}
+	}
+
+	result := base + value
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return &FloatCmd{err: errIncrFloatNaN}
+	}
+
+	r.setString(key, strconv.FormatFloat(result, 'f', -1, 64), r.currentExpireAt(key))
+	return &FloatCmd{val: result}
+}
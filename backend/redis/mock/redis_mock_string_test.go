@@ -0,0 +1,295 @@
+package mock
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+func TestRedisMock_IncrDecr(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	if val := mock.Incr(ctx, "counter").Val(); val != 1 {
+		t.Errorf("Expected 1, got %d", val)
+	}
+	if val := mock.IncrBy(ctx, "counter", 10).Val(); val != 11 {
+		t.Errorf("Expected 11, got %d", val)
+	}
+	if val := mock.Decr(ctx, "counter").Val(); val != 10 {
+		t.Errorf("Expected 10, got %d", val)
+	}
+	if val := mock.DecrBy(ctx, "counter", 15).Val(); val != -5 {
+		t.Errorf("Expected -5, got %d", val)
+	}
+	if val := mock.Get(ctx, "counter").Val(); val != "-5" {
+		t.Errorf("Expected stored value -5, got %q", val)
+	}
+
+	// INCR保留原有TTL
+	mock.Set(ctx, "ttl", "1", time.Minute)
+	mock.Incr(ctx, "ttl")
+	if ttl := mock.TTL(ctx, "ttl").Val(); ttl <= 0 {
+		t.Errorf("Expected TTL to be kept, got %v", ttl)
+	}
+
+	mock.Set(ctx, "str", "abc", 0)
+	if err := mock.Incr(ctx, "str").Err(); !errors.Is(err, rediserr.NotInteger) {
+		t.Errorf("Expected NotInteger, got %v", err)
+	}
+
+	// 与Redis一致，不接受正号、空白与前导零
+	for _, value := range []string{"+5", " 5", "5 ", "05", "-0", "", "1e3"} {
+		mock.Set(ctx, "strict", value, 0)
+		if err := mock.Incr(ctx, "strict").Err(); !errors.Is(err, rediserr.NotInteger) {
+			t.Errorf("%q: expected NotInteger, got %v", value, err)
+		}
+	}
+	mock.Set(ctx, "zero", "0", 0)
+	if val := mock.IncrBy(ctx, "zero", 5).Val(); val != 5 {
+		t.Errorf("Expected 5, got %d", val)
+	}
+
+	mock.Set(ctx, "max", math.MaxInt64, 0)
+	err := mock.Incr(ctx, "max").Err()
+	if err == nil || err.Error() != "ERR increment or decrement would overflow" {
+		t.Errorf("Expected overflow error, got %v", err)
+	}
+
+	mock.RPush(ctx, "list", "a")
+	if err := mock.Incr(ctx, "list").Err(); !errors.Is(err, rediserr.WrongType) {
+		t.Errorf("Expected WrongType, got %v", err)
+	}
+}
+
+func TestRedisMock_IncrByFloat(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	mock.Set(ctx, "f", "10.5", 0)
+	if val := mock.IncrByFloat(ctx, "f", 0.1).Val(); val != 10.6 {
+		t.Errorf("Expected 10.6, got %v", val)
+	}
+	if val := mock.Get(ctx, "f").Val(); val != "10.6" {
+		t.Errorf("Expected stored value 10.6, got %q", val)
+	}
+
+	mock.Set(ctx, "str", "abc", 0)
+	if err := mock.IncrByFloat(ctx, "str", 1).Err(); !errors.Is(err, rediserr.NotFloat) {
+		t.Errorf("Expected NotFloat, got %v", err)
+	}
+
+	mock.Set(ctx, "big", "1.7e308", 0)
+	if err := mock.IncrByFloat(ctx, "big", 1.7e308).Err(); err == nil {
+		t.Error("Expected error for infinite result")
+	}
+}
+
+func TestRedisMock_MultiKeyStrings(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	if err := mock.MSet(ctx, "a", "1", "b", 2).Err(); err != nil {
+		t.Fatalf("MSet failed: %v", err)
+	}
+	if err := mock.MSet(ctx, "odd").Err(); err == nil {
+		t.Error("Expected error for odd number of arguments")
+	}
+	mock.RPush(ctx, "list", "x")
+
+	vals := mock.MGet(ctx, "a", "missing", "b", "list").Val()
+	if len(vals) != 4 || vals[0] != "1" || vals[1] != nil || vals[2] != "2" || vals[3] != nil {
+		t.Errorf("Unexpected MGet result: %v", vals)
+	}
+
+	if mock.MSetNX(ctx, map[string]interface{}{"a": "x", "c": "3"}).Val() {
+		t.Error("Expected MSetNX to fail when a key exists")
+	}
+	if mock.Exists(ctx, "c").Val() != 0 {
+		t.Error("Expected MSetNX not to write any key")
+	}
+	if !mock.MSetNX(ctx, []string{"c", "3", "d", "4"}).Val() {
+		t.Error("Expected MSetNX to succeed")
+	}
+}
+
+func TestRedisMock_GetVariants(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	if err := mock.GetSet(ctx, "k", "v1").Err(); err != rediserr.Nil {
+		t.Errorf("Expected Nil for GetSet on missing key, got %v", err)
+	}
+	mock.Expire(ctx, "k", time.Minute)
+	if val := mock.GetSet(ctx, "k", "v2").Val(); val != "v1" {
+		t.Errorf("Expected v1, got %q", val)
+	}
+	if ttl := mock.TTL(ctx, "k").Val(); ttl != -1*time.Second {
+		t.Errorf("Expected GetSet to clear TTL, got %v", ttl)
+	}
+
+	if val := mock.GetEx(ctx, "k", time.Minute).Val(); val != "v2" {
+		t.Errorf("Expected v2, got %q", val)
+	}
+	if ttl := mock.TTL(ctx, "k").Val(); ttl <= 0 {
+		t.Errorf("Expected GetEx to set TTL, got %v", ttl)
+	}
+	mock.GetEx(ctx, "k", -1)
+	if ttl := mock.TTL(ctx, "k").Val(); ttl <= 0 {
+		t.Errorf("Expected negative expiration to keep TTL, got %v", ttl)
+	}
+	mock.GetEx(ctx, "k", 0)
+	if ttl := mock.TTL(ctx, "k").Val(); ttl != -1*time.Second {
+		t.Errorf("Expected GetEx 0 to persist key, got %v", ttl)
+	}
+
+	if val := mock.GetDel(ctx, "k").Val(); val != "v2" {
+		t.Errorf("Expected v2, got %q", val)
+	}
+	if err := mock.GetDel(ctx, "k").Err(); err != rediserr.Nil {
+		t.Errorf("Expected Nil after GetDel, got %v", err)
+	}
+}
+
+func TestRedisMock_StrLen(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	if val := mock.StrLen(ctx, "missing").Val(); val != 0 {
+		t.Errorf("Expected 0 for missing key, got %d", val)
+	}
+	mock.Set(ctx, "k", "héllo", 0)
+	if val := mock.StrLen(ctx, "k").Val(); val != 6 {
+		t.Errorf("Expected byte length 6, got %d", val)
+	}
+	mock.LPush(ctx, "list", "a")
+	if err := mock.StrLen(ctx, "list").Err(); err != rediserr.WrongType {
+		t.Errorf("Expected WrongType for list, got %v", err)
+	}
+}
+
+func TestRedisMock_SetOptions(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	if err := mock.SetEX(ctx, "k", "v", 0).Err(); err == nil {
+		t.Error("Expected error for SetEX without expiration")
+	}
+	mock.PSetEX(ctx, "k", "v", 1500*time.Millisecond)
+
+	// KeepTTL保留原有TTL
+	mock.Set(ctx, "k", "v2", KeepTTL)
+	if ttl := mock.TTL(ctx, "k").Val(); ttl <= 0 {
+		t.Errorf("Expected KeepTTL to keep TTL, got %v", ttl)
+	}
+
+	old, err := mock.SetArgs(ctx, "k", "v3", SetArgs{Get: true, KeepTTL: true}).Result()
+	if err != nil || old != "v2" {
+		t.Errorf("Expected old value v2, got %q (%v)", old, err)
+	}
+	if ttl := mock.TTL(ctx, "k").Val(); ttl <= 0 {
+		t.Errorf("Expected SET KEEPTTL to keep TTL, got %v", ttl)
+	}
+
+	if err := mock.SetArgs(ctx, "k", "v4", SetArgs{Mode: "NX"}).Err(); err != rediserr.Nil {
+		t.Errorf("Expected Nil for SET NX on existing key, got %v", err)
+	}
+	if err := mock.SetArgs(ctx, "new", "v", SetArgs{Mode: "XX"}).Err(); err != rediserr.Nil {
+		t.Errorf("Expected Nil for SET XX on missing key, got %v", err)
+	}
+	if err := mock.SetArgs(ctx, "new", "v", SetArgs{Get: true}).Err(); err != rediserr.Nil {
+		t.Errorf("Expected Nil for SET GET on missing key, got %v", err)
+	}
+	if mock.Get(ctx, "new").Val() != "v" {
+		t.Error("Expected SET GET to write the value")
+	}
+	if err := mock.SetArgs(ctx, "k", "v", SetArgs{KeepTTL: true, TTL: time.Second}).Err(); !errors.Is(err, rediserr.Syntax) {
+		t.Errorf("Expected syntax error, got %v", err)
+	}
+
+	mock.RPush(ctx, "list", "x")
+	if err := mock.SetArgs(ctx, "list", "v", SetArgs{Get: true}).Err(); !errors.Is(err, rediserr.WrongType) {
+		t.Errorf("Expected WrongType, got %v", err)
+	}
+}
+
+func TestRedisRecorder_StringCommandsReplay(t *testing.T) {
+	var trace bytes.Buffer
+	recorder := NewRedisRecorder(NewRedisMock(), "conn1", &trace)
+	defer recorder.Close()
+	ctx := context.Background()
+
+	recorder.MSet(ctx, "a", "1", "b", "x")
+	recorder.MGet(ctx, "a", "b", "missing")
+	recorder.Incr(ctx, "a")
+	recorder.IncrBy(ctx, "a", 5)
+	recorder.DecrBy(ctx, "a", 2)
+	recorder.IncrByFloat(ctx, "a", 0.5)
+	recorder.Incr(ctx, "b")
+	recorder.SetEX(ctx, "t", "v", time.Minute)
+	recorder.Set(ctx, "t", "v2", KeepTTL)
+	recorder.TTL(ctx, "t")
+	recorder.SetArgs(ctx, "t", "v3", SetArgs{Mode: "XX", Get: true})
+	recorder.GetEx(ctx, "t", 0)
+	recorder.GetSet(ctx, "t", "v4")
+	recorder.GetDel(ctx, "t")
+	recorder.MSetNX(ctx, "a", "1", "c", "2")
+
+	replayer := NewMockReplayer()
+	defer replayer.Close()
+	diffs, err := replayer.Replay(ctx, bytes.NewReader(trace.Bytes()))
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("Expected no diffs, got %+v", diffs)
+	}
+}
+
+func TestRedisMock_ConcurrentExpiredReads(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	// 读命令只持有读锁，遇到过期键时不能修改数据（go test -race）
+	keys := []string{"a", "b", "c"}
+	for _, key := range keys {
+		mock.PSetEX(ctx, key, "v", time.Millisecond)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, key := range keys {
+				if err := mock.Get(ctx, key).Err(); err != rediserr.Nil {
+					t.Errorf("Expected Nil for expired key, got %v", err)
+				}
+				mock.MGet(ctx, key)
+				mock.GetBit(ctx, key, 0)
+				mock.Exists(ctx, key)
+				mock.TTL(ctx, key)
+			}
+			mock.DBSize(ctx)
+		}()
+	}
+	wg.Wait()
+
+	if n := mock.Del(ctx, "a").Val(); n != 0 {
+		t.Errorf("Expected DEL not to count expired key, got %d", n)
+	}
+}
@@ -2,9 +2,13 @@ package mock
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/devtoolbox/redis/rediserr"
 )
 
 func TestRedisMock_BasicOperations(t *testing.T) {
@@ -119,6 +123,33 @@ func TestRedisMock_ExpireOperations(t *testing.T) {
 	}
 }
 
+func TestRedisMock_Rename(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	mock.Set(ctx, "old", "value", time.Minute)
+	mock.Set(ctx, "existing", "other", 0)
+
+	// 目标键已存在时被覆盖，TTL随键移动
+	if err := mock.Rename(ctx, "old", "existing").Err(); err != nil {
+		t.Fatalf("Rename failed: %v", err)
+	}
+	if val, _ := mock.Get(ctx, "existing").Result(); val != "value" {
+		t.Errorf("Expected renamed value, got %q", val)
+	}
+	if ttl := mock.TTL(ctx, "existing").Val(); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("Expected TTL to move with the key, got %v", ttl)
+	}
+	if n := mock.Exists(ctx, "old").Val(); n != 0 {
+		t.Error("Expected source key to be removed")
+	}
+
+	if err := mock.Rename(ctx, "missing", "other").Err(); !errors.Is(err, rediserr.NoSuchKey) {
+		t.Errorf("Expected no such key, got %v", err)
+	}
+}
+
 func TestRedisMock_HashOperations(t *testing.T) {
 	mock := NewRedisMock()
 	defer mock.Close()
@@ -282,6 +313,30 @@ func TestRedisMock_DatabaseOperations(t *testing.T) {
 	}
 }
 
+func TestRedisMock_Info(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	ctx := context.Background()
+
+	if info := mock.Info(ctx, "keyspace").Val(); info != "# Keyspace\r\n" {
+		t.Errorf("Expected empty keyspace, got %q", info)
+	}
+
+	mock.Select(ctx, 3)
+	mock.Set(ctx, "a", "1", 0)
+	mock.Set(ctx, "b", "2", time.Hour)
+	info := mock.Info(ctx, "keyspace").Val()
+	if !strings.HasPrefix(info, "# Keyspace\r\ndb3:keys=2,expires=1,avg_ttl=") {
+		t.Errorf("Unexpected keyspace: %q", info)
+	}
+	if all := mock.Info(ctx).Val(); !strings.Contains(all, "# Server\r\n") || !strings.Contains(all, "db3:keys=2") {
+		t.Errorf("Expected all sections, got %q", all)
+	}
+	if other := mock.Info(ctx, "memory").Val(); other != "" {
+		t.Errorf("Expected unknown section to be empty, got %q", other)
+	}
+}
+
 func TestRedisMock_ConcurrentAccess(t *testing.T) {
 	mock := NewRedisMock()
 	defer mock.Close()
@@ -315,4 +370,26 @@ func TestRedisMock_ConcurrentAccess(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		<-done
 	}
-}
\ No newline at end of file
+}
+
+func TestRedisMock_ErrorSentinels(t *testing.T) {
+	mock := NewRedisMock()
+	ctx := context.Background()
+
+	if err := mock.Get(ctx, "missing").Err(); !errors.Is(err, rediserr.Nil) {
+		t.Errorf("Expected rediserr.Nil for missing key, got %v", err)
+	}
+
+	mock.HSet(ctx, "hash_key", "field", "value")
+	if err := mock.Get(ctx, "hash_key").Err(); !errors.Is(err, rediserr.WrongType) {
+		t.Errorf("Expected rediserr.WrongType for hash key, got %v", err)
+	}
+	if err := mock.LLen(ctx, "hash_key").Err(); !errors.Is(err, rediserr.WrongType) {
+		t.Errorf("Expected rediserr.WrongType for LLen on hash key, got %v", err)
+	}
+
+	mock.Close()
+	if err := mock.Get(ctx, "hash_key").Err(); !errors.Is(err, rediserr.Closed) {
+		t.Errorf("Expected rediserr.Closed after Close, got %v", err)
+	}
+}
@@ -0,0 +1,1110 @@
+package mock
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReplayDiff 回放结果与录制结果不一致的记录
+type ReplayDiff struct {
+	Entry  TraceEntry  `json:"entry"`
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// RedisReplayer 命令回放器，将录制的轨迹按连接回放到目标客户端
+type RedisReplayer struct {
+	factory func(connectionID string) RedisInterface
+	clients map[string]RedisInterface
+}
+
+// NewRedisReplayer 创建新的命令回放器
+// factory为每个录制连接创建一个目标客户端
+func NewRedisReplayer(factory func(connectionID string) RedisInterface) *RedisReplayer {
+	return &RedisReplayer{
+		factory: factory,
+		clients: make(map[string]RedisInterface),
+	}
+}
+
+// NewMockReplayer 创建回放到RedisMock的回放器
+// 所有录制连接共享同一组Mock数据库，与录制时共享同一个Redis服务一致；每个连接分别记录自己SELECT的数据库
+func NewMockReplayer() *RedisReplayer {
+	databases := &mockDatabases{mocks: make(map[int]*RedisMock)}
+	return NewRedisReplayer(func(connectionID string) RedisInterface {
+		return &mockConnection{RedisInterface: databases.get(0), databases: databases}
+	})
+}
+
+// mockDatabases 回放时共享的Mock数据库，每个数据库编号对应一个Mock实例
+type mockDatabases struct {
+	mocks map[int]*RedisMock
+}
+
+// get 返回数据库对应的Mock，不存在时创建
+func (d *mockDatabases) get(index int) *RedisMock {
+	mock, exists := d.mocks[index]
+	if !exists {
+		mock = NewRedisMock()
+		mock.db = index
+		d.mocks[index] = mock
+	}
+	return mock
+}
+
+// mockConnection 一个录制连接的回放目标，命令发送到连接当前SELECT的数据库
+type mockConnection struct {
+	RedisInterface
+	databases *mockDatabases
+}
+
+// Select 切换连接使用的数据库，不影响其他连接
+func (c *mockConnection) Select(ctx context.Context, index int) *StatusCmd {
+	if index < 0 || index > 15 {
+		return c.RedisInterface.Select(ctx, index)
+	}
+	target := c.databases.get(index)
+	cmd := target.Select(ctx, index)
+	if cmd.Err() == nil {
+		c.RedisInterface = target
+	}
+	return cmd
+}
+
+// FlushAll 清空所有数据库
+func (c *mockConnection) FlushAll(ctx context.Context) *StatusCmd {
+	var cmd *StatusCmd
+	for _, mock := range c.databases.mocks {
+		if cmd = mock.FlushAll(ctx); cmd.Err() != nil {
+			return cmd
+		}
+	}
+	return cmd
+}
+
+// Close 关闭共享的Mock数据库，回放器关闭时调用
+func (c *mockConnection) Close() error {
+	for _, mock := range c.databases.mocks {
+		mock.Close()
+	}
+	return nil
+}
+
+// Client 获取录制连接对应的目标客户端
+func (p *RedisReplayer) Client(connectionID string) RedisInterface {
+	client, exists := p.clients[connectionID]
+	if !exists {
+		client = p.factory(connectionID)
+		p.clients[connectionID] = client
+	}
+	return client
+}
+
+// Close 关闭所有目标客户端
+func (p *RedisReplayer) Close() error {
+	for id, client := range p.clients {
+		client.Close()
+		delete(p.clients, id)
+	}
+	return nil
+}
+
+// blockingCommands 回放时需要限制等待时间的阻塞命令
+var blockingCommands = map[string]bool{
+	"BLPOP":    true,
+	"BRPOP":    true,
+	"BLMOVE":   true,
+	"BZPOPMIN": true,
+	"XREAD":    true,
+}
+
+// ReadTrace 读取JSONL格式的命令轨迹
+func ReadTrace(reader io.Reader) ([]TraceEntry, error) {
+	var entries []TraceEntry
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.UseNumber()
+
+		var entry TraceEntry
+		if err := decoder.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("failed to parse trace line %d: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read trace: %v", err)
+	}
+
+	return entries, nil
+}
+
+// Replay 回放整条轨迹并返回与录制结果不一致的命令
+func (p *RedisReplayer) Replay(ctx context.Context, reader io.Reader) ([]ReplayDiff, error) {
+	entries, err := ReadTrace(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	// 多个连接的轨迹按命令完成时间排序，阻塞命令排在唤醒它的写入之后
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entryEnd(entries[i]).Before(entryEnd(entries[j]))
+	})
+
+	var diffs []ReplayDiff
+	for _, entry := range entries {
+		diff, err := p.ReplayEntry(ctx, entry)
+		if err != nil {
+			return diffs, err
+		}
+		if diff != nil {
+			diffs = append(diffs, *diff)
+		}
+	}
+
+	return diffs, nil
+}
+
+// entryEnd 计算命令完成时间
+func entryEnd(entry TraceEntry) time.Time {
+	return entry.Time.Add(time.Duration(entry.LatencyUs) * time.Microsecond)
+}
+
+// ReplayEntry 回放单条命令，结果一致时返回nil
+func (p *RedisReplayer) ReplayEntry(ctx context.Context, entry TraceEntry) (*ReplayDiff, error) {
+	args := entry.Args
+
+	// 阻塞命令最多等待录制时的耗时再加1秒，避免无限期阻塞回放
+	if blockingCommands[entry.Command] {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(entry.LatencyUs)*time.Microsecond+time.Second)
+		defer cancel()
+	}
+
+	// 自动生成的流消息ID使用录制结果，保证回放后的数据与录制时一致
+	if entry.Command == "XADD" && entry.Error == "" {
+		args = replayXAddArgs(args, entry.Result)
+	}
+
+	cmd, err := executeTraceCommand(ctx, p.Client(entry.ConnectionID), entry.Command, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay seq %d (%s): %v", entry.Seq, entry.Command, err)
+	}
+
+	result, cmdErr := cmdResult(cmd)
+	errText := ""
+	if cmdErr != nil {
+		errText = cmdErr.Error()
+		result = nil
+	}
+
+	if errText == entry.Error && resultsMatch(entry.Command, entry.Result, result) {
+		return nil, nil
+	}
+
+	return &ReplayDiff{
+		Entry:  entry,
+		Result: result,
+		Error:  errText,
+	}, nil
+}
+
+// replayXAddArgs 将XADD参数中的*替换为录制时生成的消息ID
+func replayXAddArgs(args []interface{}, result interface{}) []interface{} {
+	id, ok := result.(string)
+	if !ok || id == "" {
+		return args
+	}
+	replaced := make([]interface{}, len(args))
+	copy(replaced, args)
+	for i := 1; i < len(replaced); i++ {
+		if replaced[i] == "*" {
+			replaced[i] = id
+			break
+		}
+	}
+	return replaced
+}
+
+// resultsMatch 比较录制结果与回放结果
+func resultsMatch(command string, expected, actual interface{}) bool {
+	expected = normalizeTraceValue(expected)
+	actual = normalizeTraceValue(encodeTraceValue(actual))
+
+	switch command {
+	case "TTL":
+		// 回放时间与录制时间不同，剩余时间允许1秒误差
+		e, eok := traceNumber(expected)
+		a, aok := traceNumber(actual)
+		if !eok || !aok {
+			return false
+		}
+		if e < 0 || a < 0 {
+			return e == a
+		}
+		diff := e - a
+		return diff >= -1000 && diff <= 1000
+	case "SMEMBERS", "HKEYS", "HVALS", "KEYS":
+		// 无序结果按排序后比较
+		return reflect.DeepEqual(sortedTraceSlice(expected), sortedTraceSlice(actual))
+	}
+
+	return reflect.DeepEqual(expected, actual)
+}
+
+// normalizeTraceValue 通过JSON往返统一录制值与回放值的表示形式
+func normalizeTraceValue(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var normalized interface{}
+	if err := decoder.Decode(&normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+// sortedTraceSlice 将切片结果排序
+func sortedTraceSlice(value interface{}) []string {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	result := make([]string, len(items))
+	for i, item := range items {
+		result[i] = fmt.Sprintf("%v", item)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// traceNumber 将轨迹中的数字转换为int64
+func traceNumber(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i, true
+		}
+		f, err := v.Float64()
+		return int64(f), err == nil
+	case float64:
+		return int64(v), true
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case string:
+		i, err := strconv.ParseInt(v, 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// traceArgs 轨迹参数读取工具
+type traceArgs []interface{}
+
+func (a traceArgs) require(n int) error {
+	if len(a) < n {
+		return fmt.Errorf("expected at least %d arguments, got %d", n, len(a))
+	}
+	return nil
+}
+
+func (a traceArgs) str(i int) string {
+	if s, ok := a[i].(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", a[i])
+}
+
+func (a traceArgs) int64(i int) (int64, error) {
+	n, ok := traceNumber(a[i])
+	if !ok {
+		return 0, fmt.Errorf("argument %d is not an integer: %v", i, a[i])
+	}
+	return n, nil
+}
+
+func (a traceArgs) float(i int) (float64, error) {
+	switch v := a[i].(type) {
+	case json.Number:
+		return v.Float64()
+	case float64:
+		return v, nil
+	}
+	return strconv.ParseFloat(a.str(i), 64)
+}
+
+func (a traceArgs) duration(i int) (time.Duration, error) {
+	ms, err := a.int64(i)
+	if err != nil {
+		return 0, err
+	}
+	// 录制时保留的KeepTTL哨兵值
+	if ms == KeepTTL {
+		return KeepTTL, nil
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+func (a traceArgs) strings(from int) []string {
+	result := make([]string, 0, len(a)-from)
+	for i := from; i < len(a); i++ {
+		result = append(result, a.str(i))
+	}
+	return result
+}
+
+func (a traceArgs) values(from int) []interface{} {
+	result := make([]interface{}, 0, len(a)-from)
+	for i := from; i < len(a); i++ {
+		result = append(result, a.str(i))
+	}
+	return result
+}
+
+// decodeTraceArgs 将{"$bytes":"<Base64>"}参数还原为原始字节组成的字符串
+func decodeTraceArgs(rawArgs []interface{}) ([]interface{}, error) {
+	args := make([]interface{}, len(rawArgs))
+	for i, arg := range rawArgs {
+		args[i] = arg
+		tagged, ok := arg.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		encoded, ok := tagged["$bytes"].(string)
+		if !ok || len(tagged) != 1 {
+			return nil, fmt.Errorf("argument %d has an unknown type: %v", i, arg)
+		}
+		b, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("argument %d is not valid base64: %v", i, err)
+		}
+		args[i] = string(b)
+	}
+	return args, nil
+}
+
+// executeTraceCommand 按命令名将轨迹参数映射回RedisInterface方法调用
+func executeTraceCommand(ctx context.Context, client RedisInterface, command string, rawArgs []interface{}) (interface{}, error) {
+	decoded, err := decodeTraceArgs(rawArgs)
+	if err != nil {
+		return nil, err
+	}
+	args := traceArgs(decoded)
+
+	switch command {
+	case "PING":
+		return client.Ping(ctx), nil
+	case "FLUSHDB":
+		return client.FlushDB(ctx), nil
+	case "FLUSHALL":
+		return client.FlushAll(ctx), nil
+	case "DBSIZE":
+		return client.DBSize(ctx), nil
+	case "INFO":
+		return client.Info(ctx, args.strings(0)...), nil
+	case "DEL":
+		return client.Del(ctx, args.strings(0)...), nil
+	case "EXISTS":
+		return client.Exists(ctx, args.strings(0)...), nil
+	case "SELECT":
+		if err := args.require(1); err != nil {
+			return nil, err
+		}
+		index, err := args.int64(0)
+		if err != nil {
+			return nil, err
+		}
+		return client.Select(ctx, int(index)), nil
+	case "BLPOP", "BRPOP", "BZPOPMIN":
+		if err := args.require(2); err != nil {
+			return nil, err
+		}
+		timeout, err := args.duration(0)
+		if err != nil {
+			return nil, err
+		}
+		switch command {
+		case "BLPOP":
+			return client.BLPop(ctx, timeout, args.strings(1)...), nil
+		case "BRPOP":
+			return client.BRPop(ctx, timeout, args.strings(1)...), nil
+		default:
+			return client.BZPopMin(ctx, timeout, args.strings(1)...), nil
+		}
+	case "XREAD":
+		a, err := parseXReadArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return client.XRead(ctx, a), nil
+	case "PFCOUNT":
+		return client.PFCount(ctx, args.strings(0)...), nil
+	case "MGET":
+		return client.MGet(ctx, args.strings(0)...), nil
+	case "MSET":
+		return client.MSet(ctx, args.values(0)...), nil
+	case "MSETNX":
+		return client.MSetNX(ctx, args.values(0)...), nil
+	case "SCAN":
+		return parseScanArgs(ctx, client, args)
+	case "CLUSTER":
+		if err := args.require(1); err != nil {
+			return nil, err
+		}
+		switch subcommand := strings.ToUpper(args.str(0)); subcommand {
+		case "SLOTS":
+			return client.ClusterSlots(ctx), nil
+		case "NODES":
+			return client.ClusterNodes(ctx), nil
+		case "KEYSLOT":
+			if err := args.require(2); err != nil {
+				return nil, err
+			}
+			return client.ClusterKeySlot(ctx, args.str(1)), nil
+		default:
+			return nil, fmt.Errorf("unsupported CLUSTER subcommand: %s", subcommand)
+		}
+	}
+
+	// 以下命令第一个参数均为键名
+	if err := args.require(1); err != nil {
+		return nil, err
+	}
+	key := args.str(0)
+
+	switch command {
+	case "GET":
+		return client.Get(ctx, key), nil
+	case "SET", "SETNX":
+		if err := args.require(3); err != nil {
+			return nil, err
+		}
+		expiration, err := args.duration(2)
+		if err != nil {
+			return nil, err
+		}
+		if command == "SETNX" {
+			return client.SetNX(ctx, key, args.str(1), expiration), nil
+		}
+		return client.Set(ctx, key, args.str(1), expiration), nil
+	case "SETEX", "PSETEX":
+		if err := args.require(3); err != nil {
+			return nil, err
+		}
+		expiration, err := args.duration(2)
+		if err != nil {
+			return nil, err
+		}
+		if command == "PSETEX" {
+			return client.PSetEX(ctx, key, args.str(1), expiration), nil
+		}
+		return client.SetEX(ctx, key, args.str(1), expiration), nil
+	case "SETARGS":
+		if err := args.require(2); err != nil {
+			return nil, err
+		}
+		a, err := parseSetArgs(args[2:])
+		if err != nil {
+			return nil, err
+		}
+		return client.SetArgs(ctx, key, args.str(1), a), nil
+	case "GETSET":
+		if err := args.require(2); err != nil {
+			return nil, err
+		}
+		return client.GetSet(ctx, key, args.str(1)), nil
+	case "GETDEL":
+		return client.GetDel(ctx, key), nil
+	case "GETEX":
+		if err := args.require(2); err != nil {
+			return nil, err
+		}
+		expiration, err := args.duration(1)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetEx(ctx, key, expiration), nil
+	case "STRLEN":
+		return client.StrLen(ctx, key), nil
+	case "INCR":
+		return client.Incr(ctx, key), nil
+	case "DECR":
+		return client.Decr(ctx, key), nil
+	case "INCRBY", "DECRBY":
+		if err := args.require(2); err != nil {
+			return nil, err
+		}
+		n, err := args.int64(1)
+		if err != nil {
+			return nil, err
+		}
+		if command == "DECRBY" {
+			return client.DecrBy(ctx, key, n), nil
+		}
+		return client.IncrBy(ctx, key, n), nil
+	case "INCRBYFLOAT":
+		if err := args.require(2); err != nil {
+			return nil, err
+		}
+		f, err := args.float(1)
+		if err != nil {
+			return nil, err
+		}
+		return client.IncrByFloat(ctx, key, f), nil
+	case "SETBIT":
+		if err := args.require(3); err != nil {
+			return nil, err
+		}
+		offset, err := args.int64(1)
+		if err != nil {
+			return nil, err
+		}
+		value, err := args.int64(2)
+		if err != nil {
+			return nil, err
+		}
+		return client.SetBit(ctx, key, offset, int(value)), nil
+	case "GETBIT":
+		if err := args.require(2); err != nil {
+			return nil, err
+		}
+		offset, err := args.int64(1)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetBit(ctx, key, offset), nil
+	case "BITCOUNT":
+		if len(args) < 3 {
+			return client.BitCount(ctx, key, nil), nil
+		}
+		start, err := args.int64(1)
+		if err != nil {
+			return nil, err
+		}
+		end, err := args.int64(2)
+		if err != nil {
+			return nil, err
+		}
+		return client.BitCount(ctx, key, &BitCount{Start: start, End: end}), nil
+	case "BITPOS":
+		if err := args.require(2); err != nil {
+			return nil, err
+		}
+		numbers := make([]int64, 0, len(args)-1)
+		for i := 1; i < len(args); i++ {
+			n, err := args.int64(i)
+			if err != nil {
+				return nil, err
+			}
+			numbers = append(numbers, n)
+		}
+		return client.BitPos(ctx, key, numbers[0], numbers[1:]...), nil
+	case "BITFIELD":
+		return client.BitField(ctx, key, args.values(1)...), nil
+	case "PFADD":
+		return client.PFAdd(ctx, key, args.values(1)...), nil
+	case "PFMERGE":
+		return client.PFMerge(ctx, key, args.strings(1)...), nil
+	case "GEOADD":
+		if (len(args)-1)%3 != 0 {
+			return nil, fmt.Errorf("GEOADD expects longitude/latitude/member triples")
+		}
+		locations := make([]*GeoLocation, 0, (len(args)-1)/3)
+		for i := 1; i < len(args); i += 3 {
+			lon, err := args.float(i)
+			if err != nil {
+				return nil, err
+			}
+			lat, err := args.float(i + 1)
+			if err != nil {
+				return nil, err
+			}
+			locations = append(locations, &GeoLocation{Name: args.str(i + 2), Longitude: lon, Latitude: lat})
+		}
+		return client.GeoAdd(ctx, key, locations...), nil
+	case "GEOPOS":
+		return client.GeoPos(ctx, key, args.strings(1)...), nil
+	case "GEOHASH":
+		return client.GeoHash(ctx, key, args.strings(1)...), nil
+	case "GEODIST":
+		if err := args.require(4); err != nil {
+			return nil, err
+		}
+		return client.GeoDist(ctx, key, args.str(1), args.str(2), args.str(3)), nil
+	case "GEORADIUS":
+		if err := args.require(5); err != nil {
+			return nil, err
+		}
+		lon, err := args.float(1)
+		if err != nil {
+			return nil, err
+		}
+		lat, err := args.float(2)
+		if err != nil {
+			return nil, err
+		}
+		query, err := parseGeoRadiusArgs(args[3:])
+		if err != nil {
+			return nil, err
+		}
+		return client.GeoRadius(ctx, key, lon, lat, query), nil
+	case "GEORADIUSBYMEMBER":
+		if err := args.require(4); err != nil {
+			return nil, err
+		}
+		query, err := parseGeoRadiusArgs(args[2:])
+		if err != nil {
+			return nil, err
+		}
+		return client.GeoRadiusByMember(ctx, key, args.str(1), query), nil
+	case "GEOSEARCH", "GEOSEARCHLOCATION":
+		q, err := parseGeoSearchArgs(args[1:])
+		if err != nil {
+			return nil, err
+		}
+		if command == "GEOSEARCH" {
+			return client.GeoSearch(ctx, key, &q.GeoSearchQuery), nil
+		}
+		return client.GeoSearchLocation(ctx, key, q), nil
+	case "JSON.SET":
+		if err := args.require(3); err != nil {
+			return nil, err
+		}
+		if len(args) > 3 {
+			return client.JSONSetMode(ctx, key, args.str(1), args.str(2), args.str(3)), nil
+		}
+		return client.JSONSet(ctx, key, args.str(1), args.str(2)), nil
+	case "JSON.GET":
+		return client.JSONGet(ctx, key, args.strings(1)...), nil
+	case "JSON.DEL", "JSON.TYPE", "JSON.OBJKEYS":
+		if err := args.require(2); err != nil {
+			return nil, err
+		}
+		switch command {
+		case "JSON.DEL":
+			return client.JSONDel(ctx, key, args.str(1)), nil
+		case "JSON.TYPE":
+			return client.JSONType(ctx, key, args.str(1)), nil
+		default:
+			return client.JSONObjKeys(ctx, key, args.str(1)), nil
+		}
+	case "JSON.ARRAPPEND":
+		if err := args.require(2); err != nil {
+			return nil, err
+		}
+		return client.JSONArrAppend(ctx, key, args.str(1), args.values(2)...), nil
+	case "JSON.NUMINCRBY":
+		if err := args.require(3); err != nil {
+			return nil, err
+		}
+		value, err := args.float(2)
+		if err != nil {
+			return nil, err
+		}
+		return client.JSONNumIncrBy(ctx, key, args.str(1), value), nil
+	case "EXPIRE":
+		if err := args.require(2); err != nil {
+			return nil, err
+		}
+		expiration, err := args.duration(1)
+		if err != nil {
+			return nil, err
+		}
+		return client.Expire(ctx, key, expiration), nil
+	case "TTL":
+		return client.TTL(ctx, key), nil
+	case "PTTL":
+		return client.PTTL(ctx, key), nil
+	case "PERSIST":
+		return client.Persist(ctx, key), nil
+	case "TYPE":
+		return client.Type(ctx, key), nil
+	case "RENAME":
+		if err := args.require(2); err != nil {
+			return nil, err
+		}
+		return client.Rename(ctx, key, args.str(1)), nil
+	case "DUMP":
+		return client.Dump(ctx, key), nil
+	case "RESTORE":
+		if err := args.require(3); err != nil {
+			return nil, err
+		}
+		ttl, err := args.duration(1)
+		if err != nil {
+			return nil, err
+		}
+		if len(args) > 3 && strings.EqualFold(args.str(3), "REPLACE") {
+			return client.RestoreReplace(ctx, key, ttl, args.str(2)), nil
+		}
+		return client.Restore(ctx, key, ttl, args.str(2)), nil
+	case "KEYS":
+		return client.Keys(ctx, key), nil
+	case "HGET", "HEXISTS":
+		if err := args.require(2); err != nil {
+			return nil, err
+		}
+		if command == "HEXISTS" {
+			return client.HExists(ctx, key, args.str(1)), nil
+		}
+		return client.HGet(ctx, key, args.str(1)), nil
+	case "HSET":
+		return client.HSet(ctx, key, args.values(1)...), nil
+	case "HDEL":
+		return client.HDel(ctx, key, args.strings(1)...), nil
+	case "HGETALL":
+		return client.HGetAll(ctx, key), nil
+	case "HKEYS":
+		return client.HKeys(ctx, key), nil
+	case "HVALS":
+		return client.HVals(ctx, key), nil
+	case "LPUSH":
+		return client.LPush(ctx, key, args.values(1)...), nil
+	case "RPUSH":
+		return client.RPush(ctx, key, args.values(1)...), nil
+	case "LPOP":
+		return client.LPop(ctx, key), nil
+	case "RPOP":
+		return client.RPop(ctx, key), nil
+	case "LLEN":
+		return client.LLen(ctx, key), nil
+	case "LRANGE", "ZRANGE", "ZRANGEWITHSCORES":
+		if err := args.require(3); err != nil {
+			return nil, err
+		}
+		start, err := args.int64(1)
+		if err != nil {
+			return nil, err
+		}
+		stop, err := args.int64(2)
+		if err != nil {
+			return nil, err
+		}
+		switch command {
+		case "LRANGE":
+			return client.LRange(ctx, key, start, stop), nil
+		case "ZRANGE":
+			return client.ZRange(ctx, key, start, stop), nil
+		default:
+			return client.ZRangeWithScores(ctx, key, start, stop), nil
+		}
+	case "SADD":
+		return client.SAdd(ctx, key, args.values(1)...), nil
+	case "SREM":
+		return client.SRem(ctx, key, args.values(1)...), nil
+	case "SMEMBERS":
+		return client.SMembers(ctx, key), nil
+	case "SISMEMBER":
+		if err := args.require(2); err != nil {
+			return nil, err
+		}
+		return client.SIsMember(ctx, key, args.str(1)), nil
+	case "SCARD":
+		return client.SCard(ctx, key), nil
+	case "ZADD":
+		if (len(args)-1)%2 != 0 {
+			return nil, fmt.Errorf("ZADD expects score/member pairs")
+		}
+		members := make([]*Z, 0, (len(args)-1)/2)
+		for i := 1; i < len(args); i += 2 {
+			score, err := args.float(i)
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, &Z{Score: score, Member: args.str(i + 1)})
+		}
+		return client.ZAdd(ctx, key, members...), nil
+	case "ZREM":
+		return client.ZRem(ctx, key, args.values(1)...), nil
+	case "LMOVE", "BLMOVE":
+		if err := args.require(4); err != nil {
+			return nil, err
+		}
+		if command == "LMOVE" {
+			return client.LMove(ctx, key, args.str(1), args.str(2), args.str(3)), nil
+		}
+		if err := args.require(5); err != nil {
+			return nil, err
+		}
+		timeout, err := args.duration(4)
+		if err != nil {
+			return nil, err
+		}
+		return client.BLMove(ctx, key, args.str(1), args.str(2), args.str(3), timeout), nil
+	case "XADD":
+		a, err := parseXAddArgs(args)
+		if err != nil {
+			return nil, err
+		}
+		return client.XAdd(ctx, a), nil
+	case "XLEN":
+		return client.XLen(ctx, key), nil
+	case "XRANGE":
+		if err := args.require(3); err != nil {
+			return nil, err
+		}
+		return client.XRange(ctx, key, args.str(1), args.str(2)), nil
+	case "ZCARD":
+		return client.ZCard(ctx, key), nil
+	case "ZSCORE":
+		if err := args.require(2); err != nil {
+			return nil, err
+		}
+		return client.ZScore(ctx, key, args.str(1)), nil
+	}
+
+	return nil, fmt.Errorf("unsupported command in trace: %s", command)
+}
+
+// parseXAddArgs 解析Redis命令格式的XADD参数
+func parseXAddArgs(args traceArgs) (*XAddArgs, error) {
+	a := &XAddArgs{Stream: args.str(0)}
+	i := 1
+	for ; i < len(args); i++ {
+		switch strings.ToUpper(args.str(i)) {
+		case "NOMKSTREAM":
+			a.NoMkStream = true
+			continue
+		case "MAXLEN":
+			i++
+			if i < len(args) && args.str(i) == "~" {
+				a.Approx = true
+				i++
+			}
+			if i >= len(args) {
+				return nil, fmt.Errorf("XADD MAXLEN requires a value")
+			}
+			maxLen, err := args.int64(i)
+			if err != nil {
+				return nil, err
+			}
+			a.MaxLen = maxLen
+			continue
+		}
+		break
+	}
+	if i >= len(args) {
+		return nil, fmt.Errorf("XADD requires an ID")
+	}
+	a.ID = args.str(i)
+	a.Values = args.strings(i + 1)
+	return a, nil
+}
+
+// parseSetArgs 解析Redis命令格式的SET选项
+func parseSetArgs(args traceArgs) (SetArgs, error) {
+	var a SetArgs
+	for i := 0; i < len(args); i++ {
+		option := strings.ToUpper(args.str(i))
+		switch option {
+		case "KEEPTTL":
+			a.KeepTTL = true
+		case "GET":
+			a.Get = true
+		case "NX", "XX":
+			a.Mode = option
+		case "EXAT", "PX":
+			if i+1 >= len(args) {
+				return a, fmt.Errorf("SET %s requires a value", option)
+			}
+			n, err := args.int64(i + 1)
+			if err != nil {
+				return a, err
+			}
+			if option == "EXAT" {
+				a.ExpireAt = time.Unix(n, 0)
+			} else {
+				a.TTL = time.Duration(n) * time.Millisecond
+			}
+			i++
+		default:
+			return a, fmt.Errorf("unexpected SET argument: %s", args.str(i))
+		}
+	}
+	return a, nil
+}
+
+// parseGeoRadiusArgs 解析Redis命令格式的GEORADIUS半径、单位与选项
+func parseGeoRadiusArgs(args traceArgs) (*GeoRadiusQuery, error) {
+	radius, err := args.float(0)
+	if err != nil {
+		return nil, err
+	}
+	q := &GeoRadiusQuery{Radius: radius, Unit: args.str(1)}
+	for i := 2; i < len(args); i++ {
+		switch option := strings.ToUpper(args.str(i)); option {
+		case "WITHCOORD":
+			q.WithCoord = true
+		case "WITHDIST":
+			q.WithDist = true
+		case "WITHHASH":
+			q.WithGeoHash = true
+		case "ASC", "DESC":
+			q.Sort = option
+		case "COUNT":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("GEORADIUS COUNT requires a value")
+			}
+			n, err := args.int64(i + 1)
+			if err != nil {
+				return nil, err
+			}
+			q.Count = int(n)
+			i++
+		default:
+			return nil, fmt.Errorf("unexpected GEORADIUS argument: %s", args.str(i))
+		}
+	}
+	return q, nil
+}
+
+// parseGeoSearchArgs 解析Redis命令格式的GEOSEARCH参数
+func parseGeoSearchArgs(args traceArgs) (*GeoSearchLocationQuery, error) {
+	q := &GeoSearchLocationQuery{}
+	// need 检查选项后是否还有n个参数
+	need := func(i, n int) error {
+		if i+n >= len(args) {
+			return fmt.Errorf("GEOSEARCH %s requires %d values", args.str(i), n)
+		}
+		return nil
+	}
+
+	for i := 0; i < len(args); i++ {
+		var err error
+		switch option := strings.ToUpper(args.str(i)); option {
+		case "FROMMEMBER":
+			if err = need(i, 1); err == nil {
+				q.Member = args.str(i + 1)
+				i++
+			}
+		case "FROMLONLAT":
+			if err = need(i, 2); err == nil {
+				if q.Longitude, err = args.float(i + 1); err == nil {
+					q.Latitude, err = args.float(i + 2)
+				}
+				i += 2
+			}
+		case "BYRADIUS":
+			if err = need(i, 2); err == nil {
+				q.Radius, err = args.float(i + 1)
+				q.RadiusUnit = args.str(i + 2)
+				i += 2
+			}
+		case "BYBOX":
+			if err = need(i, 3); err == nil {
+				if q.BoxWidth, err = args.float(i + 1); err == nil {
+					q.BoxHeight, err = args.float(i + 2)
+				}
+				q.BoxUnit = args.str(i + 3)
+				i += 3
+			}
+		case "COUNT":
+			if err = need(i, 1); err == nil {
+				var n int64
+				n, err = args.int64(i + 1)
+				q.Count = int(n)
+				i++
+			}
+		case "ANY":
+			q.CountAny = true
+		case "ASC", "DESC":
+			q.Sort = option
+		case "WITHCOORD":
+			q.WithCoord = true
+		case "WITHDIST":
+			q.WithDist = true
+		case "WITHHASH":
+			q.WithHash = true
+		default:
+			err = fmt.Errorf("unexpected GEOSEARCH argument: %s", args.str(i))
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return q, nil
+}
+
+// parseXReadArgs 解析Redis命令格式的XREAD参数
+func parseXReadArgs(args traceArgs) (*XReadArgs, error) {
+	// 未记录BLOCK时为非阻塞读取
+	a := &XReadArgs{Block: -1}
+	for i := 0; i < len(args); i++ {
+		switch strings.ToUpper(args.str(i)) {
+		case "COUNT", "BLOCK":
+			if i+1 >= len(args) {
+				return nil, fmt.Errorf("XREAD %s requires a value", args.str(i))
+			}
+			n, err := args.int64(i + 1)
+			if err != nil {
+				return nil, err
+			}
+			if strings.ToUpper(args.str(i)) == "COUNT" {
+				a.Count = n
+			} else {
+				a.Block = time.Duration(n) * time.Millisecond
+			}
+			i++
+		case "STREAMS":
+			a.Streams = args.strings(i + 1)
+			return a, nil
+		default:
+			return nil, fmt.Errorf("unexpected XREAD argument: %s", args.str(i))
+		}
+	}
+	return nil, fmt.Errorf("XREAD requires STREAMS")
+}
+
+// parseScanArgs 解析SCAN的游标与MATCH、COUNT选项并执行
+func parseScanArgs(ctx context.Context, client RedisInterface, args traceArgs) (interface{}, error) {
+	if err := args.require(1); err != nil {
+		return nil, err
+	}
+	cursor, err := strconv.ParseUint(args.str(0), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SCAN cursor: %v", args[0])
+	}
+
+	var match string
+	var count int64
+	for i := 1; i < len(args); i += 2 {
+		if err := args.require(i + 2); err != nil {
+			return nil, err
+		}
+		switch option := strings.ToUpper(args.str(i)); option {
+		case "MATCH":
+			match = args.str(i + 1)
+		case "COUNT":
+			if count, err = args.int64(i + 1); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported SCAN option: %s", option)
+		}
+	}
+	return client.Scan(ctx, cursor, match, count), nil
+}
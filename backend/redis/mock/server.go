@@ -0,0 +1,407 @@
+package mock
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+// 服务端认证错误，与Redis 6一致
+var (
+	errNoAuth    = rediserr.New(rediserr.NoAuth.Code, "Authentication required.")
+	errWrongPass = rediserr.New(rediserr.WrongPass.Code, "invalid username-password pair or user is disabled.")
+)
+
+// Server 通过RESP协议对外提供RedisInterface
+// 只实现连接相关的命令和少量常用命令，用于让真实的go-redis客户端在测试中验证TLS、ACL等连接参数
+type Server struct {
+	client RedisInterface
+
+	mutex    sync.Mutex
+	users    map[string]string // ACL用户名 -> 密码，为空时不需要认证
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// NewServer 创建RESP服务，命令由client执行
+func NewServer(client RedisInterface) *Server {
+	return &Server{
+		client: client,
+		users:  make(map[string]string),
+		conns:  make(map[net.Conn]struct{}),
+	}
+}
+
+// AddUser 添加ACL用户，添加任意用户后连接必须先AUTH
+// 用户名为default时相当于requirepass，可以只用密码认证
+func (s *Server) AddUser(username, password string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.users[username] = password
+}
+
+// Serve 在listener上接受连接，直到Close被调用
+func (s *Server) Serve(listener net.Listener) error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return rediserr.Closed
+	}
+	s.listener = listener
+	s.mutex.Unlock()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.mutex.Lock()
+			closed := s.closed
+			s.mutex.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+
+		s.mutex.Lock()
+		s.conns[conn] = struct{}{}
+		s.mutex.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(conn)
+
+			s.mutex.Lock()
+			delete(s.conns, conn)
+			s.mutex.Unlock()
+		}()
+	}
+}
+
+// Close 停止接受连接并断开已有连接，不会关闭client
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	s.closed = true
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mutex.Unlock()
+
+	s.wg.Wait()
+	return nil
+}
+
+// serveConn 处理单个连接上的命令
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+
+	s.mutex.Lock()
+	authenticated := len(s.users) == 0
+	s.mutex.Unlock()
+
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				writeReply(writer, rediserr.Err("Protocol error: "+err.Error()))
+				writer.Flush()
+			}
+			return
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		command := strings.ToUpper(args[0])
+		var reply interface{}
+		switch {
+		case command == "AUTH":
+			reply = s.auth(args[1:])
+			authenticated = reply == "OK"
+		case command == "QUIT":
+			writeReply(writer, "OK")
+			writer.Flush()
+			return
+		case !authenticated:
+			reply = errNoAuth
+		default:
+			reply = s.execute(command, args[1:])
+		}
+
+		writeReply(writer, reply)
+		// 流水线中的命令处理完后再统一写出
+		if reader.Buffered() == 0 {
+			if err := writer.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// auth 处理AUTH [username] password
+func (s *Server) auth(args []string) interface{} {
+	var username, password string
+	switch len(args) {
+	case 1:
+		username, password = "default", args[0]
+	case 2:
+		username, password = args[0], args[1]
+	default:
+		return wrongArgs("auth")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.users) == 0 {
+		return rediserr.Err("AUTH <password> called without any password configured for the default user. Are you sure your configuration is correct?")
+	}
+	if expected, ok := s.users[username]; !ok || expected != password {
+		return errWrongPass
+	}
+	return "OK"
+}
+
+// execute 执行普通命令，返回回复值或错误
+func (s *Server) execute(command string, args []string) interface{} {
+	ctx := context.Background()
+	switch command {
+	case "PING":
+		if len(args) > 0 {
+			return []byte(args[0])
+		}
+		return s.result(s.client.Ping(ctx).Result())
+	case "ECHO":
+		if len(args) != 1 {
+			return wrongArgs("echo")
+		}
+		return []byte(args[0])
+	case "SELECT":
+		if len(args) != 1 {
+			return wrongArgs("select")
+		}
+		db, err := strconv.Atoi(args[0])
+		if err != nil {
+			return rediserr.NotInteger
+		}
+		return s.result(s.client.Select(ctx, db).Result())
+	case "DBSIZE":
+		return s.result(s.client.DBSize(ctx).Result())
+	case "INFO":
+		info, err := s.client.Info(ctx, args...).Result()
+		if err != nil {
+			return err
+		}
+		return []byte(info)
+	case "GET":
+		if len(args) != 1 {
+			return wrongArgs("get")
+		}
+		value, err := s.client.Get(ctx, args[0]).Result()
+		if err != nil {
+			return err
+		}
+		return []byte(value)
+	case "SET":
+		if len(args) < 2 {
+			return wrongArgs("set")
+		}
+		var expiration time.Duration
+		for i := 2; i < len(args); i += 2 {
+			if i+1 >= len(args) {
+				return rediserr.Syntax
+			}
+			n, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil || n <= 0 {
+				return rediserr.NotInteger
+			}
+			switch strings.ToUpper(args[i]) {
+			case "EX":
+				expiration = time.Duration(n) * time.Second
+			case "PX":
+				expiration = time.Duration(n) * time.Millisecond
+			default:
+				return rediserr.Syntax
+			}
+		}
+		return s.result(s.client.Set(ctx, args[0], args[1], expiration).Result())
+	case "DEL":
+		if len(args) == 0 {
+			return wrongArgs("del")
+		}
+		return s.result(s.client.Del(ctx, args...).Result())
+	case "EXISTS":
+		if len(args) == 0 {
+			return wrongArgs("exists")
+		}
+		return s.result(s.client.Exists(ctx, args...).Result())
+	case "TYPE":
+		if len(args) != 1 {
+			return wrongArgs("type")
+		}
+		return s.result(s.client.Type(ctx, args[0]).Result())
+	case "FLUSHDB":
+		return s.result(s.client.FlushDB(ctx).Result())
+	case "RENAME":
+		if len(args) != 2 {
+			return wrongArgs("rename")
+		}
+		return s.result(s.client.Rename(ctx, args[0], args[1]).Result())
+	case "EXPIRE":
+		if len(args) != 2 {
+			return wrongArgs("expire")
+		}
+		seconds, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return rediserr.NotInteger
+		}
+		return s.result(s.client.Expire(ctx, args[0], time.Duration(seconds)*time.Second).Result())
+	case "PTTL":
+		if len(args) != 1 {
+			return wrongArgs("pttl")
+		}
+		ttl, err := s.client.PTTL(ctx, args[0]).Result()
+		if err != nil {
+			return err
+		}
+		if ttl < 0 {
+			return int64(ttl)
+		}
+		return ttl.Milliseconds()
+	case "PERSIST":
+		if len(args) != 1 {
+			return wrongArgs("persist")
+		}
+		return s.result(s.client.Persist(ctx, args[0]).Result())
+	case "DUMP":
+		if len(args) != 1 {
+			return wrongArgs("dump")
+		}
+		payload, err := s.client.Dump(ctx, args[0]).Result()
+		if err != nil {
+			return err
+		}
+		return []byte(payload)
+	case "RESTORE":
+		if len(args) < 3 {
+			return wrongArgs("restore")
+		}
+		ms, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return rediserr.NotInteger
+		}
+		ttl := time.Duration(ms) * time.Millisecond
+		switch {
+		case len(args) == 3:
+			return s.result(s.client.Restore(ctx, args[0], ttl, args[2]).Result())
+		case len(args) == 4 && strings.EqualFold(args[3], "REPLACE"):
+			return s.result(s.client.RestoreReplace(ctx, args[0], ttl, args[2]).Result())
+		}
+		return rediserr.Syntax
+	}
+	return rediserr.Err(fmt.Sprintf("unknown command '%s'", strings.ToLower(command)))
+}
+
+// result 将命令结果转换为回复值，出错时返回错误
+func (s *Server) result(value interface{}, err error) interface{} {
+	if err != nil {
+		return err
+	}
+	return value
+}
+
+func wrongArgs(command string) error {
+	return rediserr.Err(fmt.Sprintf("wrong number of arguments for '%s' command", command))
+}
+
+// readCommand 读取一条RESP数组命令，也支持以空格分隔的内联命令
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := readLine(reader)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return strings.Fields(line), nil
+	}
+
+	count, err := strconv.Atoi(line[1:])
+	if err != nil || count < 0 {
+		return nil, fmt.Errorf("invalid multibulk length")
+	}
+	args := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		header, err := readLine(reader)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(header, "$") {
+			return nil, fmt.Errorf("expected '$', got '%s'", header)
+		}
+		size, err := strconv.Atoi(header[1:])
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("invalid bulk length")
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args = append(args, string(data[:size]))
+	}
+	return args, nil
+}
+
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// writeReply 按RESP2编码回复
+// string为简单字符串，[]byte为批量字符串，rediserr.Nil为空批量字符串
+func writeReply(writer *bufio.Writer, reply interface{}) {
+	switch v := reply.(type) {
+	case error:
+		if errors.Is(v, rediserr.Nil) {
+			writer.WriteString("$-1\r\n")
+			return
+		}
+		var redisErr *rediserr.Error
+		if !errors.As(v, &redisErr) {
+			redisErr = rediserr.Err(v.Error())
+		}
+		fmt.Fprintf(writer, "-%s\r\n", redisErr.Error())
+	case string:
+		fmt.Fprintf(writer, "+%s\r\n", v)
+	case []byte:
+		fmt.Fprintf(writer, "$%d\r\n%s\r\n", len(v), v)
+	case int64:
+		fmt.Fprintf(writer, ":%d\r\n", v)
+	case bool:
+		if v {
+			writer.WriteString(":1\r\n")
+		} else {
+			writer.WriteString(":0\r\n")
+		}
+	default:
+		fmt.Fprintf(writer, "-ERR unsupported reply type %T\r\n", v)
+	}
+}
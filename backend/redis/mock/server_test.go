@@ -0,0 +1,53 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/devtoolbox/redis/rediserr"
+	"github.com/go-redis/redis/v8"
+)
+
+func TestServer_ACL(t *testing.T) {
+	mock := NewRedisMock()
+	defer mock.Close()
+	server := NewServer(mock)
+	server.AddUser("app", "secret")
+	defer server.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	go server.Serve(listener)
+	ctx := context.Background()
+
+	client := redis.NewClient(&redis.Options{Addr: listener.Addr().String(), Username: "app", Password: "secret", DB: 1})
+	defer client.Close()
+	if err := client.Set(ctx, "greeting", "hello", 0).Err(); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if value := mock.Get(ctx, "greeting").Val(); value != "hello" {
+		t.Errorf("Expected value to reach the mock, got %q", value)
+	}
+	if err := client.Get(ctx, "missing").Err(); err != redis.Nil {
+		t.Errorf("Expected redis.Nil, got %v", err)
+	}
+	if n := client.Del(ctx, "greeting", "missing").Val(); n != 1 {
+		t.Errorf("Expected 1 deleted, got %d", n)
+	}
+
+	wrong := redis.NewClient(&redis.Options{Addr: listener.Addr().String(), Username: "app", Password: "wrong", MaxRetries: -1})
+	defer wrong.Close()
+	if err := rediserr.FromClient(wrong.Ping(ctx).Err()); !errors.Is(err, rediserr.WrongPass) {
+		t.Errorf("Expected WRONGPASS, got %v", err)
+	}
+
+	anonymous := redis.NewClient(&redis.Options{Addr: listener.Addr().String(), MaxRetries: -1})
+	defer anonymous.Close()
+	if err := rediserr.FromClient(anonymous.Ping(ctx).Err()); !errors.Is(err, rediserr.NoAuth) {
+		t.Errorf("Expected NOAUTH, got %v", err)
+	}
+}
@@ -5,6 +5,12 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/devtoolbox/redis/cluster"
+	"github.com/devtoolbox/redis/geo"
+	"github.com/devtoolbox/redis/hll"
+	"github.com/devtoolbox/redis/rediserr"
+	"github.com/devtoolbox/redis/rejson"
 )
 
 // MockRedisManager Mock Redis管理器实现
@@ -82,6 +88,50 @@ func (m *MockRedisManager) initMockData() {
 		UpdatedAt: now.Add(-time.Hour),
 	}
 	
+	// HyperLogLog：每日UV
+	uv := hll.New()
+	for i := 0; i < 1000; i++ {
+		uv.Add([]byte(fmt.Sprintf("user:%d", i)))
+	}
+	m.data.Keys["uv:daily:20240101"] = MockKeyData{
+		Type:      "string",
+		Value:     string(uv.Bytes()),
+		TTL:       -1, // 永不过期
+		CreatedAt: now.Add(-time.Hour * 24),
+		UpdatedAt: now.Add(-time.Hour),
+	}
+	
+	// 位图：用户1001在1月的签到记录，第1、2、3、5、8天签到
+	m.data.Keys["signin:1001:202401"] = MockKeyData{
+		Type:      "string",
+		Value:     string([]byte{0xe9, 0x00, 0x00, 0x00}),
+		TTL:       -1, // 永不过期
+		CreatedAt: now.Add(-time.Hour * 24 * 7),
+		UpdatedAt: now.Add(-time.Hour * 2),
+	}
+	
+	// RedisJSON文档：用户资料
+	m.data.Keys["json:user:1001"] = MockKeyData{
+		Type:      rejson.TypeName,
+		Value:     `{"id":1001,"name":"张三","email":"zhangsan@example.com","roles":["admin","editor"],"profile":{"city":"北京","age":28},"active":true}`,
+		TTL:       -1, // 永不过期
+		CreatedAt: now.Add(-time.Hour * 12),
+		UpdatedAt: now.Add(-time.Minute * 45),
+	}
+	
+	// 地理位置：附近的用户，分数为geohash编码
+	m.data.Keys["geo:users:nearby"] = MockKeyData{
+		Type: "zset",
+		Value: map[string]interface{}{
+			"user:1001": geo.Encode(116.397128, 39.916527), // 北京
+			"user:1002": geo.Encode(121.473701, 31.230416), // 上海
+			"user:1003": geo.Encode(113.264385, 23.129112), // 广州
+		},
+		TTL:       -1, // 永不过期
+		CreatedAt: now.Add(-time.Hour * 5),
+		UpdatedAt: now.Add(-time.Minute * 20),
+	}
+	
 	m.data.Keys["zset:scores"] = MockKeyData{
 		Type: "zset",
 		Value: map[string]interface{}{
@@ -96,13 +146,13 @@ func (m *MockRedisManager) initMockData() {
 }
 
 // GetKeyInfo 获取键信息
-func (m *MockRedisManager) GetKeyInfo(keyName string) (*KeyInfo, error) {
+func (m *MockRedisManager) GetKeyInfo(keyName, view string) (*KeyInfo, error) {
 	m.mutex.RLock()
 	defer m.mutex.RUnlock()
 	
 	keyData, exists := m.data.Keys[keyName]
 	if !exists {
-		return nil, fmt.Errorf("键 '%s' 不存在", keyName)
+		return nil, fmt.Errorf("键 '%s' 不存在: %w", keyName, rediserr.Nil)
 	}
 	
 	// 检查TTL是否过期
@@ -118,7 +168,7 @@ func (m *MockRedisManager) GetKeyInfo(keyName string) (*KeyInfo, error) {
 			delete(m.data.Keys, keyName)
 			m.mutex.Unlock()
 			m.mutex.RLock()
-			return nil, fmt.Errorf("键 '%s' 已过期", keyName)
+			return nil, fmt.Errorf("键 '%s' 已过期: %w", keyName, rediserr.Nil)
 		}
 		ttl = remaining
 	}
@@ -129,12 +179,31 @@ func (m *MockRedisManager) GetKeyInfo(keyName string) (*KeyInfo, error) {
 		size = int64(len(valueBytes))
 	}
 	
+	// HyperLogLog和位图以可读形式展示，而不是原始字节
+	value := keyData.Value
+	var format string
+	switch keyData.Type {
+	case "string":
+		if str, ok := keyData.Value.(string); ok {
+			size = int64(len(str))
+			format, value = renderStringValue(str, view)
+		}
+	case rejson.TypeName:
+		// 模块类型返回解析后的文档
+		if str, ok := keyData.Value.(string); ok {
+			size = int64(len(str))
+		}
+		format, value = renderJSONValue(keyData.Value)
+	}
+	
 	return &KeyInfo{
 		Name:      keyName,
 		Type:      keyData.Type,
 		TTL:       ttl,
 		Size:      size,
-		Value:     keyData.Value,
+		Value:     value,
+		Format:    format,
+		Slot:      cluster.Slot(keyName),
 		CreatedAt: keyData.CreatedAt,
 		UpdatedAt: keyData.UpdatedAt,
 	}, nil
@@ -146,7 +215,7 @@ func (m *MockRedisManager) DeleteKey(keyName string) error {
 	defer m.mutex.Unlock()
 	
 	if _, exists := m.data.Keys[keyName]; !exists {
-		return fmt.Errorf("键 '%s' 不存在", keyName)
+		return fmt.Errorf("键 '%s' 不存在: %w", keyName, rediserr.Nil)
 	}
 	
 	delete(m.data.Keys, keyName)
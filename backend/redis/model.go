@@ -1,6 +1,10 @@
 package main
 
-import "time"
+import (
+	"time"
+
+	"github.com/devtoolbox/redis/policy"
+)
 
 // PingResponse ping接口响应结构
 type PingResponse struct {
@@ -55,15 +59,40 @@ type KeyInfo struct {
 	TTL       int64       `json:"ttl"`
 	Size      int64       `json:"size,omitempty"`
 	Value     interface{} `json:"value,omitempty"`
+	Format    string      `json:"format,omitempty"` // 展示格式：hyperloglog、bitmap、hex、json
+	Slot      int         `json:"slot"`             // 集群模式下键所属的槽位（CRC16）
 	CreatedAt time.Time   `json:"created_at,omitempty"`
 	UpdatedAt time.Time   `json:"updated_at,omitempty"`
 }
 
+// GeoJSONFeatureCollection 地理位置键的GeoJSON表示
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []GeoJSONFeature `json:"features"`
+}
+
+// GeoJSONFeature 地理位置成员
+type GeoJSONFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   GeoJSONGeometry        `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// GeoJSONGeometry 点坐标，顺序为[经度, 纬度]
+type GeoJSONGeometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
 // DeleteKeyResponse Redis键删除响应结构
 type DeleteKeyResponse struct {
-	Status  string `json:"status"`
-	Message string `json:"message"`
-	Success bool   `json:"success"`
+	Status    string           `json:"status"`
+	Message   string           `json:"message"`
+	Success   bool             `json:"success"`
+	Policy    *policy.Decision `json:"policy,omitempty"`    // 危险命令规则的检查结果
+	PreviewID string           `json:"previewId,omitempty"` // dryRun时返回，规则要求预览时需要带上
+	UndoID    uint64           `json:"undoId,omitempty"`    // 撤销删除时使用的ID
+	UndoError string           `json:"undoError,omitempty"` // 无法保存删除前的状态时，删除不能撤销的原因
 }
 
 // MockRedisData Mock Redis数据结构
@@ -92,8 +121,8 @@ const (
 
 // RedisManager Redis管理器接口
 type RedisManager interface {
-	// GetKeyInfo 获取键信息
-	GetKeyInfo(keyName string) (*KeyInfo, error)
+	// GetKeyInfo 获取键信息，view指定二进制字符串的展示格式（bitmap、hex），为空时自动选择
+	GetKeyInfo(keyName, view string) (*KeyInfo, error)
 	// DeleteKey 删除键
 	DeleteKey(keyName string) error
 	// KeyExists 检查键是否存在
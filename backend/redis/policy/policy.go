@@ -0,0 +1,296 @@
+package policy
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/devtoolbox/redis/command"
+	"github.com/devtoolbox/redis/glob"
+)
+
+// Action 危险操作的处理方式，严格程度依次递增
+type Action string
+
+const (
+	// Allow 直接执行
+	Allow Action = "allow"
+	// Confirm 需要在请求中输入确认文本
+	Confirm Action = "confirm"
+	// DryRun 需要先预览影响范围，再带着预览ID执行
+	DryRun Action = "dryRun"
+	// Block 禁止执行
+	Block Action = "block"
+)
+
+// DefaultEnvironment 未设置环境标签或标签没有对应规则时使用的规则名
+const DefaultEnvironment = "default"
+
+// previewTTL 预览ID的有效期
+const previewTTL = 5 * time.Minute
+
+var (
+	// ErrBlocked 操作被环境的规则禁止
+	ErrBlocked = errors.New("operation blocked by policy")
+	// ErrConfirmationRequired 操作需要输入确认文本
+	ErrConfirmationRequired = errors.New("confirmation required")
+	// ErrPreviewRequired 操作需要先预览
+	ErrPreviewRequired = errors.New("dry-run preview required")
+)
+
+// Rules 一个环境的危险操作规则，未设置的动作视为Allow
+type Rules struct {
+	Flush               Action   `json:"flush,omitempty"`               // FLUSHDB、FLUSHALL
+	BulkDelete          Action   `json:"bulkDelete,omitempty"`          // 一次删除多个键
+	BulkDeleteThreshold int      `json:"bulkDeleteThreshold,omitempty"` // 删除的键数达到该值时视为批量删除，默认2
+	RenameOverwrite     Action   `json:"renameOverwrite,omitempty"`     // RENAME覆盖已存在的键
+	ProtectedWrite      Action   `json:"protectedWrite,omitempty"`      // 写入或删除匹配受保护模式的键
+	ProtectedPatterns   []string `json:"protectedPatterns,omitempty"`   // 受保护的键模式，语法与KEYS相同
+}
+
+// Defaults 内置的环境规则，配置文件中同名的环境会覆盖对应的规则
+func Defaults() map[string]Rules {
+	return map[string]Rules{
+		DefaultEnvironment: {Flush: Confirm},
+		"dev":              {},
+		"test":             {},
+		"staging": {
+			Flush:           Confirm,
+			BulkDelete:      Confirm,
+			RenameOverwrite: Confirm,
+			ProtectedWrite:  Confirm,
+		},
+		"prod": {
+			Flush:           Block,
+			BulkDelete:      DryRun,
+			RenameOverwrite: Confirm,
+			ProtectedWrite:  Block,
+		},
+	}
+}
+
+// Operation 待检查的操作
+type Operation struct {
+	Target    string   // 执行操作的连接，预览只对同一个连接有效
+	Command   string   // 命令名，例如DEL、RENAME、FLUSHDB
+	Keys      []string // 写入或删除的键
+	Overwrite bool     // RENAME的目标键已存在
+}
+
+// Approval 请求中携带的确认信息
+type Approval struct {
+	Confirm   string // 用户输入的确认文本
+	PreviewID string // 预览时返回的ID
+}
+
+// Decision 检查结果
+type Decision struct {
+	Action       Action   `json:"action"`
+	Reasons      []string `json:"reasons,omitempty"`
+	Confirmation string   `json:"confirmation,omitempty"` // Action为Confirm时需要输入的文本
+}
+
+// preview 一次预览，只能使用一次
+type preview struct {
+	fingerprint string
+	expiresAt   time.Time
+}
+
+// Engine 按连接的环境标签检查危险操作
+type Engine struct {
+	rules    map[string]Rules
+	previews map[string]preview
+	mutex    sync.Mutex
+}
+
+// NewEngine 使用内置规则与configured创建规则引擎，configured中的环境覆盖同名的内置规则
+func NewEngine(configured map[string]Rules) (*Engine, error) {
+	rules := Defaults()
+	for env, r := range configured {
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("invalid command policy for %q: %v", env, err)
+		}
+		rules[strings.ToLower(env)] = r
+	}
+	return &Engine{
+		rules:    rules,
+		previews: make(map[string]preview),
+	}, nil
+}
+
+// Rules 返回环境适用的规则，标签不区分大小写
+func (e *Engine) Rules(env string) Rules {
+	if r, ok := e.rules[strings.ToLower(env)]; ok {
+		return r
+	}
+	return e.rules[DefaultEnvironment]
+}
+
+// Evaluate 返回操作在环境中适用的最严格的动作
+func (e *Engine) Evaluate(env string, op Operation) Decision {
+	r := e.Rules(env)
+	decision := Decision{Action: Allow}
+	apply := func(action Action, reason string) {
+		if action == "" || action == Allow {
+			return
+		}
+		decision.Reasons = append(decision.Reasons, reason)
+		if severity(action) > severity(decision.Action) {
+			decision.Action = action
+		}
+	}
+
+	category := command.Classify(op.Command)
+	if category == command.Flush {
+		apply(r.Flush, fmt.Sprintf("%s removes every key", strings.ToUpper(op.Command)))
+	}
+	threshold := r.BulkDeleteThreshold
+	if threshold <= 0 {
+		threshold = 2
+	}
+	if category == command.Delete && len(op.Keys) >= threshold {
+		apply(r.BulkDelete, fmt.Sprintf("deletes %d keys", len(op.Keys)))
+	}
+	if strings.EqualFold(op.Command, "RENAME") && op.Overwrite {
+		apply(r.RenameOverwrite, "overwrites an existing key")
+	}
+	if category != command.Read && len(r.ProtectedPatterns) > 0 {
+		if category == command.Flush {
+			apply(r.ProtectedWrite, "removes protected keys")
+		} else if key, pattern, ok := r.protected(op.Keys); ok {
+			apply(r.ProtectedWrite, fmt.Sprintf("key %q matches protected pattern %q", key, pattern))
+		}
+	}
+
+	if decision.Action == Confirm {
+		decision.Confirmation = confirmation(env, op)
+	}
+	return decision
+}
+
+// Check 检查操作是否可以执行，需要确认或预览时检查approval
+// 返回的错误为ErrBlocked、ErrConfirmationRequired或ErrPreviewRequired
+func (e *Engine) Check(env string, op Operation, approval Approval) (Decision, error) {
+	decision := e.Evaluate(env, op)
+	switch decision.Action {
+	case Block:
+		return decision, fmt.Errorf("%w: %s", ErrBlocked, strings.Join(decision.Reasons, ", "))
+	case Confirm:
+		if approval.Confirm != decision.Confirmation {
+			return decision, fmt.Errorf("%w: type %q to continue", ErrConfirmationRequired, decision.Confirmation)
+		}
+	case DryRun:
+		if !e.usePreview(approval.PreviewID, fingerprint(env, op)) {
+			return decision, fmt.Errorf("%w: run the operation with dryRun first", ErrPreviewRequired)
+		}
+	}
+	return decision, nil
+}
+
+// Preview 记录一次预览并返回预览ID，在有效期内可以用于执行相同的操作
+// 操作被禁止时不生成预览ID
+func (e *Engine) Preview(env string, op Operation) (Decision, string, error) {
+	decision := e.Evaluate(env, op)
+	if decision.Action == Block {
+		return decision, "", nil
+	}
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return decision, "", fmt.Errorf("failed to generate preview id: %v", err)
+	}
+	id := hex.EncodeToString(raw)
+	now := time.Now()
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for previewID, p := range e.previews {
+		if now.After(p.expiresAt) {
+			delete(e.previews, previewID)
+		}
+	}
+	e.previews[id] = preview{fingerprint: fingerprint(env, op), expiresAt: now.Add(previewTTL)}
+	return decision, id, nil
+}
+
+// usePreview 检查并消耗预览ID
+func (e *Engine) usePreview(id, fingerprint string) bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	p, ok := e.previews[id]
+	if !ok || time.Now().After(p.expiresAt) || p.fingerprint != fingerprint {
+		return false
+	}
+	delete(e.previews, id)
+	return true
+}
+
+// protected 返回第一个匹配受保护模式的键
+func (r Rules) protected(keys []string) (string, string, bool) {
+	for _, key := range keys {
+		for _, pattern := range r.ProtectedPatterns {
+			if glob.Match(pattern, key) {
+				return key, pattern, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// validate 检查规则中的动作与模式
+func (r Rules) validate() error {
+	for _, action := range []Action{r.Flush, r.BulkDelete, r.RenameOverwrite, r.ProtectedWrite} {
+		if action != "" && severity(action) < 0 {
+			return fmt.Errorf("unknown action %q", action)
+		}
+	}
+	for _, pattern := range r.ProtectedPatterns {
+		if !glob.Valid(pattern) {
+			return fmt.Errorf("invalid protected pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+// severity 动作的严格程度，未知动作返回-1
+func severity(action Action) int {
+	switch action {
+	case Allow:
+		return 0
+	case Confirm:
+		return 1
+	case DryRun:
+		return 2
+	case Block:
+		return 3
+	}
+	return -1
+}
+
+// confirmation 需要输入的确认文本：命令名加上环境标签，例如"FLUSHDB prod"
+// 单个键的操作还需要带上键名
+func confirmation(env string, op Operation) string {
+	if env == "" {
+		env = DefaultEnvironment
+	}
+	text := strings.ToUpper(op.Command) + " " + env
+	if len(op.Keys) == 1 {
+		text += " " + op.Keys[0]
+	}
+	return text
+}
+
+// fingerprint 操作的摘要，预览与执行时必须一致
+func fingerprint(env string, op Operation) string {
+	keys := append([]string(nil), op.Keys...)
+	sort.Strings(keys)
+	return fmt.Sprintf("%s\x00%s\x00%s\x00%t\x00%s",
+		strings.ToLower(env), op.Target, strings.ToUpper(op.Command), op.Overwrite, strings.Join(keys, "\x00"))
+}
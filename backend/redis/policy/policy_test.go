@@ -0,0 +1,99 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEngine_Evaluate(t *testing.T) {
+	engine, err := NewEngine(map[string]Rules{
+		"prod": {
+			Flush:               Block,
+			BulkDelete:          DryRun,
+			BulkDeleteThreshold: 3,
+			RenameOverwrite:     Confirm,
+			ProtectedWrite:      Block,
+			ProtectedPatterns:   []string{"session:*"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+
+	tests := []struct {
+		env    string
+		op     Operation
+		action Action
+	}{
+		{"dev", Operation{Command: "FLUSHDB"}, Allow},
+		{"", Operation{Command: "FLUSHDB"}, Confirm},
+		{"unknown", Operation{Command: "FLUSHALL"}, Confirm},
+		{"staging", Operation{Command: "DEL", Keys: []string{"a", "b"}}, Confirm},
+		{"staging", Operation{Command: "DEL", Keys: []string{"a"}}, Allow},
+		{"PROD", Operation{Command: "FLUSHDB"}, Block},
+		{"prod", Operation{Command: "DEL", Keys: []string{"a", "b"}}, Allow},
+		{"prod", Operation{Command: "DEL", Keys: []string{"a", "b", "c"}}, DryRun},
+		{"prod", Operation{Command: "RENAME", Keys: []string{"a", "b"}}, Allow},
+		{"prod", Operation{Command: "RENAME", Keys: []string{"a", "b"}, Overwrite: true}, Confirm},
+		{"prod", Operation{Command: "SET", Keys: []string{"session:1"}}, Block},
+		{"prod", Operation{Command: "GET", Keys: []string{"session:1"}}, Allow},
+		// 与Redis一致，*可以匹配/
+		{"prod", Operation{Command: "DEL", Keys: []string{"session:eu/1"}}, Block},
+		// 同时命中多条规则时取最严格的动作
+		{"prod", Operation{Command: "DEL", Keys: []string{"a", "b", "session:1"}}, Block},
+	}
+	for _, tt := range tests {
+		if got := engine.Evaluate(tt.env, tt.op); got.Action != tt.action {
+			t.Errorf("Evaluate(%q, %+v) = %+v, want %s", tt.env, tt.op, got, tt.action)
+		}
+	}
+
+	if _, err := NewEngine(map[string]Rules{"prod": {Flush: "maybe"}}); err == nil {
+		t.Error("Expected unknown action to be rejected")
+	}
+	if _, err := NewEngine(map[string]Rules{"prod": {ProtectedPatterns: []string{"["}}}); err == nil {
+		t.Error("Expected invalid pattern to be rejected")
+	}
+}
+
+func TestEngine_Check(t *testing.T) {
+	engine, _ := NewEngine(nil)
+
+	// 需要确认的操作必须输入命令名与环境标签
+	flush := Operation{Target: "c1", Command: "FLUSHDB"}
+	decision, err := engine.Check("staging", flush, Approval{})
+	if !errors.Is(err, ErrConfirmationRequired) || decision.Confirmation != "FLUSHDB staging" {
+		t.Fatalf("Check = %+v, %v", decision, err)
+	}
+	if _, err := engine.Check("staging", flush, Approval{Confirm: "FLUSHDB staging"}); err != nil {
+		t.Errorf("Expected confirmed flush to pass: %v", err)
+	}
+	if _, err := engine.Check("prod", flush, Approval{Confirm: "FLUSHDB prod"}); !errors.Is(err, ErrBlocked) {
+		t.Errorf("Expected flush on prod to be blocked, got %v", err)
+	}
+
+	// 需要预览的操作必须带上相同操作的预览ID，预览ID只能使用一次
+	bulk := Operation{Target: "c1", Command: "DEL", Keys: []string{"a", "b"}}
+	if _, err := engine.Check("prod", bulk, Approval{}); !errors.Is(err, ErrPreviewRequired) {
+		t.Fatalf("Expected preview to be required, got %v", err)
+	}
+	_, id, err := engine.Preview("prod", bulk)
+	if err != nil || id == "" {
+		t.Fatalf("Preview = %q, %v", id, err)
+	}
+	other := Operation{Target: "c2", Command: "DEL", Keys: []string{"a", "b"}}
+	if _, err := engine.Check("prod", other, Approval{PreviewID: id}); !errors.Is(err, ErrPreviewRequired) {
+		t.Errorf("Expected preview of another connection to be rejected, got %v", err)
+	}
+	reordered := Operation{Target: "c1", Command: "DEL", Keys: []string{"b", "a"}}
+	if _, err := engine.Check("prod", reordered, Approval{PreviewID: id}); err != nil {
+		t.Errorf("Expected preview to match regardless of key order: %v", err)
+	}
+	if _, err := engine.Check("prod", bulk, Approval{PreviewID: id}); !errors.Is(err, ErrPreviewRequired) {
+		t.Errorf("Expected preview id to be single use, got %v", err)
+	}
+
+	if _, id, _ := engine.Preview("prod", flush); id != "" {
+		t.Error("Expected no preview id for a blocked operation")
+	}
+}
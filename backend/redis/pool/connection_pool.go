@@ -2,24 +2,209 @@ package pool
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/devtoolbox/redis/mock"
+	"github.com/devtoolbox/redis/rediserr"
 )
+
+// 连接模式
+const (
+	// ModeStandalone 单节点连接
+	ModeStandalone = "standalone"
+	// ModeCluster 集群连接，只能使用0号数据库
+	ModeCluster = "cluster"
+	// ModeSentinel 通过哨兵发现主节点，故障转移后自动重连
+	ModeSentinel = "sentinel"
+)
+
+// mockClusterShards 模拟模式下集群连接的节点数量
+const mockClusterShards = 3
+
+// ConnectOptions 建立连接的通用参数
+type ConnectOptions struct {
+	DB       int
+	Username string // Redis 6 ACL用户名，为空时使用default用户
+	Password string
+	Profile     string // 保存的连接配置名称，直接连接时为空
+	Environment string // 环境标签，例如dev、staging、prod
+	TLS      *TLSOptions // 为nil时使用明文连接
+	SSH      *SSHOptions // 非nil时经由SSH跳板机连接
+
+	// 以下选项为0时使用go-redis的默认值
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	PoolTimeout  time.Duration
+	IdleTimeout  time.Duration
+	PoolSize     int
+	MinIdleConns int
+	MaxRetries   int
+}
+
 // RedisConnection Redis连接信息
 type RedisConnection struct {
 	ID       string       `json:"id"`
 	Host     string       `json:"host"`
 	Port     int          `json:"port"`
 	DB       int          `json:"db"`
-	Alias    string       `json:"alias"`
+	Profile  string       `json:"profile,omitempty"`     // 通过保存的配置连接时为配置名称
+	Environment string    `json:"environment,omitempty"` // 环境标签
+	Mode     string       `json:"mode"`
+	Username string       `json:"username,omitempty"` // ACL用户名
+	TLS      bool         `json:"tls"`
+	SSH      string       `json:"ssh,omitempty"` // 跳板机，例如 ops@bastion:22
+	Nodes    []string     `json:"nodes,omitempty"` // 集群的种子节点或哨兵地址
+	URI      string       `json:"uri"`             // 规范化的连接串，密码已隐藏
+	Sentinel *SentinelTopology `json:"sentinel,omitempty"` // 哨兵报告的当前主从节点，由ListConnections填充
 	Client   mock.RedisInterface `json:"-"`
 	IsMock   bool         `json:"isMock"`
 	CreatedAt time.Time   `json:"createdAt"`
 	LastUsed time.Time    `json:"lastUsed"`
+	Health   Health       `json:"health"` // 最近一次健康检查的结果
+	Refs     int          `json:"refs"`   // 持有该连接的Token数量，为0时可以被淘汰
+
+	monitor *sentinelMonitor
+	tunnel  *sshTunnel
+	params  connectParams // 建立连接的参数，切换数据库时使用
+	key     string        // params的摘要，用于复用相同的连接
+}
+
+// connectParams 建立连接的模式、地址与参数
+type connectParams struct {
+	mode     string
+	addrs    []string
+	opts     ConnectOptions
+	sentinel *SentinelConfig
+}
+
+// key 计算参数的摘要，相同摘要的连接可以共用一个客户端
+// 连接配置名称与环境标签也计入摘要，标签不同的连接不会共用
+func (p connectParams) key() string {
+	data, _ := json.Marshal(struct {
+		Mode     string
+		Addrs    []string
+		Options  ConnectOptions
+		Sentinel *SentinelConfig
+	}{p.mode, p.addrs, p.opts, p.sentinel})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// endpoint 除数据库外参数相同的连接属于同一个端点
+func (p connectParams) endpoint() string {
+	p.opts.DB = 0
+	return p.key()
+}
+
+// dial 按参数创建go-redis客户端并确认连接可用，tunnel不为空时经由跳板机连接
+func (p connectParams) dial(tlsConfig *tls.Config, tunnel *sshTunnel) (redis.UniversalClient, error) {
+	opts := p.opts
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	switch p.mode {
+	case ModeCluster:
+		client := redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        p.addrs,
+			Username:     opts.Username,
+			Password:     opts.Password,
+			TLSConfig:    tlsConfig,
+			DialTimeout:  opts.DialTimeout,
+			ReadTimeout:  opts.ReadTimeout,
+			WriteTimeout: opts.WriteTimeout,
+			PoolTimeout:  opts.PoolTimeout,
+			IdleTimeout:  opts.IdleTimeout,
+			PoolSize:     opts.PoolSize,
+			MinIdleConns: opts.MinIdleConns,
+			MaxRetries:   opts.MaxRetries,
+			Dialer:       tunnel.dialer(),
+		})
+		// 集群连接需要确认拓扑可用，CLUSTER SLOTS失败说明目标不是集群
+		if err := client.ClusterSlots(ctx).Err(); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to connect to Redis cluster: %w", rediserr.FromClient(err))
+		}
+		return client, nil
+	case ModeSentinel:
+		client := redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       p.sentinel.MasterName,
+			SentinelAddrs:    p.sentinel.Addrs,
+			SentinelPassword: p.sentinel.Password,
+			Username:         opts.Username,
+			Password:         opts.Password,
+			DB:               opts.DB,
+			TLSConfig:        tlsConfig,
+			DialTimeout:      opts.DialTimeout,
+			ReadTimeout:      opts.ReadTimeout,
+			WriteTimeout:     opts.WriteTimeout,
+			PoolTimeout:      opts.PoolTimeout,
+			IdleTimeout:      opts.IdleTimeout,
+			PoolSize:         opts.PoolSize,
+			MinIdleConns:     opts.MinIdleConns,
+			MaxRetries:       opts.MaxRetries,
+			Dialer:           tunnel.dialer(),
+		})
+		if err := client.Ping(ctx).Err(); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to connect to Redis master %q: %w", p.sentinel.MasterName, rediserr.FromClient(err))
+		}
+		return client, nil
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:         p.addrs[0],
+		Username:     opts.Username,
+		Password:     opts.Password,
+		DB:           opts.DB,
+		TLSConfig:    tlsConfig,
+		DialTimeout:  opts.DialTimeout,
+		ReadTimeout:  opts.ReadTimeout,
+		WriteTimeout: opts.WriteTimeout,
+		PoolTimeout:  opts.PoolTimeout,
+		IdleTimeout:  opts.IdleTimeout,
+		PoolSize:     opts.PoolSize,
+		MinIdleConns: opts.MinIdleConns,
+		MaxRetries:   opts.MaxRetries,
+		Dialer:       tunnel.dialer(),
+	})
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", rediserr.FromClient(err))
+	}
+	return client, nil
+}
+
+// snapshot 复制连接信息，调用方需持有连接池的锁
+func (c *RedisConnection) snapshot() *RedisConnection {
+	snapshot := *c
+	if c.monitor != nil {
+		snapshot.Sentinel = c.monitor.Topology()
+	}
+	return &snapshot
+}
+
+// close 关闭客户端、哨兵监听以及SSH隧道
+func (c *RedisConnection) close() {
+	if c.monitor != nil {
+		c.monitor.Close()
+	}
+	if c.Client != nil {
+		c.Client.Close()
+	}
+	c.tunnel.Close()
 }
 
 // ConnectionPool Redis连接池
@@ -28,6 +213,7 @@ type ConnectionPool struct {
 	mutex       sync.RWMutex
 	maxConn     int
 	mockMode    bool
+	traceDir    string
 }
 
 // NewConnectionPool 创建新的连接池
@@ -53,19 +239,46 @@ func (cp *ConnectionPool) IsMockMode() bool {
 	return cp.mockMode
 }
 
+// SetTraceDir 设置命令轨迹目录，为空时不录制
+// 开启后每个新连接的命令会写入 <traceDir>/<连接ID>.jsonl
+func (cp *ConnectionPool) SetTraceDir(dir string) {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+	cp.traceDir = dir
+}
+
 // CreateConnection 创建新的Redis连接
-func (cp *ConnectionPool) CreateConnection(id, host string, port, db int, password, alias string) (*RedisConnection, error) {
+func (cp *ConnectionPool) CreateConnection(id, host string, port, db int, password, profile string) (*RedisConnection, error) {
+	return cp.CreateConnectionWithOptions(id, host, port, ConnectOptions{
+		DB:       db,
+		Password: password,
+		Profile:  profile,
+	})
+}
+
+// CreateConnectionWithOptions 创建新的Redis连接，支持ACL用户名与TLS
+func (cp *ConnectionPool) CreateConnectionWithOptions(id, host string, port int, opts ConnectOptions) (*RedisConnection, error) {
+	db := opts.DB
+	tlsConfig, err := opts.tlsConfig(host)
+	if err != nil {
+		return nil, err
+	}
+	params := connectParams{mode: ModeStandalone, addrs: []string{net.JoinHostPort(host, strconv.Itoa(port))}, opts: opts}
+	key := params.key()
+
 	cp.mutex.Lock()
 	defer cp.mutex.Unlock()
 
-	// 检查连接数限制
-	if len(cp.connections) >= cp.maxConn {
-		return nil, fmt.Errorf("maximum connections limit reached: %d", cp.maxConn)
+	if conn := cp.reuse(key); conn != nil {
+		return conn, nil
+	}
+	if err := cp.checkCapacity(id); err != nil {
+		return nil, err
 	}
 
-	// 检查连接是否已存在
-	if _, exists := cp.connections[id]; exists {
-		return nil, fmt.Errorf("connection with id %s already exists", id)
+	tunnel, err := opts.openTunnel(cp.mockMode)
+	if err != nil {
+		return nil, err
 	}
 
 	var client mock.RedisInterface
@@ -78,24 +291,16 @@ func (cp *ConnectionPool) CreateConnection(id, host string, port, db int, passwo
 		if db > 0 {
 			ctx := context.Background()
 			if err := client.Select(ctx, db).Err(); err != nil {
-				return nil, fmt.Errorf("failed to select database %d: %v", db, err)
+				tunnel.Close()
+				return nil, fmt.Errorf("failed to select database %d: %w", db, err)
 			}
 		}
 	} else {
-		// 创建真实Redis客户端
-		realClient := redis.NewClient(&redis.Options{
-			Addr:     fmt.Sprintf("%s:%d", host, port),
-			Password: password,
-			DB:       db,
-		})
-		
-		// 测试连接
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		
-		if err := realClient.Ping(ctx).Err(); err != nil {
-			realClient.Close()
-			return nil, fmt.Errorf("failed to connect to Redis: %v", err)
+		// 创建真实Redis客户端并测试连接
+		realClient, err := params.dial(tlsConfig, tunnel)
+		if err != nil {
+			tunnel.Close()
+			return nil, err
 		}
 		
 		// 使用适配器包装真实客户端
@@ -109,23 +314,281 @@ func (cp *ConnectionPool) CreateConnection(id, host string, port, db int, passwo
 		Host:      host,
 		Port:      port,
 		DB:        db,
-		Alias:     alias,
+		Profile:   opts.Profile,
+		Environment: opts.Environment,
+		Mode:      ModeStandalone,
+		URI:       FormatURI(host, port, opts),
+		Username:  opts.Username,
+		TLS:       tlsConfig != nil,
+		SSH:       opts.sshString(),
 		Client:    client,
 		IsMock:    isMock,
 		CreatedAt: time.Now(),
 		LastUsed:  time.Now(),
+		tunnel:    tunnel,
+		params:    params,
+		key:       key,
+	}
+
+	if err := cp.addConnection(conn); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// CreateClusterConnection 创建Redis集群连接，addrs为种子节点地址（host:port）
+// 集群拓扑由go-redis根据CLUSTER SLOTS自动发现，命令按键的槽位路由到对应节点
+func (cp *ConnectionPool) CreateClusterConnection(id string, addrs []string, opts ConnectOptions) (*RedisConnection, error) {
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("at least one cluster node address is required")
+	}
+	if opts.DB != 0 {
+		return nil, fmt.Errorf("cluster mode only supports database 0")
+	}
+
+	// 第一个种子节点作为连接的展示地址
+	host, portText, _ := net.SplitHostPort(addrs[0])
+	port, _ := strconv.Atoi(portText)
+
+	tlsConfig, err := opts.tlsConfig(host)
+	if err != nil {
+		return nil, err
+	}
+	params := connectParams{mode: ModeCluster, addrs: append([]string(nil), addrs...), opts: opts}
+	key := params.key()
+
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+
+	if conn := cp.reuse(key); conn != nil {
+		return conn, nil
+	}
+	if err := cp.checkCapacity(id); err != nil {
+		return nil, err
+	}
+
+	tunnel, err := opts.openTunnel(cp.mockMode)
+	if err != nil {
+		return nil, err
+	}
+
+	var client mock.RedisInterface
+	isMock := cp.mockMode
+
+	if cp.mockMode {
+		// 模拟集群按槽位分片，跨槽位的多键命令与真实集群一样返回CROSSSLOT
+		client = mock.NewRedisClusterMock(mockClusterShards)
+	} else {
+		clusterClient, err := params.dial(tlsConfig, tunnel)
+		if err != nil {
+			tunnel.Close()
+			return nil, err
+		}
+
+		client = mock.NewRedisClientAdapter(clusterClient)
 	}
 
-	// 添加到连接池
-	cp.connections[id] = conn
+	conn := &RedisConnection{
+		ID:        id,
+		Host:      host,
+		Port:      port,
+		Profile:   opts.Profile,
+		Environment: opts.Environment,
+		Mode:      ModeCluster,
+		URI:       FormatURI(host, port, opts),
+		Username:  opts.Username,
+		TLS:       tlsConfig != nil,
+		SSH:       opts.sshString(),
+		Nodes:     append([]string(nil), addrs...),
+		Client:    client,
+		IsMock:    isMock,
+		CreatedAt: time.Now(),
+		LastUsed:  time.Now(),
+		tunnel:    tunnel,
+		params:    params,
+		key:       key,
+	}
 
+	if err := cp.addConnection(conn); err != nil {
+		return nil, err
+	}
 	return conn, nil
 }
 
+// CreateSentinelConnection 通过哨兵连接主节点
+// go-redis的FailoverClient在每次建立连接时向哨兵询问主节点地址，
+// 收到+switch-master后关闭旧连接，因此故障转移对调用方透明
+// TLS与ACL用户名同时用于哨兵和数据节点
+func (cp *ConnectionPool) CreateSentinelConnection(id string, sentinel SentinelConfig, opts ConnectOptions) (*RedisConnection, error) {
+	if sentinel.MasterName == "" {
+		return nil, fmt.Errorf("sentinel master name is required")
+	}
+	if len(sentinel.Addrs) == 0 {
+		return nil, fmt.Errorf("at least one sentinel address is required")
+	}
+
+	// 第一个哨兵作为连接的展示地址
+	host, portText, _ := net.SplitHostPort(sentinel.Addrs[0])
+	port, _ := strconv.Atoi(portText)
+
+	// 主节点地址由哨兵动态返回，ServerName未指定时只能使用哨兵的主机名
+	tlsConfig, err := opts.tlsConfig(host)
+	if err != nil {
+		return nil, err
+	}
+	db := opts.DB
+	params := connectParams{mode: ModeSentinel, addrs: sentinel.Addrs, opts: opts, sentinel: &sentinel}
+	key := params.key()
+
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+
+	if conn := cp.reuse(key); conn != nil {
+		return conn, nil
+	}
+	if err := cp.checkCapacity(id); err != nil {
+		return nil, err
+	}
+
+	tunnel, err := opts.openTunnel(cp.mockMode)
+	if err != nil {
+		return nil, err
+	}
+
+	var client mock.RedisInterface
+	var source sentinelSource
+	isMock := cp.mockMode
+
+	if cp.mockMode {
+		client = mock.NewRedisMock()
+		if db > 0 {
+			if err := client.Select(context.Background(), db).Err(); err != nil {
+				tunnel.Close()
+				return nil, fmt.Errorf("failed to select database %d: %w", db, err)
+			}
+		}
+		source = newMockSentinel()
+	} else {
+		failoverClient, err := params.dial(tlsConfig, tunnel)
+		if err != nil {
+			tunnel.Close()
+			return nil, err
+		}
+
+		client = mock.NewRedisClientAdapter(failoverClient)
+		source = newRedisSentinel(sentinel, tlsConfig, tunnel.dialer())
+	}
+
+	conn := &RedisConnection{
+		ID:        id,
+		Host:      host,
+		Port:      port,
+		DB:        db,
+		Profile:   opts.Profile,
+		Environment: opts.Environment,
+		Mode:      ModeSentinel,
+		URI:       FormatURI(host, port, opts),
+		Username:  opts.Username,
+		TLS:       tlsConfig != nil,
+		SSH:       opts.sshString(),
+		Nodes:     append([]string(nil), sentinel.Addrs...),
+		Client:    client,
+		IsMock:    isMock,
+		CreatedAt: time.Now(),
+		LastUsed:  time.Now(),
+		tunnel:    tunnel,
+		monitor:   newSentinelMonitor(sentinel.MasterName, source),
+		params:    params,
+		key:       key,
+	}
+
+	if err := cp.addConnection(conn); err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// checkCapacity 检查ID冲突与连接数限制，调用方需持有写锁
+// 连接数达到上限时关闭最久未使用且没有被引用的连接，全部连接都在使用中时返回错误
+func (cp *ConnectionPool) checkCapacity(id string) error {
+	// 检查连接是否已存在
+	if _, exists := cp.connections[id]; exists {
+		return fmt.Errorf("connection with id %s already exists", id)
+	}
+
+	// 检查连接数限制
+	if len(cp.connections) >= cp.maxConn && !cp.evictIdle() {
+		return fmt.Errorf("maximum connections limit reached: %d", cp.maxConn)
+	}
+	return nil
+}
+
+// evictIdle 关闭最久未使用的未引用连接，没有可关闭的连接时返回false，调用方需持有写锁
+func (cp *ConnectionPool) evictIdle() bool {
+	var oldest *RedisConnection
+	for _, conn := range cp.connections {
+		if conn.Refs == 0 && (oldest == nil || conn.LastUsed.Before(oldest.LastUsed)) {
+			oldest = conn
+		}
+	}
+	if oldest == nil {
+		return false
+	}
+	oldest.close()
+	delete(cp.connections, oldest.ID)
+	return true
+}
+
+// reuse 查找端点、凭据与选项完全相同的连接并增加引用计数，调用方需持有写锁
+func (cp *ConnectionPool) reuse(key string) *RedisConnection {
+	for _, conn := range cp.connections {
+		if conn.key == key {
+			conn.Refs++
+			conn.LastUsed = time.Now()
+			return conn
+		}
+	}
+	return nil
+}
+
+
+// addConnection 开启录制时包装客户端，然后加入连接池，调用方需持有写锁
+func (cp *ConnectionPool) addConnection(conn *RedisConnection) error {
+	if cp.traceDir != "" {
+		recorder, err := newRecorder(cp.traceDir, conn.ID, conn.Client)
+		if err != nil {
+			conn.close()
+			return err
+		}
+		conn.Client = recorder
+	}
+
+	// 创建时已经确认过连接可用
+	conn.Health = Health{Healthy: true, CheckedAt: conn.CreatedAt}
+	conn.Refs = 1
+	cp.connections[conn.ID] = conn
+	return nil
+}
+
+// newRecorder 为连接创建命令录制器，写入dir下的 <连接ID>.jsonl
+func newRecorder(dir, id string, client mock.RedisInterface) (*mock.RedisRecorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create trace directory: %v", err)
+	}
+
+	tracePath := filepath.Join(dir, filepath.Base(id)+".jsonl")
+	file, err := os.OpenFile(tracePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open trace file: %v", err)
+	}
+
+	return mock.NewRedisRecorder(client, id, file), nil
+}
+
 // GetConnection 获取连接
 func (cp *ConnectionPool) GetConnection(id string) (*RedisConnection, error) {
-	cp.mutex.RLock()
-	defer cp.mutex.RUnlock()
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
 
 	conn, exists := cp.connections[id]
 	if !exists {
@@ -149,11 +612,7 @@ func (cp *ConnectionPool) RemoveConnection(id string) error {
 	}
 
 	// 关闭Redis客户端
-	if conn.Client != nil {
-		if closer, ok := conn.Client.(interface{ Close() error }); ok {
-			closer.Close()
-		}
-	}
+	conn.close()
 
 	// 从连接池中删除
 	delete(cp.connections, id)
@@ -161,19 +620,69 @@ func (cp *ConnectionPool) RemoveConnection(id string) error {
 	return nil
 }
 
-// ListConnections 列出所有连接
+// ListConnections 列出所有连接，按创建时间排序
+// 返回的是副本，哨兵连接的副本中包含最近一次解析到的主从节点
 func (cp *ConnectionPool) ListConnections() []*RedisConnection {
 	cp.mutex.RLock()
 	defer cp.mutex.RUnlock()
 
 	connections := make([]*RedisConnection, 0, len(cp.connections))
 	for _, conn := range cp.connections {
-		connections = append(connections, conn)
+		connections = append(connections, conn.snapshot())
 	}
+	sort.Slice(connections, func(i, j int) bool {
+		if connections[i].CreatedAt.Equal(connections[j].CreatedAt) {
+			return connections[i].ID < connections[j].ID
+		}
+		return connections[i].CreatedAt.Before(connections[j].CreatedAt)
+	})
 
 	return connections
 }
 
+// Release 释放一个引用，例如Token过期时
+// 引用为0的连接保持打开以便复用，连接数达到上限时优先被关闭
+func (cp *ConnectionPool) Release(id string) {
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+
+	if conn, exists := cp.connections[id]; exists && conn.Refs > 0 {
+		conn.Refs--
+	}
+}
+
+// GetConnectionInfo 返回连接的副本，与GetConnection不同，不会更新最后使用时间
+func (cp *ConnectionPool) GetConnectionInfo(id string) (*RedisConnection, error) {
+	cp.mutex.RLock()
+	defer cp.mutex.RUnlock()
+
+	conn, exists := cp.connections[id]
+	if !exists {
+		return nil, fmt.Errorf("connection with id %s not found", id)
+	}
+	return conn.snapshot(), nil
+}
+
+// Ping 检测连接是否可用并返回往返时间，不会更新最后使用时间
+func (cp *ConnectionPool) Ping(ctx context.Context, id string) (time.Duration, error) {
+	cp.mutex.RLock()
+	conn, exists := cp.connections[id]
+	var client mock.RedisInterface
+	if exists {
+		client = conn.Client
+	}
+	cp.mutex.RUnlock()
+	if !exists {
+		return 0, fmt.Errorf("connection with id %s not found", id)
+	}
+
+	start := time.Now()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
 // GetConnectionCount 获取连接数量
 func (cp *ConnectionPool) GetConnectionCount() int {
 	cp.mutex.RLock()
@@ -182,20 +691,22 @@ func (cp *ConnectionPool) GetConnectionCount() int {
 	return len(cp.connections)
 }
 
-// CleanupIdleConnections 清理空闲连接
-func (cp *ConnectionPool) CleanupIdleConnections(idleTimeout time.Duration) {
+// CleanupIdleConnections 清理没有被引用的空闲连接，返回被关闭的连接ID
+// 仍有Token引用的连接不关闭，Token过期或被撤销后释放引用
+func (cp *ConnectionPool) CleanupIdleConnections(idleTimeout time.Duration) []string {
 	cp.mutex.Lock()
 	defer cp.mutex.Unlock()
 
+	var removed []string
 	now := time.Now()
 	for id, conn := range cp.connections {
-		if now.Sub(conn.LastUsed) > idleTimeout {
-			if conn.Client != nil {
-				conn.Client.Close()
-			}
+		if conn.Refs == 0 && now.Sub(conn.LastUsed) > idleTimeout {
+			conn.close()
 			delete(cp.connections, id)
+			removed = append(removed, id)
 		}
 	}
+	return removed
 }
 
 // Close 关闭连接池
@@ -204,11 +715,7 @@ func (cp *ConnectionPool) Close() {
 	defer cp.mutex.Unlock()
 
 	for _, conn := range cp.connections {
-		if conn.Client != nil {
-			if closer, ok := conn.Client.(interface{ Close() error }); ok {
-				closer.Close()
-			}
-		}
+		conn.close()
 	}
 
 	cp.connections = make(map[string]*RedisConnection)
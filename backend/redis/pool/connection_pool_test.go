@@ -3,8 +3,13 @@ package pool
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
+	"time"
+
+	"github.com/devtoolbox/redis/mock"
 )
 
 func TestConnectionPool_CreateMockConnection(t *testing.T) {
@@ -60,6 +65,80 @@ func TestConnectionPool_RealMode(t *testing.T) {
 	}
 }
 
+func TestConnectionPool_CreateClusterConnection(t *testing.T) {
+	pool := NewConnectionPool(10)
+	defer pool.Close()
+	pool.SetMockMode(true)
+
+	conn, err := pool.CreateClusterConnection("cluster1", []string{"10.0.0.1:7000", "10.0.0.2:7000"}, ConnectOptions{Password: "password", Profile: "prod"})
+	if err != nil {
+		t.Fatalf("Failed to create cluster connection: %v", err)
+	}
+	if conn.Mode != ModeCluster || conn.Host != "10.0.0.1" || conn.Port != 7000 || len(conn.Nodes) != 2 {
+		t.Errorf("Unexpected cluster connection: %+v", conn)
+	}
+	if err := conn.Client.Ping(context.Background()).Err(); err != nil {
+		t.Errorf("Ping failed: %v", err)
+	}
+	if slots := conn.Client.ClusterSlots(context.Background()).Val(); len(slots) != 3 {
+		t.Errorf("Expected 3 slot ranges in mock cluster, got %+v", slots)
+	}
+
+	if _, err := pool.CreateClusterConnection("cluster2", nil, ConnectOptions{}); err == nil {
+		t.Error("Expected error without node addresses")
+	}
+	if _, err := pool.CreateClusterConnection("cluster1", []string{"10.0.0.1:7000"}, ConnectOptions{}); err == nil {
+		t.Error("Expected error for duplicate connection id")
+	}
+}
+
+func TestConnectionPool_CreateSentinelConnection(t *testing.T) {
+	pool := NewConnectionPool(10)
+	defer pool.Close()
+	pool.SetMockMode(true)
+
+	sentinel := SentinelConfig{MasterName: "mymaster", Addrs: []string{"10.0.0.1:26379", "10.0.0.2:26379"}}
+	conn, err := pool.CreateSentinelConnection("ha1", sentinel, ConnectOptions{DB: 2, Password: "password", Profile: "ha"})
+	if err != nil {
+		t.Fatalf("Failed to create sentinel connection: %v", err)
+	}
+	if conn.Mode != ModeSentinel || conn.Host != "10.0.0.1" || conn.Port != 26379 || conn.DB != 2 {
+		t.Errorf("Unexpected sentinel connection: %+v", conn)
+	}
+	ctx := context.Background()
+	if err := conn.Client.Set(ctx, "k", "v", 0).Err(); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	listed := pool.ListConnections()
+	if len(listed) != 1 || listed[0].Sentinel == nil || listed[0].Sentinel.Master != "127.0.0.1:6379" || len(listed[0].Sentinel.Replicas) != 2 {
+		t.Fatalf("Expected resolved topology in listing, got %+v", listed)
+	}
+
+	// 故障转移后列表中的主节点随之更新，连接继续可用
+	conn.monitor.source.(*mockSentinel).failover()
+	deadline := time.Now().Add(2 * time.Second)
+	for pool.ListConnections()[0].Sentinel.Master != "127.0.0.1:6380" {
+		if time.Now().After(deadline) {
+			t.Fatalf("Topology not refreshed after failover: %+v", pool.ListConnections()[0].Sentinel)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if value := conn.Client.Get(ctx, "k").Val(); value != "v" {
+		t.Errorf("Expected v after failover, got %q", value)
+	}
+
+	if _, err := pool.CreateSentinelConnection("ha2", SentinelConfig{Addrs: sentinel.Addrs}, ConnectOptions{}); err == nil {
+		t.Error("Expected error without master name")
+	}
+	if _, err := pool.CreateSentinelConnection("ha2", SentinelConfig{MasterName: "mymaster"}, ConnectOptions{}); err == nil {
+		t.Error("Expected error without sentinel addresses")
+	}
+	if err := pool.RemoveConnection("ha1"); err != nil {
+		t.Errorf("RemoveConnection failed: %v", err)
+	}
+}
+
 func TestConnectionPool_ModeSwitch(t *testing.T) {
 	pool := NewConnectionPool(10)
 	defer pool.Close()
@@ -125,6 +204,100 @@ func TestConnectionPool_MockConnectionLifecycle(t *testing.T) {
 	}
 }
 
+func TestConnectionPool_PingAndInfo(t *testing.T) {
+	pool := NewConnectionPool(10)
+	defer pool.Close()
+	pool.SetMockMode(true)
+
+	first, err := pool.CreateConnection("info1", "localhost", 6379, 0, "password", "dev")
+	if err != nil {
+		t.Fatalf("Failed to create connection: %v", err)
+	}
+	if _, err := pool.CreateConnection("info2", "localhost", 6379, 1, "password", ""); err != nil {
+		t.Fatalf("Failed to create connection: %v", err)
+	}
+	lastUsed := first.LastUsed
+
+	// Ping和GetConnectionInfo不计为使用，空闲连接仍然可以被清理
+	time.Sleep(5 * time.Millisecond)
+	if _, err := pool.Ping(context.Background(), "info1"); err != nil {
+		t.Errorf("Ping failed: %v", err)
+	}
+	info, err := pool.GetConnectionInfo("info1")
+	if err != nil {
+		t.Fatalf("GetConnectionInfo failed: %v", err)
+	}
+	if !info.LastUsed.Equal(lastUsed) || info.Profile != "dev" {
+		t.Errorf("Unexpected connection info: %+v", info)
+	}
+	if info == first {
+		t.Error("Expected GetConnectionInfo to return a copy")
+	}
+
+	listed := pool.ListConnections()
+	if len(listed) != 2 || listed[0].ID != "info1" || listed[1].ID != "info2" {
+		t.Errorf("Expected connections ordered by creation time, got %+v", listed)
+	}
+
+	if _, err := pool.Ping(context.Background(), "missing"); err == nil {
+		t.Error("Expected error when pinging missing connection")
+	}
+	if _, err := pool.GetConnectionInfo("missing"); err == nil {
+		t.Error("Expected error for missing connection")
+	}
+}
+
+func TestConnectionPool_ReuseAndEviction(t *testing.T) {
+	pool := NewConnectionPool(2)
+	defer pool.Close()
+	pool.SetMockMode(true)
+
+	first, err := pool.CreateConnection("a", "localhost", 6379, 0, "password", "")
+	if err != nil {
+		t.Fatalf("Failed to create connection: %v", err)
+	}
+
+	// 端点与凭据相同的连接复用已有客户端，引用计数加一
+	again, err := pool.CreateConnection("b", "localhost", 6379, 0, "password", "")
+	if err != nil {
+		t.Fatalf("Failed to reuse connection: %v", err)
+	}
+	if again.ID != "a" || again.Client != first.Client || again.Refs != 2 {
+		t.Errorf("Expected connection a to be reused with 2 refs, got %s with %d refs", again.ID, again.Refs)
+	}
+
+	// 密码或数据库不同时创建新连接
+	if _, err := pool.CreateConnection("c", "localhost", 6379, 0, "other", ""); err != nil {
+		t.Fatalf("Failed to create connection: %v", err)
+	}
+	if _, err := pool.CreateConnection("c", "localhost", 6379, 1, "password", ""); err == nil {
+		t.Error("Expected error for duplicate connection id")
+	}
+
+	// 所有连接都被引用时拒绝新连接
+	if _, err := pool.CreateConnection("d", "localhost", 6379, 2, "", ""); err == nil {
+		t.Error("Expected error when every connection is in use")
+	}
+
+	// 释放后最久未使用的空闲连接被淘汰
+	pool.Release("a")
+	pool.Release("a")
+	pool.Release("a")
+	pool.Release("c")
+	if info, _ := pool.GetConnectionInfo("a"); info.Refs != 0 {
+		t.Errorf("Expected refs to stop at 0, got %d", info.Refs)
+	}
+	if _, err := pool.CreateConnection("d", "localhost", 6379, 2, "", ""); err != nil {
+		t.Fatalf("Expected idle connection to be evicted: %v", err)
+	}
+	if _, err := pool.GetConnectionInfo("a"); err == nil {
+		t.Error("Expected least recently used connection a to be evicted")
+	}
+	if _, err := pool.GetConnectionInfo("c"); err != nil {
+		t.Errorf("Expected connection c to stay open: %v", err)
+	}
+}
+
 func TestConnectionPool_MockConnectionConcurrency(t *testing.T) {
 	pool := NewConnectionPool(10)
 	defer pool.Close()
@@ -172,4 +345,36 @@ func TestConnectionPool_MockConnectionConcurrency(t *testing.T) {
 	if pool.GetConnectionCount() != numGoroutines {
 		t.Errorf("Expected %d connections, got %d", numGoroutines, pool.GetConnectionCount())
 	}
-}
\ No newline at end of file
+}
+
+func TestConnectionPool_TraceRecording(t *testing.T) {
+	pool := NewConnectionPool(10)
+	defer pool.Close()
+	pool.SetMockMode(true)
+	traceDir := t.TempDir()
+	pool.SetTraceDir(traceDir)
+
+	conn, err := pool.CreateConnection("trace1", "localhost", 6379, 0, "password", "trace")
+	if err != nil {
+		t.Fatalf("Failed to create connection: %v", err)
+	}
+
+	ctx := context.Background()
+	conn.Client.Set(ctx, "k", "v", 0)
+	conn.Client.Get(ctx, "k")
+	pool.RemoveConnection("trace1")
+
+	file, err := os.Open(filepath.Join(traceDir, "trace1.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to open trace file: %v", err)
+	}
+	defer file.Close()
+
+	entries, err := mock.ReadTrace(file)
+	if err != nil {
+		t.Fatalf("Failed to read trace: %v", err)
+	}
+	if len(entries) != 2 || entries[1].Command != "GET" {
+		t.Errorf("Unexpected trace entries: %+v", entries)
+	}
+}
@@ -0,0 +1,146 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/devtoolbox/redis/mock"
+)
+
+// KeyspaceDB INFO keyspace中一个数据库的统计
+type KeyspaceDB struct {
+	DB      int   `json:"db"`
+	Keys    int64 `json:"keys"`
+	Expires int64 `json:"expires"`
+	AvgTTL  int64 `json:"avgTtl"` // 毫秒
+}
+
+// SwitchDB 切换到同一端点的另一个数据库，返回切换后的连接
+// 原连接可能被其他Token共用，因此不会修改它的客户端，而是复用或创建目标数据库的连接，
+// 成功后释放原连接的一个引用；需要创建新连接时使用newID
+func (cp *ConnectionPool) SwitchDB(id, newID string, db int) (*RedisConnection, error) {
+	if db < 0 {
+		return nil, fmt.Errorf("invalid database %d", db)
+	}
+
+	cp.mutex.RLock()
+	conn, exists := cp.connections[id]
+	var params connectParams
+	var host string
+	var port int
+	if exists {
+		params, host, port = conn.params, conn.Host, conn.Port
+	}
+	cp.mutex.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("connection with id %s not found", id)
+	}
+	if db == params.opts.DB {
+		return cp.GetConnection(id)
+	}
+
+	opts := params.opts
+	opts.DB = db
+	var next *RedisConnection
+	var err error
+	switch params.mode {
+	case ModeCluster:
+		return nil, fmt.Errorf("cluster mode only supports database 0")
+	case ModeSentinel:
+		next, err = cp.CreateSentinelConnection(newID, *params.sentinel, opts)
+	default:
+		next, err = cp.CreateConnectionWithOptions(newID, host, port, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cp.Release(id)
+	return next, nil
+}
+
+// Keyspace 返回各数据库的键数量，没有键的数据库不会出现，结果按数据库编号排序
+// 集群连接只有0号数据库，使用所有节点的DBSIZE之和；
+// 模拟模式下每个数据库是一个独立的模拟实例，汇总同一端点下各数据库连接的统计
+func (cp *ConnectionPool) Keyspace(ctx context.Context, id string) ([]KeyspaceDB, error) {
+	cp.mutex.RLock()
+	conn, exists := cp.connections[id]
+	var clients []mock.RedisInterface
+	if exists {
+		clients = append(clients, conn.Client)
+		if conn.IsMock && conn.Mode != ModeCluster {
+			endpoint := conn.params.endpoint()
+			for _, other := range cp.connections {
+				if other != conn && other.params.endpoint() == endpoint {
+					clients = append(clients, other.Client)
+				}
+			}
+		}
+	}
+	cp.mutex.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("connection with id %s not found", id)
+	}
+
+	if conn.Mode == ModeCluster {
+		keys, err := conn.Client.DBSize(ctx).Result()
+		if err != nil || keys == 0 {
+			return []KeyspaceDB{}, err
+		}
+		return []KeyspaceDB{{DB: 0, Keys: keys}}, nil
+	}
+
+	merged := make(map[int]KeyspaceDB)
+	for _, client := range clients {
+		info, err := client.Info(ctx, "keyspace").Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, db := range parseKeyspace(info) {
+			merged[db.DB] = db
+		}
+	}
+
+	result := make([]KeyspaceDB, 0, len(merged))
+	for _, db := range merged {
+		result = append(result, db)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].DB < result[j].DB
+	})
+	return result, nil
+}
+
+// parseKeyspace 解析INFO keyspace中形如 db0:keys=1,expires=0,avg_ttl=0 的行
+func parseKeyspace(info string) []KeyspaceDB {
+	var result []KeyspaceDB
+	for _, line := range strings.Split(info, "\n") {
+		name, fields, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok || !strings.HasPrefix(name, "db") {
+			continue
+		}
+		index, err := strconv.Atoi(strings.TrimPrefix(name, "db"))
+		if err != nil {
+			continue
+		}
+
+		db := KeyspaceDB{DB: index}
+		for _, field := range strings.Split(fields, ",") {
+			key, value, _ := strings.Cut(field, "=")
+			n, _ := strconv.ParseInt(value, 10, 64)
+			switch key {
+			case "keys":
+				db.Keys = n
+			case "expires":
+				db.Expires = n
+			case "avg_ttl":
+				db.AvgTTL = n
+			}
+		}
+		result = append(result, db)
+	}
+	return result
+}
@@ -0,0 +1,141 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/devtoolbox/redis/mock"
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+func TestConnectionPool_SwitchDBMock(t *testing.T) {
+	pool := NewConnectionPool(10)
+	defer pool.Close()
+	pool.SetMockMode(true)
+	ctx := context.Background()
+
+	first, err := pool.CreateConnection("db0", "localhost", 6379, 0, "password", "")
+	if err != nil {
+		t.Fatalf("Failed to create connection: %v", err)
+	}
+	first.Client.Set(ctx, "a", "1", 0)
+	first.Client.Set(ctx, "b", "2", time.Hour)
+
+	// 切换到1号数据库创建新连接，原连接的引用被释放
+	second, err := pool.SwitchDB("db0", "db1", 1)
+	if err != nil {
+		t.Fatalf("SwitchDB failed: %v", err)
+	}
+	if second.ID != "db1" || second.DB != 1 || second.Refs != 1 {
+		t.Errorf("Unexpected connection after switch: %+v", second)
+	}
+	if info, _ := pool.GetConnectionInfo("db0"); info.Refs != 0 {
+		t.Errorf("Expected db0 to be released, got %d refs", info.Refs)
+	}
+	if n := second.Client.DBSize(ctx).Val(); n != 0 {
+		t.Errorf("Expected database 1 to be empty, got %d keys", n)
+	}
+	second.Client.Set(ctx, "c", "3", 0)
+
+	keyspace, err := pool.Keyspace(ctx, "db1")
+	if err != nil {
+		t.Fatalf("Keyspace failed: %v", err)
+	}
+	if len(keyspace) != 2 || keyspace[0].DB != 0 || keyspace[0].Keys != 2 || keyspace[0].Expires != 1 ||
+		keyspace[0].AvgTTL <= 0 || keyspace[1] != (KeyspaceDB{DB: 1, Keys: 1}) {
+		t.Errorf("Unexpected keyspace: %+v", keyspace)
+	}
+
+	// 切换回0号数据库复用原连接，切换到当前数据库不改变引用
+	back, err := pool.SwitchDB("db1", "unused", 0)
+	if err != nil {
+		t.Fatalf("SwitchDB failed: %v", err)
+	}
+	if back.ID != "db0" || back.Refs != 1 {
+		t.Errorf("Expected db0 to be reused, got %s with %d refs", back.ID, back.Refs)
+	}
+	if same, err := pool.SwitchDB("db0", "unused", 0); err != nil || same.ID != "db0" || same.Refs != 1 {
+		t.Errorf("Expected switching to the current database to keep the connection, got %+v, %v", same, err)
+	}
+
+	if _, err := pool.SwitchDB("db0", "bad", 16); err == nil {
+		t.Error("Expected error for database out of range")
+	}
+	if _, err := pool.SwitchDB("db0", "bad", -1); err == nil {
+		t.Error("Expected error for negative database")
+	}
+	if _, err := pool.SwitchDB("missing", "bad", 1); err == nil {
+		t.Error("Expected error for missing connection")
+	}
+
+	if _, err := pool.CreateClusterConnection("cluster", []string{"localhost:7000"}, ConnectOptions{}); err != nil {
+		t.Fatalf("Failed to create cluster connection: %v", err)
+	}
+	if _, err := pool.SwitchDB("cluster", "bad", 1); err == nil {
+		t.Error("Expected error when switching database in cluster mode")
+	}
+}
+
+func TestConnectionPool_SwitchDBReal(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	backend := mock.NewRedisMock()
+	defer backend.Close()
+	server := mock.NewServer(backend)
+	defer server.Close()
+	go server.Serve(listener)
+
+	_, portText, _ := net.SplitHostPort(listener.Addr().String())
+	port, _ := strconv.Atoi(portText)
+	pool := NewConnectionPool(10)
+	defer pool.Close()
+	ctx := context.Background()
+
+	conn, err := pool.CreateConnectionWithOptions("real0", "127.0.0.1", port, ConnectOptions{})
+	if err != nil {
+		t.Fatalf("Failed to create connection: %v", err)
+	}
+	// 连接池客户端上的SELECT返回错误而不是只切换其中一个socket
+	if err := conn.Client.Select(ctx, 1).Err(); err == nil {
+		t.Error("Expected SELECT on a pooled client to fail")
+	} else if !errors.As(err, new(*rediserr.Error)) {
+		t.Errorf("Expected a Redis error, got %v", err)
+	}
+
+	next, err := pool.SwitchDB("real0", "real1", 2)
+	if err != nil {
+		t.Fatalf("SwitchDB failed: %v", err)
+	}
+	if err := next.Client.Set(ctx, "k", "v", 0).Err(); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if db := backend.GetDB(); db != 2 {
+		t.Errorf("Expected the new client to select database 2, got %d", db)
+	}
+
+	keyspace, err := pool.Keyspace(ctx, "real1")
+	if err != nil {
+		t.Fatalf("Keyspace failed: %v", err)
+	}
+	if !reflect.DeepEqual(keyspace, []KeyspaceDB{{DB: 2, Keys: 1}}) {
+		t.Errorf("Unexpected keyspace: %+v", keyspace)
+	}
+}
+
+func TestParseKeyspace(t *testing.T) {
+	info := "# Keyspace\r\ndb0:keys=12,expires=3,avg_ttl=4500\r\ndb5:keys=1,expires=0,avg_ttl=0\r\nbogus\r\n"
+	expected := []KeyspaceDB{{DB: 0, Keys: 12, Expires: 3, AvgTTL: 4500}, {DB: 5, Keys: 1}}
+	if got := parseKeyspace(info); !reflect.DeepEqual(got, expected) {
+		t.Errorf("parseKeyspace = %+v, want %+v", got, expected)
+	}
+	if got := parseKeyspace("# Keyspace\r\n"); len(got) != 0 {
+		t.Errorf("Expected empty keyspace, got %+v", got)
+	}
+}
@@ -0,0 +1,110 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/devtoolbox/redis/mock"
+)
+
+// Health 连接的健康状态，由后台健康检查更新
+type Health struct {
+	Healthy   bool      `json:"healthy"`
+	LatencyMs float64   `json:"latencyMs"`
+	Failures  int       `json:"failures"` // 连续失败的次数，成功后清零
+	LastError string    `json:"lastError,omitempty"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// CheckHealth PING连接并记录结果，不会更新最后使用时间
+// 经由SSH隧道的连接失败时，如果跳板机连接已断开则重新连接后再试一次
+func (cp *ConnectionPool) CheckHealth(ctx context.Context, id string) (Health, error) {
+	cp.mutex.RLock()
+	conn, exists := cp.connections[id]
+	cp.mutex.RUnlock()
+	if !exists {
+		return Health{}, fmt.Errorf("connection with id %s not found", id)
+	}
+
+	latency, err := cp.Ping(ctx, id)
+	if err != nil && !conn.tunnel.alive() {
+		if err = conn.tunnel.reconnect(); err == nil {
+			latency, err = cp.Ping(ctx, id)
+		}
+	}
+
+	cp.mutex.Lock()
+	defer cp.mutex.Unlock()
+
+	health := conn.Health
+	health.CheckedAt = time.Now()
+	if err != nil {
+		health.Healthy = false
+		health.Failures++
+		health.LastError = err.Error()
+	} else {
+		health = Health{
+			Healthy:   true,
+			LatencyMs: float64(latency.Microseconds()) / 1000,
+			CheckedAt: health.CheckedAt,
+		}
+	}
+	// 检查期间连接可能已被移除，此时只返回结果
+	if cp.connections[id] == conn {
+		conn.Health = health
+	}
+	return health, err
+}
+
+// Reconnect 使用建立连接时的参数重新创建客户端，并在持有锁时替换连接上的旧客户端
+// 经由SSH隧道的连接由CheckHealth重连跳板机，模拟连接没有网络连接可以重建，两者都不处理
+func (cp *ConnectionPool) Reconnect(id string) error {
+	cp.mutex.RLock()
+	conn, exists := cp.connections[id]
+	var params connectParams
+	var host string
+	var rebuild bool
+	if exists {
+		params, host = conn.params, conn.Host
+		rebuild = !conn.IsMock && conn.tunnel == nil
+	}
+	traceDir := cp.traceDir
+	cp.mutex.RUnlock()
+	if !exists {
+		return fmt.Errorf("connection with id %s not found", id)
+	}
+	if !rebuild {
+		return nil
+	}
+
+	tlsConfig, err := params.opts.tlsConfig(host)
+	if err != nil {
+		return err
+	}
+	realClient, err := params.dial(tlsConfig, nil)
+	if err != nil {
+		return err
+	}
+	var client mock.RedisInterface = mock.NewRedisClientAdapter(realClient)
+	if traceDir != "" {
+		if client, err = newRecorder(traceDir, id, client); err != nil {
+			realClient.Close()
+			return err
+		}
+	}
+
+	cp.mutex.Lock()
+	// 重建期间连接可能已被移除
+	if cp.connections[id] != conn {
+		cp.mutex.Unlock()
+		client.Close()
+		return fmt.Errorf("connection with id %s not found", id)
+	}
+	previous := conn.Client
+	conn.Client = client
+	cp.mutex.Unlock()
+
+	previous.Close()
+	return nil
+}
@@ -0,0 +1,259 @@
+package pool
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// sentinelRefreshInterval 定期刷新拓扑的间隔，防止错过哨兵的切换事件
+const sentinelRefreshInterval = 30 * time.Second
+
+// sentinelEvents 可能改变主从节点的哨兵事件
+var sentinelEvents = []string{"+switch-master", "+slave", "+sdown", "-sdown"}
+
+// SentinelConfig 哨兵连接参数
+type SentinelConfig struct {
+	MasterName string
+	Addrs      []string // 哨兵地址（host:port）
+	Password   string   // 哨兵自身的密码，可以与数据节点不同
+}
+
+// SentinelTopology 哨兵报告的当前主从节点
+type SentinelTopology struct {
+	MasterName string    `json:"masterName"`
+	Master     string    `json:"master"`
+	Replicas   []string  `json:"replicas"`
+	Error      string    `json:"error,omitempty"` // 最近一次刷新失败的原因
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// sentinelSource 查询主从地址，并在拓扑可能变化时发出通知
+type sentinelSource interface {
+	Resolve(ctx context.Context) (master string, replicas []string, err error)
+	Changes() <-chan struct{}
+	Close() error
+}
+
+// sentinelMonitor 缓存最新的拓扑，收到切换事件或定时刷新
+// 连接本身由go-redis的FailoverClient在切换后重连，这里只负责展示
+type sentinelMonitor struct {
+	masterName string
+	source     sentinelSource
+	topology   atomic.Pointer[SentinelTopology]
+	cancel     context.CancelFunc
+	done       chan struct{}
+}
+
+// newSentinelMonitor 同步刷新一次拓扑后在后台监听变化
+func newSentinelMonitor(masterName string, source sentinelSource) *sentinelMonitor {
+	ctx, cancel := context.WithCancel(context.Background())
+	m := &sentinelMonitor{
+		masterName: masterName,
+		source:     source,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+	m.refresh(ctx)
+	go m.run(ctx)
+	return m
+}
+
+func (m *sentinelMonitor) run(ctx context.Context) {
+	defer close(m.done)
+
+	ticker := time.NewTicker(sentinelRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-m.source.Changes():
+		case <-ticker.C:
+		}
+		m.refresh(ctx)
+	}
+}
+
+// refresh 查询哨兵并更新缓存，失败时保留上一次的地址并记录错误
+func (m *sentinelMonitor) refresh(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	topology := &SentinelTopology{MasterName: m.masterName, UpdatedAt: time.Now()}
+	master, replicas, err := m.source.Resolve(ctx)
+	if err != nil {
+		if previous := m.topology.Load(); previous != nil {
+			topology.Master = previous.Master
+			topology.Replicas = previous.Replicas
+		}
+		topology.Error = err.Error()
+	} else {
+		topology.Master = master
+		topology.Replicas = replicas
+	}
+	if topology.Replicas == nil {
+		topology.Replicas = []string{}
+	}
+	m.topology.Store(topology)
+}
+
+// Topology 返回最近一次刷新的拓扑
+func (m *sentinelMonitor) Topology() *SentinelTopology {
+	return m.topology.Load()
+}
+
+// Close 停止监听并关闭哨兵连接
+func (m *sentinelMonitor) Close() error {
+	m.cancel()
+	<-m.done
+	return m.source.Close()
+}
+
+// redisSentinel 通过真实的哨兵查询主从地址
+type redisSentinel struct {
+	masterName string
+	clients    []*redis.SentinelClient
+	pubsub     *redis.PubSub
+	changes    chan struct{}
+}
+
+// newRedisSentinel 为每个哨兵创建客户端，并订阅第一个哨兵的切换事件
+func newRedisSentinel(config SentinelConfig, tlsConfig *tls.Config, dialer func(ctx context.Context, network, addr string) (net.Conn, error)) *redisSentinel {
+	s := &redisSentinel{
+		masterName: config.MasterName,
+		changes:    make(chan struct{}, 1),
+	}
+	for _, addr := range config.Addrs {
+		s.clients = append(s.clients, redis.NewSentinelClient(&redis.Options{
+			Addr:      addr,
+			Password:  config.Password,
+			TLSConfig: tlsConfig,
+			Dialer:    dialer,
+		}))
+	}
+
+	// PubSub断开后会自动重连，哨兵不可用时依靠定时刷新
+	s.pubsub = s.clients[0].Subscribe(context.Background(), sentinelEvents...)
+	go func() {
+		for range s.pubsub.Channel() {
+			select {
+			case s.changes <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return s
+}
+
+// Resolve 依次询问每个哨兵，返回第一个成功的结果
+// 处于主观下线、客观下线或断开状态的从节点不计入
+func (s *redisSentinel) Resolve(ctx context.Context) (string, []string, error) {
+	var lastErr error
+	for _, client := range s.clients {
+		addr, err := client.GetMasterAddrByName(ctx, s.masterName).Result()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if len(addr) != 2 {
+			lastErr = fmt.Errorf("unexpected master address %v", addr)
+			continue
+		}
+
+		slaves, err := client.Slaves(ctx, s.masterName).Result()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		replicas := []string{}
+		for _, slave := range slaves {
+			fields := sentinelFields(slave)
+			if strings.Contains(fields["flags"], "s_down") ||
+				strings.Contains(fields["flags"], "o_down") ||
+				strings.Contains(fields["flags"], "disconnected") {
+				continue
+			}
+			replicas = append(replicas, net.JoinHostPort(fields["ip"], fields["port"]))
+		}
+		return net.JoinHostPort(addr[0], addr[1]), replicas, nil
+	}
+	return "", nil, fmt.Errorf("no sentinel could resolve master %q: %w", s.masterName, lastErr)
+}
+
+// sentinelFields 将SENTINEL SLAVES返回的键值数组转换为map
+func sentinelFields(entry interface{}) map[string]string {
+	fields := make(map[string]string)
+	values, _ := entry.([]interface{})
+	for i := 0; i+1 < len(values); i += 2 {
+		key, _ := values[i].(string)
+		value, _ := values[i+1].(string)
+		fields[key] = value
+	}
+	return fields
+}
+
+func (s *redisSentinel) Changes() <-chan struct{} {
+	return s.changes
+}
+
+func (s *redisSentinel) Close() error {
+	s.pubsub.Close()
+	for _, client := range s.clients {
+		client.Close()
+	}
+	return nil
+}
+
+// mockSentinel 模拟模式下的哨兵，提供固定的主从地址，测试中可以模拟故障转移
+type mockSentinel struct {
+	mutex    sync.Mutex
+	master   string
+	replicas []string
+	changes  chan struct{}
+}
+
+func newMockSentinel() *mockSentinel {
+	return &mockSentinel{
+		master:   "127.0.0.1:6379",
+		replicas: []string{"127.0.0.1:6380", "127.0.0.1:6381"},
+		changes:  make(chan struct{}, 1),
+	}
+}
+
+func (s *mockSentinel) Resolve(ctx context.Context) (string, []string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", nil, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.master, append([]string{}, s.replicas...), nil
+}
+
+// failover 将第一个从节点提升为主节点，原主节点变为从节点
+func (s *mockSentinel) failover() {
+	s.mutex.Lock()
+	s.master, s.replicas = s.replicas[0], append(append([]string{}, s.replicas[1:]...), s.master)
+	s.mutex.Unlock()
+
+	select {
+	case s.changes <- struct{}{}:
+	default:
+	}
+}
+
+func (s *mockSentinel) Changes() <-chan struct{} {
+	return s.changes
+}
+
+func (s *mockSentinel) Close() error {
+	return nil
+}
@@ -0,0 +1,252 @@
+package pool
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// defaultSSHPort 未指定端口时使用的SSH端口
+const defaultSSHPort = 22
+
+// sshDialTimeout 连接跳板机的超时时间
+const sshDialTimeout = 10 * time.Second
+
+// SSHOptions SSH跳板机参数，密码与私钥至少提供一个
+type SSHOptions struct {
+	Host       string
+	Port       int // 为0时使用22
+	User       string
+	Password   string
+	PrivateKey string // PEM格式的私钥
+	Passphrase string // 私钥口令，私钥未加密时为空
+	KnownHosts string // known_hosts格式的内容，用于校验跳板机的主机密钥
+}
+
+// Addr 跳板机地址
+func (o *SSHOptions) Addr() string {
+	port := o.Port
+	if port == 0 {
+		port = defaultSSHPort
+	}
+	return net.JoinHostPort(o.Host, strconv.Itoa(port))
+}
+
+// String 连接列表中展示的跳板机，例如 ops@bastion:22
+func (o *SSHOptions) String() string {
+	return o.User + "@" + o.Addr()
+}
+
+// clientConfig 构建SSH客户端配置，认证方式或known_hosts有误时返回错误
+func (o *SSHOptions) clientConfig() (*ssh.ClientConfig, error) {
+	if o.Host == "" || o.User == "" {
+		return nil, fmt.Errorf("ssh host and user are required")
+	}
+
+	var auth []ssh.AuthMethod
+	if o.PrivateKey != "" {
+		var signer ssh.Signer
+		var err error
+		if o.Passphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(o.PrivateKey), []byte(o.Passphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey([]byte(o.PrivateKey))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid ssh private key: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if o.Password != "" {
+		auth = append(auth, ssh.Password(o.Password))
+	}
+	if len(auth) == 0 {
+		return nil, fmt.Errorf("ssh password or private key is required")
+	}
+
+	hostKeyCallback, err := knownHostsCallback(o.KnownHosts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ssh.ClientConfig{
+		User:            o.User,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         sshDialTimeout,
+	}, nil
+}
+
+// knownHostsCallback 根据known_hosts内容校验主机密钥
+// knownhosts包只能读取文件，这里写入临时文件后立即删除
+func knownHostsCallback(content string) (ssh.HostKeyCallback, error) {
+	if content == "" {
+		return nil, fmt.Errorf("ssh known_hosts is required to verify the host key")
+	}
+
+	file, err := os.CreateTemp("", "known_hosts")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create known_hosts file: %v", err)
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.WriteString(content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write known_hosts file: %v", err)
+	}
+
+	callback, err := knownhosts.New(file.Name())
+	if err != nil {
+		return nil, fmt.Errorf("invalid ssh known_hosts: %w", err)
+	}
+	return callback, nil
+}
+
+// sshTunnel 经由跳板机转发的SSH连接，生命周期与RedisConnection一致
+// 跳板机断开后可以通过reconnect重新连接，已有的go-redis客户端无需重建
+type sshTunnel struct {
+	opts  *SSHOptions
+	mutex sync.Mutex
+	// client 当前的SSH连接，reconnect时替换
+	client *ssh.Client
+	closed bool
+	conns  sync.WaitGroup
+}
+
+// dialSSH 连接跳板机并完成认证
+func dialSSH(opts *SSHOptions) (*sshTunnel, error) {
+	client, err := opts.dial()
+	if err != nil {
+		return nil, err
+	}
+	return &sshTunnel{opts: opts, client: client}, nil
+}
+
+func (o *SSHOptions) dial() (*ssh.Client, error) {
+	config, err := o.clientConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := ssh.Dial("tcp", o.Addr(), config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ssh host %s: %w", o.Addr(), err)
+	}
+	return client, nil
+}
+
+func (t *sshTunnel) current() *ssh.Client {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.client
+}
+
+// alive 发送keepalive请求确认SSH连接仍然可用
+func (t *sshTunnel) alive() bool {
+	if t == nil {
+		return true
+	}
+	_, _, err := t.current().SendRequest("keepalive@openssh.com", true, nil)
+	return err == nil
+}
+
+// reconnect 重新连接跳板机并替换当前的SSH连接
+func (t *sshTunnel) reconnect() error {
+	if t == nil {
+		return nil
+	}
+	client, err := t.opts.dial()
+	if err != nil {
+		return err
+	}
+
+	t.mutex.Lock()
+	if t.closed {
+		t.mutex.Unlock()
+		client.Close()
+		return fmt.Errorf("ssh tunnel is closed")
+	}
+	previous := t.client
+	t.client = client
+	t.mutex.Unlock()
+
+	previous.Close()
+	return nil
+}
+
+// dialer 返回用于go-redis的拨号函数，没有隧道时为nil，即直接连接
+func (t *sshTunnel) dialer() func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if t == nil {
+		return nil
+	}
+	return t.Dial
+}
+
+// Dial 通过跳板机连接addr，签名与redis.Options.Dialer一致
+// SSH通道不支持读写超时，因此经net.Pipe转接，让go-redis的超时设置照常生效
+func (t *sshTunnel) Dial(ctx context.Context, network, addr string) (net.Conn, error) {
+	remote, err := t.current().DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("ssh tunnel failed to reach %s: %w", addr, err)
+	}
+
+	local, bridge := net.Pipe()
+	t.conns.Add(2)
+	go func() {
+		defer t.conns.Done()
+		io.Copy(remote, bridge)
+		remote.Close()
+	}()
+	go func() {
+		defer t.conns.Done()
+		io.Copy(bridge, remote)
+		bridge.Close()
+	}()
+	return local, nil
+}
+
+// Close 关闭SSH连接，所有经由隧道的连接随之断开
+func (t *sshTunnel) Close() error {
+	if t == nil {
+		return nil
+	}
+	t.mutex.Lock()
+	t.closed = true
+	client := t.client
+	t.mutex.Unlock()
+
+	err := client.Close()
+	t.conns.Wait()
+	return err
+}
+
+// openTunnel 按需建立SSH隧道，未配置SSH时返回nil
+// 模拟模式下只校验参数，不实际连接跳板机
+func (o ConnectOptions) openTunnel(mockMode bool) (*sshTunnel, error) {
+	if o.SSH == nil {
+		return nil, nil
+	}
+	if mockMode {
+		_, err := o.SSH.clientConfig()
+		return nil, err
+	}
+	return dialSSH(o.SSH)
+}
+
+// sshString 连接列表中展示的跳板机，未使用SSH时为空
+func (o ConnectOptions) sshString() string {
+	if o.SSH == nil {
+		return ""
+	}
+	return o.SSH.String()
+}
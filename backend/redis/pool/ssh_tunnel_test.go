@@ -0,0 +1,216 @@
+package pool
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/devtoolbox/redis/mock"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// testBastion 进程内的SSH跳板机，只支持direct-tcpip端口转发
+type testBastion struct {
+	addr       string
+	hostKey    ssh.Signer
+	sessions   atomic.Int64
+	forwarded  atomic.Int64
+	clientKey  ssh.PublicKey
+	knownHosts string
+}
+
+func newTestBastion(t *testing.T, clientKey ssh.PublicKey) *testBastion {
+	t.Helper()
+	_, hostPriv, _ := ed25519.GenerateKey(rand.Reader)
+	hostKey, err := ssh.NewSignerFromKey(hostPriv)
+	if err != nil {
+		t.Fatalf("NewSignerFromKey failed: %v", err)
+	}
+	b := &testBastion{hostKey: hostKey, clientKey: clientKey}
+
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(meta ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if meta.User() == "ops" && string(password) == "bastion-pass" {
+				return nil, nil
+			}
+			return nil, io.EOF
+		},
+		PublicKeyCallback: func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if meta.User() == "ops" && b.clientKey != nil && string(key.Marshal()) == string(b.clientKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, io.EOF
+		},
+	}
+	config.AddHostKey(hostKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	b.addr = listener.Addr().String()
+	b.knownHosts = knownhosts.Line([]string{knownhosts.Normalize(b.addr)}, hostKey.PublicKey()) + "\n"
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn, config)
+		}
+	}()
+	return b
+}
+
+func (b *testBastion) serve(conn net.Conn, config *ssh.ServerConfig) {
+	serverConn, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	b.sessions.Add(1)
+	defer b.sessions.Add(-1)
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "direct-tcpip" {
+			newChannel.Reject(ssh.UnknownChannelType, "only port forwarding is supported")
+			continue
+		}
+		var target struct {
+			Host       string
+			Port       uint32
+			OriginHost string
+			OriginPort uint32
+		}
+		if err := ssh.Unmarshal(newChannel.ExtraData(), &target); err != nil {
+			newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		upstream, err := net.Dial("tcp", net.JoinHostPort(target.Host, strconv.Itoa(int(target.Port))))
+		if err != nil {
+			newChannel.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		channel, reqs, err := newChannel.Accept()
+		if err != nil {
+			upstream.Close()
+			continue
+		}
+		b.forwarded.Add(1)
+		go ssh.DiscardRequests(reqs)
+		go func() {
+			io.Copy(channel, upstream)
+			channel.Close()
+		}()
+		go func() {
+			io.Copy(upstream, channel)
+			upstream.Close()
+		}()
+	}
+	serverConn.Wait()
+}
+
+func TestConnectionPool_SSHTunnel(t *testing.T) {
+	_, clientPriv, _ := ed25519.GenerateKey(rand.Reader)
+	clientSigner, _ := ssh.NewSignerFromKey(clientPriv)
+	block, err := ssh.MarshalPrivateKey(clientPriv, "test")
+	if err != nil {
+		t.Fatalf("MarshalPrivateKey failed: %v", err)
+	}
+	bastion := newTestBastion(t, clientSigner.PublicKey())
+	bastionHost, bastionPortText, _ := net.SplitHostPort(bastion.addr)
+	bastionPort, _ := strconv.Atoi(bastionPortText)
+
+	// 跳板机后面的Redis
+	backend := mock.NewRedisMock()
+	defer backend.Close()
+	server := mock.NewServer(backend)
+	defer server.Close()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	go server.Serve(listener)
+	_, redisPortText, _ := net.SplitHostPort(listener.Addr().String())
+	redisPort, _ := strconv.Atoi(redisPortText)
+
+	pool := NewConnectionPool(10)
+	defer pool.Close()
+	ctx := context.Background()
+
+	sshOpts := &SSHOptions{Host: bastionHost, Port: bastionPort, User: "ops", Password: "bastion-pass", KnownHosts: bastion.knownHosts}
+	conn, err := pool.CreateConnectionWithOptions("ssh1", "127.0.0.1", redisPort, ConnectOptions{SSH: sshOpts})
+	if err != nil {
+		t.Fatalf("Failed to connect through SSH tunnel: %v", err)
+	}
+	if conn.SSH != "ops@"+bastion.addr {
+		t.Errorf("Unexpected SSH in listing: %q", conn.SSH)
+	}
+	if err := conn.Client.Set(ctx, "via", "bastion", 0).Err(); err != nil {
+		t.Fatalf("Set through tunnel failed: %v", err)
+	}
+	if value := backend.Get(ctx, "via").Val(); value != "bastion" {
+		t.Errorf("Expected value to reach Redis behind the bastion, got %q", value)
+	}
+	if bastion.forwarded.Load() == 0 {
+		t.Error("Expected the connection to be forwarded by the bastion")
+	}
+
+	keyOpts := &SSHOptions{Host: bastionHost, Port: bastionPort, User: "ops", PrivateKey: string(pem.EncodeToMemory(block)), KnownHosts: bastion.knownHosts}
+	if _, err := pool.CreateConnectionWithOptions("ssh2", "127.0.0.1", redisPort, ConnectOptions{SSH: keyOpts}); err != nil {
+		t.Fatalf("Failed to connect with private key: %v", err)
+	}
+
+	// 跳板机连接断开后，健康检查重新连接隧道，已有的客户端继续可用
+	conn.tunnel.current().Close()
+	health, err := pool.CheckHealth(ctx, "ssh1")
+	if err != nil || !health.Healthy {
+		t.Fatalf("Expected tunnel to be reconnected, got %+v (%v)", health, err)
+	}
+	if value := conn.Client.Get(ctx, "via").Val(); value != "bastion" {
+		t.Errorf("Expected value after reconnect, got %q", value)
+	}
+
+	// 隧道随连接一起关闭
+	pool.RemoveConnection("ssh1")
+	pool.RemoveConnection("ssh2")
+	deadline := time.Now().Add(2 * time.Second)
+	for bastion.sessions.Load() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected SSH sessions to be closed, %d still open", bastion.sessions.Load())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// 主机密钥不匹配或认证失败时拒绝连接
+	other := newTestBastion(t, nil)
+	wrongHostKey := *sshOpts
+	wrongHostKey.KnownHosts = knownhosts.Line([]string{knownhosts.Normalize(bastion.addr)}, other.hostKey.PublicKey())
+	if _, err := pool.CreateConnectionWithOptions("ssh3", "127.0.0.1", redisPort, ConnectOptions{SSH: &wrongHostKey}); err == nil {
+		t.Error("Expected error for mismatched host key")
+	}
+	wrongPassword := *sshOpts
+	wrongPassword.Password = "wrong"
+	if _, err := pool.CreateConnectionWithOptions("ssh3", "127.0.0.1", redisPort, ConnectOptions{SSH: &wrongPassword}); err == nil {
+		t.Error("Expected error for wrong SSH password")
+	}
+	noKnownHosts := *sshOpts
+	noKnownHosts.KnownHosts = ""
+	if _, err := pool.CreateConnectionWithOptions("ssh3", "127.0.0.1", redisPort, ConnectOptions{SSH: &noKnownHosts}); err == nil {
+		t.Error("Expected error without known_hosts")
+	}
+	if count := pool.GetConnectionCount(); count != 0 {
+		t.Errorf("Expected no connections left, got %d", count)
+	}
+}
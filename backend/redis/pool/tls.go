@@ -0,0 +1,56 @@
+package pool
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+)
+
+// TLSOptions TLS连接参数，证书和私钥均为PEM格式
+type TLSOptions struct {
+	ServerName         string // 为空时使用连接的主机名
+	CACert             string // 为空时使用系统根证书
+	ClientCert         string // 双向认证的客户端证书，需与ClientKey同时提供
+	ClientKey          string
+	InsecureSkipVerify bool // 跳过服务端证书校验，仅用于自签名的测试环境
+}
+
+// Config 根据PEM内容构建tls.Config，host用于默认的ServerName
+func (o *TLSOptions) Config(host string) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         o.ServerName,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+	if config.ServerName == "" {
+		config.ServerName = host
+	}
+
+	if o.CACert != "" {
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM([]byte(o.CACert)) {
+			return nil, fmt.Errorf("no valid certificates found in CA PEM")
+		}
+		config.RootCAs = roots
+	}
+
+	if o.ClientCert != "" || o.ClientKey != "" {
+		if o.ClientCert == "" || o.ClientKey == "" {
+			return nil, fmt.Errorf("client certificate and key must be provided together")
+		}
+		cert, err := tls.X509KeyPair([]byte(o.ClientCert), []byte(o.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// tlsConfig 为nil选项返回nil，表示明文连接
+func (o ConnectOptions) tlsConfig(host string) (*tls.Config, error) {
+	if o.TLS == nil {
+		return nil, nil
+	}
+	return o.TLS.Config(host)
+}
@@ -0,0 +1,182 @@
+package pool
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultRedisPort URI中未指定端口时使用的端口
+const defaultRedisPort = 6379
+
+// maskedPassword 连接列表中代替密码显示的内容
+const maskedPassword = "****"
+
+// URIConfig 从连接串解析出的连接参数
+type URIConfig struct {
+	Host    string
+	Port    int
+	Options ConnectOptions // Password为URI中的原始密码，调用方可能还需要解密
+}
+
+// ParseURI 解析redis://与rediss://连接串
+// 格式：redis[s]://[[username][:password]@]host[:port][/db][?option=value&...]
+// 支持的选项：dial_timeout、read_timeout、write_timeout、pool_timeout、idle_timeout（如3s，纯数字按秒计），
+// pool_size、min_idle_conns、max_retries，以及rediss专用的skip_verify
+func ParseURI(uri string) (*URIConfig, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis URI: %w", err)
+	}
+
+	config := &URIConfig{}
+	switch u.Scheme {
+	case "redis":
+	case "rediss":
+		config.Options.TLS = &TLSOptions{}
+	default:
+		return nil, fmt.Errorf("invalid redis URI scheme %q: must be redis or rediss", u.Scheme)
+	}
+
+	config.Host = u.Hostname()
+	if config.Host == "" {
+		return nil, fmt.Errorf("invalid redis URI: host is required")
+	}
+	config.Port = defaultRedisPort
+	if portText := u.Port(); portText != "" {
+		port, err := strconv.Atoi(portText)
+		if err != nil || port <= 0 || port > 65535 {
+			return nil, fmt.Errorf("invalid redis URI port: %s", portText)
+		}
+		config.Port = port
+	}
+
+	if u.User != nil {
+		config.Options.Username = u.User.Username()
+		config.Options.Password, _ = u.User.Password()
+	}
+
+	if path := strings.Trim(u.Path, "/"); path != "" {
+		db, err := strconv.Atoi(path)
+		if err != nil || db < 0 {
+			return nil, fmt.Errorf("invalid redis URI database: %s", path)
+		}
+		config.Options.DB = db
+	}
+
+	if err := parseURIOptions(u.Query(), &config.Options); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// parseURIOptions 解析查询参数中的连接选项，未知选项返回错误
+func parseURIOptions(query url.Values, opts *ConnectOptions) error {
+	for name, values := range query {
+		value := values[len(values)-1]
+		var err error
+		switch name {
+		case "dial_timeout":
+			opts.DialTimeout, err = parseURIDuration(value)
+		case "read_timeout":
+			opts.ReadTimeout, err = parseURIDuration(value)
+		case "write_timeout":
+			opts.WriteTimeout, err = parseURIDuration(value)
+		case "pool_timeout":
+			opts.PoolTimeout, err = parseURIDuration(value)
+		case "idle_timeout":
+			opts.IdleTimeout, err = parseURIDuration(value)
+		case "pool_size":
+			opts.PoolSize, err = strconv.Atoi(value)
+		case "min_idle_conns":
+			opts.MinIdleConns, err = strconv.Atoi(value)
+		case "max_retries":
+			opts.MaxRetries, err = strconv.Atoi(value)
+		case "skip_verify":
+			if opts.TLS == nil {
+				return fmt.Errorf("invalid redis URI option skip_verify: requires rediss scheme")
+			}
+			opts.TLS.InsecureSkipVerify, err = strconv.ParseBool(value)
+		default:
+			return fmt.Errorf("invalid redis URI option %q", name)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid redis URI option %s=%s", name, value)
+		}
+	}
+	return nil
+}
+
+// parseURIDuration 解析时长，纯数字按秒计
+func parseURIDuration(value string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+	return d, nil
+}
+
+// FormatURI 生成规范化的连接串，密码以****代替，选项按名称排序且省略默认值
+func FormatURI(host string, port int, opts ConnectOptions) string {
+	u := url.URL{
+		Scheme: "redis",
+		Host:   net.JoinHostPort(host, strconv.Itoa(port)),
+		Path:   "/" + strconv.Itoa(opts.DB),
+	}
+	if opts.TLS != nil {
+		u.Scheme = "rediss"
+	}
+	if opts.Password != "" {
+		u.User = url.UserPassword(opts.Username, maskedPassword)
+	} else if opts.Username != "" {
+		u.User = url.User(opts.Username)
+	}
+
+	query := make(map[string]string)
+	durations := map[string]time.Duration{
+		"dial_timeout":  opts.DialTimeout,
+		"read_timeout":  opts.ReadTimeout,
+		"write_timeout": opts.WriteTimeout,
+		"pool_timeout":  opts.PoolTimeout,
+		"idle_timeout":  opts.IdleTimeout,
+	}
+	for name, d := range durations {
+		if d != 0 {
+			query[name] = d.String()
+		}
+	}
+	ints := map[string]int{
+		"pool_size":      opts.PoolSize,
+		"min_idle_conns": opts.MinIdleConns,
+		"max_retries":    opts.MaxRetries,
+	}
+	for name, n := range ints {
+		if n != 0 {
+			query[name] = strconv.Itoa(n)
+		}
+	}
+	if opts.TLS != nil && opts.TLS.InsecureSkipVerify {
+		query["skip_verify"] = "true"
+	}
+
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+"="+url.QueryEscape(query[name]))
+	}
+	u.RawQuery = strings.Join(parts, "&")
+
+	// url.URL会转义*，这里保持掩码可读
+	return strings.Replace(u.String(), url.QueryEscape(maskedPassword), maskedPassword, 1)
+}
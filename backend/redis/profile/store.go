@@ -0,0 +1,428 @@
+package profile
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// storeVersion 文件格式版本
+const storeVersion = 1
+
+// KeySize AES-256密钥长度，KeyFunc应返回该长度的密钥
+const KeySize = 32
+
+// checkPlaintext 用于在打开文件时确认密钥是否正确
+const checkPlaintext = "devtoolbox-redis-profiles"
+
+var (
+	// ErrNotFound 配置不存在
+	ErrNotFound = errors.New("profile not found")
+	// ErrDuplicateName 名称已被其他配置使用
+	ErrDuplicateName = errors.New("profile name already exists")
+	// ErrKeyMismatch 密钥与文件不匹配，通常是更换了RSA私钥或主口令
+	ErrKeyMismatch = errors.New("profile store key mismatch: the RSA key or master passphrase has changed")
+)
+
+// KeyFunc 根据文件中保存的salt派生AES-256密钥
+type KeyFunc func(salt []byte) ([]byte, error)
+
+// PassphraseKey 使用主口令派生密钥
+func PassphraseKey(passphrase string) KeyFunc {
+	return func(salt []byte) ([]byte, error) {
+		return scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, KeySize)
+	}
+}
+
+// TLS 保存的TLS参数，客户端私钥与密码一起加密保存
+type TLS struct {
+	ServerName         string `json:"serverName,omitempty"`
+	CACert             string `json:"caCert,omitempty"`
+	ClientCert         string `json:"clientCert,omitempty"`
+	ClientKey          string `json:"-"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+}
+
+// Profile 保存的连接配置，Password和TLS.ClientKey不会以明文写入文件或返回给前端
+type Profile struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Host        string    `json:"host"`
+	Port        int       `json:"port"`
+	DB          int       `json:"db"`
+	Username    string    `json:"username,omitempty"`
+	TLS         *TLS      `json:"tls,omitempty"`
+	Environment string    `json:"environment,omitempty"` // 环境标签，例如dev、staging、prod
+	Password    string    `json:"-"`
+	HasPassword bool      `json:"hasPassword"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Validate 检查必填字段
+func (p *Profile) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("profile name is required")
+	}
+	if p.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	if p.Port <= 0 || p.Port > 65535 {
+		return fmt.Errorf("invalid port number: %d", p.Port)
+	}
+	if p.DB < 0 {
+		return fmt.Errorf("invalid database number: %d", p.DB)
+	}
+	return nil
+}
+
+// secrets 加密保存的字段
+type secrets struct {
+	Password  string `json:"password,omitempty"`
+	ClientKey string `json:"clientKey,omitempty"`
+}
+
+// storedProfile 文件中的配置，secrets为AES-GCM密文
+type storedProfile struct {
+	Profile
+	Secrets []byte `json:"secrets,omitempty"`
+}
+
+// storeFile 配置文件的格式
+type storeFile struct {
+	Version  int             `json:"version"`
+	Salt     []byte          `json:"salt"`
+	Check    []byte          `json:"check"`
+	Profiles []storedProfile `json:"profiles"`
+}
+
+// Store 保存在本地文件中的连接配置，敏感字段使用派生密钥加密
+type Store struct {
+	mutex    sync.RWMutex
+	path     string
+	salt     []byte
+	check    []byte
+	aead     cipher.AEAD
+	profiles map[string]*Profile
+}
+
+// Open 打开配置文件，文件不存在时在第一次保存时创建
+func Open(path string, key KeyFunc) (*Store, error) {
+	s := &Store{path: path, profiles: make(map[string]*Profile)}
+
+	var file storeFile
+	content, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(content, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse profile store %s: %v", path, err)
+		}
+		if file.Version != storeVersion {
+			return nil, fmt.Errorf("unsupported profile store version %d", file.Version)
+		}
+	case os.IsNotExist(err):
+		file.Salt = make([]byte, 16)
+		if _, err := rand.Read(file.Salt); err != nil {
+			return nil, fmt.Errorf("failed to generate salt: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("failed to read profile store %s: %v", path, err)
+	}
+	s.salt = file.Salt
+	if err := s.setKey(key); err != nil {
+		return nil, err
+	}
+
+	if file.Check == nil {
+		if s.check, err = s.seal([]byte(checkPlaintext), "check"); err != nil {
+			return nil, err
+		}
+	} else {
+		plaintext, err := s.open(file.Check, "check")
+		if err != nil || string(plaintext) != checkPlaintext {
+			return nil, ErrKeyMismatch
+		}
+		s.check = file.Check
+	}
+
+	for i := range file.Profiles {
+		stored := &file.Profiles[i]
+		profile := stored.Profile
+		if stored.Secrets != nil {
+			plaintext, err := s.open(stored.Secrets, profile.ID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decrypt profile %q: %v", profile.Name, err)
+			}
+			var sec secrets
+			if err := json.Unmarshal(plaintext, &sec); err != nil {
+				return nil, fmt.Errorf("failed to decode profile %q: %v", profile.Name, err)
+			}
+			profile.Password = sec.Password
+			if profile.TLS != nil {
+				profile.TLS.ClientKey = sec.ClientKey
+			}
+		}
+		s.profiles[profile.ID] = &profile
+	}
+	return s, nil
+}
+
+// Rekey 使用新的密钥与salt重新加密所有敏感字段，例如RSA私钥轮换之后
+// 写入失败时继续使用原来的密钥
+func (s *Store) Rekey(key KeyFunc) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %v", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previousSalt, previousAEAD, previousCheck := s.salt, s.aead, s.check
+	s.salt = salt
+	err := s.setKey(key)
+	if err == nil {
+		s.check, err = s.seal([]byte(checkPlaintext), "check")
+	}
+	if err == nil {
+		err = s.save()
+	}
+	if err != nil {
+		s.salt, s.aead, s.check = previousSalt, previousAEAD, previousCheck
+		return err
+	}
+	return nil
+}
+
+// setKey 根据当前的salt派生密钥并创建AES-GCM
+func (s *Store) setKey(key KeyFunc) error {
+	derived, err := key(s.salt)
+	if err != nil {
+		return err
+	}
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return fmt.Errorf("invalid profile store key: %v", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+	s.aead = aead
+	return nil
+}
+
+// seal 加密数据，nonce放在密文前面，aad绑定配置ID防止密文被挪用到其他配置
+func (s *Store) seal(plaintext []byte, aad string) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	return s.aead.Seal(nonce, nonce, plaintext, []byte(aad)), nil
+}
+
+func (s *Store) open(ciphertext []byte, aad string) ([]byte, error) {
+	size := s.aead.NonceSize()
+	if len(ciphertext) < size {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	return s.aead.Open(nil, ciphertext[:size], ciphertext[size:], []byte(aad))
+}
+
+// List 返回所有配置，按名称排序
+func (s *Store) List() []Profile {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	list := make([]Profile, 0, len(s.profiles))
+	for _, profile := range s.profiles {
+		list = append(list, profile.clone())
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Get 返回指定配置，包含解密后的密码
+func (s *Store) Get(id string) (Profile, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	profile, ok := s.profiles[id]
+	if !ok {
+		return Profile{}, ErrNotFound
+	}
+	return profile.clone(), nil
+}
+
+// Create 保存新配置并分配ID
+func (s *Store) Create(p Profile) (Profile, error) {
+	if err := p.Validate(); err != nil {
+		return Profile{}, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.nameTaken(p.Name, "") {
+		return Profile{}, ErrDuplicateName
+	}
+	id, err := newID()
+	if err != nil {
+		return Profile{}, err
+	}
+	p.ID = id
+	p.CreatedAt = time.Now()
+	p.UpdatedAt = p.CreatedAt
+	p = p.clone()
+
+	s.profiles[id] = &p
+	if err := s.save(); err != nil {
+		delete(s.profiles, id)
+		return Profile{}, err
+	}
+	return p.clone(), nil
+}
+
+// Update 替换已有配置，ID与创建时间保持不变
+func (s *Store) Update(id string, p Profile) (Profile, error) {
+	if err := p.Validate(); err != nil {
+		return Profile{}, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous, ok := s.profiles[id]
+	if !ok {
+		return Profile{}, ErrNotFound
+	}
+	if s.nameTaken(p.Name, id) {
+		return Profile{}, ErrDuplicateName
+	}
+	p.ID = id
+	p.CreatedAt = previous.CreatedAt
+	p.UpdatedAt = time.Now()
+	p = p.clone()
+
+	s.profiles[id] = &p
+	if err := s.save(); err != nil {
+		s.profiles[id] = previous
+		return Profile{}, err
+	}
+	return p.clone(), nil
+}
+
+// Delete 删除配置
+func (s *Store) Delete(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous, ok := s.profiles[id]
+	if !ok {
+		return ErrNotFound
+	}
+	delete(s.profiles, id)
+	if err := s.save(); err != nil {
+		s.profiles[id] = previous
+		return err
+	}
+	return nil
+}
+
+func (s *Store) nameTaken(name, exceptID string) bool {
+	for id, profile := range s.profiles {
+		if id != exceptID && profile.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// save 加密敏感字段后写入临时文件再重命名，避免写到一半时文件损坏
+func (s *Store) save() error {
+	file := storeFile{
+		Version:  storeVersion,
+		Salt:     s.salt,
+		Check:    s.check,
+		Profiles: make([]storedProfile, 0, len(s.profiles)),
+	}
+	for _, profile := range s.profiles {
+		stored := storedProfile{Profile: *profile}
+		sec := secrets{Password: profile.Password}
+		if profile.TLS != nil {
+			sec.ClientKey = profile.TLS.ClientKey
+		}
+		if sec != (secrets{}) {
+			plaintext, err := json.Marshal(sec)
+			if err != nil {
+				return err
+			}
+			if stored.Secrets, err = s.seal(plaintext, profile.ID); err != nil {
+				return err
+			}
+		}
+		file.Profiles = append(file.Profiles, stored)
+	}
+	sort.Slice(file.Profiles, func(i, j int) bool {
+		return file.Profiles[i].Name < file.Profiles[j].Name
+	})
+
+	content, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create profile directory: %v", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".profiles-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write profile store: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write profile store: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write profile store: %v", err)
+	}
+	return nil
+}
+
+// clone 复制配置，避免调用方修改存储中的TLS指针
+func (p *Profile) clone() Profile {
+	c := *p
+	if p.TLS != nil {
+		tls := *p.TLS
+		c.TLS = &tls
+	}
+	c.HasPassword = c.Password != ""
+	return c
+}
+
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate profile id: %v", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,168 @@
+package profile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testKey 测试用的固定密钥，避免scrypt拖慢测试
+func testKey(b byte) KeyFunc {
+	return func(salt []byte) ([]byte, error) {
+		key := make([]byte, KeySize)
+		for i := range key {
+			key[i] = b ^ salt[i%len(salt)]
+		}
+		return key, nil
+	}
+}
+
+func TestStore_CRUDAndPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	store, err := Open(path, testKey(1))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+
+	created, err := store.Create(Profile{
+		Name:        "prod",
+		Host:        "10.0.0.1",
+		Port:        6379,
+		DB:          2,
+		Username:    "ops",
+		Password:    "s3cret-pass",
+		Environment: "prod",
+		TLS:         &TLS{ServerName: "redis.internal", ClientCert: "CERT", ClientKey: "s3cret-key"},
+	})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if created.ID == "" || !created.HasPassword {
+		t.Errorf("unexpected created profile: %+v", created)
+	}
+	if _, err := store.Create(Profile{Name: "prod", Host: "h", Port: 1}); !errors.Is(err, ErrDuplicateName) {
+		t.Errorf("expected ErrDuplicateName, got %v", err)
+	}
+	if _, err := store.Create(Profile{Name: "dev", Host: "localhost", Port: 6379}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	for _, secret := range []string{"s3cret-pass", "s3cret-key"} {
+		if strings.Contains(string(content), secret) {
+			t.Errorf("profile store contains plaintext secret %q", secret)
+		}
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v (%v)", info.Mode().Perm(), err)
+	}
+
+	reopened, err := Open(path, testKey(1))
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	list := reopened.List()
+	if len(list) != 2 || list[0].Name != "dev" || list[1].Name != "prod" {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+	got, err := reopened.Get(created.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.Password != "s3cret-pass" || got.TLS == nil || got.TLS.ClientKey != "s3cret-key" || got.Environment != "prod" {
+		t.Errorf("secrets not restored: %+v %+v", got, got.TLS)
+	}
+
+	got.Host = "10.0.0.2"
+	got.Password = ""
+	updated, err := reopened.Update(created.ID, got)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if updated.Host != "10.0.0.2" || updated.HasPassword || !updated.CreatedAt.Equal(created.CreatedAt) {
+		t.Errorf("unexpected updated profile: %+v", updated)
+	}
+
+	if err := reopened.Delete(created.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := reopened.Get(created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if err := reopened.Delete(created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStore_KeyMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	store, err := Open(path, PassphraseKey("correct horse"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := store.Create(Profile{Name: "dev", Host: "localhost", Port: 6379, Password: "pw"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if _, err := Open(path, PassphraseKey("wrong")); !errors.Is(err, ErrKeyMismatch) {
+		t.Errorf("expected ErrKeyMismatch, got %v", err)
+	}
+	reopened, err := Open(path, PassphraseKey("correct horse"))
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if list := reopened.List(); len(list) != 1 || list[0].Password != "pw" {
+		t.Errorf("unexpected profiles: %+v", list)
+	}
+}
+
+func TestStore_Rekey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	store, err := Open(path, testKey(1))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := store.Create(Profile{Name: "dev", Host: "localhost", Port: 6379, Password: "pw"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := store.Rekey(testKey(2)); err != nil {
+		t.Fatalf("Rekey: %v", err)
+	}
+	if _, err := Open(path, testKey(1)); !errors.Is(err, ErrKeyMismatch) {
+		t.Errorf("expected the old key to be rejected, got %v", err)
+	}
+	reopened, err := Open(path, testKey(2))
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if list := reopened.List(); len(list) != 1 || list[0].Password != "pw" {
+		t.Errorf("unexpected profiles: %+v", list)
+	}
+}
+
+func TestStore_Validate(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "profiles.json"), testKey(1))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	invalid := []Profile{
+		{Host: "localhost", Port: 6379},
+		{Name: "a", Port: 6379},
+		{Name: "a", Host: "localhost", Port: 70000},
+		{Name: "a", Host: "localhost", Port: 6379, DB: -1},
+	}
+	for _, p := range invalid {
+		if _, err := store.Create(p); err == nil {
+			t.Errorf("expected validation error for %+v", p)
+		}
+	}
+	if _, err := store.Update("missing", Profile{Name: "a", Host: "localhost", Port: 6379}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package rediserr
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Error Redis服务端错误
+// Code为Redis错误前缀（如WRONGTYPE、MOVED），Message为前缀之后的描述
+type Error struct {
+	Code    string
+	Message string
+}
+
+// New 创建Redis错误
+func New(code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Err 创建通用的ERR错误
+func Err(message string) *Error {
+	return &Error{Code: "ERR", Message: message}
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return e.Code
+	}
+	return e.Code + " " + e.Message
+}
+
+// RedisError 实现go-redis的redis.Error接口
+func (e *Error) RedisError() {}
+
+// Is 错误前缀相同即匹配，ERR错误的描述也需一致
+// 例如任意MOVED、WRONGTYPE错误都分别匹配Moved、WrongType，但ERR错误需要完整描述一致
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	if e.Code != t.Code {
+		return false
+	}
+	return e.Code != "ERR" || t.Message == "" || t.Message == e.Message
+}
+
+// 与go-redis保持一致的客户端错误
+var (
+	// Nil 键或字段不存在
+	Nil = redis.Nil
+	// Closed 客户端已关闭
+	Closed = redis.ErrClosed
+)
+
+// Redis服务端错误哨兵，使用errors.Is判断
+var (
+	WrongType   = New("WRONGTYPE", "Operation against a key holding the wrong kind of value")
+	NoScript    = New("NOSCRIPT", "")
+	ReadOnly    = New("READONLY", "")
+	Moved       = New("MOVED", "")
+	Ask         = New("ASK", "")
+	CrossSlot   = New("CROSSSLOT", "")
+	TryAgain    = New("TRYAGAIN", "")
+	ClusterDown = New("CLUSTERDOWN", "")
+	BusyKey     = New("BUSYKEY", "")
+	NoAuth      = New("NOAUTH", "")
+	WrongPass   = New("WRONGPASS", "")
+	NoPerm      = New("NOPERM", "")
+	Syntax      = Err("syntax error")
+	NotInteger  = Err("value is not an integer or out of range")
+	NotFloat    = Err("value is not a valid float")
+	NoSuchKey   = Err("no such key")
+	InvalidDB   = Err("DB index is out of range")
+)
+
+// Parse 解析Redis错误文本，例如 "WRONGTYPE Operation against..."
+func Parse(text string) *Error {
+	code, message, _ := strings.Cut(text, " ")
+	return New(code, message)
+}
+
+// FromClient 将go-redis返回的错误转换为本包的错误
+// redis.Nil、客户端关闭和context错误原样返回，服务端错误转换为*Error
+func FromClient(err error) error {
+	if err == nil || err == Nil || err == Closed {
+		return err
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	var redisErr redis.Error
+	if errors.As(err, &redisErr) {
+		if _, ok := redisErr.(*Error); ok {
+			return err
+		}
+		return Parse(redisErr.Error())
+	}
+	return err
+}
+
+// IsNil 判断是否为键或字段不存在
+func IsNil(err error) bool {
+	return errors.Is(err, Nil)
+}
+
+// HTTPStatus 将错误映射为HTTP状态码
+func HTTPStatus(err error) int {
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, Nil), errors.Is(err, NoSuchKey), errors.Is(err, NoScript):
+		return http.StatusNotFound
+	case errors.Is(err, WrongType), errors.Is(err, BusyKey):
+		return http.StatusConflict
+	case errors.Is(err, ReadOnly), errors.Is(err, NoPerm):
+		return http.StatusForbidden
+	case errors.Is(err, NoAuth), errors.Is(err, WrongPass):
+		return http.StatusUnauthorized
+	case errors.Is(err, Moved), errors.Is(err, Ask), errors.Is(err, CrossSlot):
+		return http.StatusMisdirectedRequest
+	case errors.Is(err, Closed), errors.Is(err, TryAgain), errors.Is(err, ClusterDown):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return http.StatusRequestTimeout
+	}
+
+	// 其余ERR错误视为请求参数问题
+	var redisErr *Error
+	if errors.As(err, &redisErr) && redisErr.Code == "ERR" {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,81 @@
+package rediserr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/go-redis/redis/v8"
+)
+
+func TestError_Is(t *testing.T) {
+	moved := Parse("MOVED 3999 127.0.0.1:6381")
+	if !errors.Is(moved, Moved) {
+		t.Error("Expected MOVED error to match Moved")
+	}
+	if errors.Is(moved, Ask) {
+		t.Error("Expected MOVED error not to match Ask")
+	}
+
+	if !errors.Is(Parse("ERR syntax error"), Syntax) {
+		t.Error("Expected ERR syntax error to match Syntax")
+	}
+	if errors.Is(Parse("ERR unknown command"), Syntax) {
+		t.Error("Expected different ERR message not to match Syntax")
+	}
+
+	// 非ERR错误只比较前缀，描述不同也匹配
+	if !errors.Is(Parse("WRONGTYPE Operation against a key holding a different type"), WrongType) {
+		t.Error("Expected WRONGTYPE error with a message to match WrongType")
+	}
+
+	wrapped := fmt.Errorf("获取失败: %w", WrongType)
+	if !errors.Is(wrapped, WrongType) {
+		t.Error("Expected wrapped error to match WrongType")
+	}
+}
+
+func TestFromClient(t *testing.T) {
+	if err := FromClient(redis.Nil); err != Nil {
+		t.Errorf("Expected Nil, got %v", err)
+	}
+	if err := FromClient(redis.ErrClosed); err != Closed {
+		t.Errorf("Expected Closed, got %v", err)
+	}
+	if err := FromClient(context.DeadlineExceeded); err != context.DeadlineExceeded {
+		t.Errorf("Expected context error to pass through, got %v", err)
+	}
+
+	// go-redis服务端错误实现了redis.Error接口
+	converted := FromClient(New("WRONGTYPE", "Operation against a key holding the wrong kind of value"))
+	if !errors.Is(converted, WrongType) {
+		t.Errorf("Expected WrongType, got %v", converted)
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+	}{
+		{nil, http.StatusOK},
+		{Nil, http.StatusNotFound},
+		{fmt.Errorf("键不存在: %w", Nil), http.StatusNotFound},
+		{WrongType, http.StatusConflict},
+		{ReadOnly, http.StatusForbidden},
+		{Parse("WRONGPASS invalid username-password pair or user is disabled."), http.StatusUnauthorized},
+		{Parse("MOVED 1 127.0.0.1:7000"), http.StatusMisdirectedRequest},
+		{Closed, http.StatusServiceUnavailable},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout},
+		{Err("unknown command"), http.StatusBadRequest},
+		{errors.New("dial tcp: connection refused"), http.StatusInternalServerError},
+	}
+
+	for _, c := range cases {
+		if status := HTTPStatus(c.err); status != c.status {
+			t.Errorf("HTTPStatus(%v) = %d, expected %d", c.err, status, c.status)
+		}
+	}
+}
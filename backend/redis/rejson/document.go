@@ -0,0 +1,260 @@
+// Package rejson 实现RedisJSON模块的文档模型与路径查询
+// 文档中的对象保留键的插入顺序，数字以json.Number保存以区分整数和浮点数，
+// 序列化结果与JSON.GET的紧凑输出一致。
+package rejson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// TypeName RedisJSON在Redis中的类型名
+const TypeName = "ReJSON-RL"
+
+// ErrInvalidJSON 不是合法的JSON文本
+var ErrInvalidJSON = errors.New("rejson: invalid JSON")
+
+// Object 保留键顺序的JSON对象
+type Object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewObject 创建空对象
+func NewObject() *Object {
+	return &Object{values: make(map[string]interface{})}
+}
+
+// Keys 按插入顺序返回所有键
+func (o *Object) Keys() []string {
+	return append([]string(nil), o.keys...)
+}
+
+// Len 键的数量
+func (o *Object) Len() int {
+	return len(o.keys)
+}
+
+// Get 读取键对应的值
+func (o *Object) Get(key string) (interface{}, bool) {
+	value, ok := o.values[key]
+	return value, ok
+}
+
+// Set 写入键，新键追加在末尾
+func (o *Object) Set(key string, value interface{}) {
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// Delete 删除键，键存在时返回true
+func (o *Object) Delete(key string) bool {
+	if _, ok := o.values[key]; !ok {
+		return false
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// MarshalJSON 按键顺序序列化
+func (o *Object) MarshalJSON() ([]byte, error) {
+	return Marshal(o), nil
+}
+
+// Parse 解析JSON文本，对象解析为*Object，数组为[]interface{}，数字为json.Number
+func Parse(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	value, err := decodeValue(decoder)
+	if err != nil {
+		return nil, ErrInvalidJSON
+	}
+	// 不允许多余的内容
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, ErrInvalidJSON
+	}
+	return value, nil
+}
+
+// FromValue 将Go值转换为文档节点
+// string和[]byte视为JSON文本，其他值先按encoding/json编码
+func FromValue(value interface{}) (interface{}, error) {
+	var data []byte
+	switch v := value.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("rejson: encode value: %w", err)
+		}
+		data = encoded
+	}
+	return Parse(data)
+}
+
+// decodeValue 从Token流中读取一个完整的值
+func decodeValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			object := NewObject()
+			for decoder.More() {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, ok := keyToken.(string)
+				if !ok {
+					return nil, ErrInvalidJSON
+				}
+				value, err := decodeValue(decoder)
+				if err != nil {
+					return nil, err
+				}
+				object.Set(key, value)
+			}
+			if _, err := decoder.Token(); err != nil {
+				return nil, err
+			}
+			return object, nil
+		case '[':
+			array := make([]interface{}, 0)
+			for decoder.More() {
+				value, err := decodeValue(decoder)
+				if err != nil {
+					return nil, err
+				}
+				array = append(array, value)
+			}
+			if _, err := decoder.Token(); err != nil {
+				return nil, err
+			}
+			return array, nil
+		}
+		return nil, ErrInvalidJSON
+	default:
+		return t, nil
+	}
+}
+
+// Marshal 生成紧凑的JSON文本，与JSON.GET默认输出一致
+func Marshal(value interface{}) []byte {
+	var buf bytes.Buffer
+	writeValue(&buf, value)
+	return buf.Bytes()
+}
+
+func writeValue(buf *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		buf.WriteString(v.String())
+	case string:
+		writeString(buf, v)
+	case *Object:
+		buf.WriteByte('{')
+		for i, key := range v.keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeString(buf, key)
+			buf.WriteByte(':')
+			writeValue(buf, v.values[key])
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeValue(buf, item)
+		}
+		buf.WriteByte(']')
+	default:
+		// 文档之外的值按encoding/json处理
+		data, err := json.Marshal(v)
+		if err != nil {
+			buf.WriteString("null")
+			return
+		}
+		buf.Write(data)
+	}
+}
+
+// writeString 写入字符串，不转义HTML字符
+func writeString(buf *bytes.Buffer, s string) {
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	buf.Write(bytes.TrimSuffix(out.Bytes(), []byte("\n")))
+}
+
+// Type 返回JSON.TYPE使用的类型名
+func Type(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case *Object:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	return "unknown"
+}
+
+// IncrBy 数字加上增量，两者都是整数时结果仍为整数
+func IncrBy(value json.Number, delta float64) (json.Number, error) {
+	if i, err := value.Int64(); err == nil && delta == float64(int64(delta)) {
+		sum := i + int64(delta)
+		// 溢出时按浮点数计算
+		if (int64(delta) >= 0) == (sum >= i) {
+			return json.Number(strconv.FormatInt(sum, 10)), nil
+		}
+	}
+
+	f, err := value.Float64()
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(f + delta)
+	if err != nil {
+		return "", fmt.Errorf("rejson: result is not a finite number")
+	}
+	return json.Number(data), nil
+}
@@ -0,0 +1,325 @@
+package rejson
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Path 解析后的路径
+// 以$开头的为JSONPath，返回所有匹配；其他为旧版路径（如.a.b[0]），最多匹配一个值
+type Path struct {
+	raw       string
+	legacy    bool
+	selectors []selector
+}
+
+// selector 路径中的一段
+type selector struct {
+	recursive bool
+	wildcard  bool
+	names     []string
+	indexes   []int
+}
+
+// Match 路径匹配到的值，Steps为从根到该值的键（string）或下标（int）
+type Match struct {
+	Value interface{}
+	Steps []interface{}
+}
+
+// ParsePath 解析路径，空路径与"."表示根
+func ParsePath(path string) (*Path, error) {
+	p := &Path{raw: path}
+
+	expr := path
+	switch {
+	case strings.HasPrefix(path, "$"):
+		expr = path[1:]
+	case path == "" || path == ".":
+		p.legacy = true
+		expr = ""
+	case strings.HasPrefix(path, ".") || strings.HasPrefix(path, "["):
+		p.legacy = true
+	default:
+		p.legacy = true
+		expr = "." + path
+	}
+
+	for pos := 0; pos < len(expr); {
+		var sel selector
+		switch {
+		case strings.HasPrefix(expr[pos:], ".."):
+			sel.recursive = true
+			pos += 2
+		case expr[pos] == '.':
+			pos++
+		case expr[pos] != '[':
+			return nil, p.syntaxError(pos)
+		}
+
+		if pos >= len(expr) || expr[pos] != '[' {
+			end := pos
+			for end < len(expr) && expr[end] != '.' && expr[end] != '[' {
+				end++
+			}
+			name := expr[pos:end]
+			if name == "" {
+				return nil, p.syntaxError(pos)
+			}
+			if name == "*" {
+				sel.wildcard = true
+			} else {
+				sel.names = []string{name}
+			}
+			pos = end
+			p.selectors = append(p.selectors, sel)
+			continue
+		}
+
+		// 方括号：[*]、[0]、[-1]、[0,2]、['a']、["a","b"]
+		end, err := p.parseBracket(expr, pos, &sel)
+		if err != nil {
+			return nil, err
+		}
+		pos = end
+		p.selectors = append(p.selectors, sel)
+	}
+
+	return p, nil
+}
+
+// parseBracket 解析从pos开始的方括号，返回右括号之后的位置
+func (p *Path) parseBracket(expr string, pos int, sel *selector) (int, error) {
+	i := pos + 1
+	for {
+		for i < len(expr) && expr[i] == ' ' {
+			i++
+		}
+		if i >= len(expr) {
+			return 0, p.syntaxError(i)
+		}
+
+		switch c := expr[i]; {
+		case c == '*':
+			sel.wildcard = true
+			i++
+		case c == '\'' || c == '"':
+			end := i + 1
+			for end < len(expr) && expr[end] != c {
+				if expr[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expr) {
+				return 0, p.syntaxError(i)
+			}
+			name := expr[i+1 : end]
+			if c == '"' {
+				unquoted, err := strconv.Unquote(expr[i : end+1])
+				if err != nil {
+					return 0, p.syntaxError(i)
+				}
+				name = unquoted
+			} else {
+				name = strings.ReplaceAll(name, `\'`, `'`)
+			}
+			sel.names = append(sel.names, name)
+			i = end + 1
+		default:
+			end := i
+			for end < len(expr) && (expr[end] == '-' || expr[end] >= '0' && expr[end] <= '9') {
+				end++
+			}
+			index, err := strconv.Atoi(expr[i:end])
+			if err != nil {
+				return 0, p.syntaxError(i)
+			}
+			sel.indexes = append(sel.indexes, index)
+			i = end
+		}
+
+		for i < len(expr) && expr[i] == ' ' {
+			i++
+		}
+		if i >= len(expr) {
+			return 0, p.syntaxError(i)
+		}
+		switch expr[i] {
+		case ']':
+			if sel.wildcard && (len(sel.names) > 0 || len(sel.indexes) > 0) {
+				return 0, p.syntaxError(i)
+			}
+			return i + 1, nil
+		case ',':
+			i++
+		default:
+			return 0, p.syntaxError(i)
+		}
+	}
+}
+
+func (p *Path) syntaxError(pos int) error {
+	return fmt.Errorf("invalid JSON path '%s' at position %d", p.raw, pos)
+}
+
+// String 原始路径
+func (p *Path) String() string {
+	return p.raw
+}
+
+// Legacy 是否为旧版路径
+func (p *Path) Legacy() bool {
+	return p.legacy
+}
+
+// IsRoot 是否指向根
+func (p *Path) IsRoot() bool {
+	return len(p.selectors) == 0
+}
+
+// Select 返回所有匹配，按文档顺序排列；旧版路径最多返回一个
+func (p *Path) Select(root interface{}) []Match {
+	matches := selectAll(root, p.selectors)
+	if p.legacy && len(matches) > 1 {
+		matches = matches[:1]
+	}
+	return matches
+}
+
+// Parents 路径最后一段为单个键名时，返回其父节点中的对象和该键名
+// 用于JSON.SET创建新的键
+func (p *Path) Parents(root interface{}) ([]Match, string, bool) {
+	if len(p.selectors) == 0 {
+		return nil, "", false
+	}
+	last := p.selectors[len(p.selectors)-1]
+	if last.recursive || last.wildcard || len(last.names) != 1 || len(last.indexes) != 0 {
+		return nil, "", false
+	}
+
+	var parents []Match
+	for _, m := range selectAll(root, p.selectors[:len(p.selectors)-1]) {
+		if _, ok := m.Value.(*Object); ok {
+			parents = append(parents, m)
+		}
+	}
+	if p.legacy && len(parents) > 1 {
+		parents = parents[:1]
+	}
+	return parents, last.names[0], true
+}
+
+func selectAll(root interface{}, selectors []selector) []Match {
+	current := []Match{{Value: root}}
+	for _, sel := range selectors {
+		var next []Match
+		for _, m := range current {
+			if sel.recursive {
+				walk(m, func(node Match) {
+					next = append(next, sel.children(node)...)
+				})
+			} else {
+				next = append(next, sel.children(m)...)
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+// walk 先序遍历节点及其所有后代
+func walk(m Match, fn func(Match)) {
+	fn(m)
+	switch v := m.Value.(type) {
+	case *Object:
+		for _, key := range v.keys {
+			walk(Match{Value: v.values[key], Steps: appendStep(m.Steps, key)}, fn)
+		}
+	case []interface{}:
+		for i, item := range v {
+			walk(Match{Value: item, Steps: appendStep(m.Steps, i)}, fn)
+		}
+	}
+}
+
+// children 返回节点中被选中的子节点
+func (s selector) children(m Match) []Match {
+	var result []Match
+	switch v := m.Value.(type) {
+	case *Object:
+		if s.wildcard {
+			for _, key := range v.keys {
+				result = append(result, Match{Value: v.values[key], Steps: appendStep(m.Steps, key)})
+			}
+		}
+		for _, name := range s.names {
+			if value, ok := v.values[name]; ok {
+				result = append(result, Match{Value: value, Steps: appendStep(m.Steps, name)})
+			}
+		}
+	case []interface{}:
+		if s.wildcard {
+			for i, item := range v {
+				result = append(result, Match{Value: item, Steps: appendStep(m.Steps, i)})
+			}
+		}
+		for _, index := range s.indexes {
+			if index < 0 {
+				index += len(v)
+			}
+			if index >= 0 && index < len(v) {
+				result = append(result, Match{Value: v[index], Steps: appendStep(m.Steps, index)})
+			}
+		}
+	}
+	return result
+}
+
+func appendStep(steps []interface{}, step interface{}) []interface{} {
+	return append(steps[:len(steps):len(steps)], step)
+}
+
+// Replace 将Steps指向的值替换为value，返回新的根
+func Replace(root interface{}, steps []interface{}, value interface{}) interface{} {
+	if len(steps) == 0 {
+		return value
+	}
+	switch v := root.(type) {
+	case *Object:
+		key := steps[0].(string)
+		child, _ := v.Get(key)
+		v.Set(key, Replace(child, steps[1:], value))
+	case []interface{}:
+		index := steps[0].(int)
+		v[index] = Replace(v[index], steps[1:], value)
+	}
+	return root
+}
+
+// Remove 删除Steps指向的值，返回新的根；删除根时返回nil和true
+// 同一数组中删除多个元素时，调用方需按下标从大到小的顺序删除
+func Remove(root interface{}, steps []interface{}) (interface{}, bool) {
+	if len(steps) == 0 {
+		return nil, true
+	}
+	switch v := root.(type) {
+	case *Object:
+		key := steps[0].(string)
+		if len(steps) == 1 {
+			v.Delete(key)
+			return root, false
+		}
+		child, _ := v.Get(key)
+		child, _ = Remove(child, steps[1:])
+		v.Set(key, child)
+	case []interface{}:
+		index := steps[0].(int)
+		if len(steps) == 1 {
+			return append(v[:index:index], v[index+1:]...), false
+		}
+		v[index], _ = Remove(v[index], steps[1:])
+	}
+	return root, false
+}
@@ -0,0 +1,125 @@
+package rejson
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const storeJSON = `{"store":{"book":[{"title":"A","price":8.95},{"title":"B","price":12}],"bicycle":{"color":"red","price":19.95}},"name":"<shop>"}`
+
+func mustParse(t *testing.T, data string) interface{} {
+	t.Helper()
+	doc, err := Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return doc
+}
+
+func selectJSON(t *testing.T, doc interface{}, path string) string {
+	t.Helper()
+	p, err := ParsePath(path)
+	if err != nil {
+		t.Fatalf("ParsePath(%q) failed: %v", path, err)
+	}
+	values := make([]interface{}, 0)
+	for _, m := range p.Select(doc) {
+		values = append(values, m.Value)
+	}
+	return string(Marshal(values))
+}
+
+func TestParseAndMarshal_PreservesOrder(t *testing.T) {
+	doc := mustParse(t, storeJSON)
+	if got := string(Marshal(doc)); got != storeJSON {
+		t.Errorf("Round trip mismatch:\n got %s\nwant %s", got, storeJSON)
+	}
+	if data, _ := json.Marshal(mustParse(t, `{"z":1,"a":{"y":2,"b":3}}`)); string(data) != `{"z":1,"a":{"y":2,"b":3}}` {
+		t.Errorf("encoding/json lost key order: %s", data)
+	}
+
+	if _, err := Parse([]byte(`{"a":1} x`)); err == nil {
+		t.Error("Expected error for trailing content")
+	}
+	if _, err := Parse([]byte(`{"a":`)); err == nil {
+		t.Error("Expected error for truncated JSON")
+	}
+}
+
+func TestPath_Select(t *testing.T) {
+	doc := mustParse(t, storeJSON)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"$", "[" + storeJSON + "]"},
+		{".", "[" + storeJSON + "]"},
+		{"$.store.book[*].title", `["A","B"]`},
+		{"$..price", `[8.95,12,19.95]`},
+		{"$.store.book[-1].title", `["B"]`},
+		{"$.store.book[0,1].price", `[8.95,12]`},
+		{"$['store']['bicycle'].color", `["red"]`},
+		{"$.store.*.color", `["red"]`},
+		{"$.missing", `[]`},
+		{".store.bicycle.color", `["red"]`},
+		{"store.book[1].price", `[12]`},
+		{"..price", `[8.95]`},
+	}
+	for _, tt := range tests {
+		if got := selectJSON(t, doc, tt.path); got != tt.want {
+			t.Errorf("Select(%q) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+
+	for _, path := range []string{"$.", "$[", "$[1", "$['a]", "$a", "$.a[?(@.b)]"} {
+		if _, err := ParsePath(path); err == nil {
+			t.Errorf("Expected error for path %q", path)
+		}
+	}
+}
+
+func TestReplaceAndRemove(t *testing.T) {
+	doc := mustParse(t, storeJSON)
+
+	p, _ := ParsePath("$..price")
+	for _, m := range p.Select(doc) {
+		doc = Replace(doc, m.Steps, json.Number("1"))
+	}
+	if got := selectJSON(t, doc, "$..price"); got != `[1,1,1]` {
+		t.Errorf("Unexpected prices after replace: %s", got)
+	}
+
+	p, _ = ParsePath("$.store.book[*]")
+	matches := p.Select(doc)
+	for i := len(matches) - 1; i >= 0; i-- {
+		doc, _ = Remove(doc, matches[i].Steps)
+	}
+	if got := selectJSON(t, doc, "$.store.book"); got != `[[]]` {
+		t.Errorf("Unexpected books after remove: %s", got)
+	}
+
+	if _, root := Remove(doc, nil); !root {
+		t.Error("Expected removing empty steps to remove the root")
+	}
+}
+
+func TestTypeAndIncrBy(t *testing.T) {
+	doc := mustParse(t, `[1,1.5,"s",true,null,{},[]]`)
+	want := []string{"integer", "number", "string", "boolean", "null", "object", "array"}
+	for i, value := range doc.([]interface{}) {
+		if got := Type(value); got != want[i] {
+			t.Errorf("Type(%v) = %s, want %s", value, got, want[i])
+		}
+	}
+
+	if n, _ := IncrBy("2", 3); n != "5" {
+		t.Errorf("Expected integer result 5, got %s", n)
+	}
+	if n, _ := IncrBy("2", 0.5); n != "2.5" {
+		t.Errorf("Expected 2.5, got %s", n)
+	}
+	if n, _ := IncrBy("9223372036854775807", 1); n != "9223372036854776000" {
+		t.Errorf("Expected overflow to fall back to float, got %s", n)
+	}
+}
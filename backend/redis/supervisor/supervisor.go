@@ -0,0 +1,314 @@
+package supervisor
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/devtoolbox/redis/auth"
+	"github.com/devtoolbox/redis/config"
+	"github.com/devtoolbox/redis/pool"
+)
+
+// 默认的维护间隔
+const (
+	defaultSweepInterval       = time.Minute
+	defaultIdleTimeout         = 30 * time.Minute
+	defaultHealthCheckInterval = 30 * time.Second
+	defaultMaxBackoff          = time.Minute
+	defaultKeyRotation         = 7 * 24 * time.Hour
+	defaultEncryptionRotation  = 30 * 24 * time.Hour
+	defaultEncryptionGrace     = 24 * time.Hour
+)
+
+// minBackoff 不健康连接第一次重试前的等待时间，之后每次翻倍直到MaxBackoff
+// 检查间隔更短时使用检查间隔
+const minBackoff = time.Second
+
+// healthCheckTimeout 单次健康检查的超时时间
+const healthCheckTimeout = 5 * time.Second
+
+// reconnectAfter 连续失败达到该次数后重建客户端，之后每次按退避时间重试时都重建一次
+const reconnectAfter = 3
+
+// Config 后台维护的时间间隔，为0的字段使用默认值，为负数时关闭对应的任务
+type Config struct {
+	SweepInterval       time.Duration // 清理过期Token与空闲连接的间隔
+	IdleTimeout         time.Duration // 没有被Token引用的连接空闲超过该时间后关闭
+	HealthCheckInterval time.Duration // 健康连接的检查间隔
+	MaxBackoff          time.Duration // 不健康连接重试的最长间隔
+	KeyRotation         time.Duration // Token签名密钥的轮换周期
+	EncryptionRotation  time.Duration // 自动生成的RSA密钥的轮换周期
+	EncryptionGrace     time.Duration // RSA密钥被替换后仍然可以解密的时间
+}
+
+// EncryptionKeys 可以轮换的RSA密钥
+type EncryptionKeys interface {
+	CurrentEncryptionKey() (string, time.Time)
+	RotateEncryptionKey() (string, error)
+	PruneEncryptionKeys(grace time.Duration) error
+}
+
+// ConfigFromSecurity 从安全配置中读取维护间隔（秒）
+func ConfigFromSecurity(security *config.Security) Config {
+	if security == nil {
+		return Config{}
+	}
+	seconds := func(n int) time.Duration {
+		return time.Duration(n) * time.Second
+	}
+	return Config{
+		SweepInterval:       seconds(security.TokenSweepInterval),
+		IdleTimeout:         seconds(security.IdleTimeout),
+		HealthCheckInterval: seconds(security.HealthCheckInterval),
+		MaxBackoff:          seconds(security.ReconnectMaxBackoff),
+		KeyRotation:         seconds(security.TokenKeyRotation),
+		EncryptionRotation:  seconds(security.EncryptionKeyRotation),
+		EncryptionGrace:     seconds(security.EncryptionKeyGrace),
+	}
+}
+
+func (c Config) withDefaults() Config {
+	if c.SweepInterval == 0 {
+		c.SweepInterval = defaultSweepInterval
+	}
+	if c.IdleTimeout == 0 {
+		c.IdleTimeout = defaultIdleTimeout
+	}
+	if c.HealthCheckInterval == 0 {
+		c.HealthCheckInterval = defaultHealthCheckInterval
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = defaultMaxBackoff
+	}
+	if c.KeyRotation == 0 {
+		c.KeyRotation = defaultKeyRotation
+	}
+	if c.EncryptionRotation == 0 {
+		c.EncryptionRotation = defaultEncryptionRotation
+	}
+	if c.EncryptionGrace <= 0 {
+		c.EncryptionGrace = defaultEncryptionGrace
+	}
+	return c
+}
+
+// Supervisor 定期清理过期Token、关闭空闲连接并检查连接健康状况
+// 不健康的连接按指数退避重试，连续失败多次后重建客户端，恢复后重新按正常间隔检查
+type Supervisor struct {
+	pool   *pool.ConnectionPool
+	tokens *auth.TokenManager
+	keys   EncryptionKeys
+	config Config
+
+	mutex     sync.Mutex
+	schedules map[string]*schedule // 连接ID -> 健康检查计划
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// schedule 单个连接的健康检查计划
+type schedule struct {
+	next      time.Time
+	unhealthy bool
+}
+
+// New 创建后台维护任务，需要调用Start启动
+func New(connectionPool *pool.ConnectionPool, tokens *auth.TokenManager, config Config) *Supervisor {
+	return &Supervisor{
+		pool:      connectionPool,
+		tokens:    tokens,
+		config:    config.withDefaults(),
+		schedules: make(map[string]*schedule),
+	}
+}
+
+// SetEncryptionKeys 设置需要定期轮换的RSA密钥，需在Start之前调用
+func (s *Supervisor) SetEncryptionKeys(keys EncryptionKeys) {
+	s.keys = keys
+}
+
+// Start 在后台运行维护任务
+func (s *Supervisor) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	var wg sync.WaitGroup
+	if s.config.SweepInterval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.every(ctx, s.config.SweepInterval, s.Sweep)
+		}()
+	}
+	if s.config.HealthCheckInterval > 0 {
+		// 以最短的退避时间为粒度轮询，使重试不必等到下一个检查周期
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.every(ctx, s.minBackoff(), func() { s.CheckHealth(ctx) })
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(s.done)
+	}()
+}
+
+// Stop 停止维护任务并等待正在进行的检查结束
+func (s *Supervisor) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	<-s.done
+}
+
+func (s *Supervisor) every(ctx context.Context, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fn()
+		}
+	}
+}
+
+// Sweep 清理过期Token并释放其连接的引用，到期时轮换签名密钥与RSA密钥，关闭没有被引用的空闲连接
+func (s *Supervisor) Sweep() {
+	for _, tokenInfo := range s.tokens.CleanupExpiredTokens() {
+		s.pool.Release(tokenInfo.ConnectionID)
+	}
+
+	if _, createdAt := s.tokens.CurrentKey(); s.config.KeyRotation > 0 && time.Since(createdAt) > s.config.KeyRotation {
+		if id, err := s.tokens.RotateKey(); err != nil {
+			log.Printf("Failed to rotate token signing key: %v", err)
+		} else {
+			log.Printf("Rotated token signing key: %s", id)
+		}
+	}
+	s.rotateEncryptionKey()
+
+	if s.config.IdleTimeout < 0 {
+		return
+	}
+	for _, id := range s.pool.CleanupIdleConnections(s.config.IdleTimeout) {
+		log.Printf("Closed idle Redis connection: %s", id)
+	}
+}
+
+// rotateEncryptionKey 到期时轮换RSA密钥，并删除超过宽限期的旧密钥
+// 私钥来自配置文件时不轮换
+func (s *Supervisor) rotateEncryptionKey() {
+	if s.keys == nil {
+		return
+	}
+	if _, createdAt := s.keys.CurrentEncryptionKey(); s.config.EncryptionRotation > 0 && !createdAt.IsZero() && time.Since(createdAt) > s.config.EncryptionRotation {
+		if id, err := s.keys.RotateEncryptionKey(); err != nil {
+			log.Printf("Failed to rotate RSA key: %v", err)
+		} else {
+			log.Printf("Rotated RSA key: %s", id)
+		}
+	}
+	if err := s.keys.PruneEncryptionKeys(s.config.EncryptionGrace); err != nil {
+		log.Printf("Failed to prune RSA keys: %v", err)
+	}
+}
+
+// CheckHealth 并发检查到期的连接，更新下一次检查的时间
+func (s *Supervisor) CheckHealth(ctx context.Context) {
+	now := time.Now()
+	var due []string
+
+	s.mutex.Lock()
+	live := make(map[string]bool)
+	for _, conn := range s.pool.ListConnections() {
+		live[conn.ID] = true
+		plan, scheduled := s.schedules[conn.ID]
+		if !scheduled {
+			// 新连接在创建时已经检查过
+			s.schedules[conn.ID] = &schedule{next: conn.CreatedAt.Add(s.config.HealthCheckInterval)}
+			continue
+		}
+		if !now.Before(plan.next) {
+			due = append(due, conn.ID)
+		}
+	}
+	for id := range s.schedules {
+		if !live[id] {
+			delete(s.schedules, id)
+		}
+	}
+	s.mutex.Unlock()
+
+	var wg sync.WaitGroup
+	for _, id := range due {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			s.check(ctx, id)
+		}(id)
+	}
+	wg.Wait()
+}
+
+// check 检查单个连接，失败时按连续失败次数计算退避时间
+// 连续失败reconnectAfter次后重建客户端并立即重新检查
+func (s *Supervisor) check(ctx context.Context, id string) {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	health, err := s.pool.CheckHealth(ctx, id)
+	if err != nil && health.Failures >= reconnectAfter {
+		if s.pool.Reconnect(id) == nil {
+			health, err = s.pool.CheckHealth(ctx, id)
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	plan, tracked := s.schedules[id]
+	if !tracked {
+		return
+	}
+	if err != nil {
+		if !plan.unhealthy {
+			log.Printf("Redis connection %s is unhealthy: %v", id, err)
+		}
+		plan.unhealthy = true
+		plan.next = time.Now().Add(s.backoff(health.Failures))
+		return
+	}
+	if plan.unhealthy {
+		log.Printf("Redis connection %s recovered", id)
+	}
+	plan.unhealthy = false
+	plan.next = time.Now().Add(s.config.HealthCheckInterval)
+}
+
+func (s *Supervisor) minBackoff() time.Duration {
+	if s.config.HealthCheckInterval < minBackoff {
+		return s.config.HealthCheckInterval
+	}
+	return minBackoff
+}
+
+// backoff 第n次连续失败后的等待时间
+func (s *Supervisor) backoff(failures int) time.Duration {
+	delay := s.minBackoff()
+	for i := 1; i < failures && delay < s.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > s.config.MaxBackoff {
+		delay = s.config.MaxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,282 @@
+package supervisor
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/devtoolbox/redis/auth"
+	configpkg "github.com/devtoolbox/redis/config"
+	"github.com/devtoolbox/redis/mock"
+	"github.com/devtoolbox/redis/pool"
+)
+
+func TestSupervisor_Sweep(t *testing.T) {
+	connectionPool := pool.NewConnectionPool(10)
+	defer connectionPool.Close()
+	connectionPool.SetMockMode(true)
+	tokens := auth.NewTokenManager(time.Hour)
+
+	for i, id := range []string{"idle", "held"} {
+		if _, err := connectionPool.CreateConnection(id, "localhost", 6379, i, "", ""); err != nil {
+			t.Fatalf("CreateConnection: %v", err)
+		}
+	}
+	connectionPool.Release("idle")
+	heldToken, _ := tokens.GenerateToken(auth.Session{ConnectionID: "held"})
+
+	s := New(connectionPool, tokens, Config{IdleTimeout: 20 * time.Millisecond})
+	time.Sleep(30 * time.Millisecond)
+
+	if _, err := connectionPool.CreateConnection("busy", "localhost", 6379, 2, "", ""); err != nil {
+		t.Fatalf("CreateConnection: %v", err)
+	}
+	busyToken, _ := tokens.GenerateToken(auth.Session{ConnectionID: "busy"})
+
+	s.Sweep()
+
+	if _, err := connectionPool.GetConnectionInfo("idle"); err == nil {
+		t.Error("Expected unreferenced idle connection to be closed")
+	}
+	// 仍被Token引用的空闲连接保持打开
+	if _, err := connectionPool.GetConnectionInfo("held"); err != nil {
+		t.Errorf("Expected referenced idle connection to stay open: %v", err)
+	}
+	if _, err := tokens.ValidateToken(heldToken.Token); err != nil {
+		t.Errorf("Expected token of referenced connection to stay valid: %v", err)
+	}
+	if _, err := connectionPool.GetConnectionInfo("busy"); err != nil {
+		t.Errorf("Expected busy connection to stay open: %v", err)
+	}
+	if _, err := tokens.ValidateToken(busyToken.Token); err != nil {
+		t.Errorf("Expected token of busy connection to stay valid: %v", err)
+	}
+
+	// 过期的Token被清理
+	shortTokens := auth.NewTokenManager(time.Millisecond)
+	shortTokens.GenerateToken(auth.Session{ConnectionID: "busy"})
+	time.Sleep(5 * time.Millisecond)
+	New(connectionPool, shortTokens, Config{IdleTimeout: -1}).Sweep()
+	if count := shortTokens.GetTokenCount(); count != 0 {
+		t.Errorf("Expected expired tokens to be removed, got %d", count)
+	}
+	if info, err := connectionPool.GetConnectionInfo("busy"); err != nil {
+		t.Errorf("Expected idle reaping to be disabled: %v", err)
+	} else if info.Refs != 0 {
+		t.Errorf("Expected expired token to release its connection, got %d refs", info.Refs)
+	}
+
+	// 签名密钥超过轮换周期后被替换
+	oldKey, _ := tokens.CurrentKey()
+	New(connectionPool, tokens, Config{IdleTimeout: -1, KeyRotation: time.Hour}).Sweep()
+	if key, _ := tokens.CurrentKey(); key != oldKey {
+		t.Error("Expected signing key to be kept before the rotation period")
+	}
+	New(connectionPool, tokens, Config{IdleTimeout: -1, KeyRotation: time.Nanosecond}).Sweep()
+	if key, _ := tokens.CurrentKey(); key == oldKey {
+		t.Error("Expected signing key to be rotated")
+	}
+	if _, err := tokens.ValidateToken(busyToken.Token); err != nil {
+		t.Errorf("Expected token signed with the previous key to stay valid: %v", err)
+	}
+}
+
+func TestSupervisor_HealthCheckAndRecovery(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	backend := mock.NewRedisMock()
+	server := mock.NewServer(backend)
+	go server.Serve(listener)
+
+	host, portText, _ := net.SplitHostPort(addr)
+	port, _ := strconv.Atoi(portText)
+	connectionPool := pool.NewConnectionPool(10)
+	defer connectionPool.Close()
+	if _, err := connectionPool.CreateConnectionWithOptions("c1", host, port, pool.ConnectOptions{MaxRetries: -1}); err != nil {
+		t.Fatalf("CreateConnectionWithOptions: %v", err)
+	}
+
+	s := New(connectionPool, auth.NewTokenManager(time.Hour), Config{
+		HealthCheckInterval: 20 * time.Millisecond,
+		MaxBackoff:          40 * time.Millisecond,
+	})
+	s.Start()
+	defer s.Stop()
+
+	waitFor := func(what string, cond func(pool.Health) bool) pool.Health {
+		t.Helper()
+		deadline := time.Now().Add(3 * time.Second)
+		for {
+			info, err := connectionPool.GetConnectionInfo("c1")
+			if err != nil {
+				t.Fatalf("GetConnectionInfo: %v", err)
+			}
+			if cond(info.Health) {
+				return info.Health
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Timed out waiting for %s: %+v", what, info.Health)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	healthy := waitFor("a health check", func(h pool.Health) bool {
+		return h.Healthy && h.LatencyMs > 0
+	})
+
+	// 服务端停止后连接被标记为不健康，连续失败次数递增
+	server.Close()
+	unhealthy := waitFor("unhealthy", func(h pool.Health) bool {
+		return !h.Healthy && h.Failures >= 2
+	})
+	if unhealthy.LastError == "" || !unhealthy.CheckedAt.After(healthy.CheckedAt) {
+		t.Errorf("Unexpected unhealthy state: %+v", unhealthy)
+	}
+
+	// 服务端在原地址恢复后连接重新变为健康
+	listener, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Skipf("Cannot listen on %s again: %v", addr, err)
+	}
+	server = mock.NewServer(backend)
+	go server.Serve(listener)
+	defer server.Close()
+
+	waitFor("recovery", func(h pool.Health) bool {
+		return h.Healthy && h.Failures == 0 && h.LastError == ""
+	})
+}
+
+func TestSupervisor_RebuildsClient(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	server := mock.NewServer(mock.NewRedisMock())
+	go server.Serve(listener)
+	defer server.Close()
+
+	host, portText, _ := net.SplitHostPort(listener.Addr().String())
+	port, _ := strconv.Atoi(portText)
+	connectionPool := pool.NewConnectionPool(10)
+	defer connectionPool.Close()
+	conn, err := connectionPool.CreateConnectionWithOptions("c1", host, port, pool.ConnectOptions{MaxRetries: -1})
+	if err != nil {
+		t.Fatalf("CreateConnectionWithOptions: %v", err)
+	}
+	// 客户端被关闭后服务端仍然可用，只有重建客户端才能恢复
+	conn.Client.Close()
+
+	s := New(connectionPool, auth.NewTokenManager(time.Hour), Config{
+		HealthCheckInterval: 20 * time.Millisecond,
+		MaxBackoff:          40 * time.Millisecond,
+	})
+	s.Start()
+	defer s.Stop()
+
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		info, err := connectionPool.GetConnectionInfo("c1")
+		if err != nil {
+			t.Fatalf("GetConnectionInfo: %v", err)
+		}
+		if info.Health.Healthy && info.Health.LatencyMs > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for the client to be rebuilt: %+v", info.Health)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := connectionPool.Ping(context.Background(), "c1"); err != nil {
+		t.Errorf("Expected rebuilt client to respond: %v", err)
+	}
+}
+
+func TestSupervisor_Backoff(t *testing.T) {
+	s := New(nil, nil, Config{MaxBackoff: 10 * time.Second})
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for i, want := range expected {
+		if got := s.backoff(i + 1); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, want)
+		}
+	}
+
+	fast := New(nil, nil, Config{HealthCheckInterval: 100 * time.Millisecond, MaxBackoff: time.Second})
+	if got := fast.backoff(1); got != 100*time.Millisecond {
+		t.Errorf("Expected first retry after the check interval, got %v", got)
+	}
+}
+
+func TestConfigFromSecurity(t *testing.T) {
+	config := ConfigFromSecurity(nil).withDefaults()
+	if config.SweepInterval != defaultSweepInterval || config.IdleTimeout != defaultIdleTimeout ||
+		config.HealthCheckInterval != defaultHealthCheckInterval || config.MaxBackoff != defaultMaxBackoff ||
+		config.KeyRotation != defaultKeyRotation {
+		t.Errorf("Unexpected defaults: %+v", config)
+	}
+
+	config = ConfigFromSecurity(&configpkg.Security{TokenSweepInterval: 5, IdleTimeout: -1, HealthCheckInterval: 10, TokenKeyRotation: -1}).withDefaults()
+	if config.SweepInterval != 5*time.Second || config.IdleTimeout >= 0 ||
+		config.HealthCheckInterval != 10*time.Second || config.MaxBackoff != defaultMaxBackoff || config.KeyRotation >= 0 {
+		t.Errorf("Unexpected config: %+v", config)
+	}
+}
+
+// fakeEncryptionKeys 记录轮换与清理的调用
+type fakeEncryptionKeys struct {
+	createdAt time.Time
+	rotations int
+	graces    []time.Duration
+}
+
+func (f *fakeEncryptionKeys) CurrentEncryptionKey() (string, time.Time) {
+	return "key", f.createdAt
+}
+
+func (f *fakeEncryptionKeys) RotateEncryptionKey() (string, error) {
+	f.rotations++
+	f.createdAt = time.Now()
+	return "rotated", nil
+}
+
+func (f *fakeEncryptionKeys) PruneEncryptionKeys(grace time.Duration) error {
+	f.graces = append(f.graces, grace)
+	return nil
+}
+
+func TestSupervisor_RotateEncryptionKey(t *testing.T) {
+	connectionPool := pool.NewConnectionPool(1)
+	defer connectionPool.Close()
+	tokens := auth.NewTokenManager(time.Hour)
+
+	keys := &fakeEncryptionKeys{createdAt: time.Now().Add(-2 * time.Hour)}
+	s := New(connectionPool, tokens, Config{IdleTimeout: -1, EncryptionRotation: time.Hour})
+	s.SetEncryptionKeys(keys)
+	s.Sweep()
+	s.Sweep()
+	if keys.rotations != 1 {
+		t.Errorf("Expected one rotation, got %d", keys.rotations)
+	}
+	if len(keys.graces) != 2 || keys.graces[0] != defaultEncryptionGrace {
+		t.Errorf("Expected old keys to be pruned with the default grace, got %v", keys.graces)
+	}
+
+	// 私钥来自配置文件（创建时间为零）或关闭轮换时不轮换
+	static := &fakeEncryptionKeys{}
+	s.SetEncryptionKeys(static)
+	s.Sweep()
+	disabled := &fakeEncryptionKeys{createdAt: time.Now().Add(-2 * time.Hour)}
+	s = New(connectionPool, tokens, Config{IdleTimeout: -1, EncryptionRotation: -1})
+	s.SetEncryptionKeys(disabled)
+	s.Sweep()
+	if static.rotations != 0 || disabled.rotations != 0 {
+		t.Errorf("Expected no rotation, got %d and %d", static.rotations, disabled.rotations)
+	}
+}
@@ -0,0 +1,220 @@
+package undo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/devtoolbox/redis/mock"
+	"github.com/devtoolbox/redis/rediserr"
+)
+
+// 默认的日志容量
+const (
+	DefaultMaxChanges = 100            // 每个连接保留的修改数量
+	DefaultMaxAge     = 24 * time.Hour // 修改的保留时间
+)
+
+var (
+	// ErrNotFound 修改不存在或已被淘汰
+	ErrNotFound = errors.New("change not found")
+	// ErrRestored 修改已经撤销过
+	ErrRestored = errors.New("change already restored")
+)
+
+// Snapshot 修改前键的DUMP数据与剩余TTL
+type Snapshot struct {
+	Key     string `json:"key"`
+	Existed bool   `json:"existed"` // 修改前键是否存在，不存在时撤销会删除该键
+	Size    int    `json:"size"`    // DUMP数据的字节数
+	TTL     int64  `json:"ttl"`     // 剩余毫秒数，-1表示不过期
+	Payload string `json:"-"`
+}
+
+// Change 一次可以撤销的修改
+type Change struct {
+	ID           uint64     `json:"id"`
+	Time         time.Time  `json:"time"`
+	ConnectionID string     `json:"connectionId"`
+	SessionID    string     `json:"sessionId,omitempty"`
+	Command      string     `json:"command"`
+	Keys         []string   `json:"keys"`
+	Snapshots    []Snapshot `json:"snapshots"`
+	RestoredAt   *time.Time `json:"restoredAt,omitempty"`
+}
+
+// Journal 按连接保存最近的修改，超过maxChanges或maxAge的修改被淘汰
+type Journal struct {
+	maxChanges int
+	maxAge     time.Duration
+	nextID     uint64
+	changes    map[string][]*Change // 按时间从旧到新排列
+	mutex      sync.Mutex
+}
+
+// NewJournal 创建撤销日志，参数不大于0时使用默认值
+func NewJournal(maxChanges int, maxAge time.Duration) *Journal {
+	if maxChanges <= 0 {
+		maxChanges = DefaultMaxChanges
+	}
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+	return &Journal{
+		maxChanges: maxChanges,
+		maxAge:     maxAge,
+		changes:    make(map[string][]*Change),
+	}
+}
+
+// Record 记录一次修改，分配ID与时间并返回记录后的修改
+func (j *Journal) Record(change Change) Change {
+	change.Time = time.Now()
+	change.RestoredAt = nil
+	if change.Keys == nil {
+		for _, snapshot := range change.Snapshots {
+			change.Keys = append(change.Keys, snapshot.Key)
+		}
+	}
+
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.nextID++
+	change.ID = j.nextID
+	j.prune(change.Time)
+
+	changes := append(j.changes[change.ConnectionID], &change)
+	if len(changes) > j.maxChanges {
+		changes = changes[len(changes)-j.maxChanges:]
+	}
+	j.changes[change.ConnectionID] = changes
+	return change
+}
+
+// List 返回连接最近的修改，按时间从新到旧排列
+func (j *Journal) List(connectionID string) []Change {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	j.prune(time.Now())
+	changes := j.changes[connectionID]
+	result := make([]Change, 0, len(changes))
+	for i := len(changes) - 1; i >= 0; i-- {
+		result = append(result, *changes[i])
+	}
+	return result
+}
+
+// Get 返回连接的一次修改
+func (j *Journal) Get(connectionID string, id uint64) (Change, error) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	change := j.find(connectionID, id)
+	if change == nil {
+		return Change{}, ErrNotFound
+	}
+	return *change, nil
+}
+
+// Claim 将修改标记为已撤销并返回，修改已撤销时返回ErrRestored
+// 撤销失败时调用Release，修改可以再次撤销
+func (j *Journal) Claim(connectionID string, id uint64) (Change, error) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	change := j.find(connectionID, id)
+	if change == nil {
+		return Change{}, ErrNotFound
+	}
+	if change.RestoredAt != nil {
+		return *change, ErrRestored
+	}
+	now := time.Now()
+	change.RestoredAt = &now
+	return *change, nil
+}
+
+// Release 取消Claim的标记
+func (j *Journal) Release(connectionID string, id uint64) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+
+	if change := j.find(connectionID, id); change != nil {
+		change.RestoredAt = nil
+	}
+}
+
+// find 查找修改，调用方需持有锁
+func (j *Journal) find(connectionID string, id uint64) *Change {
+	for _, change := range j.changes[connectionID] {
+		if change.ID == id {
+			return change
+		}
+	}
+	return nil
+}
+
+// prune 淘汰超过保留时间的修改，调用方需持有锁
+func (j *Journal) prune(now time.Time) {
+	cutoff := now.Add(-j.maxAge)
+	for connectionID, changes := range j.changes {
+		i := 0
+		for i < len(changes) && changes[i].Time.Before(cutoff) {
+			i++
+		}
+		if i == len(changes) {
+			delete(j.changes, connectionID)
+		} else if i > 0 {
+			j.changes[connectionID] = changes[i:]
+		}
+	}
+}
+
+// Capture 使用DUMP与PTTL保存键的当前状态
+func Capture(ctx context.Context, client mock.RedisInterface, keys ...string) ([]Snapshot, error) {
+	snapshots := make([]Snapshot, 0, len(keys))
+	for _, key := range keys {
+		payload, err := client.Dump(ctx, key).Result()
+		if rediserr.IsNil(err) {
+			snapshots = append(snapshots, Snapshot{Key: key, TTL: -1})
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to dump %q: %w", key, err)
+		}
+		ttl, err := client.PTTL(ctx, key).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ttl of %q: %w", key, err)
+		}
+		snapshot := Snapshot{Key: key, Existed: true, Size: len(payload), TTL: -1, Payload: payload}
+		if ttl > 0 {
+			snapshot.TTL = max(ttl.Milliseconds(), 1)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// Apply 将键恢复为快照中的状态：存在的键用RESTORE REPLACE写回，原本不存在的键被删除
+func Apply(ctx context.Context, client mock.RedisInterface, snapshots []Snapshot) error {
+	for _, snapshot := range snapshots {
+		if !snapshot.Existed {
+			if err := client.Del(ctx, snapshot.Key).Err(); err != nil {
+				return fmt.Errorf("failed to delete %q: %w", snapshot.Key, err)
+			}
+			continue
+		}
+		var ttl time.Duration
+		if snapshot.TTL > 0 {
+			ttl = time.Duration(snapshot.TTL) * time.Millisecond
+		}
+		if err := client.RestoreReplace(ctx, snapshot.Key, ttl, snapshot.Payload).Err(); err != nil {
+			return fmt.Errorf("failed to restore %q: %w", snapshot.Key, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package undo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/devtoolbox/redis/mock"
+)
+
+func TestJournal_Bounded(t *testing.T) {
+	journal := NewJournal(2, time.Hour)
+	for _, key := range []string{"a", "b", "c"} {
+		journal.Record(Change{ConnectionID: "conn", Command: "DEL", Snapshots: []Snapshot{{Key: key}}})
+	}
+	journal.Record(Change{ConnectionID: "other", Command: "DEL"})
+
+	changes := journal.List("conn")
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %d", len(changes))
+	}
+	if changes[0].Keys[0] != "c" || changes[1].Keys[0] != "b" {
+		t.Errorf("Expected newest changes first, got %v and %v", changes[0].Keys, changes[1].Keys)
+	}
+	if _, err := journal.Claim("conn", 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected evicted change to be gone, got %v", err)
+	}
+	if _, err := journal.Claim("other", changes[0].ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected changes to be scoped to their connection, got %v", err)
+	}
+}
+
+func TestJournal_Claim(t *testing.T) {
+	journal := NewJournal(0, 0)
+	change := journal.Record(Change{ConnectionID: "conn", Command: "DEL"})
+
+	if _, err := journal.Claim("conn", change.ID); err != nil {
+		t.Fatalf("Claim failed: %v", err)
+	}
+	if _, err := journal.Claim("conn", change.ID); !errors.Is(err, ErrRestored) {
+		t.Errorf("Expected ErrRestored, got %v", err)
+	}
+	journal.Release("conn", change.ID)
+	if _, err := journal.Claim("conn", change.ID); err != nil {
+		t.Errorf("Expected released change to be claimable, got %v", err)
+	}
+}
+
+func TestCaptureApply(t *testing.T) {
+	client := mock.NewRedisMock()
+	defer client.Close()
+	ctx := context.Background()
+
+	client.HSet(ctx, "hash", "field", "value")
+	client.Set(ctx, "temp", "value", time.Minute)
+
+	snapshots, err := Capture(ctx, client, "hash", "temp", "new")
+	if err != nil {
+		t.Fatalf("Capture failed: %v", err)
+	}
+	if !snapshots[0].Existed || snapshots[0].TTL != -1 {
+		t.Errorf("Expected persistent hash snapshot, got %+v", snapshots[0])
+	}
+	if snapshots[1].TTL <= 0 || snapshots[1].TTL > time.Minute.Milliseconds() {
+		t.Errorf("Expected TTL to be captured, got %d", snapshots[1].TTL)
+	}
+	if snapshots[2].Existed {
+		t.Error("Expected missing key to be recorded as absent")
+	}
+
+	client.Del(ctx, "hash", "temp")
+	client.Set(ctx, "new", "created", 0)
+	if err := Apply(ctx, client, snapshots); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if val, _ := client.HGet(ctx, "hash", "field").Result(); val != "value" {
+		t.Errorf("Expected hash to be restored, got %q", val)
+	}
+	if ttl := client.PTTL(ctx, "temp").Val(); ttl <= 0 {
+		t.Errorf("Expected restored key to keep its TTL, got %v", ttl)
+	}
+	if n := client.Exists(ctx, "new").Val(); n != 0 {
+		t.Error("Expected key created after the snapshot to be removed")
+	}
+}